@@ -0,0 +1,90 @@
+package tgbot
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// AdminNotifier is implemented by senders that can alert the bot admins.
+// Bot modules can type assert their Sender to it to report problems.
+type AdminNotifier interface {
+	NotifyAdmin(level slog.Level, text string, fields ...slog.Attr) error
+}
+
+var _ AdminNotifier = (*Service)(nil)
+
+// NotifyAdmin sends a structured alert to the configured admin chat.
+// It is a no-op if no admin chat is configured.
+func (s *Service) NotifyAdmin(level slog.Level, text string, fields ...slog.Attr) error {
+	if s.cfg.AdminChatID == 0 {
+		return nil
+	}
+
+	if _, err := s.Send(s.cfg.AdminChatID, Message{
+		Text:               formatAdminNotification(s.username, level, text, fields),
+		DisableLinkPreview: true,
+	}); err != nil {
+		return fmt.Errorf("send admin notification: %w", err)
+	}
+
+	return nil
+}
+
+// notifyAdminAsync sends the admin notification in the background, errors are logged
+func (s *Service) notifyAdminAsync(level slog.Level, text string, fields ...slog.Attr) {
+	if s.cfg.AdminChatID == 0 {
+		return
+	}
+
+	go func() {
+		if err := s.NotifyAdmin(level, text, fields...); err != nil {
+			s.logger.Error("failed to notify admin",
+				slog.String("err", err.Error()),
+				slog.String("text", text),
+			)
+		}
+	}()
+}
+
+func formatAdminNotification(username string, level slog.Level, text string, fields []slog.Attr) string {
+	var b strings.Builder
+
+	b.WriteString(levelEmoji(level))
+	b.WriteString(" ")
+	b.WriteString(level.String())
+	if len(username) > 0 {
+		b.WriteString(" · @")
+		b.WriteString(username)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(text)
+
+	if len(fields) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, field := range fields {
+		b.WriteString("\n• ")
+		b.WriteString(field.Key)
+		b.WriteString(": ")
+		b.WriteString(field.Value.String())
+	}
+
+	return b.String()
+}
+
+func levelEmoji(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "🚨"
+	case level >= slog.LevelWarn:
+		return "⚠️"
+	case level >= slog.LevelInfo:
+		return "ℹ️"
+	default:
+		return "🐛"
+	}
+}
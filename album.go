@@ -0,0 +1,180 @@
+package tgbot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	minAlbumSize = 2
+	maxAlbumSize = 10
+)
+
+// Media item types of an album
+const (
+	MediaPhoto    = "photo"
+	MediaVideo    = "video"
+	MediaDocument = "document"
+	MediaAudio    = "audio"
+)
+
+var (
+	ErrAlbumSize  = fmt.Errorf("album must have %d to %d items", minAlbumSize, maxAlbumSize)
+	ErrAlbumMixed = errors.New("documents and audio can't be mixed with other media in an album")
+)
+
+// MediaItem is a single photo, video, document or audio file of an album,
+// set either the URL or file ID, or the data to upload
+type MediaItem struct {
+	Type     string `json:"type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+// SendAlbum sends the album of the message and returns all sent messages,
+// Send only returns the first one
+func (s *Service) SendAlbum(ctx context.Context, chatID int64, msg Message) ([]*models.Message, error) {
+	msg = s.localize(chatID, msg).convertMarkdown()
+
+	var sent []*models.Message
+
+	result := s.pipeline.enqueue(chatID, func() (*models.Message, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		sent, err = s.sendAlbum(ctx, chatID, msg)
+		if len(sent) == 0 {
+			return nil, err
+		}
+
+		return sent[0], err
+	})
+
+	select {
+	case res := <-result:
+		return sent, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Service) sendAlbum(ctx context.Context, chatID int64, msg Message) ([]*models.Message, error) {
+	media, err := msg.albumMedia()
+	if err != nil {
+		return nil, err
+	}
+
+	var replyParams *models.ReplyParameters
+	if msg.ReplyTo > 0 {
+		replyParams = &models.ReplyParameters{
+			ChatID:                   chatID,
+			MessageID:                msg.ReplyTo,
+			AllowSendingWithoutReply: true,
+		}
+	}
+
+	sent, err := s.bot.SendMediaGroup(ctx, &bot.SendMediaGroupParams{
+		ChatID:               chatID,
+		BusinessConnectionID: msg.BusinessConnectionID,
+		MessageThreadID:      msg.ThreadID,
+		Media:                media,
+		ReplyParameters:      replyParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("send media group: %w", err)
+	}
+
+	if len(msg.BusinessConnectionID) == 0 {
+		for _, m := range sent {
+			s.tracker.track(chatID, m)
+		}
+	}
+
+	return sent, nil
+}
+
+// albumMedia converts the album items, the caption goes on the first item so
+// Telegram shows it for the whole album
+func (m Message) albumMedia() ([]models.InputMedia, error) {
+	if len(m.Album) < minAlbumSize || len(m.Album) > maxAlbumSize {
+		return nil, ErrAlbumSize
+	}
+
+	var documents, audio int
+	for _, item := range m.Album {
+		switch item.Type {
+		case MediaDocument:
+			documents++
+		case MediaAudio:
+			audio++
+		}
+	}
+
+	if (documents > 0 && documents != len(m.Album)) || (audio > 0 && audio != len(m.Album)) {
+		return nil, ErrAlbumMixed
+	}
+
+	media := make([]models.InputMedia, 0, len(m.Album))
+
+	for i, item := range m.Album {
+		var caption string
+		var entities []models.MessageEntity
+		var parseMode models.ParseMode
+		if i == 0 {
+			caption = m.escapeText()
+			entities = m.Entities
+			parseMode = m.parseMode()
+		}
+
+		ref := item.URL
+		var attachment *bytes.Reader
+		if len(item.Data) > 0 {
+			name := item.FileName
+			if name == "" {
+				name = fmt.Sprintf("file%d", i)
+			}
+
+			ref = "attach://" + name
+			attachment = bytes.NewReader(item.Data)
+		}
+
+		switch item.Type {
+		case MediaPhoto:
+			p := &models.InputMediaPhoto{Media: ref, Caption: caption, ParseMode: parseMode, CaptionEntities: entities}
+			if attachment != nil {
+				p.MediaAttachment = attachment
+			}
+			media = append(media, p)
+		case MediaVideo:
+			v := &models.InputMediaVideo{Media: ref, Caption: caption, ParseMode: parseMode, CaptionEntities: entities}
+			if attachment != nil {
+				v.MediaAttachment = attachment
+			}
+			media = append(media, v)
+		case MediaDocument:
+			d := &models.InputMediaDocument{Media: ref, Caption: caption, ParseMode: parseMode, CaptionEntities: entities}
+			if attachment != nil {
+				d.MediaAttachment = attachment
+			}
+			media = append(media, d)
+		case MediaAudio:
+			a := &models.InputMediaAudio{Media: ref, Caption: caption, ParseMode: parseMode, CaptionEntities: entities}
+			if attachment != nil {
+				a.MediaAttachment = attachment
+			}
+			media = append(media, a)
+		default:
+			return nil, fmt.Errorf("unsupported album media type %q", item.Type)
+		}
+	}
+
+	return media, nil
+}
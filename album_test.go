@@ -0,0 +1,48 @@
+package tgbot
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlbumMedia(t *testing.T) {
+	msg := Message{
+		Text: "caption",
+		Album: []MediaItem{
+			{Type: MediaPhoto, URL: "https://example.com/a.jpg"},
+			{Type: MediaVideo, Data: []byte("video"), FileName: "b.mp4"},
+		},
+	}
+
+	media, err := msg.albumMedia()
+	require.NoError(t, err)
+	require.Len(t, media, 2)
+
+	photo := media[0].(*models.InputMediaPhoto)
+	require.Equal(t, "https://example.com/a.jpg", photo.Media)
+	require.Equal(t, "caption", photo.Caption)
+
+	video := media[1].(*models.InputMediaVideo)
+	require.Equal(t, "attach://b.mp4", video.Media)
+	require.NotNil(t, video.MediaAttachment)
+	require.Empty(t, video.Caption)
+}
+
+func TestAlbumMediaValidation(t *testing.T) {
+	_, err := Message{Album: []MediaItem{{Type: MediaPhoto, URL: "a"}}}.albumMedia()
+	require.ErrorIs(t, err, ErrAlbumSize)
+
+	_, err = Message{Album: []MediaItem{
+		{Type: MediaPhoto, URL: "a"},
+		{Type: MediaDocument, URL: "b"},
+	}}.albumMedia()
+	require.ErrorIs(t, err, ErrAlbumMixed)
+
+	_, err = Message{Album: []MediaItem{
+		{Type: MediaDocument, URL: "a"},
+		{Type: MediaDocument, URL: "b"},
+	}}.albumMedia()
+	require.NoError(t, err)
+}
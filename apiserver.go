@@ -0,0 +1,107 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultAPIServerURL = "https://api.telegram.org"
+
+// APIServer configures a self-hosted telegram-bot-api server. Bots on their
+// own server upload files up to 2GB and download files of any size, instead
+// of the 50MB and 20MB of api.telegram.org.
+type APIServer struct {
+	// URL of the server, e.g. http://localhost:8081
+	URL string
+	// Local is set for servers started with --local. Their file paths are
+	// absolute paths on the server, the files are read from disk instead of
+	// downloaded.
+	Local bool
+	// ServerDir and Dir rewrite the file paths of a local server whose
+	// working directory (--dir) is mounted elsewhere here, e.g. a container
+	// volume of /var/lib/telegram-bot-api mounted at /data/bot-api
+	ServerDir string
+	Dir       string
+}
+
+func (a *APIServer) problems() []error {
+	if a == nil {
+		return nil
+	}
+
+	var problems []error
+
+	if u, err := url.Parse(a.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Errorf("APIServer.URL %q must be an absolute http or https URL", a.URL))
+	}
+
+	if (a.ServerDir == "") != (a.Dir == "") {
+		problems = append(problems, errors.New("APIServer.ServerDir and APIServer.Dir must be set together"))
+	}
+
+	if a.ServerDir != "" && !a.Local {
+		problems = append(problems, errors.New("APIServer.ServerDir only applies to a server with Local set"))
+	}
+
+	return problems
+}
+
+// apiServerURL returns the URL of the Bot API server without trailing slash
+func (s *Service) apiServerURL() string {
+	if s.cfg.APIServer != nil {
+		return strings.TrimSuffix(s.cfg.APIServer.URL, "/")
+	}
+
+	return defaultAPIServerURL
+}
+
+// fetchFile returns the contents of a file returned by getFile, files of a
+// local server are read from disk. The download URL holds the bot token, the
+// file is cached by its ID instead.
+func (s *Service) fetchFile(ctx context.Context, filePath string) ([]byte, error) {
+	if path, ok := s.localFile(filePath); ok {
+		return os.ReadFile(path)
+	}
+
+	return fetchURL(ctx, s.fileURL(ctx, filePath))
+}
+
+// localFile returns where a file returned by getFile is on disk, for files
+// of a local server
+func (s *Service) localFile(filePath string) (string, bool) {
+	server := s.cfg.APIServer
+	if server == nil || !server.Local {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(filePath, "file://")
+	if !filepath.IsAbs(path) {
+		return "", false
+	}
+
+	return server.localPath(path), true
+}
+
+// fileURL returns the download URL of a file returned by getFile
+func (s *Service) fileURL(ctx context.Context, filePath string) string {
+	return fmt.Sprintf("%s/file/bot%s/%s", s.apiServerURL(), s.botToken(ctx), filePath)
+}
+
+// localPath rewrites a path on the server to the path it is mounted at
+func (a *APIServer) localPath(path string) string {
+	if a.ServerDir == "" {
+		return path
+	}
+
+	rel, err := filepath.Rel(a.ServerDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+
+	return filepath.Join(a.Dir, rel)
+}
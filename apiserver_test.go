@@ -0,0 +1,69 @@
+package tgbot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestAPIServer(t *testing.T) {
+	const token = "123456:test-token"
+
+	var filePath string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot" + token + "/getMe":
+			fmt.Fprint(w, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"test_bot"}}`)
+		case "/bot" + token + "/getFile":
+			fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"abc","file_path":%q}}`, filePath)
+		case "/file/bot" + token + "/photos/a.jpg":
+			_, _ = w.Write([]byte("remote"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	server := &APIServer{URL: srv.URL + "/"}
+
+	s, err := NewService(logger, &Config{Token: token, SkipGetMe: true, APIServer: server})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	filePath = "photos/a.jpg"
+	data, err := s.DownloadFile("abc")
+	require.NoError(t, err)
+	require.Equal(t, "remote", string(data))
+
+	// Local servers return absolute paths on the server, read from where
+	// its directory is mounted
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "videos"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "videos", "b.mp4"), []byte("local"), 0o600))
+
+	server.Local, server.ServerDir, server.Dir = true, "/var/lib/telegram-bot-api", dir
+
+	filePath = "/var/lib/telegram-bot-api/videos/b.mp4"
+	data, err = s.DownloadFile("def")
+	require.NoError(t, err)
+	require.Equal(t, "local", string(data))
+}
+
+func TestAPIServerProblems(t *testing.T) {
+	require.Empty(t, (&APIServer{URL: "http://localhost:8081"}).problems())
+	require.Len(t, (&APIServer{URL: "localhost:8081"}).problems(), 1)
+	require.Len(t, (&APIServer{URL: "http://localhost:8081", Dir: "/data"}).problems(), 1)
+	require.Len(t, (&APIServer{URL: "http://localhost:8081", ServerDir: "/srv", Dir: "/data"}).problems(), 1)
+
+	server := &APIServer{Local: true, ServerDir: "/srv", Dir: "/data"}
+	require.Equal(t, "/data/a/b.jpg", server.localPath("/srv/a/b.jpg"))
+	require.Equal(t, "/other/b.jpg", server.localPath("/other/b.jpg"))
+}
@@ -2,20 +2,29 @@
 package tgbot
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/Davincible/cache"
 	"github.com/gammazero/workerpool"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
-	"go.uber.org/ratelimit"
 	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot/cache"
+	"github.com/Davincible/tgbot/secrets"
 )
 
 const (
@@ -24,25 +33,68 @@ const (
 	defaultWebhookTimeout = 30 * time.Second
 )
 
-// Sender defines the interface for sending messages and managing telegram content
+// Sender defines the interface for sending messages and managing telegram
+// content. Bots and modules that only use part of it depend on one of the
+// interfaces it is made of, so their tests need small fakes only.
 type Sender interface {
+	MessageSender
+	MessageEditor
+	FileDownloader
+	ProfileProvider
+}
+
+// MessageSender sends messages
+type MessageSender interface {
 	Send(userID int64, msg Message) (*models.Message, error)
+	SendTyping(chatID int64) error
+}
+
+// MessageEditor changes, deletes and pins sent messages
+type MessageEditor interface {
 	EditMessage(chatID int64, msgID int, msg Message) (*models.Message, error)
 	DeleteMessage(chatID int64, msgID int) error
+	PinMessage(chatID int64, msgID int, silent bool) error
+	UnpinMessage(chatID int64, msgID int) error
+	UnpinAllMessages(chatID int64) error
+}
+
+// FileDownloader downloads the files of messages
+type FileDownloader interface {
 	DownloadFile(fileID any) ([]byte, error)
+}
+
+// ProfileProvider returns the profiles of the bot and its users
+type ProfileProvider interface {
 	GetProfilePhoto(chatID int64) ([]byte, error)
 	BotUsername() string
-	SendTyping(chatID int64) error
 }
 
-// Bot defines the interface for telegram bot behavior
+// ContextSender is a Sender whose calls take a context, so callers can
+// propagate cancellation and deadlines. Calls without a deadline get the
+// same default timeouts as the Sender methods.
+type ContextSender interface {
+	Sender
+	SendContext(ctx context.Context, chatID int64, msg Message) (*models.Message, error)
+	EditMessageContext(ctx context.Context, chatID int64, msgID int, msg Message) (*models.Message, error)
+	DeleteMessageContext(ctx context.Context, chatID int64, msgID int) error
+	DownloadFileContext(ctx context.Context, fileID any) ([]byte, error)
+	GetProfilePhotoContext(ctx context.Context, chatID int64) ([]byte, error)
+	SendTypingContext(ctx context.Context, chatID int64) error
+	PinMessageContext(ctx context.Context, chatID int64, msgID int, silent bool) error
+	UnpinMessageContext(ctx context.Context, chatID int64, msgID int) error
+	UnpinAllMessagesContext(ctx context.Context, chatID int64) error
+}
+
+var _ ContextSender = (*Service)(nil)
+
+// Bot defines the interface for telegram bot behavior. Bots that only need
+// some of it implement the capability interfaces and are wrapped with AdaptBot.
 type Bot interface {
-	SetSender(b Sender)
-	Commands() map[string]func(ctx context.Context, b *bot.Bot, update *models.Update)
-	CommandsList() []models.BotCommand
-	CallBacks() map[string]CallBack
-	Middleware() []bot.Middleware
-	DefaultHandler() bot.HandlerFunc
+	SenderSetter
+	CommandProvider
+	CallbackProvider
+	MiddlewareProvider
+	DefaultHandlerProvider
 }
 
 // CallBack represents a telegram callback configuration
@@ -61,6 +113,105 @@ type Config struct {
 	Polling            bool
 	SkipGetMe          bool
 	UseTestEnvironment bool
+	RateLimit          RateLimitConfig
+	WebhookWatchdog    WatchdogConfig
+	// AdminChatID is the chat that receives the admin notifications
+	AdminChatID int64
+	// Admins are the user IDs that bypass maintenance mode
+	Admins []int64
+	// MaxUpdateAge is the age after which updates are considered stale,
+	// zero disables the check
+	MaxUpdateAge time.Duration
+	// StaleUpdatePolicy determines what happens to stale updates
+	StaleUpdatePolicy StaleUpdatePolicy
+	// SendWorkers is the number of workers draining the send pipeline
+	SendWorkers int
+	// Locales stores the language and timezone per user, defaults to memory
+	Locales LocaleStore
+	// TimezonePrompt lets users pick their timezone with /timezone
+	TimezonePrompt bool
+	// Moderation runs group messages through classifiers, disabled without classifiers
+	Moderation ModerationConfig
+	// FileCache caches downloaded files and the profile photos of users,
+	// defaults to memory. See cache.NewLimitedDisk and cache.NewRedis with
+	// cache.BytesCodec for other backends. Expired files are removed every
+	// 10 minutes from caches implementing cache.Sweeper.
+	FileCache cache.Cache[[]byte]
+	// FileCacheTTL is how long files are cached, defaults to 24 hours
+	FileCacheTTL time.Duration
+	// FileCacheMaxSize is the size of the largest file cached, larger files
+	// are downloaded every time. Defaults to 5 MiB, negative disables the
+	// cache.
+	FileCacheMaxSize int64
+	// FileCacheTotalSize is the size of the default memory cache, the least
+	// recently used files are evicted past it. Defaults to 64 MiB.
+	FileCacheTotalSize int64
+	// Profiles records the users and chats the bot sees, for ExportUsers and
+	// ExportChats. Disabled when nil.
+	Profiles ProfileStore
+	// Catalog translates message keys sent as Message.Text, the language is
+	// the stored locale of the chat, falling back to DefaultLanguage (en)
+	Catalog         Catalog
+	DefaultLanguage string
+	// InstanceName and InstanceValues describe this deployment of the bot,
+	// handlers read them with BotInstanceFromContext
+	InstanceName   string
+	InstanceValues map[string]any
+	// AutoAnswerCallbacks answers callback queries the handlers did not
+	// answer, so buttons don't keep showing a loading indicator
+	AutoAnswerCallbacks bool
+	// WebhookMaxBody is the size limit of webhook requests, defaults to 1 MiB
+	WebhookMaxBody int64
+	// OnWebhookReject is called for every rejected webhook request
+	OnWebhookReject func(r *http.Request, rejection WebhookRejection)
+	// SplitLongMessages splits texts over 4096 and captions over 1024
+	// characters into several messages, Send returns the first of them and
+	// SendSplit all of them
+	SplitLongMessages bool
+	// TokenProvider supplies the token instead of Token. It is called for
+	// every request, wrap it in secrets.Cached to rotate the token without
+	// restarting.
+	TokenProvider secrets.Provider
+	// ChatWorkers handles the updates of up to this many chats in parallel,
+	// the updates of one chat are still handled one at a time and in order.
+	// Zero handles all updates one at a time.
+	ChatWorkers int
+	// Retry configures the retries of sent messages on flood waits and
+	// server errors, retries are on by default
+	Retry RetryConfig
+	// Schedules stores the messages sent with Schedule, defaults to memory.
+	// Use a DBScheduleStore for scheduled messages to survive restarts.
+	Schedules ScheduleStore
+	// DryRun sends the requests to the Bot API to the recorder instead of
+	// Telegram, no token is needed then
+	DryRun *Recorder
+	// Templates renders the named messages of Render in the language of the
+	// chat
+	Templates *Templates
+	// APIServer sends the requests to a self-hosted Bot API server instead
+	// of api.telegram.org
+	APIServer *APIServer
+	// LongCaptions is how captions over 1024 characters are sent, they fail
+	// by default
+	LongCaptions CaptionOverflow
+	// ReadMoreStore keeps the full texts of CaptionOverflowReadMore for 30
+	// days, defaults to memory. ReadMoreText is the label of the button,
+	// Read more by default.
+	ReadMoreStore cache.Cache[Message]
+	ReadMoreText  string
+	// SendAPIToken enables SendHandler, requests must carry it as a bearer
+	// token
+	SendAPIToken string
+	// WebhookCertificate is the path of a self-signed certificate uploaded
+	// with setWebhook so Telegram trusts it, usually the certFile of
+	// ListenAndServeWebhook
+	WebhookCertificate string
+	// WebhookHealthPath is the health endpoint of ListenAndServeWebhook,
+	// defaults to /healthz
+	WebhookHealthPath string
+	// DeleteWebhookOnShutdown deletes the webhook in Shutdown, so Telegram
+	// keeps the updates until the next start instead of retrying deliveries
+	DeleteWebhookOnShutdown bool
 }
 
 // Service implements the telegram bot service
@@ -69,9 +220,47 @@ type Service struct {
 	logger    *slog.Logger
 	bot       *bot.Bot
 	pool      *workerpool.WorkerPool
+	pipeline  *sendPipeline
+	chatQueue *chatQueue
+	scheduler *scheduler
 	username  string
-	fileCache *cache.Cache[[]byte]
-	ratelimit ratelimit.Limiter
+	fileCache cache.Cache[[]byte]
+	ratelimit *rateLimiter
+
+	maintenance  maintenanceState
+	staleSummary staleSummary
+	tracker      messageTracker
+	locale       localeState
+	polls        pollSubscriptions
+	reactions    reactionSubscriptions
+	payments     paymentState
+	giveaways    giveawaySubscriptions
+	joins        joinState
+	interceptors sendInterceptors
+	commands     CommandSet
+
+	callbackAnswers sync.Map
+
+	runMu             sync.Mutex
+	runMode           runMode
+	runCancel         context.CancelFunc
+	lastWebhookUpdate atomic.Int64
+	webhookStats      webhookStats
+	lastToken         atomic.Pointer[string]
+	webhookServer     *http.Server
+
+	// inflight counts the updates being handled, queuedUpdates the ones
+	// accepted by the webhook and not handled yet. handlerCtx is canceled
+	// when Shutdown gives up waiting on them.
+	inflight       sync.WaitGroup
+	queuedUpdates  atomic.Int64
+	intakeClosed   atomic.Bool
+	handlerCtx     context.Context
+	cancelHandlers context.CancelFunc
+
+	// closeCtx is canceled by Close, stopping the background loops
+	closeCtx    context.Context
+	closeCancel context.CancelFunc
 }
 
 // NewService creates a new telegram service instance
@@ -80,33 +269,52 @@ func NewService(logger *slog.Logger, cfg *Config) (*Service, error) {
 		return nil, err
 	}
 
-	b, username, err := initializeBot(logger, cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	fileCache, err := cache.New[[]byte](&cache.Config{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create file cache: %w", err)
+	workers := cfg.SendWorkers
+	if workers <= 0 {
+		workers = defaultWorkerPoolSize
 	}
+	pool := workerpool.New(workers)
 
 	srv := &Service{
 		cfg:       cfg,
 		logger:    logger,
-		bot:       b,
-		pool:      workerpool.New(defaultWorkerPoolSize),
-		username:  username,
-		fileCache: fileCache,
-		ratelimit: ratelimit.New(30),
+		pool:      pool,
+		pipeline:  newSendPipeline(pool),
+		fileCache: cfg.FileCache,
+		ratelimit: newRateLimiter(cfg.RateLimit),
+	}
+
+	srv.handlerCtx, srv.cancelHandlers = context.WithCancel(context.Background())
+	srv.closeCtx, srv.closeCancel = context.WithCancel(context.Background())
+
+	if cfg.ChatWorkers > 0 {
+		srv.chatQueue = newChatQueue(cfg.ChatWorkers)
+	}
+
+	if cfg.Bot != nil {
+		srv.commands = srv.commandSet()
+	}
+
+	if err := srv.initializeBot(); err != nil {
+		return nil, err
 	}
 
 	if err := srv.setupBot(); err != nil {
 		return nil, err
 	}
 
+	srv.scheduler = newScheduler(cfg.Schedules, logger, srv.SendContext)
+	srv.scheduler.start()
+
+	if sweeper, ok := srv.fileCache.(cache.Sweeper); ok && cfg.FileCacheMaxSize >= 0 {
+		go srv.sweepFileCache(sweeper)
+	}
+
 	return srv, nil
 }
 
+// validateConfig checks the configuration and sets the defaults. It reports
+// all problems at once, joined in an error wrapping ErrInvalidConfig.
 func validateConfig(logger *slog.Logger, cfg *Config) error {
 	if logger == nil {
 		return ErrNilLogger
@@ -114,29 +322,127 @@ func validateConfig(logger *slog.Logger, cfg *Config) error {
 	if cfg == nil {
 		return ErrNilConfig
 	}
-	if cfg.UseWebhook && len(cfg.WebhookURL) == 0 {
-		return fmt.Errorf("webhook setup requested but no webhook URL provided")
+	if err := cfg.resolveToken(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if cfg.DryRun != nil && cfg.Token == "" {
+		cfg.Token = dryRunToken
+	}
+	if problems := cfg.problems(); len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	if cfg.Locales == nil {
+		cfg.Locales = NewMemoryLocaleStore()
+	}
+	if cfg.FileCacheTotalSize <= 0 {
+		cfg.FileCacheTotalSize = defaultFileCacheTotalSize
+	}
+	if cfg.FileCache == nil {
+		cfg.FileCache = cache.NewLimitedMemory(cfg.FileCacheTotalSize, func(file []byte) int64 {
+			return int64(len(file))
+		})
+	}
+	if cfg.FileCacheTTL == 0 {
+		cfg.FileCacheTTL = defaultFileCacheTTL
+	}
+	if cfg.FileCacheMaxSize == 0 {
+		cfg.FileCacheMaxSize = defaultFileCacheMaxSize
+	}
+	if cfg.Schedules == nil {
+		cfg.Schedules = NewMemoryScheduleStore()
+	}
+	if cfg.ReadMoreStore == nil {
+		cfg.ReadMoreStore = cache.NewMemory[Message]()
 	}
 	return nil
 }
 
-func initializeBot(logger *slog.Logger, cfg *Config) (*bot.Bot, string, error) {
-	options := createBotOptions(logger, cfg)
-	b, err := bot.New(cfg.Token, options...)
+var (
+	tokenPattern         = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]+$`)
+	webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+)
+
+func (cfg *Config) problems() []error {
+	var problems []error
+
+	switch {
+	case cfg.Token == "":
+		problems = append(problems, errors.New("Token is required, get one from @BotFather"))
+	case !tokenPattern.MatchString(cfg.Token):
+		problems = append(problems, errors.New("Token is malformed, it should look like 123456789:AAE... as issued by @BotFather"))
+	}
+
+	if cfg.UseWebhook && cfg.Polling {
+		problems = append(problems, errors.New("UseWebhook and Polling are mutually exclusive, enable one of them"))
+	}
+
+	if cfg.UseWebhook && cfg.WebhookURL == "" {
+		problems = append(problems, errors.New("WebhookURL is required when UseWebhook is set"))
+	}
+
+	if cfg.WebhookURL != "" {
+		if u, err := url.Parse(cfg.WebhookURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			problems = append(problems, fmt.Errorf("WebhookURL %q must be an absolute https URL, Telegram does not deliver to plain http", cfg.WebhookURL))
+		}
+	}
+
+	if cfg.WebhookSecret != "" && !webhookSecretPattern.MatchString(cfg.WebhookSecret) {
+		problems = append(problems, errors.New("WebhookSecret must be 1-256 characters of A-Z, a-z, 0-9, _ and -"))
+	}
+
+	if cfg.SendAPIToken != "" && len(cfg.SendAPIToken) < minSendAPIToken {
+		problems = append(problems, fmt.Errorf("SendAPIToken must be at least %d characters", minSendAPIToken))
+	}
+
+	if cfg.FileCacheTTL < 0 {
+		problems = append(problems, errors.New("FileCacheTTL must not be negative, use zero for the default"))
+	}
+
+	if cfg.WebhookHealthPath != "" && !strings.HasPrefix(cfg.WebhookHealthPath, "/") {
+		problems = append(problems, fmt.Errorf("WebhookHealthPath %q must start with /", cfg.WebhookHealthPath))
+	}
+
+	if cfg.WebhookMaxBody < 0 {
+		problems = append(problems, errors.New("WebhookMaxBody must not be negative, use zero for the default"))
+	}
+
+	if cfg.MaxUpdateAge < 0 {
+		problems = append(problems, errors.New("MaxUpdateAge must not be negative, use zero to disable the check"))
+	}
+
+	if cfg.SendWorkers < 0 {
+		problems = append(problems, errors.New("SendWorkers must not be negative, use zero for the default"))
+	}
+
+	if cfg.ChatWorkers < 0 {
+		problems = append(problems, errors.New("ChatWorkers must not be negative, use zero to handle updates one at a time"))
+	}
+
+	problems = append(problems, cfg.RateLimit.problems()...)
+	problems = append(problems, cfg.Retry.problems()...)
+	problems = append(problems, cfg.APIServer.problems()...)
+
+	return problems
+}
+
+func (s *Service) initializeBot() error {
+	options := s.createBotOptions()
+	b, err := bot.New(s.cfg.Token, options...)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create bot: %w", err)
+		return fmt.Errorf("failed to create bot: %w", err)
 	}
 
-	username := ""
-	if !cfg.SkipGetMe {
+	s.bot = b
+
+	if !s.cfg.SkipGetMe {
 		self, err := b.GetMe(context.Background())
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to get bot info: %w", err)
+			return fmt.Errorf("failed to get bot info: %w", err)
 		}
-		username = self.Username
+		s.username = self.Username
 	}
 
-	return b, username, nil
+	return nil
 }
 
 func (s *Service) setupBot() error {
@@ -160,12 +466,20 @@ func (s *Service) setupBot() error {
 }
 
 func (s *Service) registerHandlers() {
-	for command, handler := range s.cfg.Bot.Commands() {
-		s.bot.RegisterHandler(bot.HandlerTypeMessageText, command, bot.MatchTypePrefix, handler)
+	for _, route := range s.commandRoutes() {
+		route := route
+		s.bot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+			return update.Message != nil && route.matches(update.Message.Text, s.username)
+		}, route.handler)
 	}
 }
 
 func (s *Service) setupCommands() {
+	if s.commands != nil {
+		s.setupCommandMenus()
+		return
+	}
+
 	commandList := s.cfg.Bot.CommandsList()
 	if len(commandList) == 0 {
 		return
@@ -202,11 +516,22 @@ func (s *Service) setupWebhook() error {
 
 	s.ensureWebhookSecret()
 
-	_, err := s.bot.SetWebhook(ctx, &bot.SetWebhookParams{
+	params := &bot.SetWebhookParams{
 		URL:            s.cfg.WebhookURL,
 		SecretToken:    s.cfg.WebhookSecret,
 		AllowedUpdates: allowedUpdates,
-	})
+	}
+
+	if s.cfg.WebhookCertificate != "" {
+		cert, err := os.ReadFile(s.cfg.WebhookCertificate)
+		if err != nil {
+			return fmt.Errorf("read webhook certificate: %w", err)
+		}
+
+		params.Certificate = &models.InputFileUpload{Filename: filepath.Base(s.cfg.WebhookCertificate), Data: bytes.NewReader(cert)}
+	}
+
+	_, err := s.bot.SetWebhook(ctx, params)
 	return err
 }
 
@@ -222,9 +547,13 @@ func (s *Service) ensureWebhookSecret() {
 
 func (s *Service) startBot() {
 	if s.cfg.UseWebhook {
-		go s.bot.StartWebhook(context.Background())
+		s.run(runModeWebhook)
+
+		if s.cfg.WebhookWatchdog.Enabled {
+			go s.watchWebhook()
+		}
 	} else if s.cfg.Polling {
-		go s.bot.Start(context.Background())
+		s.run(runModePolling)
 	}
 
 	if len(s.username) > 0 {
@@ -232,20 +561,36 @@ func (s *Service) startBot() {
 	} else {
 		s.logger.Debug("Telegram connected")
 	}
+
+	s.notifyAdminAsync(slog.LevelInfo, "Bot started")
 }
 
 // Public methods
 
-func (s *Service) WebhookHandler() http.HandlerFunc {
-	return s.bot.WebhookHandler()
-}
-
+// Close stops the service after the messages on the send pipeline, see
+// Shutdown to also wait for the updates being handled
 func (s *Service) Close() {
+	s.closeCancel()
+
+	if s.scheduler != nil {
+		s.scheduler.stop()
+	}
+
+	if s.chatQueue != nil {
+		s.chatQueue.stop()
+	}
+
+	s.pipeline.close()
 	s.pool.StopWait()
 }
 
 func (s *Service) SendTyping(chatID int64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultWebhookTimeout)
+	return s.SendTypingContext(context.Background(), chatID)
+}
+
+// SendTypingContext is SendTyping with a context
+func (s *Service) SendTypingContext(ctx context.Context, chatID int64) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultWebhookTimeout)
 	defer cancel()
 
 	_, err := s.bot.SendChatAction(ctx, &bot.SendChatActionParams{
@@ -3,6 +3,8 @@ package tgbot
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
 	"sync"
 
 	"github.com/go-telegram/bot"
@@ -22,7 +24,11 @@ type BotMerger struct {
 	commandsList []models.BotCommand
 
 	defaultHandlers []bot.HandlerFunc
+	inlineHandlers  []bot.HandlerFunc
 	setSenders      []func(s Sender)
+
+	// merged are the bots merged so far, a bot merged again is skipped
+	merged []Bot
 }
 
 // MergerConfig defines the configuration for the bot merger
@@ -65,7 +71,8 @@ func NewBotMerger(config MergerConfig) (*BotMerger, error) {
 	}, nil
 }
 
-// MergeBots merges multiple bots into the merger
+// MergeBots merges multiple bots into the merger, bots already merged are
+// skipped
 func (m *BotMerger) MergeBots(bots ...Bot) error {
 	m.Lock()
 	defer m.Unlock()
@@ -80,6 +87,12 @@ func (m *BotMerger) MergeBots(bots ...Bot) error {
 }
 
 func (m *BotMerger) mergeBot(bot Bot) error {
+	if slices.ContainsFunc(m.merged, func(merged Bot) bool { return sameBot(merged, bot) }) {
+		m.logger.Info("bot already merged, skipping it",
+			slog.String("bot", fmt.Sprintf("%T", bot)))
+		return nil
+	}
+
 	if err := m.mergeCommands(bot.Commands()); err != nil {
 		return err
 	}
@@ -92,8 +105,14 @@ func (m *BotMerger) mergeBot(bot Bot) error {
 	}
 
 	m.middleware = append(m.middleware, bot.Middleware()...)
-	m.defaultHandlers = append(m.defaultHandlers, bot.DefaultHandler())
+	if handler := bot.DefaultHandler(); handler != nil {
+		m.defaultHandlers = append(m.defaultHandlers, handler)
+	}
+	if handler := inlineHandler(bot); handler != nil {
+		m.inlineHandlers = append(m.inlineHandlers, handler)
+	}
 	m.setSenders = append(m.setSenders, bot.SetSender)
+	m.merged = append(m.merged, bot)
 
 	// Set the sender on the merged bot
 	if m.sender != nil {
@@ -177,47 +196,67 @@ func (m *BotMerger) handleCallbackConflict(pattern string, newCallback, existing
 	return nil
 }
 
+// mergeCommandsList adds the commands to the command menu. Commands already
+// listed with the same description are skipped, so merging a bot twice
+// doesn't list its commands twice, other conflicts follow the strategy.
 func (m *BotMerger) mergeCommandsList(newCommands []models.BotCommand) {
 	for _, cmd := range newCommands {
-		shouldAdd := true
-
-		// Check for existing command
-		for _, existing := range m.commandsList {
-			if existing.Command == cmd.Command {
-				shouldAdd = false
-
-				// Handle conflict based on strategy
-				switch m.config.ConflictStrategy {
-				case KeepOriginal:
-					m.logger.Info("keeping original command in list",
-						slog.String("command", cmd.Command))
-				case ReplaceWithNew:
-					// Replace the existing command description
-					existing.Description = cmd.Description
-					m.logger.Info("replaced command description in list",
-						slog.String("command", cmd.Command))
-				case SuffixConflicting:
-					// Add suffixed command to list
-					suffixedCmd := cmd
-					suffixedCmd.Command = cmd.Command + m.config.DefaultSuffix
-					m.commandsList = append(m.commandsList, suffixedCmd)
-					m.logger.Info("added suffixed command to list",
+		i := m.commandIndex(cmd.Command)
+
+		switch {
+		case i < 0:
+			m.commandsList = append(m.commandsList, cmd)
+			m.logger.Info("added new command to list",
+				slog.String("command", cmd.Command))
+		case m.commandsList[i] == cmd:
+			// Already listed
+		case m.config.ConflictStrategy == ReplaceWithNew:
+			m.commandsList[i].Description = cmd.Description
+			m.logger.Info("replaced command description in list",
+				slog.String("command", cmd.Command))
+		case m.config.ConflictStrategy == SuffixConflicting:
+			suffixed := cmd
+			suffixed.Command = cmd.Command + m.config.DefaultSuffix
+
+			if j := m.commandIndex(suffixed.Command); j >= 0 {
+				if m.commandsList[j] != suffixed {
+					m.logger.Warn("suffixed command is taken, not listing it",
 						slog.String("original", cmd.Command),
-						slog.String("suffixed", suffixedCmd.Command))
+						slog.String("suffixed", suffixed.Command))
 				}
-				break
+				continue
 			}
-		}
 
-		// Add new command if no conflict or if using suffix strategy
-		if shouldAdd && m.config.ConflictStrategy != SuffixConflicting {
-			m.commandsList = append(m.commandsList, cmd)
-			m.logger.Info("added new command to list",
+			m.commandsList = append(m.commandsList, suffixed)
+			m.logger.Info("added suffixed command to list",
+				slog.String("original", cmd.Command),
+				slog.String("suffixed", suffixed.Command))
+		default:
+			m.logger.Info("keeping original command in list",
 				slog.String("command", cmd.Command))
 		}
 	}
 }
 
+// sameBot reports whether a and b are the same bot, bots of types that
+// can't be compared are never the same
+func sameBot(a, b Bot) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// commandIndex returns the position of the command in the list, -1 if it
+// is not listed
+func (m *BotMerger) commandIndex(command string) int {
+	return slices.IndexFunc(m.commandsList, func(c models.BotCommand) bool {
+		return c.Command == command
+	})
+}
+
 // Bot interface implementation
 
 func (m *BotMerger) SetSender(s Sender) {
@@ -237,10 +276,12 @@ func (m *BotMerger) Commands() map[string]func(ctx context.Context, b *bot.Bot,
 	return m.commands
 }
 
+// CommandsList returns a copy of the merged command menu, in the order the
+// commands were merged
 func (m *BotMerger) CommandsList() []models.BotCommand {
 	m.RLock()
 	defer m.RUnlock()
-	return m.commandsList
+	return slices.Clone(m.commandsList)
 }
 
 func (m *BotMerger) CallBacks() map[string]CallBack {
@@ -265,6 +306,24 @@ func (m *BotMerger) DefaultHandler() bot.HandlerFunc {
 	}
 }
 
+// InlineHandler passes inline queries to the inline handlers of the merged
+// bots, nil if none of them handles inline queries
+func (m *BotMerger) InlineHandler() bot.HandlerFunc {
+	m.RLock()
+	handlers := m.inlineHandlers
+	m.RUnlock()
+
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		for _, handler := range handlers {
+			handler(ctx, b, update)
+		}
+	}
+}
+
 func (config *MergerConfig) validateConfig() error {
 	if config.Logger == nil {
 		return fmt.Errorf("logger cannot be nil")
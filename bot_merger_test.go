@@ -57,14 +57,104 @@ func TestChainableMerger(t *testing.T) {
 
 // ExampleBot implementation remains the same as before
 type ExampleBot struct {
-	commands map[string]func(ctx context.Context, b *bot.Bot, update *models.Update)
+	commands       map[string]func(ctx context.Context, b *bot.Bot, update *models.Update)
+	commandsList   []models.BotCommand
+	middleware     []bot.Middleware
+	defaultHandler bot.HandlerFunc
 }
 
 func (eb *ExampleBot) SetSender(b Sender) {}
 func (eb *ExampleBot) Commands() map[string]func(ctx context.Context, b *bot.Bot, update *models.Update) {
 	return eb.commands
 }
-func (eb *ExampleBot) CommandsList() []models.BotCommand { return nil }
+func (eb *ExampleBot) CommandsList() []models.BotCommand { return eb.commandsList }
 func (eb *ExampleBot) CallBacks() map[string]CallBack    { return nil }
-func (eb *ExampleBot) Middleware() []bot.Middleware      { return nil }
-func (eb *ExampleBot) DefaultHandler() bot.HandlerFunc   { return nil }
+func (eb *ExampleBot) Middleware() []bot.Middleware      { return eb.middleware }
+func (eb *ExampleBot) DefaultHandler() bot.HandlerFunc   { return eb.defaultHandler }
+
+func TestMergeCommandsList(t *testing.T) {
+	first := &ExampleBot{commandsList: []models.BotCommand{
+		{Command: "start", Description: "Start"},
+	}}
+	second := &ExampleBot{commandsList: []models.BotCommand{
+		{Command: "start", Description: "Begin"},
+		{Command: "help", Description: "Help"},
+	}}
+
+	tests := []struct {
+		name     string
+		strategy ConflictStrategy
+		want     []models.BotCommand
+	}{
+		{"keep original", KeepOriginal, []models.BotCommand{
+			{Command: "start", Description: "Start"},
+			{Command: "help", Description: "Help"},
+		}},
+		{"replace with new", ReplaceWithNew, []models.BotCommand{
+			{Command: "start", Description: "Begin"},
+			{Command: "help", Description: "Help"},
+		}},
+		{"suffix conflicting", SuffixConflicting, []models.BotCommand{
+			{Command: "start", Description: "Start"},
+			{Command: "start_alt", Description: "Begin"},
+			{Command: "help", Description: "Help"},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			merger, err := NewBotMerger(MergerConfig{
+				ConflictStrategy: tc.strategy,
+				DefaultSuffix:    "_alt",
+				Logger:           slog.Default(),
+			})
+			assert.NoError(t, err)
+
+			assert.NoError(t, merger.MergeBots(first, second))
+			assert.Equal(t, tc.want, merger.CommandsList())
+
+			// Merging the same bots again lists nothing twice
+			assert.NoError(t, merger.MergeBots(second))
+			assert.NoError(t, merger.MergeBots(first, second))
+			assert.Equal(t, tc.want, merger.CommandsList())
+
+			// The list is a copy
+			merger.CommandsList()[0].Description = "changed"
+			assert.Equal(t, tc.want, merger.CommandsList())
+		})
+	}
+}
+
+func TestMergeSameBotTwice(t *testing.T) {
+	merger, err := NewBotMerger(MergerConfig{
+		ConflictStrategy: SuffixConflicting,
+		DefaultSuffix:    "_alt",
+		Logger:           slog.Default(),
+	})
+	assert.NoError(t, err)
+
+	var handled int
+	example := &ExampleBot{
+		commands: map[string]func(ctx context.Context, b *bot.Bot, update *models.Update){
+			"/start": func(ctx context.Context, b *bot.Bot, update *models.Update) {},
+		},
+		commandsList: []models.BotCommand{{Command: "start", Description: "Start"}},
+		middleware: []bot.Middleware{func(next bot.HandlerFunc) bot.HandlerFunc {
+			return next
+		}},
+		defaultHandler: func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			handled++
+		},
+	}
+
+	assert.NoError(t, merger.MergeBots(example))
+	assert.NoError(t, merger.MergeBots(example, example))
+
+	assert.Len(t, merger.Middleware(), 1)
+	assert.Len(t, merger.setSenders, 1)
+	assert.NotContains(t, merger.Commands(), "/start_alt")
+	assert.Equal(t, []models.BotCommand{{Command: "start", Description: "Start"}}, merger.CommandsList())
+
+	merger.DefaultHandler()(context.Background(), nil, &models.Update{})
+	assert.Equal(t, 1, handled)
+}
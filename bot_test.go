@@ -82,3 +82,23 @@ func TestDownloadFile(t *testing.T) {
 
 	fmt.Println(data[:50])
 }
+
+func TestValidateConfig(t *testing.T) {
+	logger := slog.Default()
+
+	err := validateConfig(logger, &Config{
+		Token:         "not-a-token",
+		UseWebhook:    true,
+		Polling:       true,
+		WebhookURL:    "http://example.com/hook",
+		WebhookSecret: "has spaces",
+	})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	for _, problem := range []string{"Token is malformed", "mutually exclusive", "https URL", "WebhookSecret"} {
+		require.ErrorContains(t, err, problem)
+	}
+
+	cfg := &Config{Token: "123456:AAE-abc_def", UseWebhook: true, WebhookURL: "https://example.com/hook"}
+	require.NoError(t, validateConfig(logger, cfg))
+	require.NotNil(t, cfg.Locales)
+}
@@ -0,0 +1,200 @@
+package businessbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	tBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+var (
+	ErrConnectionDisabled = errors.New("business connection disabled")
+	ErrCannotReply        = errors.New("bot is not allowed to reply in this business connection")
+)
+
+// MessageHandler is called for every message received through a business connection
+type MessageHandler func(ctx context.Context, conn models.BusinessConnection, msg *models.Message)
+
+type Config struct {
+	// OnConnection is called when a business account connects, disconnects or
+	// changes the permissions of the bot
+	OnConnection func(ctx context.Context, conn models.BusinessConnection)
+	// OnMessage is called for new messages in the connected chats
+	OnMessage MessageHandler
+	// OnEdited is called for edited messages in the connected chats
+	OnEdited MessageHandler
+	// OnDeleted is called when messages are deleted in the connected chats
+	OnDeleted func(ctx context.Context, conn models.BusinessConnection, deleted *models.BusinessMessagesDeleted)
+}
+
+// Bot routes business account updates to the configured handlers and keeps a
+// registry of the business connections it has seen.
+type Bot struct {
+	logger *slog.Logger
+	sender tgbot.MessageSender
+	cfg    Config
+
+	mutex       sync.RWMutex
+	connections map[string]models.BusinessConnection
+}
+
+// Create new business bot
+func New(logger *slog.Logger, cfg Config) *Bot {
+	return &Bot{
+		logger:      logger,
+		cfg:         cfg,
+		connections: make(map[string]models.BusinessConnection),
+	}
+}
+
+// Implement Bot interface
+func (b *Bot) SetSender(s tgbot.Sender) {
+	b.sender = s
+}
+
+func (b *Bot) Commands() map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	return map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update){}
+}
+
+func (b *Bot) CommandsList() []models.BotCommand {
+	return []models.BotCommand{}
+}
+
+func (b *Bot) CallBacks() map[string]tgbot.CallBack {
+	return map[string]tgbot.CallBack{}
+}
+
+func (b *Bot) DefaultHandler() tBot.HandlerFunc {
+	return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {}
+}
+
+func (b *Bot) Middleware() []tBot.Middleware {
+	return []tBot.Middleware{
+		b.BusinessMiddleware(),
+	}
+}
+
+// BusinessMiddleware handles the business updates, other updates are passed on
+func (b *Bot) BusinessMiddleware() tBot.Middleware {
+	return func(next tBot.HandlerFunc) tBot.HandlerFunc {
+		return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+			switch {
+			case update.BusinessConnection != nil:
+				b.handleConnection(ctx, *update.BusinessConnection)
+			case update.BusinessMessage != nil:
+				b.handleMessage(ctx, update.BusinessMessage, b.cfg.OnMessage)
+			case update.EditedBusinessMessage != nil:
+				b.handleMessage(ctx, update.EditedBusinessMessage, b.cfg.OnEdited)
+			case update.DeletedBusinessMessages != nil:
+				b.handleDeleted(ctx, update.DeletedBusinessMessages)
+			default:
+				next(ctx, bot, update)
+			}
+		}
+	}
+}
+
+// Connections returns all known business connections
+func (b *Bot) Connections() []models.BusinessConnection {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	conns := make([]models.BusinessConnection, 0, len(b.connections))
+	for _, conn := range b.connections {
+		conns = append(conns, conn)
+	}
+
+	return conns
+}
+
+// Connection returns the business connection with the given ID
+func (b *Bot) Connection(id string) (models.BusinessConnection, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	conn, ok := b.connections[id]
+	return conn, ok
+}
+
+// AddConnection registers a business connection, e.g. one restored from storage
+func (b *Bot) AddConnection(conn models.BusinessConnection) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.connections[conn.ID] = conn
+}
+
+// Reply sends a message on behalf of the connected business account.
+// Connections that are not in the registry are left to the API to validate.
+func (b *Bot) Reply(connectionID string, chatID int64, msg tgbot.Message) (*models.Message, error) {
+	if conn, ok := b.Connection(connectionID); ok {
+		if !conn.IsEnabled {
+			return nil, ErrConnectionDisabled
+		}
+
+		if !conn.CanReply {
+			return nil, ErrCannotReply
+		}
+	}
+
+	msg.BusinessConnectionID = connectionID
+
+	sent, err := b.sender.Send(chatID, msg)
+	if err != nil {
+		return nil, fmt.Errorf("send business reply: %w", err)
+	}
+
+	return sent, nil
+}
+
+func (b *Bot) handleConnection(ctx context.Context, conn models.BusinessConnection) {
+	b.AddConnection(conn)
+
+	b.logger.Info("business connection updated",
+		slog.String("id", conn.ID),
+		slog.Int64("user", conn.User.ID),
+		slog.Bool("enabled", conn.IsEnabled),
+		slog.Bool("can_reply", conn.CanReply),
+	)
+
+	if b.cfg.OnConnection != nil {
+		b.cfg.OnConnection(ctx, conn)
+	}
+}
+
+func (b *Bot) handleMessage(ctx context.Context, msg *models.Message, handler MessageHandler) {
+	if handler == nil {
+		return
+	}
+
+	handler(ctx, b.lookupConnection(msg.BusinessConnectionID), msg)
+}
+
+func (b *Bot) handleDeleted(ctx context.Context, deleted *models.BusinessMessagesDeleted) {
+	if b.cfg.OnDeleted == nil {
+		return
+	}
+
+	b.cfg.OnDeleted(ctx, b.lookupConnection(deleted.BusinessConnectionID), deleted)
+}
+
+// lookupConnection returns the connection from the registry. Connections
+// established before the bot started are only known by their ID until the
+// next business_connection update.
+func (b *Bot) lookupConnection(id string) models.BusinessConnection {
+	if conn, ok := b.Connection(id); ok {
+		return conn
+	}
+
+	b.logger.Debug("message from unknown business connection",
+		slog.String("id", id),
+	)
+
+	return models.BusinessConnection{ID: id}
+}
@@ -0,0 +1,142 @@
+package businessbot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	tBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+// fakeSender records the sent messages per chat
+type fakeSender struct {
+	sent map[int64][]tgbot.Message
+	err  error
+}
+
+func (f *fakeSender) Send(chatID int64, msg tgbot.Message) (*models.Message, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.sent[chatID] = append(f.sent[chatID], msg)
+	return &models.Message{ID: len(f.sent[chatID])}, nil
+}
+
+func (f *fakeSender) SendTyping(int64) error {
+	return nil
+}
+
+func newTestBot(cfg Config) (*Bot, *fakeSender) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	sender := &fakeSender{sent: make(map[int64][]tgbot.Message)}
+
+	b := New(logger, cfg)
+	b.sender = sender
+
+	return b, sender
+}
+
+func TestBusinessMiddleware(t *testing.T) {
+	var (
+		connections []models.BusinessConnection
+		messages    []string
+		edited      []string
+		deleted     []int
+		passed      int
+	)
+
+	b, _ := newTestBot(Config{
+		OnConnection: func(_ context.Context, conn models.BusinessConnection) {
+			connections = append(connections, conn)
+		},
+		OnMessage: func(_ context.Context, conn models.BusinessConnection, msg *models.Message) {
+			messages = append(messages, conn.ID+":"+msg.Text)
+			require.Equal(t, int64(7), conn.User.ID)
+		},
+		OnEdited: func(_ context.Context, conn models.BusinessConnection, msg *models.Message) {
+			edited = append(edited, conn.ID+":"+msg.Text)
+		},
+		OnDeleted: func(_ context.Context, conn models.BusinessConnection, msgs *models.BusinessMessagesDeleted) {
+			require.Equal(t, "conn", conn.ID)
+			deleted = append(deleted, msgs.MessageIDs...)
+		},
+	})
+
+	handler := b.BusinessMiddleware()(func(context.Context, *tBot.Bot, *models.Update) {
+		passed++
+	})
+	ctx := context.Background()
+
+	conn := models.BusinessConnection{ID: "conn", User: models.User{ID: 7}, IsEnabled: true, CanReply: true}
+	handler(ctx, nil, &models.Update{BusinessConnection: &conn})
+
+	require.Equal(t, []models.BusinessConnection{conn}, connections)
+	registered, ok := b.Connection("conn")
+	require.True(t, ok)
+	require.Equal(t, conn, registered)
+
+	// Messages get the connection from the registry
+	handler(ctx, nil, &models.Update{BusinessMessage: &models.Message{BusinessConnectionID: "conn", Text: "hello"}})
+	handler(ctx, nil, &models.Update{EditedBusinessMessage: &models.Message{BusinessConnectionID: "other", Text: "edited"}})
+	handler(ctx, nil, &models.Update{DeletedBusinessMessages: &models.BusinessMessagesDeleted{
+		BusinessConnectionID: "conn",
+		MessageIDs:           []int{1, 2},
+	}})
+
+	require.Equal(t, []string{"conn:hello"}, messages)
+	// Unknown connections only have their ID
+	require.Equal(t, []string{"other:edited"}, edited)
+	require.Equal(t, []int{1, 2}, deleted)
+
+	// Other updates are passed on
+	require.Zero(t, passed)
+	handler(ctx, nil, &models.Update{Message: &models.Message{Text: "regular"}})
+	require.Equal(t, 1, passed)
+
+	// Updates without a handler are dropped
+	b, _ = newTestBot(Config{})
+	handler = b.BusinessMiddleware()(func(context.Context, *tBot.Bot, *models.Update) {
+		passed++
+	})
+	handler(ctx, nil, &models.Update{BusinessMessage: &models.Message{Text: "hello"}})
+	require.Equal(t, 1, passed)
+}
+
+func TestReply(t *testing.T) {
+	b, sender := newTestBot(Config{})
+
+	b.AddConnection(models.BusinessConnection{ID: "enabled", IsEnabled: true, CanReply: true})
+	b.AddConnection(models.BusinessConnection{ID: "disabled", IsEnabled: false, CanReply: true})
+	b.AddConnection(models.BusinessConnection{ID: "read-only", IsEnabled: true})
+
+	sent, err := b.Reply("enabled", 42, tgbot.Message{Text: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, 1, sent.ID)
+	require.Equal(t, []tgbot.Message{{Text: "hi", BusinessConnectionID: "enabled"}}, sender.sent[42])
+
+	_, err = b.Reply("disabled", 42, tgbot.Message{Text: "hi"})
+	require.ErrorIs(t, err, ErrConnectionDisabled)
+
+	_, err = b.Reply("read-only", 42, tgbot.Message{Text: "hi"})
+	require.ErrorIs(t, err, ErrCannotReply)
+	require.Len(t, sender.sent[42], 1)
+
+	// Unknown connections are left to the API
+	_, err = b.Reply("unknown", 43, tgbot.Message{Text: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, "unknown", sender.sent[43][0].BusinessConnectionID)
+
+	sender.err = errors.New("flood")
+	_, err = b.Reply("enabled", 42, tgbot.Message{Text: "hi"})
+	require.ErrorIs(t, err, sender.err)
+
+	require.Len(t, b.Connections(), 3)
+}
@@ -2,6 +2,11 @@ package loginbot
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-telegram/bot/models"
 	"golang.org/x/exp/slog"
@@ -11,12 +16,49 @@ import (
 	tBot "github.com/go-telegram/bot"
 )
 
+const (
+	cmdLogins      = "/logins"
+	cmdLoginAnswer = "/loginanswer"
+	cmdLoginCancel = "/logincancel"
+)
+
+// CommandSet declares the admin commands, they are hidden from the menu as
+// they only work in the admin chat
+func (b *Bot) CommandSet() tgbot.CommandSet {
+	if b.adminChat == 0 {
+		return nil
+	}
+
+	return tgbot.CommandSet{
+		{
+			Name:        strings.TrimPrefix(cmdLogins, "/"),
+			Handler:     b.handleLogins,
+			Description: "List pending login requests",
+			Hidden:      true,
+		},
+		{
+			Name:        strings.TrimPrefix(cmdLoginAnswer, "/"),
+			Handler:     b.handleLoginAnswer,
+			Description: "Answer a login request on behalf of the user",
+			Usage:       "<chat> <answer>",
+			Hidden:      true,
+		},
+		{
+			Name:        strings.TrimPrefix(cmdLoginCancel, "/"),
+			Handler:     b.handleLoginCancel,
+			Description: "Cancel a login request",
+			Usage:       "<chat>",
+			Hidden:      true,
+		},
+	}
+}
+
 func (b *Bot) Commands() map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
-	return map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update){}
+	return b.CommandSet().Handlers()
 }
 
 func (b *Bot) CommandsList() []models.BotCommand {
-	return []models.BotCommand{}
+	return b.CommandSet().List()
 }
 
 func (b *Bot) DefaultHandler() tBot.HandlerFunc {
@@ -48,16 +90,132 @@ func (b *Bot) handleMessage(ctx context.Context, bot *tBot.Bot, update *models.U
 		slog.String("text", update.Message.Text),
 	)
 
-	switch {
-	case b.HasOpenReq(id, reqType2Fa):
+	switch b.openReq(id) {
+	case reqType2Fa:
 		b.handle2FACallback(id, update.Message.Text)
-	case b.HasOpenReq(id, reqTypeCode):
+	case reqTypeCode:
 		b.handleCodeCallback(id, update.Message.Text)
-	case b.HasOpenReq(id, reqTypePhone):
+	case reqTypePhone:
 		b.handlePhoneCallback(id, update.Message.Text)
 	default:
-		if _, err := b.sender.Send(id, tgbot.Message{Text: "No open login requests"}); err != nil {
+		if err := b.send(id, TemplateNoOpenRequest, nil); err != nil {
 			b.logger.Error("failed to send login reply error", "error", err)
 		}
 	}
 }
+
+// handleLogins lists the pending login requests to the admin chat
+func (b *Bot) handleLogins(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	if !b.isAdminChat(update) {
+		return
+	}
+
+	waiting, err := b.conversations.Waiting(ctx)
+	if err != nil {
+		b.logger.Error("failed to list login requests", slog.String("err", err.Error()))
+		b.replyAdmin("Failed to list login requests")
+		return
+	}
+
+	if len(waiting) == 0 {
+		b.replyAdmin("No pending login requests")
+		return
+	}
+
+	chatIDs := make([]int64, 0, len(waiting))
+	for chatID := range waiting {
+		chatIDs = append(chatIDs, chatID)
+	}
+	sort.Slice(chatIDs, func(i, j int) bool {
+		return waiting[chatIDs[i]].Started.Before(waiting[chatIDs[j]].Started)
+	})
+
+	var text strings.Builder
+	text.WriteString("Pending login requests:\n")
+	for _, chatID := range chatIDs {
+		state := waiting[chatID]
+		fmt.Fprintf(&text, "\n%d: %s, waiting %s", chatID, state.Step, time.Since(state.Started).Round(time.Second))
+	}
+	fmt.Fprintf(&text, "\n\nAnswer with %s <chat> <answer>, cancel with %s <chat>", cmdLoginAnswer, cmdLoginCancel)
+
+	b.replyAdmin(text.String())
+}
+
+// handleLoginAnswer answers the open request of a user with the given text
+func (b *Bot) handleLoginAnswer(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	if !b.isAdminChat(update) {
+		return
+	}
+
+	// The answer is the rest of the text, 2FA passwords may contain spaces
+	chat, answer, _ := strings.Cut(tgbot.GetCommandArgs(update.Message.Text), " ")
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		b.replyAdmin("Usage: " + cmdLoginAnswer + " <chat> <answer>")
+		return
+	}
+
+	chatID, err := strconv.ParseInt(chat, 10, 64)
+	if err != nil {
+		b.replyAdmin("Invalid chat ID " + chat)
+		return
+	}
+
+	ok, err := b.conversations.Answer(ctx, chatID, answer)
+	switch {
+	case err != nil:
+		b.logger.Error("failed to answer login request",
+			slog.String("err", err.Error()),
+			slog.Int64("id", chatID),
+		)
+		b.replyAdmin("Failed to answer the login request")
+	case !ok:
+		b.replyAdmin(fmt.Sprintf("No open login request for %d", chatID))
+	default:
+		b.replyAdmin(fmt.Sprintf("Answered the login request of %d", chatID))
+	}
+}
+
+// handleLoginCancel cancels the open request of a user, the login fails
+// with ErrCanceled
+func (b *Bot) handleLoginCancel(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	if !b.isAdminChat(update) {
+		return
+	}
+
+	chatID, err := strconv.ParseInt(strings.TrimSpace(tgbot.GetCommandArgs(update.Message.Text)), 10, 64)
+	if err != nil {
+		b.replyAdmin("Usage: " + cmdLoginCancel + " <chat>")
+		return
+	}
+
+	if !b.HasOpenReq(chatID) {
+		b.replyAdmin(fmt.Sprintf("No open login request for %d", chatID))
+		return
+	}
+
+	if err := b.conversations.Cancel(ctx, chatID); err != nil {
+		b.logger.Error("failed to cancel login request",
+			slog.String("err", err.Error()),
+			slog.Int64("id", chatID),
+		)
+		b.replyAdmin("Failed to cancel the login request")
+		return
+	}
+
+	if err := b.send(chatID, TemplateLoginCanceled, nil); err != nil {
+		b.logger.Error("failed to send login canceled message", "error", err)
+	}
+
+	b.replyAdmin(fmt.Sprintf("Canceled the login request of %d", chatID))
+}
+
+func (b *Bot) isAdminChat(update *models.Update) bool {
+	return b.adminChat != 0 && update.Message != nil && update.Message.Chat.ID == b.adminChat
+}
+
+func (b *Bot) replyAdmin(text string) {
+	if _, err := b.sender.Send(b.adminChat, tgbot.Message{Text: text}); err != nil {
+		b.logger.Error("failed to reply to admin chat", "error", err)
+	}
+}
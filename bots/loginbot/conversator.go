@@ -2,6 +2,8 @@ package loginbot
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/celestix/gotgproto"
 	"golang.org/x/exp/slog"
@@ -16,6 +18,27 @@ type Conversator struct {
 	bot    *Bot
 	user   int64
 	phone  string
+
+	mu       sync.Mutex
+	started  time.Time
+	attempts int
+	result   *LoginResult
+}
+
+// LoginResult describes a completed login, so the embedding app can store
+// the account without parsing logs
+type LoginResult struct {
+	UserID   int64
+	Username string
+	Phone    string
+	// DC is the data center the account lives on
+	DC int
+	// SessionID identifies the stored session of the client
+	SessionID string
+	// Duration is the time from the first question to the completed login
+	Duration time.Duration
+	// Attempts is the number of codes and passwords asked, retries included
+	Attempts int
 }
 
 // NewConversator creates a new conversator sending the requests to the given chatID.
@@ -34,6 +57,8 @@ func (c *Conversator) AskPhoneNumber() (string, error) {
 		slog.Int64("user", c.user),
 	)
 
+	c.asked()
+
 	phone, err := c.bot.AskPhone(c.user)
 	if err != nil {
 		c.logger.Error("failed to ask phone number",
@@ -52,6 +77,9 @@ func (c *Conversator) AskCode() (string, error) {
 		slog.Int64("user", c.user),
 	)
 
+	c.asked()
+	c.attempt()
+
 	code, err := c.bot.SendCodeRequest(c.user)
 	if err != nil {
 		c.logger.Error("failed to ask code",
@@ -70,6 +98,9 @@ func (c *Conversator) AskPassword() (string, error) {
 		slog.Int64("user", c.user),
 	)
 
+	c.asked()
+	c.attempt()
+
 	code, err := c.bot.Ask2FACode(c.user)
 	if err != nil {
 		c.logger.Error("failed to ask 2fa code",
@@ -89,20 +120,26 @@ func (c *Conversator) AskPassword() (string, error) {
 // to enter the input correctly for the current auth status.
 // AuthStatus(authStatus AuthStatus)
 func (c *Conversator) AuthStatus(authStatus gotgproto.AuthStatus) {
-	var msg *tgbot.Message
+	var success bool
 
 	switch authStatus.Event {
 	case gotgproto.AuthStatusSuccess:
-		msg = &tgbot.Message{
-			Text:           fmt.Sprintf(loginSuccessMsg, c.phone),
-			TextFormatting: true,
-		}
+		success = true
 	case gotgproto.AuthStatusFloodWait:
 		c.logger.Debug("Telegram Login Auth Timeout",
 			slog.String("event", string(authStatus.Event)),
 			slog.Time("until", authStatus.Timeout),
 		)
+		c.notifyAdmin(slog.LevelWarn, "Login flood wait",
+			slog.Time("until", authStatus.Timeout),
+		)
 		return
+	case gotgproto.AuthStatusPhoneFailed,
+		gotgproto.AuthStatusPhoneCodeFailed,
+		gotgproto.AuthStatusPasswordFailed:
+		c.notifyAdmin(slog.LevelWarn, "Login failed",
+			slog.String("event", string(authStatus.Event)),
+		)
 	}
 
 	c.logger.Debug("Telegram Login Auth Status",
@@ -110,11 +147,11 @@ func (c *Conversator) AuthStatus(authStatus gotgproto.AuthStatus) {
 		slog.Int("attempts_left", authStatus.AttemptsLeft),
 	)
 
-	if msg == nil {
+	if !success {
 		return
 	}
 
-	if _, err := c.bot.sender.Send(c.user, *msg); err != nil {
+	if err := c.bot.send(c.user, TemplateLoginSuccess, map[string]any{"Phone": c.phone}); err != nil {
 		c.logger.Error("failed to send auth status",
 			slog.String("err", err.Error()),
 		)
@@ -127,6 +164,9 @@ func (c *Conversator) RetryPassword(attemptsLeft int) (string, error) {
 		slog.Int64("user", c.user),
 	)
 
+	c.asked()
+	c.attempt()
+
 	code, err := c.bot.Ask2FACode(c.user, attemptsLeft)
 	if err != nil {
 		c.logger.Error("failed to ask 2fa code",
@@ -139,3 +179,86 @@ func (c *Conversator) RetryPassword(attemptsLeft int) (string, error) {
 
 	return code, nil
 }
+
+// LoginCompleted is called by the mtproto client once it is authorized, it
+// emits the LoginResult when the login went through this conversator
+func (c *Conversator) LoginCompleted(userID int64, username string, dc int, sessionID string) {
+	c.mu.Lock()
+	if c.started.IsZero() || c.result != nil {
+		// A restored session, no login took place
+		c.mu.Unlock()
+		return
+	}
+
+	result := LoginResult{
+		UserID:    userID,
+		Username:  username,
+		Phone:     c.phone,
+		DC:        dc,
+		SessionID: sessionID,
+		Duration:  time.Since(c.started),
+		Attempts:  c.attempts,
+	}
+	c.result = &result
+	c.mu.Unlock()
+
+	c.logger.Info("login completed",
+		slog.Int64("user", c.user),
+		slog.Int64("account_id", userID),
+		slog.Int("dc", dc),
+		slog.Duration("duration", result.Duration),
+		slog.Int("attempts", result.Attempts),
+	)
+
+	if c.bot.onLogin != nil {
+		c.bot.onLogin(result)
+	}
+}
+
+// Result returns the result of the login, false until it completed
+func (c *Conversator) Result() (LoginResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.result == nil {
+		return LoginResult{}, false
+	}
+
+	return *c.result, true
+}
+
+// asked marks the start of the login at the first question
+func (c *Conversator) asked() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started.IsZero() {
+		c.started = time.Now()
+	}
+}
+
+func (c *Conversator) attempt() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.attempts++
+}
+
+// notifyAdmin alerts the admins if the sender supports admin notifications
+func (c *Conversator) notifyAdmin(level slog.Level, text string, fields ...slog.Attr) {
+	notifier, ok := c.bot.sender.(tgbot.AdminNotifier)
+	if !ok {
+		return
+	}
+
+	fields = append(fields,
+		slog.String("phone", c.phone),
+		slog.Int64("user", c.user),
+	)
+
+	if err := notifier.NotifyAdmin(level, text, fields...); err != nil {
+		c.logger.Error("failed to notify admin",
+			slog.String("err", err.Error()),
+		)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/dongri/phonenumber"
@@ -13,6 +12,7 @@ import (
 	"golang.org/x/exp/slog"
 
 	"github.com/Davincible/tgbot"
+	"github.com/Davincible/tgbot/cache"
 )
 
 var (
@@ -28,33 +28,36 @@ var (
 	ErrCanceled     = errors.New("request canceled")
 )
 
-const (
-	defaultTimeout  = 24 * 5 * time.Hour
-	cleanupInterval = time.Minute
-)
+const defaultTimeout = 24 * 5 * time.Hour
 
 type LoginCallback func(code string) error
 
 type Config struct {
 	Timeout time.Duration
-}
-
-type loginRequest struct {
-	reqType  string
-	response chan string
-	cancel   context.CancelFunc
-	created  time.Time
+	// Store keeps the pending requests, defaults to memory. With a
+	// persistent cache a code sent while the process restarts is picked up
+	// by the next request.
+	Store cache.Cache[tgbot.ConversationState]
+	// AdminChat may list pending requests with /logins, answer them on
+	// behalf of the user with /loginanswer and cancel them with
+	// /logincancel. Zero disables the admin commands.
+	AdminChat int64
+	// OnLogin is called with the result of every completed login
+	OnLogin func(LoginResult)
+	// Templates renders the messages to the user in their language, see the
+	// Template names. Templates it lacks in English are added, so use en as
+	// its default language or register every template in the default.
+	Templates *tgbot.Templates
 }
 
 type Bot struct {
-	logger *slog.Logger
-	sender tgbot.Sender
-	mutex  sync.RWMutex
-
-	loginRequests map[int64]map[string]*loginRequest
-	login2FAIdx   map[int64]int
-	timeout       time.Duration
-	done          chan struct{} // For graceful shutdown
+	logger    *slog.Logger
+	sender    tgbot.MessageSender
+	adminChat int64
+	onLogin   func(LoginResult)
+	templates *tgbot.Templates
+
+	conversations *tgbot.Conversations
 }
 
 // Create new login bot
@@ -64,37 +67,18 @@ func New(logger *slog.Logger, cfg Config) *Bot {
 		timeout = defaultTimeout
 	}
 
-	b := &Bot{
+	return &Bot{
 		logger:        logger,
-		loginRequests: make(map[int64]map[string]*loginRequest),
-		login2FAIdx:   make(map[int64]int),
-		timeout:       timeout,
-		done:          make(chan struct{}),
+		adminChat:     cfg.AdminChat,
+		onLogin:       cfg.OnLogin,
+		templates:     newTemplates(cfg.Templates),
+		conversations: tgbot.NewConversations(cfg.Store, timeout),
 	}
-
-	go b.cleanupStaleRequests()
-
-	return b
 }
 
-// Shutdown gracefully stops the bot and cleans up resources
+// Shutdown gracefully stops the bot, pending requests return ErrCanceled
 func (b *Bot) Shutdown(ctx context.Context) error {
-	close(b.done)
-
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-
-	// Cancel all pending requests
-	for _, requests := range b.loginRequests {
-		for _, req := range requests {
-			req.cancel()
-			close(req.response)
-		}
-	}
-
-	// Clear maps
-	b.loginRequests = make(map[int64]map[string]*loginRequest)
-	b.login2FAIdx = make(map[int64]int)
+	b.conversations.Close()
 
 	return nil
 }
@@ -114,100 +98,55 @@ func (b *Bot) Middleware() []tBot.Middleware {
 	}
 }
 
-func (b *Bot) cleanupStaleRequests() {
-	ticker := time.NewTicker(cleanupInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			b.mutex.Lock()
-			now := time.Now()
-
-			for chatID, requests := range b.loginRequests {
-				for reqType, req := range requests {
-					if now.Sub(req.created) > b.timeout {
-						req.cancel()
-						close(req.response)
-						delete(requests, reqType)
-					}
-				}
-
-				if len(requests) == 0 {
-					delete(b.loginRequests, chatID)
-					delete(b.login2FAIdx, chatID)
-				}
-			}
-			b.mutex.Unlock()
-
-		case <-b.done:
-			return
-		}
-	}
-}
-
-func (b *Bot) createRequest(chatID int64, reqType string) (chan string, context.Context, error) {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
+// await waits for the answer to the request
+func (b *Bot) await(chatID int64, reqType string) (string, error) {
+	answer, err := b.conversations.Await(context.Background(), chatID, reqType)
 
-	if _, ok := b.loginRequests[chatID]; !ok {
-		b.loginRequests[chatID] = make(map[string]*loginRequest)
-	}
-
-	if existing, ok := b.loginRequests[chatID][reqType]; ok {
-		existing.cancel()
-		close(existing.response)
-		delete(b.loginRequests[chatID], reqType)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
-	req := &loginRequest{
-		reqType:  reqType,
-		response: make(chan string, 1),
-		cancel:   cancel,
-		created:  time.Now(),
+	switch {
+	case errors.Is(err, tgbot.ErrConversationTimeout):
+		return "", ErrTimeout
+	case errors.Is(err, tgbot.ErrConversationCanceled):
+		return "", ErrCanceled
+	case err != nil:
+		return "", fmt.Errorf("await %s: %w", reqType, err)
 	}
 
-	b.loginRequests[chatID][reqType] = req
-
-	return req.response, ctx, nil
+	return answer, nil
 }
 
-func (b *Bot) getRequest(chatID int64, reqType string) (*loginRequest, bool) {
-	b.mutex.RLock()
-	defer b.mutex.RUnlock()
+// answer hands the answer to the open request of the chat
+func (b *Bot) answer(chatID int64, text string) {
+	ok, err := b.conversations.Answer(context.Background(), chatID, text)
+	if err != nil {
+		b.logger.Error("failed to answer login request",
+			slog.String("err", err.Error()),
+			slog.Int64("id", chatID),
+		)
+		return
+	}
 
-	chatReqs, ok := b.loginRequests[chatID]
 	if !ok {
-		return nil, false
+		b.logger.Error("no open login request",
+			slog.Int64("id", chatID),
+		)
 	}
-
-	req, ok := chatReqs[reqType]
-	return req, ok
 }
 
-func (b *Bot) hasAnyRequests(chatID int64) bool {
-	b.mutex.RLock()
-	defer b.mutex.RUnlock()
+// openReq returns the type of the open request of the chat
+func (b *Bot) openReq(chatID int64) string {
+	step, err := b.conversations.Step(context.Background(), chatID)
+	if err != nil {
+		b.logger.Error("failed to get login request",
+			slog.String("err", err.Error()),
+			slog.Int64("id", chatID),
+		)
+	}
 
-	m, ok := b.loginRequests[chatID]
-	return ok && len(m) > 0
+	return step
 }
 
-func (b *Bot) removeRequest(chatID int64, reqType string) {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-
-	if chatReqs, ok := b.loginRequests[chatID]; ok {
-		if req, ok := chatReqs[reqType]; ok {
-			req.cancel()
-			delete(chatReqs, reqType)
-		}
-		if len(chatReqs) == 0 {
-			delete(b.loginRequests, chatID)
-			delete(b.login2FAIdx, chatID)
-		}
-	}
+func (b *Bot) hasAnyRequests(chatID int64) bool {
+	return b.openReq(chatID) != ""
 }
 
 // Ask2FACode requests and waits for a 2FA code
@@ -218,167 +157,70 @@ func (b *Bot) Ask2FACode(chatID int64, i ...int) (string, error) {
 	}
 
 	if attemptLeft > 0 {
-		if _, err := b.sender.Send(chatID, tgbot.Message{
-			Text:           fmt.Sprintf(msg2FaIncorrect, attemptLeft),
-			TextFormatting: true,
-		}); err != nil {
+		if err := b.send(chatID, Template2FAIncorrect, map[string]any{"Attempts": attemptLeft}); err != nil {
 			return "", fmt.Errorf("send 2fa incorrect message: %w", err)
 		}
 		time.Sleep(time.Second)
 	}
 
-	if _, err := b.sender.Send(chatID, tgbot.Message{
-		Text: twofaCodeMsg,
-	}); err != nil {
+	if err := b.send(chatID, Template2FACode, nil); err != nil {
 		return "", fmt.Errorf("failed to send 2fa request: %w", err)
 	}
 
-	respChan, ctx, err := b.createRequest(chatID, reqType2Fa)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	b.mutex.Lock()
-	b.login2FAIdx[chatID] = attemptLeft + 1
-	b.mutex.Unlock()
-
-	select {
-	case resp, ok := <-respChan:
-		if !ok {
-			return "", ErrCanceled
-		}
-		return resp, nil
-	case <-ctx.Done():
-		b.removeRequest(chatID, reqType2Fa)
-		return "", ErrTimeout
-	}
+	return b.await(chatID, reqType2Fa)
 }
 
 // SendCodeRequest requests and waits for a login code
 func (b *Bot) SendCodeRequest(chatID int64) (string, error) {
-	if _, err := b.sender.Send(chatID, tgbot.Message{
-		Text: loginCodeMsg,
-	}); err != nil {
+	if err := b.send(chatID, TemplateLoginCode, nil); err != nil {
 		return "", fmt.Errorf("failed to send login code request: %w", err)
 	}
 
-	respChan, ctx, err := b.createRequest(chatID, reqTypeCode)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	select {
-	case resp, ok := <-respChan:
-		if !ok {
-			return "", ErrCanceled
-		}
-		return resp, nil
-	case <-ctx.Done():
-		b.removeRequest(chatID, reqTypeCode)
-		return "", ErrTimeout
-	}
+	return b.await(chatID, reqTypeCode)
 }
 
 // AskPhone requests and waits for a phone number
 func (b *Bot) AskPhone(chatID int64) (string, error) {
-	if _, err := b.sender.Send(chatID, tgbot.Message{
-		Text: phoneMsg,
-	}); err != nil {
+	if err := b.send(chatID, TemplatePhone, nil); err != nil {
 		return "", fmt.Errorf("failed to send phone request: %w", err)
 	}
 
-	respChan, ctx, err := b.createRequest(chatID, reqTypePhone)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	select {
-	case resp, ok := <-respChan:
-		if !ok {
-			return "", ErrCanceled
-		}
-		return resp, nil
-	case <-ctx.Done():
-		b.removeRequest(chatID, reqTypePhone)
-		return "", ErrTimeout
-	}
+	return b.await(chatID, reqTypePhone)
 }
 
 // Callback handlers
 func (b *Bot) handle2FACallback(chatID int64, text string) {
-	req, ok := b.getRequest(chatID, reqType2Fa)
-	if !ok {
-		b.logger.Error("no open login request",
-			slog.Int64("id", chatID),
-			slog.String("text", text),
-		)
-		return
-	}
-
 	code := strings.TrimSpace(text)
 	if len(code) == 0 {
-		if _, err := b.sender.Send(chatID, tgbot.Message{Text: "Invalid 2FA code"}); err != nil {
+		if err := b.send(chatID, TemplateInvalid2FA, nil); err != nil {
 			b.logger.Error("failed to send login code error", "error", err)
 		}
 		return
 	}
 
-	select {
-	case req.response <- code:
-		b.removeRequest(chatID, reqType2Fa)
-	default:
-		b.logger.Error("failed to send response - channel full or closed",
-			slog.Int64("id", chatID),
-		)
-	}
+	b.answer(chatID, code)
 }
 
 func (b *Bot) handleCodeCallback(chatID int64, text string) {
-	req, ok := b.getRequest(chatID, reqTypeCode)
-	if !ok {
-		b.logger.Error("no open login request",
-			slog.Int64("id", chatID),
-			slog.String("text", text),
-		)
-		return
-	}
-
 	code := extractCode(text)
 	if len(code) == 0 {
-		if _, err := b.sender.Send(chatID, tgbot.Message{
-			Text: "Text message does not contain a code, please try again.",
-		}); err != nil {
+		if err := b.send(chatID, TemplateInvalidCode, nil); err != nil {
 			b.logger.Error("failed to send login code error", "error", err)
 		}
 		return
 	}
 
-	select {
-	case req.response <- code:
-		b.removeRequest(chatID, reqTypeCode)
-	default:
-		b.logger.Error("failed to send response - channel full or closed",
-			slog.Int64("id", chatID),
-		)
-	}
+	b.answer(chatID, code)
 }
 
 func (b *Bot) handlePhoneCallback(chatID int64, text string) {
-	req, ok := b.getRequest(chatID, reqTypePhone)
-	if !ok {
-		b.logger.Error("no open login request",
-			slog.Int64("id", chatID),
-			slog.String("text", text),
-		)
-		return
-	}
-
-	phone := strings.TrimSpace(text)
-	country := phonenumber.GetISO3166ByNumber(phone, false).CountryCode
+	// The country lookup doesn't understand the international prefix
+	phone := strings.TrimPrefix(strings.TrimSpace(text), "+")
+	country := phonenumber.GetISO3166ByNumber(phone, false).Alpha2
 	phone = phonenumber.Parse(phone, country)
 
 	if len(phone) == 0 {
-		if _, err := b.sender.Send(chatID, tgbot.Message{Text: "Invalid phone number"}); err != nil {
+		if err := b.send(chatID, TemplateInvalidPhone, nil); err != nil {
 			b.logger.Error("failed to send phone error", "error", err)
 		}
 		return
@@ -388,30 +230,16 @@ func (b *Bot) handlePhoneCallback(chatID int64, text string) {
 		phone = "+" + phone
 	}
 
-	select {
-	case req.response <- phone:
-		b.removeRequest(chatID, reqTypePhone)
-	default:
-		b.logger.Error("failed to send response - channel full or closed",
-			slog.Int64("id", chatID),
-		)
-	}
+	b.answer(chatID, phone)
 }
 
 // HasOpenReq checks if there are any open requests for the given chat ID
 func (b *Bot) HasOpenReq(chatID int64, param ...string) bool {
-	b.mutex.RLock()
-	defer b.mutex.RUnlock()
-
-	user, ok := b.loginRequests[chatID]
-	if !ok {
-		return false
-	}
+	reqType := b.openReq(chatID)
 
 	if len(param) == 0 {
-		return len(user) > 0
+		return reqType != ""
 	}
 
-	_, ok = user[param[0]]
-	return ok
+	return reqType == param[0]
 }
@@ -0,0 +1,210 @@
+package loginbot
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	tBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+// fakeSender records the sent texts per chat
+type fakeSender struct {
+	mu   sync.Mutex
+	sent map[int64][]string
+}
+
+func (f *fakeSender) Send(chatID int64, msg tgbot.Message) (*models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sent[chatID] = append(f.sent[chatID], msg.Text)
+	return &models.Message{ID: len(f.sent[chatID])}, nil
+}
+
+func (f *fakeSender) SendTyping(int64) error {
+	return nil
+}
+
+func (f *fakeSender) texts(chatID int64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.sent[chatID]...)
+}
+
+const adminChat = 1000
+
+func newTestBot() (*Bot, *fakeSender) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	sender := &fakeSender{sent: make(map[int64][]string)}
+
+	b := New(logger, Config{AdminChat: adminChat})
+	b.sender = sender
+
+	return b, sender
+}
+
+type result struct {
+	answer string
+	err    error
+}
+
+// ask runs the request in the background until the bot waits for the answer
+func ask(t *testing.T, b *Bot, chatID int64, reqType string, request func() (string, error)) <-chan result {
+	t.Helper()
+
+	done := make(chan result, 1)
+	go func() {
+		answer, err := request()
+		done <- result{answer, err}
+	}()
+
+	require.Eventually(t, func() bool {
+		return b.HasOpenReq(chatID, reqType)
+	}, time.Second, time.Millisecond)
+
+	return done
+}
+
+func message(chatID int64, text string) *models.Update {
+	return &models.Update{Message: &models.Message{Chat: models.Chat{ID: chatID}, Text: text}}
+}
+
+func TestLoginMiddleware(t *testing.T) {
+	b, sender := newTestBot()
+	ctx := context.Background()
+
+	var passed []string
+	handler := b.LoginMiddlware()(func(_ context.Context, _ *tBot.Bot, update *models.Update) {
+		passed = append(passed, update.Message.Text)
+	})
+
+	// Without an open request codes are regular messages
+	handler(ctx, nil, message(1, "12345"))
+	require.Equal(t, []string{"12345"}, passed)
+
+	done := ask(t, b, 1, reqTypeCode, func() (string, error) { return b.SendCodeRequest(1) })
+	require.Equal(t, []string{defaultTemplates[TemplateLoginCode].Text}, sender.texts(1))
+
+	// Messages without a code and of other chats are passed on
+	handler(ctx, nil, message(1, "where is my code?"))
+	handler(ctx, nil, message(2, "54321"))
+	require.Equal(t, []string{"12345", "where is my code?", "54321"}, passed)
+
+	handler(ctx, nil, message(1, "the code is 54321, thanks"))
+
+	res := <-done
+	require.NoError(t, res.err)
+	require.Equal(t, "54321", res.answer)
+	require.False(t, b.HasOpenReq(1))
+
+	// 2FA codes are taken as sent
+	done = ask(t, b, 1, reqType2Fa, func() (string, error) { return b.Ask2FACode(1) })
+	handler(ctx, nil, message(1, " pass 12345 word "))
+
+	res = <-done
+	require.NoError(t, res.err)
+	require.Equal(t, "pass 12345 word", res.answer)
+	require.Len(t, passed, 3)
+}
+
+func TestHandleMessage(t *testing.T) {
+	b, sender := newTestBot()
+	ctx := context.Background()
+
+	// Phone numbers are normalized
+	done := ask(t, b, 1, reqTypePhone, func() (string, error) { return b.AskPhone(1) })
+
+	b.handleMessage(ctx, nil, message(1, "not a phone"))
+	require.Equal(t, defaultTemplates[TemplateInvalidPhone].Text, sender.texts(1)[1])
+	require.True(t, b.HasOpenReq(1, reqTypePhone))
+
+	b.handleMessage(ctx, nil, message(1, "+447911123456"))
+
+	res := <-done
+	require.NoError(t, res.err)
+	require.Equal(t, "+447911123456", res.answer)
+
+	// Texts without a code are rejected
+	done = ask(t, b, 1, reqTypeCode, func() (string, error) { return b.SendCodeRequest(1) })
+
+	b.handleMessage(ctx, nil, message(1, "1234"))
+	require.Equal(t, defaultTemplates[TemplateInvalidCode].Text, sender.texts(1)[3])
+
+	b.handleMessage(ctx, nil, message(1, "12345"))
+	require.Equal(t, "12345", (<-done).answer)
+
+	// Empty 2FA codes are rejected
+	done = ask(t, b, 1, reqType2Fa, func() (string, error) { return b.Ask2FACode(1) })
+
+	b.handleMessage(ctx, nil, message(1, "  "))
+	require.Equal(t, defaultTemplates[TemplateInvalid2FA].Text, sender.texts(1)[5])
+
+	b.handleMessage(ctx, nil, message(1, "secret"))
+	require.Equal(t, "secret", (<-done).answer)
+
+	b.handleMessage(ctx, nil, message(1, "12345"))
+	require.Equal(t, defaultTemplates[TemplateNoOpenRequest].Text, sender.texts(1)[6])
+}
+
+func TestAdminCommands(t *testing.T) {
+	b, sender := newTestBot()
+	ctx := context.Background()
+
+	require.Len(t, b.CommandSet(), 3)
+
+	// Other chats can't use the admin commands
+	b.handleLogins(ctx, nil, message(1, cmdLogins))
+	b.handleLoginAnswer(ctx, nil, message(1, cmdLoginAnswer+" 1 12345"))
+	require.Empty(t, sender.texts(1))
+	require.Empty(t, sender.texts(adminChat))
+
+	b.handleLogins(ctx, nil, message(adminChat, cmdLogins))
+	require.Equal(t, []string{"No pending login requests"}, sender.texts(adminChat))
+
+	code := ask(t, b, 1, reqTypeCode, func() (string, error) { return b.SendCodeRequest(1) })
+	password := ask(t, b, 2, reqType2Fa, func() (string, error) { return b.Ask2FACode(2) })
+
+	b.handleLogins(ctx, nil, message(adminChat, cmdLogins))
+	listing := sender.texts(adminChat)[1]
+	require.Contains(t, listing, "\n1: code, waiting")
+	require.Contains(t, listing, "\n2: 2fa, waiting")
+
+	// Answers may contain spaces
+	b.handleLoginAnswer(ctx, nil, message(adminChat, cmdLoginAnswer+" 2 correct horse battery"))
+	res := <-password
+	require.NoError(t, res.err)
+	require.Equal(t, "correct horse battery", res.answer)
+	require.Equal(t, "Answered the login request of 2", sender.texts(adminChat)[2])
+
+	b.handleLoginAnswer(ctx, nil, message(adminChat, cmdLoginAnswer+" 2 again"))
+	require.Equal(t, "No open login request for 2", sender.texts(adminChat)[3])
+
+	b.handleLoginAnswer(ctx, nil, message(adminChat, cmdLoginAnswer+" 2"))
+	require.Equal(t, "Usage: /loginanswer <chat> <answer>", sender.texts(adminChat)[4])
+
+	b.handleLoginAnswer(ctx, nil, message(adminChat, cmdLoginAnswer+" me 12345"))
+	require.Equal(t, "Invalid chat ID me", sender.texts(adminChat)[5])
+
+	// Canceling fails the login and tells the user
+	b.handleLoginCancel(ctx, nil, message(adminChat, cmdLoginCancel+" 1"))
+	res = <-code
+	require.ErrorIs(t, res.err, ErrCanceled)
+	require.Equal(t, defaultTemplates[TemplateLoginCanceled].Text, sender.texts(1)[1])
+	require.Equal(t, "Canceled the login request of 1", sender.texts(adminChat)[6])
+
+	b.handleLoginCancel(ctx, nil, message(adminChat, cmdLoginCancel+" 1"))
+	require.Equal(t, "No open login request for 1", sender.texts(adminChat)[7])
+
+	b.handleLoginCancel(ctx, nil, message(adminChat, cmdLoginCancel))
+	require.Equal(t, "Usage: /logincancel <chat>", sender.texts(adminChat)[8])
+}
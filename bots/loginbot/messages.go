@@ -1,13 +1,91 @@
 package loginbot
 
-var (
-	loginCodeMsg    = `🔐 Quick Start! Please enter the Telegram code you received:`
-	twofaCodeMsg    = `🔐 Please enter your 2FA code:`
-	msg2FaIncorrect = `🔐 *Oops!* Looks like the 2FA Code didn't match. 
-🌟 Please re-enter your code carefully. 
-👀 *Attempts Remaining:* %d 
-
-No worries, you've got this! 🔑`
-	phoneMsg        = `🔐 Please enter your phone number:`
-	loginSuccessMsg = `🎉 *Congratulations!* You have successfully logged into %s. 🎉`
+import (
+	"github.com/Davincible/tgbot"
 )
+
+// Names of the templates of the messages sent to the user, register them in
+// other languages on Config.Templates
+const (
+	TemplateLoginCode     = "login_code"
+	Template2FACode       = "login_2fa_code"
+	Template2FAIncorrect  = "login_2fa_incorrect"
+	TemplatePhone         = "login_phone"
+	TemplateLoginSuccess  = "login_success"
+	TemplateLoginCanceled = "login_canceled"
+	TemplateNoOpenRequest = "login_no_open_request"
+	TemplateInvalid2FA    = "login_invalid_2fa"
+	TemplateInvalidCode   = "login_invalid_code"
+	TemplateInvalidPhone  = "login_invalid_phone"
+)
+
+// defaultLanguage is the language of the built-in templates
+const defaultLanguage = "en"
+
+// defaultTemplates are the built-in English messages. Template2FAIncorrect
+// gets the attempts left as .Attempts, TemplateLoginSuccess the phone number
+// as .Phone.
+var defaultTemplates = map[string]tgbot.MessageTemplate{
+	TemplateLoginCode: {Text: `🔐 Quick Start! Please enter the Telegram code you received:`},
+	Template2FACode:   {Text: `🔐 Please enter your 2FA code:`},
+	Template2FAIncorrect: {
+		Text: `🔐 *Oops!* Looks like the 2FA Code didn't match.
+🌟 Please re-enter your code carefully.
+👀 *Attempts Remaining:* {{.Attempts}}
+
+No worries, you've got this! 🔑`,
+		TextFormatting: true,
+	},
+	TemplatePhone: {Text: `🔐 Please enter your phone number:`},
+	TemplateLoginSuccess: {
+		Text:           `🎉 *Congratulations!* You have successfully logged into {{.Phone}}. 🎉`,
+		TextFormatting: true,
+	},
+	TemplateLoginCanceled: {Text: `🔐 Your login request was canceled by support, please start again.`},
+	TemplateNoOpenRequest: {Text: `No open login requests`},
+	TemplateInvalid2FA:    {Text: `Invalid 2FA code`},
+	TemplateInvalidCode:   {Text: `Text message does not contain a code, please try again.`},
+	TemplateInvalidPhone:  {Text: `Invalid phone number`},
+}
+
+// localeSender is implemented by senders that know the language of a chat
+type localeSender interface {
+	Locale(userID int64) tgbot.Locale
+}
+
+// newTemplates adds the built-in templates missing from templates in
+// English
+func newTemplates(templates *tgbot.Templates) *tgbot.Templates {
+	if templates == nil {
+		templates = tgbot.NewTemplates(defaultLanguage)
+	}
+
+	for name, tmpl := range defaultTemplates {
+		if !templates.Has(defaultLanguage, name) {
+			templates.MustRegister(defaultLanguage, name, tmpl)
+		}
+	}
+
+	return templates
+}
+
+// language returns the language of the chat if the sender knows it
+func (b *Bot) language(chatID int64) string {
+	if l, ok := b.sender.(localeSender); ok {
+		return l.Locale(chatID).LanguageCode
+	}
+
+	return defaultLanguage
+}
+
+// send renders the template in the language of the chat and sends it
+func (b *Bot) send(chatID int64, name string, data any) error {
+	msg, err := b.templates.Render(b.language(chatID), name, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.sender.Send(chatID, msg)
+
+	return err
+}
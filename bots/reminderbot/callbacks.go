@@ -0,0 +1,136 @@
+package reminderbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+const (
+	cbSnooze = "reminder_snooze:"
+	cbDone   = "reminder_done:"
+	cbCancel = "reminder_cancel:"
+)
+
+func (b *Bot) CallBacks() map[string]tgbot.CallBack {
+	return map[string]tgbot.CallBack{
+		cbSnooze: tgbot.HandleCallback(cbSnooze, b.handleSnooze),
+		cbDone:   tgbot.HandleCallback(cbDone, b.handleDone),
+		cbCancel: tgbot.HandleCallback(cbCancel, b.handleCancel),
+	}
+}
+
+func (b *Bot) reminderButtons(id int64) []tgbot.InlineButton {
+	row := tgbot.InlineButton{}
+	for _, d := range b.cfg.Snooze {
+		row.Row = append(row.Row, tgbot.InlineButton{
+			Text:         "💤 " + formatDuration(d),
+			CallbackData: fmt.Sprintf("%s%d:%d", cbSnooze, id, int(d.Minutes())),
+		})
+	}
+
+	return []tgbot.InlineButton{
+		row,
+		{Text: "✅ Done", CallbackData: fmt.Sprintf("%s%d", cbDone, id)},
+	}
+}
+
+// handleSnooze reschedules a delivered reminder, data is "<id>:<minutes>"
+func (b *Bot) handleSnooze(ctx context.Context, cb *tgbot.CallbackContext) {
+	id, _ := cb.Int(0)
+	minutes, _ := cb.Int(1)
+
+	reminder, ok := b.chatReminder(cb, id)
+	if !ok || minutes <= 0 {
+		b.answer(ctx, cb, "This reminder no longer exists")
+		return
+	}
+
+	reminder.DueAt = time.Now().Add(time.Duration(minutes) * time.Minute)
+	reminder.DeliveredAt = time.Time{}
+
+	if err := b.store.Update(reminder); err != nil {
+		b.logger.Error("failed to snooze reminder", slog.String("err", err.Error()))
+		b.answer(ctx, cb, "Failed to snooze the reminder")
+		return
+	}
+
+	b.answer(ctx, cb, "Snoozed until "+b.formatTime(reminder.ChatID, reminder.DueAt))
+	b.closeMessage(cb, "💤 "+reminder.Text)
+}
+
+func (b *Bot) handleDone(ctx context.Context, cb *tgbot.CallbackContext) {
+	id, _ := cb.Int(0)
+
+	reminder, ok := b.chatReminder(cb, id)
+	if !ok {
+		b.answer(ctx, cb, "This reminder no longer exists")
+		return
+	}
+
+	if err := b.store.Delete(id); err != nil {
+		b.logger.Error("failed to delete reminder", slog.String("err", err.Error()))
+	}
+
+	b.answer(ctx, cb, "Done")
+	b.closeMessage(cb, "✅ "+reminder.Text)
+}
+
+func (b *Bot) handleCancel(ctx context.Context, cb *tgbot.CallbackContext) {
+	id, _ := cb.Int(0)
+
+	if _, ok := b.chatReminder(cb, id); !ok {
+		b.answer(ctx, cb, "This reminder no longer exists")
+		return
+	}
+
+	if err := b.store.Delete(id); err != nil {
+		b.answer(ctx, cb, "This reminder no longer exists")
+		return
+	}
+
+	b.answer(ctx, cb, "Reminder canceled")
+}
+
+// chatReminder returns the reminder of the callback, reminders of other
+// chats are treated as missing so their IDs can't be guessed
+func (b *Bot) chatReminder(cb *tgbot.CallbackContext, id int64) (Reminder, bool) {
+	reminder, err := b.store.Get(id)
+	if err != nil || reminder.ChatID != cb.ChatID {
+		return Reminder{}, false
+	}
+
+	return reminder, true
+}
+
+func (b *Bot) answer(ctx context.Context, cb *tgbot.CallbackContext, text string) {
+	if err := cb.Answer(ctx, text); err != nil {
+		b.logger.Error("failed to answer callback", slog.String("err", err.Error()))
+	}
+}
+
+// closeMessage replaces the delivered reminder with its final state, removing the buttons
+func (b *Bot) closeMessage(cb *tgbot.CallbackContext, text string) {
+	if cb.MessageID == 0 {
+		return
+	}
+
+	if _, err := b.getSender().EditMessage(cb.ChatID, cb.MessageID, tgbot.Message{Text: text}); err != nil {
+		b.logger.Error("failed to edit reminder", slog.String("err", err.Error()))
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
@@ -0,0 +1,216 @@
+package reminderbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+
+	tBot "github.com/go-telegram/bot"
+)
+
+const (
+	cmdRemind    = "/remind"
+	cmdReminders = "/myreminders"
+
+	askText = "What should I remind you of?"
+	askWhen = "When? E.g. 10m, 2h30m, 18:30, tomorrow 9:00 or 2024-05-01 10:00"
+)
+
+type wizardStep int
+
+const (
+	stepText wizardStep = iota
+	stepWhen
+)
+
+// wizard holds the state of a reminder being created step by step
+type wizard struct {
+	step wizardStep
+	text string
+}
+
+// wizardKey is the user creating a reminder in a chat, so members of a group
+// each have their own wizard
+type wizardKey struct {
+	chatID int64
+	userID int64
+}
+
+func wizardKeyOf(msg *models.Message) wizardKey {
+	return wizardKey{chatID: msg.Chat.ID, userID: msg.From.ID}
+}
+
+// CommandSet declares the commands, the service generates the menu and /help
+func (b *Bot) CommandSet() tgbot.CommandSet {
+	return tgbot.CommandSet{
+		{
+			Name:         strings.TrimPrefix(cmdRemind, "/"),
+			Handler:      b.handleRemind,
+			Description:  "Create a reminder",
+			Descriptions: map[string]string{"nl": "Maak een herinnering"},
+			Usage:        "[when] [text]",
+		},
+		{
+			Name:         strings.TrimPrefix(cmdReminders, "/"),
+			Handler:      b.handleList,
+			Description:  "List your reminders",
+			Descriptions: map[string]string{"nl": "Toon je herinneringen"},
+		},
+	}
+}
+
+func (b *Bot) Commands() map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	return b.CommandSet().Handlers()
+}
+
+func (b *Bot) CommandsList() []models.BotCommand {
+	return b.CommandSet().List()
+}
+
+func (b *Bot) DefaultHandler() tBot.HandlerFunc {
+	return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {}
+}
+
+// WizardMiddleware handles the replies to the reminder wizard
+func (b *Bot) WizardMiddleware() tBot.Middleware {
+	return func(next tBot.HandlerFunc) tBot.HandlerFunc {
+		return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+			msg := update.Message
+			if msg == nil || msg.From == nil {
+				next(ctx, bot, update)
+				return
+			}
+
+			b.mutex.Lock()
+			w, ok := b.wizards[wizardKeyOf(msg)]
+			if ok && strings.HasPrefix(msg.Text, "/") {
+				// Any command aborts the wizard
+				delete(b.wizards, wizardKeyOf(msg))
+				ok = false
+			}
+			b.mutex.Unlock()
+
+			if !ok {
+				next(ctx, bot, update)
+				return
+			}
+
+			b.handleWizard(msg, w)
+		}
+	}
+}
+
+// handleRemind creates a reminder from "/remind <when> <text>", or starts
+// the wizard if no arguments are given.
+func (b *Bot) handleRemind(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	msg := update.Message
+	if msg == nil || msg.From == nil {
+		return
+	}
+
+	_, args, _ := strings.Cut(msg.Text, " ")
+	args = strings.TrimSpace(args)
+
+	if len(args) == 0 {
+		b.mutex.Lock()
+		b.wizards[wizardKeyOf(msg)] = &wizard{step: stepText}
+		b.mutex.Unlock()
+
+		b.reply(msg.Chat.ID, askText)
+		return
+	}
+
+	dueAt, text, err := splitWhen(args, time.Now().In(b.location(msg.From.ID)))
+	if err != nil || len(text) == 0 {
+		b.reply(msg.Chat.ID, "Usage: /remind <when> <text>\n"+askWhen)
+		return
+	}
+
+	b.schedule(msg, text, dueAt)
+}
+
+func (b *Bot) handleWizard(msg *models.Message, w *wizard) {
+	switch w.step {
+	case stepText:
+		if len(strings.TrimSpace(msg.Text)) == 0 {
+			b.reply(msg.Chat.ID, askText)
+			return
+		}
+
+		b.mutex.Lock()
+		w.text = strings.TrimSpace(msg.Text)
+		w.step = stepWhen
+		b.mutex.Unlock()
+
+		b.reply(msg.Chat.ID, askWhen)
+	case stepWhen:
+		dueAt, err := parseWhen(msg.Text, time.Now().In(b.location(msg.From.ID)))
+		if err != nil {
+			b.reply(msg.Chat.ID, "I don't understand that time. "+askWhen)
+			return
+		}
+
+		b.mutex.Lock()
+		delete(b.wizards, wizardKeyOf(msg))
+		b.mutex.Unlock()
+
+		b.schedule(msg, w.text, dueAt)
+	}
+}
+
+func (b *Bot) schedule(msg *models.Message, text string, dueAt time.Time) {
+	reminder, err := b.Schedule(msg.Chat.ID, msg.From.ID, text, dueAt)
+	if err != nil {
+		b.logger.Error("failed to schedule reminder", slog.String("err", err.Error()))
+		b.reply(msg.Chat.ID, "Failed to create the reminder, please try again")
+		return
+	}
+
+	b.reply(msg.Chat.ID, fmt.Sprintf("👍 I will remind you on %s", b.formatTime(msg.From.ID, reminder.DueAt)))
+}
+
+func (b *Bot) handleList(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	reminders, err := b.store.List(msg.Chat.ID)
+	if err != nil {
+		b.logger.Error("failed to list reminders", slog.String("err", err.Error()))
+		return
+	}
+
+	if len(reminders) == 0 {
+		b.reply(msg.Chat.ID, "You have no reminders")
+		return
+	}
+
+	var text strings.Builder
+	var buttons []tgbot.InlineButton
+
+	text.WriteString("Your reminders:\n")
+	for i, reminder := range reminders {
+		fmt.Fprintf(&text, "\n%d. %s — %s", i+1, b.formatTime(msg.Chat.ID, reminder.DueAt), reminder.Text)
+		buttons = append(buttons, tgbot.InlineButton{
+			Text:         fmt.Sprintf("❌ Cancel %d", i+1),
+			CallbackData: fmt.Sprintf("%s%d", cbCancel, reminder.ID),
+		})
+	}
+
+	if _, err := b.getSender().Send(msg.Chat.ID, tgbot.Message{Text: text.String(), Buttons: buttons}); err != nil {
+		b.logger.Error("failed to send reminder list", slog.String("err", err.Error()))
+	}
+}
+
+func (b *Bot) reply(chatID int64, text string) {
+	if _, err := b.getSender().Send(chatID, tgbot.Message{Text: text}); err != nil {
+		b.logger.Error("failed to send reply", slog.String("err", err.Error()))
+	}
+}
@@ -0,0 +1,192 @@
+package reminderbot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tBot "github.com/go-telegram/bot"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+const (
+	defaultInterval  = 15 * time.Second
+	deliveredTimeout = 7 * 24 * time.Hour
+)
+
+var defaultSnooze = []time.Duration{10 * time.Minute, time.Hour, 24 * time.Hour}
+
+type Config struct {
+	// Store persists the reminders, defaults to an in-memory store
+	Store Store
+	// Interval between two checks for due reminders
+	Interval time.Duration
+	// Snooze are the durations offered as snooze buttons
+	Snooze []time.Duration
+}
+
+// messenger is the part of tgbot.Sender the bot uses
+type messenger interface {
+	tgbot.MessageSender
+	tgbot.MessageEditor
+}
+
+// localizer is implemented by senders that know the timezone of a chat
+type localizer interface {
+	Location(chatID int64) *time.Location
+}
+
+type Bot struct {
+	logger *slog.Logger
+	store  Store
+	cfg    Config
+
+	// sender is set by SetSender while the delivery loop runs
+	senderMu sync.RWMutex
+	sender   messenger
+
+	mutex   sync.Mutex
+	wizards map[wizardKey]*wizard
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Create new reminder bot, reminders are delivered until Shutdown is called
+func New(logger *slog.Logger, cfg Config) *Bot {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if len(cfg.Snooze) == 0 {
+		cfg.Snooze = defaultSnooze
+	}
+
+	b := &Bot{
+		logger:  logger,
+		store:   cfg.Store,
+		cfg:     cfg,
+		wizards: make(map[wizardKey]*wizard),
+		done:    make(chan struct{}),
+	}
+
+	go b.deliverLoop()
+
+	return b
+}
+
+// Shutdown stops the delivery of reminders, it can be called more than once
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.stopOnce.Do(func() { close(b.done) })
+	return nil
+}
+
+// Implement Bot interface
+func (b *Bot) SetSender(s tgbot.Sender) {
+	b.senderMu.Lock()
+	defer b.senderMu.Unlock()
+
+	b.sender = s
+}
+
+func (b *Bot) getSender() messenger {
+	b.senderMu.RLock()
+	defer b.senderMu.RUnlock()
+
+	return b.sender
+}
+
+func (b *Bot) Middleware() []tBot.Middleware {
+	return []tBot.Middleware{
+		b.WizardMiddleware(),
+	}
+}
+
+// Schedule adds a reminder for a chat
+func (b *Bot) Schedule(chatID, userID int64, text string, dueAt time.Time) (Reminder, error) {
+	reminder, err := b.store.Add(Reminder{
+		ChatID:    chatID,
+		UserID:    userID,
+		Text:      text,
+		DueAt:     dueAt,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return Reminder{}, fmt.Errorf("add reminder: %w", err)
+	}
+
+	return reminder, nil
+}
+
+// location returns the timezone of the chat if the sender knows it
+func (b *Bot) location(chatID int64) *time.Location {
+	if l, ok := b.getSender().(localizer); ok {
+		return l.Location(chatID)
+	}
+
+	return time.UTC
+}
+
+func (b *Bot) formatTime(chatID int64, t time.Time) string {
+	return t.In(b.location(chatID)).Format(tgbot.DefaultTimeLayout)
+}
+
+func (b *Bot) deliverLoop() {
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.deliverDue()
+
+			if err := b.store.DeleteDelivered(time.Now().Add(-deliveredTimeout)); err != nil {
+				b.logger.Error("failed to delete delivered reminders", slog.String("err", err.Error()))
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *Bot) deliverDue() {
+	sender := b.getSender()
+	if sender == nil {
+		return
+	}
+
+	reminders, err := b.store.Due(time.Now())
+	if err != nil {
+		b.logger.Error("failed to get due reminders", slog.String("err", err.Error()))
+		return
+	}
+
+	for _, reminder := range reminders {
+		if _, err := sender.Send(reminder.ChatID, tgbot.Message{
+			Text:    "⏰ " + reminder.Text,
+			Buttons: b.reminderButtons(reminder.ID),
+		}); err != nil {
+			b.logger.Error("failed to deliver reminder",
+				slog.String("err", err.Error()),
+				slog.Int64("id", reminder.ID),
+				slog.Int64("chat", reminder.ChatID),
+			)
+			continue
+		}
+
+		reminder.DeliveredAt = time.Now()
+		if err := b.store.Update(reminder); err != nil {
+			b.logger.Error("failed to mark reminder delivered",
+				slog.String("err", err.Error()),
+				slog.Int64("id", reminder.ID),
+			)
+		}
+	}
+}
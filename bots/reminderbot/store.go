@@ -0,0 +1,151 @@
+package reminderbot
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrReminderNotFound = errors.New("reminder not found")
+
+// Reminder is a message that is delivered to a chat at a given time
+type Reminder struct {
+	ID        int64
+	ChatID    int64
+	UserID    int64
+	Text      string
+	DueAt     time.Time
+	CreatedAt time.Time
+	// DeliveredAt is set once the reminder was sent, it is kept until the
+	// user marks it done so it can be snoozed
+	DeliveredAt time.Time
+}
+
+// Store persists reminders, implement it to keep reminders across restarts
+type Store interface {
+	// Add stores a new reminder and assigns its ID
+	Add(reminder Reminder) (Reminder, error)
+	// Get returns a reminder by ID
+	Get(id int64) (Reminder, error)
+	// Update replaces a stored reminder
+	Update(reminder Reminder) error
+	// Delete removes a reminder
+	Delete(id int64) error
+	// List returns the pending reminders of a chat ordered by due time
+	List(chatID int64) ([]Reminder, error)
+	// Due returns the undelivered reminders that are due before the given time
+	Due(before time.Time) ([]Reminder, error)
+	// DeleteDelivered removes the reminders delivered before the given time
+	DeleteDelivered(before time.Time) error
+}
+
+// MemoryStore is an in-memory reminder store
+type MemoryStore struct {
+	mu        sync.Mutex
+	lastID    int64
+	reminders map[int64]Reminder
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a new in-memory reminder store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{reminders: make(map[int64]Reminder)}
+}
+
+func (m *MemoryStore) Add(reminder Reminder) (Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	reminder.ID = m.lastID
+	m.reminders[reminder.ID] = reminder
+
+	return reminder, nil
+}
+
+func (m *MemoryStore) Get(id int64) (Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reminder, ok := m.reminders[id]
+	if !ok {
+		return Reminder{}, ErrReminderNotFound
+	}
+
+	return reminder, nil
+}
+
+func (m *MemoryStore) Update(reminder Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.reminders[reminder.ID]; !ok {
+		return ErrReminderNotFound
+	}
+
+	m.reminders[reminder.ID] = reminder
+	return nil
+}
+
+func (m *MemoryStore) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.reminders[id]; !ok {
+		return ErrReminderNotFound
+	}
+
+	delete(m.reminders, id)
+	return nil
+}
+
+func (m *MemoryStore) List(chatID int64) ([]Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var reminders []Reminder
+	for _, reminder := range m.reminders {
+		if reminder.ChatID == chatID && reminder.DeliveredAt.IsZero() {
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	sortByDue(reminders)
+	return reminders, nil
+}
+
+func (m *MemoryStore) Due(before time.Time) ([]Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var reminders []Reminder
+	for _, reminder := range m.reminders {
+		if reminder.DeliveredAt.IsZero() && !reminder.DueAt.After(before) {
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	sortByDue(reminders)
+	return reminders, nil
+}
+
+func (m *MemoryStore) DeleteDelivered(before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, reminder := range m.reminders {
+		if !reminder.DeliveredAt.IsZero() && reminder.DeliveredAt.Before(before) {
+			delete(m.reminders, id)
+		}
+	}
+
+	return nil
+}
+
+func sortByDue(reminders []Reminder) {
+	slices.SortFunc(reminders, func(a, b Reminder) int {
+		return a.DueAt.Compare(b.DueAt)
+	})
+}
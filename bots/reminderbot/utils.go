@@ -0,0 +1,87 @@
+package reminderbot
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTime = errors.New("invalid reminder time")
+
+var (
+	reDuration = regexp.MustCompile(`^(?:in\s+)?((?:\d+\s*[dhm]\s*)+)$`)
+	reDurPart  = regexp.MustCompile(`(\d+)\s*([dhm])`)
+	reClock    = regexp.MustCompile(`^(?:(today|tomorrow)\s+)?(?:at\s+)?(\d{1,2}):(\d{2})$`)
+)
+
+// parseWhen parses the time of a reminder relative to now. It accepts
+// durations like "10m", "in 2h30m" or "1d", clock times like "18:30" or
+// "tomorrow 9:00", and dates like "2024-05-01 10:00".
+func parseWhen(input string, now time.Time) (time.Time, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	if m := reDuration.FindStringSubmatch(input); m != nil {
+		var d time.Duration
+		for _, part := range reDurPart.FindAllStringSubmatch(m[1], -1) {
+			n, _ := strconv.Atoi(part[1])
+			switch part[2] {
+			case "d":
+				d += time.Duration(n) * 24 * time.Hour
+			case "h":
+				d += time.Duration(n) * time.Hour
+			case "m":
+				d += time.Duration(n) * time.Minute
+			}
+		}
+
+		if d <= 0 {
+			return time.Time{}, ErrInvalidTime
+		}
+
+		return now.Add(d), nil
+	}
+
+	if m := reClock.FindStringSubmatch(input); m != nil {
+		hour, _ := strconv.Atoi(m[2])
+		minute, _ := strconv.Atoi(m[3])
+		if hour > 23 || minute > 59 {
+			return time.Time{}, ErrInvalidTime
+		}
+
+		t := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+
+		switch {
+		case m[1] == "tomorrow":
+			t = t.AddDate(0, 0, 1)
+		case m[1] == "today" && !t.After(now):
+			return time.Time{}, ErrInvalidTime
+		case !t.After(now):
+			t = t.AddDate(0, 0, 1)
+		}
+
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 15:04", input, now.Location())
+	if err != nil || !t.After(now) {
+		return time.Time{}, ErrInvalidTime
+	}
+
+	return t, nil
+}
+
+// splitWhen splits "/remind <when> <text>" arguments into the time and the
+// reminder text, trying the longest time prefix first.
+func splitWhen(args string, now time.Time) (time.Time, string, error) {
+	fields := strings.Fields(args)
+
+	for i := min(len(fields)-1, 4); i > 0; i-- {
+		if t, err := parseWhen(strings.Join(fields[:i], " "), now); err == nil {
+			return t, strings.Join(fields[i:], " "), nil
+		}
+	}
+
+	return time.Time{}, "", ErrInvalidTime
+}
@@ -0,0 +1,55 @@
+package reminderbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWhen(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Time
+		err  bool
+	}{
+		{"10m", now.Add(10 * time.Minute), false},
+		{"in 2h 30m", now.Add(150 * time.Minute), false},
+		{"1d", now.Add(24 * time.Hour), false},
+		{"18:30", time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC), false},
+		{"9:00", time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC), false},
+		{"tomorrow at 9:00", time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC), false},
+		{"today 9:00", time.Time{}, true},
+		{"2024-06-01 10:00", time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), false},
+		{"2023-06-01 10:00", time.Time{}, true},
+		{"0m", time.Time{}, true},
+		{"soon", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseWhen(tt.in, now)
+			if tt.err {
+				require.ErrorIs(t, err, ErrInvalidTime)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitWhen(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	dueAt, text, err := splitWhen("tomorrow 9:00 call mom", now)
+	require.NoError(t, err)
+	require.Equal(t, "call mom", text)
+	require.Equal(t, time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC), dueAt)
+
+	_, _, err = splitWhen("call mom", now)
+	require.ErrorIs(t, err, ErrInvalidTime)
+}
@@ -0,0 +1,129 @@
+package reportbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+
+	tBot "github.com/go-telegram/bot"
+)
+
+const (
+	cbApprove = "report_approve:"
+	cbDelete  = "report_delete:"
+	cbBan     = "report_ban:"
+)
+
+func (b *Bot) CallBacks() map[string]tgbot.CallBack {
+	return map[string]tgbot.CallBack{
+		cbApprove: {Handler: b.handleAction(StatusApproved), MatchType: tBot.MatchTypePrefix},
+		cbDelete:  {Handler: b.handleAction(StatusDeleted), MatchType: tBot.MatchTypePrefix},
+		cbBan:     {Handler: b.handleAction(StatusBanned), MatchType: tBot.MatchTypePrefix},
+	}
+}
+
+func reportButtons(report Report) []tgbot.InlineButton {
+	if report.Status != StatusOpen {
+		return nil
+	}
+
+	row := tgbot.InlineButton{Row: []tgbot.InlineButton{
+		{Text: "✅ Approve", CallbackData: fmt.Sprintf("%s%d", cbApprove, report.ID)},
+		{Text: "🗑 Delete", CallbackData: fmt.Sprintf("%s%d", cbDelete, report.ID)},
+	}}
+
+	if report.AuthorID != 0 {
+		row.Row = append(row.Row, tgbot.InlineButton{
+			Text:         "⛔️ Ban",
+			CallbackData: fmt.Sprintf("%s%d", cbBan, report.ID),
+		})
+	}
+
+	return []tgbot.InlineButton{row}
+}
+
+// handleAction resolves a report from the admin chat queue
+func (b *Bot) handleAction(status Status) tBot.HandlerFunc {
+	return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+		query := update.CallbackQuery
+
+		msg := query.Message.Message
+		if msg == nil || msg.Chat.ID != b.cfg.AdminChatID {
+			b.answer(ctx, bot, query, "Not allowed")
+			return
+		}
+
+		_, idStr, _ := strings.Cut(query.Data, ":")
+		id, _ := strconv.ParseInt(idStr, 10, 64)
+
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		report, err := b.store.Get(id)
+		if err != nil || report.Status != StatusOpen {
+			b.answer(ctx, bot, query, "This report was already handled")
+			return
+		}
+
+		if err := b.apply(ctx, bot, report, status); err != nil {
+			b.logger.Error("failed to apply report action",
+				slog.String("err", err.Error()),
+				slog.Int64("report", report.ID),
+				slog.String("action", string(status)),
+			)
+			b.answer(ctx, bot, query, "Failed: "+err.Error())
+			return
+		}
+
+		report.Status = status
+		report.ResolvedBy = query.From.ID
+
+		if err := b.store.Update(report); err != nil {
+			b.logger.Error("failed to update report", slog.String("err", err.Error()))
+		}
+
+		if err := b.postQueue(&report); err != nil {
+			b.logger.Error("failed to update queue message", slog.String("err", err.Error()))
+		}
+
+		b.answer(ctx, bot, query, statusLabel(status))
+	}
+}
+
+func (b *Bot) apply(ctx context.Context, bot *tBot.Bot, report Report, status Status) error {
+	if status == StatusApproved {
+		return nil
+	}
+
+	if err := b.sender.DeleteMessage(report.ChatID, report.MessageID); err != nil {
+		return fmt.Errorf("delete reported message: %w", err)
+	}
+
+	if status != StatusBanned || report.AuthorID == 0 {
+		return nil
+	}
+
+	if _, err := bot.BanChatMember(ctx, &tBot.BanChatMemberParams{
+		ChatID: report.ChatID,
+		UserID: report.AuthorID,
+	}); err != nil {
+		return fmt.Errorf("ban author: %w", err)
+	}
+
+	return nil
+}
+
+func (b *Bot) answer(ctx context.Context, bot *tBot.Bot, query *models.CallbackQuery, text string) {
+	if _, err := bot.AnswerCallbackQuery(ctx, &tBot.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            text,
+	}); err != nil {
+		b.logger.Error("failed to answer callback", slog.String("err", err.Error()))
+	}
+}
@@ -0,0 +1,395 @@
+package reportbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+const (
+	cmdReport = "/report"
+
+	defaultReaction = "👎"
+	// recentMessages is the number of group messages remembered to resolve
+	// the author of reaction reports
+	recentMessages = 5000
+)
+
+var ErrNoAdminChat = errors.New("no admin chat configured")
+
+type Config struct {
+	// AdminChatID is the chat the moderation queue is posted to
+	AdminChatID int64
+	// Reaction flags a message when added by a user, defaults to 👎. Note that
+	// the bot only receives reactions if message_reaction is in the allowed
+	// updates and the bot is admin in the group.
+	Reaction string
+	// DisableReactions only accepts reports through /report
+	DisableReactions bool
+	// Threshold is the number of reports before a message enters the queue, defaults to 1
+	Threshold int
+	// Store persists the reports, defaults to memory
+	Store Store
+}
+
+type recentMessage struct {
+	authorID   int64
+	authorName string
+	text       string
+}
+
+// messenger is the part of tgbot.Sender the bot uses
+type messenger interface {
+	tgbot.MessageSender
+	tgbot.MessageEditor
+}
+
+type messageKey struct {
+	chatID    int64
+	messageID int
+}
+
+// Bot collects reports of group messages into a moderation queue in the
+// admin chat, where admins can approve, delete or ban with inline buttons.
+type Bot struct {
+	logger *slog.Logger
+	sender messenger
+	store  Store
+	cfg    Config
+
+	// mutex serializes the report aggregation and guards the recent messages
+	mutex      sync.Mutex
+	recent     map[messageKey]recentMessage
+	recentRing []messageKey
+	recentNext int
+}
+
+// Create new report bot
+func New(logger *slog.Logger, cfg Config) (*Bot, error) {
+	if cfg.AdminChatID == 0 {
+		return nil, ErrNoAdminChat
+	}
+
+	if len(cfg.Reaction) == 0 {
+		cfg.Reaction = defaultReaction
+	}
+
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 1
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	return &Bot{
+		logger:     logger,
+		store:      cfg.Store,
+		cfg:        cfg,
+		recent:     make(map[messageKey]recentMessage),
+		recentRing: make([]messageKey, 0, recentMessages),
+	}, nil
+}
+
+// Implement Bot interface
+func (b *Bot) SetSender(s tgbot.Sender) {
+	b.sender = s
+}
+
+func (b *Bot) Commands() map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	return map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update){
+		cmdReport: b.handleReport,
+	}
+}
+
+func (b *Bot) CommandsList() []models.BotCommand {
+	return []models.BotCommand{
+		{Command: strings.TrimPrefix(cmdReport, "/"), Description: "Report a message to the admins (reply to it)"},
+	}
+}
+
+func (b *Bot) DefaultHandler() tBot.HandlerFunc {
+	return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {}
+}
+
+func (b *Bot) Middleware() []tBot.Middleware {
+	return []tBot.Middleware{
+		b.ReportMiddleware(),
+	}
+}
+
+// ReportMiddleware remembers recent group messages and handles report
+// reactions, all updates are passed on to the next handler
+func (b *Bot) ReportMiddleware() tBot.Middleware {
+	return func(next tBot.HandlerFunc) tBot.HandlerFunc {
+		return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+			if msg := update.Message; msg != nil && msg.From != nil && msg.Chat.Type != tgbot.ChatTypePrivate {
+				b.remember(msg)
+			}
+
+			if reaction := update.MessageReaction; reaction != nil && !b.cfg.DisableReactions {
+				b.handleReaction(reaction)
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// handleReport files a report for the message the /report command replies to
+func (b *Bot) handleReport(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	msg := update.Message
+	if msg == nil || msg.From == nil || msg.Chat.Type == tgbot.ChatTypePrivate {
+		return
+	}
+
+	reported := msg.ReplyToMessage
+	if reported == nil {
+		b.reply(msg.Chat.ID, msg.ID, "Reply to the message you want to report with /report")
+		return
+	}
+
+	_, reason, _ := strings.Cut(msg.Text, " ")
+
+	report := Report{
+		ChatID:    msg.Chat.ID,
+		ChatTitle: msg.Chat.Title,
+		MessageID: reported.ID,
+		Text:      messageText(reported),
+	}
+
+	if reported.From != nil {
+		report.AuthorID = reported.From.ID
+		report.AuthorName = displayName(reported.From)
+	}
+
+	if err := b.file(report, msg.From.ID, strings.TrimSpace(reason)); err != nil {
+		b.logger.Error("failed to file report", slog.String("err", err.Error()))
+		return
+	}
+
+	b.reply(msg.Chat.ID, msg.ID, "Thanks, the admins have been notified")
+}
+
+func (b *Bot) handleReaction(reaction *models.MessageReactionUpdated) {
+	if reaction.User == nil || !hasEmoji(reaction.NewReaction, b.cfg.Reaction) || hasEmoji(reaction.OldReaction, b.cfg.Reaction) {
+		return
+	}
+
+	report := Report{
+		ChatID:    reaction.Chat.ID,
+		ChatTitle: reaction.Chat.Title,
+		MessageID: reaction.MessageID,
+	}
+
+	b.mutex.Lock()
+	if recent, ok := b.recent[messageKey{reaction.Chat.ID, reaction.MessageID}]; ok {
+		report.AuthorID = recent.authorID
+		report.AuthorName = recent.authorName
+		report.Text = recent.text
+	}
+	b.mutex.Unlock()
+
+	if err := b.file(report, reaction.User.ID, "reaction "+b.cfg.Reaction); err != nil {
+		b.logger.Error("failed to file reaction report", slog.String("err", err.Error()))
+	}
+}
+
+// file adds the reporter to the open report of the message, and posts or
+// updates the queue entry once the threshold is reached.
+func (b *Bot) file(report Report, reporterID int64, reason string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	existing, err := b.store.FindOpen(report.ChatID, report.MessageID)
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		report.Status = StatusOpen
+		report.CreatedAt = time.Now()
+		if report, err = b.store.Add(report); err != nil {
+			return fmt.Errorf("add report: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("find report: %w", err)
+	default:
+		if slices.Contains(existing.Reporters, reporterID) {
+			return nil
+		}
+		report = existing
+	}
+
+	report.Reporters = append(report.Reporters, reporterID)
+	if len(reason) > 0 {
+		report.Reasons = append(report.Reasons, reason)
+	}
+
+	if len(report.Reporters) >= b.cfg.Threshold {
+		if err := b.postQueue(&report); err != nil {
+			return err
+		}
+	}
+
+	if err := b.store.Update(report); err != nil {
+		return fmt.Errorf("update report: %w", err)
+	}
+
+	return nil
+}
+
+// postQueue posts the report to the admin chat, or updates the existing entry
+func (b *Bot) postQueue(report *Report) error {
+	msg := tgbot.Message{
+		Text:               formatReport(*report),
+		Buttons:            reportButtons(*report),
+		DisableLinkPreview: true,
+	}
+
+	if report.AdminMessageID != 0 {
+		if _, err := b.sender.EditMessage(b.cfg.AdminChatID, report.AdminMessageID, msg); err != nil {
+			return fmt.Errorf("update queue message: %w", err)
+		}
+		return nil
+	}
+
+	sent, err := b.sender.Send(b.cfg.AdminChatID, msg)
+	if err != nil {
+		return fmt.Errorf("post queue message: %w", err)
+	}
+
+	report.AdminMessageID = sent.ID
+
+	return nil
+}
+
+func (b *Bot) remember(msg *models.Message) {
+	key := messageKey{msg.Chat.ID, msg.ID}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.recentRing) < recentMessages {
+		b.recentRing = append(b.recentRing, key)
+	} else {
+		delete(b.recent, b.recentRing[b.recentNext])
+		b.recentRing[b.recentNext] = key
+		b.recentNext = (b.recentNext + 1) % recentMessages
+	}
+
+	b.recent[key] = recentMessage{
+		authorID:   msg.From.ID,
+		authorName: displayName(msg.From),
+		text:       messageText(msg),
+	}
+}
+
+func (b *Bot) reply(chatID int64, replyTo int, text string) {
+	if _, err := b.sender.Send(chatID, tgbot.Message{Text: text, ReplyTo: replyTo}); err != nil {
+		b.logger.Error("failed to send reply", slog.String("err", err.Error()))
+	}
+}
+
+func formatReport(report Report) string {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "🚩 Report #%d · %s\n", report.ID, statusLabel(report.Status))
+	fmt.Fprintf(&s, "Chat: %s\n", firstNonEmpty(report.ChatTitle, strconv.FormatInt(report.ChatID, 10)))
+
+	if report.AuthorID != 0 {
+		fmt.Fprintf(&s, "Author: %s (%d)\n", report.AuthorName, report.AuthorID)
+	}
+
+	fmt.Fprintf(&s, "Reports: %d\n", len(report.Reporters))
+
+	if len(report.Reasons) > 0 {
+		fmt.Fprintf(&s, "Reasons: %s\n", strings.Join(report.Reasons, "; "))
+	}
+
+	if len(report.Text) > 0 {
+		fmt.Fprintf(&s, "\n%s\n", truncate(report.Text, 500))
+	}
+
+	if link := messageLink(report.ChatID, report.MessageID); len(link) > 0 {
+		fmt.Fprintf(&s, "\n%s", link)
+	}
+
+	return s.String()
+}
+
+func statusLabel(status Status) string {
+	switch status {
+	case StatusApproved:
+		return "✅ approved"
+	case StatusDeleted:
+		return "🗑 deleted"
+	case StatusBanned:
+		return "⛔️ banned"
+	default:
+		return "open"
+	}
+}
+
+// messageLink returns the t.me link of a supergroup message
+func messageLink(chatID int64, messageID int) string {
+	id := strconv.FormatInt(chatID, 10)
+	if !strings.HasPrefix(id, "-100") {
+		return ""
+	}
+
+	return fmt.Sprintf("https://t.me/c/%s/%d", strings.TrimPrefix(id, "-100"), messageID)
+}
+
+func hasEmoji(reactions []models.ReactionType, emoji string) bool {
+	for _, reaction := range reactions {
+		if reaction.ReactionTypeEmoji != nil && reaction.ReactionTypeEmoji.Emoji == emoji {
+			return true
+		}
+	}
+
+	return false
+}
+
+func messageText(msg *models.Message) string {
+	if len(msg.Text) > 0 {
+		return msg.Text
+	}
+
+	return msg.Caption
+}
+
+func displayName(user *models.User) string {
+	if len(user.Username) > 0 {
+		return "@" + user.Username
+	}
+
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,364 @@
+package reportbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	tBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+const (
+	adminChat = -1001
+	groupChat = -1002
+)
+
+type edit struct {
+	chatID int64
+	msgID  int
+	msg    tgbot.Message
+}
+
+// fakeMessenger records the sent, edited and deleted messages
+type fakeMessenger struct {
+	mu      sync.Mutex
+	sent    map[int64][]tgbot.Message
+	edits   []edit
+	deleted []messageKey
+	err     error
+}
+
+func (f *fakeMessenger) Send(chatID int64, msg tgbot.Message) (*models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.sent[chatID] = append(f.sent[chatID], msg)
+	return &models.Message{ID: 100 + len(f.sent[chatID])}, nil
+}
+
+func (f *fakeMessenger) SendTyping(int64) error {
+	return nil
+}
+
+func (f *fakeMessenger) EditMessage(chatID int64, msgID int, msg tgbot.Message) (*models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.edits = append(f.edits, edit{chatID, msgID, msg})
+	return &models.Message{ID: msgID}, nil
+}
+
+func (f *fakeMessenger) DeleteMessage(chatID int64, msgID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+
+	f.deleted = append(f.deleted, messageKey{chatID, msgID})
+	return nil
+}
+
+func (f *fakeMessenger) PinMessage(int64, int, bool) error { return nil }
+func (f *fakeMessenger) UnpinMessage(int64, int) error     { return nil }
+func (f *fakeMessenger) UnpinAllMessages(int64) error      { return nil }
+
+func newTestBot(t *testing.T, cfg Config) (*Bot, *fakeMessenger) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	cfg.AdminChatID = adminChat
+
+	b, err := New(logger, cfg)
+	require.NoError(t, err)
+
+	sender := &fakeMessenger{sent: make(map[int64][]tgbot.Message)}
+	b.sender = sender
+
+	return b, sender
+}
+
+// apiCall is a request to the fake Bot API
+type apiCall struct {
+	method string
+	params map[string]string
+}
+
+// newTestAPI returns a bot talking to a fake Bot API that records the calls
+func newTestAPI(t *testing.T) (*tBot.Bot, func() []apiCall) {
+	t.Helper()
+
+	const token = "123456:test-token"
+
+	var (
+		mu    sync.Mutex
+		calls []apiCall
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseMultipartForm(1 << 20)
+
+		call := apiCall{method: strings.TrimPrefix(r.URL.Path, "/bot"+token+"/"), params: make(map[string]string)}
+		for key := range r.Form {
+			call.params[key] = r.FormValue(key)
+		}
+
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	bot, err := tBot.New(token, tBot.WithServerURL(srv.URL), tBot.WithSkipGetMe())
+	require.NoError(t, err)
+
+	return bot, func() []apiCall {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]apiCall(nil), calls...)
+	}
+}
+
+func groupMessage(id int, from int64, text string) *models.Message {
+	return &models.Message{
+		ID:   id,
+		Chat: models.Chat{ID: groupChat, Type: "supergroup", Title: "Group"},
+		From: &models.User{ID: from, FirstName: fmt.Sprintf("User%d", from)},
+		Text: text,
+	}
+}
+
+func reportCommand(id int, from int64, text string, reported *models.Message) *models.Update {
+	msg := groupMessage(id, from, text)
+	msg.ReplyToMessage = reported
+
+	return &models.Update{Message: msg}
+}
+
+func TestNew(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	_, err := New(logger, Config{})
+	require.ErrorIs(t, err, ErrNoAdminChat)
+}
+
+func TestReportCommand(t *testing.T) {
+	b, sender := newTestBot(t, Config{})
+	ctx := context.Background()
+
+	spam := groupMessage(10, 7, "buy now")
+	spam.From.Username = "spammer"
+
+	// Reports need a reply
+	b.handleReport(ctx, nil, reportCommand(11, 1, "/report", nil))
+	require.Equal(t, []tgbot.Message{{Text: "Reply to the message you want to report with /report", ReplyTo: 11}}, sender.sent[groupChat])
+	require.Empty(t, sender.sent[adminChat])
+
+	b.handleReport(ctx, nil, reportCommand(12, 1, "/report  spam ", spam))
+	require.Equal(t, "Thanks, the admins have been notified", sender.sent[groupChat][1].Text)
+
+	require.Len(t, sender.sent[adminChat], 1)
+	queued := sender.sent[adminChat][0]
+	require.Equal(t, "🚩 Report #1 · open\n"+
+		"Chat: Group\n"+
+		"Author: @spammer (7)\n"+
+		"Reports: 1\n"+
+		"Reasons: spam\n"+
+		"\nbuy now\n"+
+		"\nhttps://t.me/c/2/10", queued.Text)
+	require.Len(t, queued.Buttons, 1)
+	require.Equal(t, []string{"report_approve:1", "report_delete:1", "report_ban:1"}, []string{
+		queued.Buttons[0].Row[0].CallbackData,
+		queued.Buttons[0].Row[1].CallbackData,
+		queued.Buttons[0].Row[2].CallbackData,
+	})
+
+	// A second reporter updates the queue entry, the same reporter is ignored
+	b.handleReport(ctx, nil, reportCommand(13, 2, "/report", spam))
+	b.handleReport(ctx, nil, reportCommand(14, 2, "/report again", spam))
+
+	require.Len(t, sender.sent[adminChat], 1)
+	require.Len(t, sender.edits, 1)
+	require.Equal(t, int64(adminChat), sender.edits[0].chatID)
+	require.Equal(t, 101, sender.edits[0].msgID)
+	require.Contains(t, sender.edits[0].msg.Text, "Reports: 2\n")
+
+	report, err := b.store.Get(1)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, report.Reporters)
+	require.Equal(t, []string{"spam"}, report.Reasons)
+	require.Equal(t, 101, report.AdminMessageID)
+
+	// Private chats can't report
+	private := reportCommand(15, 1, "/report", spam)
+	private.Message.Chat.Type = tgbot.ChatTypePrivate
+	b.handleReport(ctx, nil, private)
+	require.Len(t, sender.sent[groupChat], 4)
+}
+
+func TestReportThreshold(t *testing.T) {
+	b, sender := newTestBot(t, Config{Threshold: 2})
+	ctx := context.Background()
+
+	spam := groupMessage(10, 7, "buy now")
+
+	b.handleReport(ctx, nil, reportCommand(11, 1, "/report", spam))
+	require.Empty(t, sender.sent[adminChat])
+
+	b.handleReport(ctx, nil, reportCommand(12, 2, "/report", spam))
+	require.Len(t, sender.sent[adminChat], 1)
+	require.Contains(t, sender.sent[adminChat][0].Text, "Author: User7 (7)\n")
+}
+
+func TestReactionReport(t *testing.T) {
+	b, sender := newTestBot(t, Config{})
+
+	var passed int
+	handler := b.ReportMiddleware()(func(context.Context, *tBot.Bot, *models.Update) {
+		passed++
+	})
+	ctx := context.Background()
+
+	handler(ctx, nil, &models.Update{Message: groupMessage(10, 7, "buy now")})
+
+	emojis := func(emoji string) []models.ReactionType {
+		if len(emoji) == 0 {
+			return nil
+		}
+
+		return []models.ReactionType{{
+			Type:              models.ReactionTypeTypeEmoji,
+			ReactionTypeEmoji: &models.ReactionTypeEmoji{Emoji: emoji},
+		}}
+	}
+
+	reaction := func(from int64, old, new string) *models.Update {
+		return &models.Update{MessageReaction: &models.MessageReactionUpdated{
+			Chat:        models.Chat{ID: groupChat, Title: "Group"},
+			MessageID:   10,
+			User:        &models.User{ID: from},
+			OldReaction: emojis(old),
+			NewReaction: emojis(new),
+		}}
+	}
+
+	// Other reactions and already reacted users are ignored
+	handler(ctx, nil, reaction(1, "", "👍"))
+	handler(ctx, nil, reaction(1, defaultReaction, defaultReaction))
+	require.Empty(t, sender.sent[adminChat])
+
+	handler(ctx, nil, reaction(1, "👍", defaultReaction))
+	require.Len(t, sender.sent[adminChat], 1)
+	require.Contains(t, sender.sent[adminChat][0].Text, "Author: User7 (7)\n")
+	require.Contains(t, sender.sent[adminChat][0].Text, "Reasons: reaction 👎\n")
+	require.Contains(t, sender.sent[adminChat][0].Text, "\nbuy now\n")
+
+	// All updates are passed on
+	require.Equal(t, 4, passed)
+
+	b, sender = newTestBot(t, Config{DisableReactions: true})
+	b.ReportMiddleware()(func(context.Context, *tBot.Bot, *models.Update) {})(ctx, nil, reaction(1, "", defaultReaction))
+	require.Empty(t, sender.sent[adminChat])
+}
+
+func TestHandleAction(t *testing.T) {
+	b, sender := newTestBot(t, Config{})
+	bot, calls := newTestAPI(t)
+	ctx := context.Background()
+
+	for id := 10; id <= 12; id++ {
+		b.handleReport(ctx, nil, reportCommand(id+10, 1, "/report", groupMessage(id, 7, "buy now")))
+	}
+
+	callback := func(chatID int64, data string) *models.Update {
+		return &models.Update{CallbackQuery: &models.CallbackQuery{
+			ID:   data,
+			From: models.User{ID: 99},
+			Message: models.MaybeInaccessibleMessage{
+				Message: &models.Message{ID: 101, Chat: models.Chat{ID: chatID}},
+			},
+			Data: data,
+		}}
+	}
+
+	callbacks := b.CallBacks()
+	handle := func(chatID int64, data string) {
+		prefix, _, _ := strings.Cut(data, ":")
+		callbacks[prefix+":"].Handler(ctx, bot, callback(chatID, data))
+	}
+
+	// Only admins can resolve reports
+	handle(groupChat, "report_approve:1")
+	require.Equal(t, "Not allowed", calls()[0].params["text"])
+
+	handle(adminChat, "report_approve:1")
+	require.Empty(t, sender.deleted)
+	require.Equal(t, statusLabel(StatusApproved), calls()[1].params["text"])
+
+	report, err := b.store.Get(1)
+	require.NoError(t, err)
+	require.Equal(t, StatusApproved, report.Status)
+	require.Equal(t, int64(99), report.ResolvedBy)
+
+	// The queue entry shows the status without buttons
+	require.Len(t, sender.edits, 1)
+	require.Contains(t, sender.edits[0].msg.Text, "🚩 Report #1 · ✅ approved\n")
+	require.Empty(t, sender.edits[0].msg.Buttons)
+
+	handle(adminChat, "report_delete:1")
+	require.Equal(t, "This report was already handled", calls()[2].params["text"])
+
+	handle(adminChat, "report_delete:2")
+	require.Equal(t, []messageKey{{groupChat, 11}}, sender.deleted)
+
+	handle(adminChat, "report_ban:3")
+	require.Equal(t, []messageKey{{groupChat, 11}, {groupChat, 12}}, sender.deleted)
+
+	var bans []apiCall
+	for _, call := range calls() {
+		if call.method == "banChatMember" {
+			bans = append(bans, call)
+		}
+	}
+	require.Len(t, bans, 1)
+	require.Equal(t, fmt.Sprint(groupChat), bans[0].params["chat_id"])
+	require.Equal(t, "7", bans[0].params["user_id"])
+
+	report, err = b.store.Get(3)
+	require.NoError(t, err)
+	require.Equal(t, StatusBanned, report.Status)
+
+	// Failed actions leave the report open
+	b.handleReport(ctx, nil, reportCommand(23, 1, "/report", groupMessage(13, 7, "buy now")))
+
+	sender.err = errors.New("flood")
+	handle(adminChat, "report_delete:4")
+
+	answers := calls()
+	require.Equal(t, "Failed: delete reported message: flood", answers[len(answers)-1].params["text"])
+
+	open, err := b.store.ListOpen()
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	require.Equal(t, int64(4), open[0].ID)
+}
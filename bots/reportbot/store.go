@@ -0,0 +1,131 @@
+package reportbot
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrReportNotFound = errors.New("report not found")
+
+// Status is the moderation state of a report
+type Status string
+
+const (
+	StatusOpen     Status = "open"
+	StatusApproved Status = "approved"
+	StatusDeleted  Status = "deleted"
+	StatusBanned   Status = "banned"
+)
+
+// Report aggregates all reports of a single message
+type Report struct {
+	ID        int64
+	ChatID    int64
+	ChatTitle string
+	MessageID int
+	// AuthorID is the author of the reported message, zero if unknown
+	AuthorID   int64
+	AuthorName string
+	Text       string
+	Reporters  []int64
+	Reasons    []string
+	Status     Status
+	// AdminMessageID is the queue message in the admin chat
+	AdminMessageID int
+	CreatedAt      time.Time
+	ResolvedBy     int64
+}
+
+// Store persists the moderation queue
+type Store interface {
+	// Add stores a new report and assigns its ID
+	Add(report Report) (Report, error)
+	Get(id int64) (Report, error)
+	Update(report Report) error
+	// FindOpen returns the open report of a message
+	FindOpen(chatID int64, messageID int) (Report, error)
+	// ListOpen returns all open reports, oldest first
+	ListOpen() ([]Report, error)
+}
+
+// MemoryStore is an in-memory report store
+type MemoryStore struct {
+	mu      sync.Mutex
+	lastID  int64
+	reports map[int64]Report
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a new in-memory report store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{reports: make(map[int64]Report)}
+}
+
+func (m *MemoryStore) Add(report Report) (Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	report.ID = m.lastID
+	m.reports[report.ID] = report
+
+	return report, nil
+}
+
+func (m *MemoryStore) Get(id int64) (Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report, ok := m.reports[id]
+	if !ok {
+		return Report{}, ErrReportNotFound
+	}
+
+	return report, nil
+}
+
+func (m *MemoryStore) Update(report Report) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.reports[report.ID]; !ok {
+		return ErrReportNotFound
+	}
+
+	m.reports[report.ID] = report
+	return nil
+}
+
+func (m *MemoryStore) FindOpen(chatID int64, messageID int) (Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, report := range m.reports {
+		if report.ChatID == chatID && report.MessageID == messageID && report.Status == StatusOpen {
+			return report, nil
+		}
+	}
+
+	return Report{}, ErrReportNotFound
+}
+
+func (m *MemoryStore) ListOpen() ([]Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var reports []Report
+	for _, report := range m.reports {
+		if report.Status == StatusOpen {
+			reports = append(reports, report)
+		}
+	}
+
+	slices.SortFunc(reports, func(a, b Report) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return reports, nil
+}
@@ -0,0 +1,54 @@
+package statsbot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+const (
+	chartWidth   = 800
+	chartHeight  = 400
+	chartPadding = 20
+	chartGap     = 4
+)
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartBar        = color.RGBA{0x2a, 0x9d, 0xf4, 0xff}
+	chartAxis       = color.RGBA{0x99, 0x99, 0x99, 0xff}
+)
+
+// renderChart draws a bar chart of the messages per day as PNG
+func renderChart(days []DayCount) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	base := chartHeight - chartPadding
+	draw.Draw(img, image.Rect(chartPadding, base, chartWidth-chartPadding, base+1), &image.Uniform{chartAxis}, image.Point{}, draw.Src)
+
+	peak := 0
+	for _, day := range days {
+		peak = max(peak, day.Count)
+	}
+
+	if len(days) > 0 && peak > 0 {
+		slot := (chartWidth - 2*chartPadding) / len(days)
+		for i, day := range days {
+			height := day.Count * (chartHeight - 2*chartPadding) / peak
+			x := chartPadding + i*slot
+			bar := image.Rect(x+chartGap/2, base-height, x+max(slot-chartGap/2, chartGap/2+1), base)
+			draw.Draw(img, bar, &image.Uniform{chartBar}, image.Point{}, draw.Src)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode chart: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
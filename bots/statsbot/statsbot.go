@@ -0,0 +1,234 @@
+package statsbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tBot "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+const (
+	cmdChatStats = "/chatstats"
+
+	defaultDays = 7
+	maxDays     = 90
+	topUsers    = 10
+)
+
+type Config struct {
+	// Store aggregates the message counts, defaults to memory
+	Store Store
+	// Chart attaches a bar chart of the messages per day to the summary
+	Chart bool
+	// AdminsOnly restricts /chatstats to the chat administrators
+	AdminsOnly bool
+}
+
+// Bot counts the messages in groups and renders a summary with /chatstats
+type Bot struct {
+	logger *slog.Logger
+	sender tgbot.MessageSender
+	store  Store
+	cfg    Config
+}
+
+// Create new chat stats bot
+func New(logger *slog.Logger, cfg Config) *Bot {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	return &Bot{
+		logger: logger,
+		store:  cfg.Store,
+		cfg:    cfg,
+	}
+}
+
+// Implement Bot interface
+func (b *Bot) SetSender(s tgbot.Sender) {
+	b.sender = s
+}
+
+func (b *Bot) Commands() map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	return map[string]func(ctx context.Context, bot *tBot.Bot, update *models.Update){
+		cmdChatStats: b.handleChatStats,
+	}
+}
+
+func (b *Bot) CommandsList() []models.BotCommand {
+	return []models.BotCommand{
+		{Command: strings.TrimPrefix(cmdChatStats, "/"), Description: "Show chat activity statistics"},
+	}
+}
+
+func (b *Bot) CallBacks() map[string]tgbot.CallBack {
+	return map[string]tgbot.CallBack{}
+}
+
+func (b *Bot) DefaultHandler() tBot.HandlerFunc {
+	return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {}
+}
+
+func (b *Bot) Middleware() []tBot.Middleware {
+	return []tBot.Middleware{
+		b.CountMiddleware(),
+	}
+}
+
+// CountMiddleware records every group message in the stats store
+func (b *Bot) CountMiddleware() tBot.Middleware {
+	return func(next tBot.HandlerFunc) tBot.HandlerFunc {
+		return func(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+			if msg := update.Message; msg != nil && msg.From != nil && !msg.From.IsBot && isGroup(msg.Chat) {
+				if err := b.store.Record(msg.Chat.ID, msg.From.ID, displayName(msg.From), time.Unix(int64(msg.Date), 0)); err != nil {
+					b.logger.Error("failed to record message", slog.String("err", err.Error()))
+				}
+			}
+
+			next(ctx, bot, update)
+		}
+	}
+}
+
+// handleChatStats renders the stats of the last days, "/chatstats 30"
+func (b *Bot) handleChatStats(ctx context.Context, bot *tBot.Bot, update *models.Update) {
+	msg := update.Message
+	if msg == nil || msg.From == nil {
+		return
+	}
+
+	if !isGroup(msg.Chat) {
+		b.reply(msg, tgbot.Message{Text: "Chat statistics are only available in groups"})
+		return
+	}
+
+	if ok, err := b.allowed(ctx, bot, msg); err != nil || !ok {
+		if err != nil {
+			b.logger.Error("failed to check permissions", slog.String("err", err.Error()))
+		}
+		return
+	}
+
+	days := defaultDays
+	if _, arg, ok := strings.Cut(msg.Text, " "); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(arg)); err == nil && n > 0 {
+			days = min(n, maxDays)
+		}
+	}
+
+	stats, err := b.store.Stats(msg.Chat.ID, time.Now().AddDate(0, 0, -(days-1)))
+	if err != nil {
+		b.logger.Error("failed to get chat stats", slog.String("err", err.Error()))
+		return
+	}
+
+	reply := tgbot.Message{Text: formatStats(stats, days)}
+
+	if b.cfg.Chart && stats.Total > 0 {
+		chart, err := renderChart(stats.Days)
+		if err != nil {
+			b.logger.Error("failed to render chart", slog.String("err", err.Error()))
+		} else {
+			reply.Image = chart
+		}
+	}
+
+	b.reply(msg, reply)
+}
+
+// allowed checks that the bot is admin in the chat, so it sees all
+// messages, and that the user is admin if AdminsOnly is set.
+func (b *Bot) allowed(ctx context.Context, bot *tBot.Bot, msg *models.Message) (bool, error) {
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		return false, fmt.Errorf("get me: %w", err)
+	}
+
+	isAdmin, err := chatAdmin(ctx, bot, msg.Chat.ID, me.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if !isAdmin {
+		b.reply(msg, tgbot.Message{Text: "Make me an admin of this group to collect statistics"})
+		return false, nil
+	}
+
+	if !b.cfg.AdminsOnly {
+		return true, nil
+	}
+
+	if isAdmin, err = chatAdmin(ctx, bot, msg.Chat.ID, msg.From.ID); err != nil || !isAdmin {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Bot) reply(msg *models.Message, reply tgbot.Message) {
+	reply.ReplyTo = msg.ID
+	if _, err := b.sender.Send(msg.Chat.ID, reply); err != nil {
+		b.logger.Error("failed to send chat stats", slog.String("err", err.Error()))
+	}
+}
+
+func chatAdmin(ctx context.Context, bot *tBot.Bot, chatID, userID int64) (bool, error) {
+	member, err := bot.GetChatMember(ctx, &tBot.GetChatMemberParams{
+		ChatID: chatID,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("get chat member: %w", err)
+	}
+
+	return member.Type == models.ChatMemberTypeOwner || member.Type == models.ChatMemberTypeAdministrator, nil
+}
+
+func formatStats(stats Stats, days int) string {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "📊 Chat statistics, last %d days\n\n", days)
+	fmt.Fprintf(&s, "Messages: %d\n", stats.Total)
+
+	if stats.Total == 0 {
+		return s.String()
+	}
+
+	fmt.Fprintf(&s, "Active users: %d\n", len(stats.Users))
+	fmt.Fprintf(&s, "Daily average: %.1f\n", float64(stats.Total)/float64(len(stats.Days)))
+
+	busiest := stats.Days[0]
+	for _, day := range stats.Days {
+		if day.Count > busiest.Count {
+			busiest = day
+		}
+	}
+	fmt.Fprintf(&s, "Busiest day: %s (%d)\n", busiest.Day.Format("Mon 2 Jan"), busiest.Count)
+
+	s.WriteString("\nTop users:\n")
+	for i, user := range stats.Users[:min(topUsers, len(stats.Users))] {
+		fmt.Fprintf(&s, "%d. %s — %d (%.0f%%)\n", i+1, user.Name, user.Count, 100*float64(user.Count)/float64(stats.Total))
+	}
+
+	return s.String()
+}
+
+func isGroup(chat models.Chat) bool {
+	return chat.Type == tgbot.ChatTypeGroup || chat.Type == tgbot.ChatTypeSupergroup
+}
+
+func displayName(user *models.User) string {
+	if len(user.Username) > 0 {
+		return "@" + user.Username
+	}
+
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
@@ -0,0 +1,117 @@
+package statsbot
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// UserCount is the number of messages of a user
+type UserCount struct {
+	UserID int64
+	Name   string
+	Count  int
+}
+
+// DayCount is the number of messages on a day
+type DayCount struct {
+	Day   time.Time
+	Count int
+}
+
+// Stats is the aggregated activity of a chat
+type Stats struct {
+	Total int
+	// Users is sorted by count, most active first
+	Users []UserCount
+	// Days is sorted by day and includes days without messages
+	Days []DayCount
+}
+
+// Store aggregates the message counts per chat, user and day
+type Store interface {
+	// Record counts a message, name is the display name of the user
+	Record(chatID, userID int64, name string, at time.Time) error
+	// Stats returns the activity of a chat since the given day
+	Stats(chatID int64, since time.Time) (Stats, error)
+}
+
+// MemoryStore is an in-memory stats store
+type MemoryStore struct {
+	mu     sync.Mutex
+	counts map[int64]map[string]map[int64]int
+	names  map[int64]string
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates a new in-memory stats store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counts: make(map[int64]map[string]map[int64]int),
+		names:  make(map[int64]string),
+	}
+}
+
+func (m *MemoryStore) Record(chatID, userID int64, name string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	days, ok := m.counts[chatID]
+	if !ok {
+		days = make(map[string]map[int64]int)
+		m.counts[chatID] = days
+	}
+
+	day := dayKey(at)
+	if days[day] == nil {
+		days[day] = make(map[int64]int)
+	}
+
+	days[day][userID]++
+	m.names[userID] = name
+
+	return nil
+}
+
+func (m *MemoryStore) Stats(chatID int64, since time.Time) (Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stats Stats
+	users := make(map[int64]int)
+
+	for day := truncateDay(since); !day.After(time.Now().UTC()); day = day.AddDate(0, 0, 1) {
+		count := 0
+		for userID, n := range m.counts[chatID][dayKey(day)] {
+			users[userID] += n
+			count += n
+		}
+
+		stats.Days = append(stats.Days, DayCount{Day: day, Count: count})
+		stats.Total += count
+	}
+
+	for userID, count := range users {
+		stats.Users = append(stats.Users, UserCount{UserID: userID, Name: m.names[userID], Count: count})
+	}
+
+	slices.SortFunc(stats.Users, func(a, b UserCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+
+	return stats, nil
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,30 @@
+package statsbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreStats(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	require.NoError(t, store.Record(1, 10, "alice", now))
+	require.NoError(t, store.Record(1, 10, "alice", now.AddDate(0, 0, -1)))
+	require.NoError(t, store.Record(1, 20, "bob", now))
+	require.NoError(t, store.Record(1, 20, "bob", now.AddDate(0, 0, -10)))
+	require.NoError(t, store.Record(2, 30, "carol", now))
+
+	stats, err := store.Stats(1, now.AddDate(0, 0, -6))
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Total)
+	require.Len(t, stats.Days, 7)
+	require.Equal(t, 2, stats.Days[6].Count)
+	require.Equal(t, []UserCount{{UserID: 10, Name: "alice", Count: 2}, {UserID: 20, Name: "bob", Count: 1}}, stats.Users)
+
+	chart, err := renderChart(stats.Days)
+	require.NoError(t, err)
+	require.NotEmpty(t, chart)
+}
@@ -0,0 +1,272 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Davincible/tgbot/cache"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const defaultBroadcastRetention = 7 * 24 * time.Hour
+
+// RecipientStatus is the outcome of a broadcast for a recipient
+type RecipientStatus string
+
+const (
+	RecipientSent RecipientStatus = "sent"
+	// RecipientBlocked is a user that blocked the bot
+	RecipientBlocked RecipientStatus = "blocked"
+	// RecipientDeactivated is a deleted user account
+	RecipientDeactivated RecipientStatus = "deactivated"
+	// RecipientNotFound is a chat that doesn't exist or the bot is not in
+	RecipientNotFound RecipientStatus = "not_found"
+	RecipientFailed   RecipientStatus = "failed"
+)
+
+// RecipientResult is the outcome of a broadcast for a recipient
+type RecipientResult struct {
+	ChatID    int64           `json:"chat_id"`
+	Status    RecipientStatus `json:"status"`
+	MessageID int             `json:"message_id,omitempty"`
+	Err       string          `json:"err,omitempty"`
+}
+
+// BroadcastProgress reports the progress of a broadcast
+type BroadcastProgress struct {
+	Total int
+	// Done is the number of handled recipients, including Resumed
+	Done   int
+	Counts map[RecipientStatus]int
+	// Resumed is the number of recipients handled by an earlier run
+	Resumed int
+}
+
+// BroadcastReport is the outcome of a broadcast
+type BroadcastReport struct {
+	BroadcastProgress
+	Results []RecipientResult
+}
+
+// BroadcastOptions configures a broadcast
+type BroadcastOptions struct {
+	// ID identifies the broadcast in the Store, required to resume
+	ID string
+	// Store records the outcome per recipient, so a broadcast started again
+	// with the same ID after a restart skips the recipients it already
+	// handled. Failed recipients are tried again.
+	Store cache.Cache[RecipientResult]
+	// Retention is how long outcomes are kept in the Store, defaults to a week
+	Retention time.Duration
+	// Concurrency is the number of messages in flight, defaults to the
+	// number of send workers. The rate limiter and retries of the service
+	// apply to every message.
+	Concurrency int
+	// OnProgress is called after each recipient, calls are serialized
+	OnProgress func(BroadcastProgress)
+	// Progress receives the progress after each recipient, updates are
+	// dropped while the channel is full
+	Progress chan<- BroadcastProgress
+}
+
+// Broadcast sends the message to all chats and reports the outcome per
+// recipient. It returns an error only when the broadcast was interrupted,
+// failed recipients are listed in the report.
+func (s *Service) Broadcast(chatIDs []int64, msg Message, opts BroadcastOptions) (*BroadcastReport, error) {
+	return s.BroadcastContext(context.Background(), chatIDs, msg, opts)
+}
+
+// BroadcastContext is Broadcast with a context, canceling it stops the
+// broadcast after the messages in flight
+func (s *Service) BroadcastContext(ctx context.Context, chatIDs []int64, msg Message, opts BroadcastOptions) (*BroadcastReport, error) {
+	if opts.Store != nil && opts.ID == "" {
+		return nil, errors.New("broadcast: ID is required with a Store")
+	}
+
+	if opts.Retention <= 0 {
+		opts.Retention = defaultBroadcastRetention
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = s.cfg.SendWorkers
+		if opts.Concurrency <= 0 {
+			opts.Concurrency = defaultWorkerPoolSize
+		}
+	}
+
+	b := newBroadcast(len(chatIDs), opts)
+
+	return b.run(ctx, chatIDs, func(ctx context.Context, chatID int64) (*models.Message, error) {
+		return s.SendContext(ctx, chatID, msg)
+	})
+}
+
+type broadcast struct {
+	opts BroadcastOptions
+
+	mu     sync.Mutex
+	report BroadcastReport
+}
+
+func newBroadcast(total int, opts BroadcastOptions) *broadcast {
+	return &broadcast{
+		opts: opts,
+		report: BroadcastReport{
+			BroadcastProgress: BroadcastProgress{
+				Total:  total,
+				Counts: make(map[RecipientStatus]int),
+			},
+			Results: make([]RecipientResult, 0, total),
+		},
+	}
+}
+
+func (b *broadcast) run(ctx context.Context, chatIDs []int64, send func(ctx context.Context, chatID int64) (*models.Message, error)) (*BroadcastReport, error) {
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, b.opts.Concurrency)
+	)
+
+	for _, chatID := range chatIDs {
+		if previous, ok := b.resume(ctx, chatID); ok {
+			b.record(previous, true)
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(chatID int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			sent, err := send(ctx, chatID)
+
+			// Recipients interrupted by cancellation are sent on resume
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+
+			result := RecipientResult{ChatID: chatID, Status: recipientStatus(err)}
+			if err != nil {
+				result.Err = err.Error()
+			} else if sent != nil {
+				result.MessageID = sent.ID
+			}
+
+			b.save(result)
+			b.record(result, false)
+		}(chatID)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return b.snapshot(), fmt.Errorf("broadcast interrupted: %w", err)
+	}
+
+	return b.snapshot(), nil
+}
+
+// resume returns the outcome of an earlier run for the recipient, failed
+// recipients are sent again
+func (b *broadcast) resume(ctx context.Context, chatID int64) (RecipientResult, bool) {
+	if b.opts.Store == nil {
+		return RecipientResult{}, false
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	result, ok, err := b.opts.Store.Get(ctx, b.key(chatID))
+	if err != nil || !ok || result.Status == RecipientFailed {
+		return RecipientResult{}, false
+	}
+
+	return result, true
+}
+
+func (b *broadcast) save(result RecipientResult) {
+	if b.opts.Store == nil {
+		return
+	}
+
+	// Saved even when the broadcast is canceled right after
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	_ = b.opts.Store.Set(ctx, b.key(result.ChatID), result, b.opts.Retention)
+}
+
+func (b *broadcast) key(chatID int64) string {
+	return "broadcast:" + b.opts.ID + ":" + strconv.FormatInt(chatID, 10)
+}
+
+func (b *broadcast) record(result RecipientResult, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.report.Results = append(b.report.Results, result)
+	b.report.Counts[result.Status]++
+	b.report.Done++
+	if resumed {
+		b.report.Resumed++
+	}
+
+	progress := b.report.BroadcastProgress
+	progress.Counts = make(map[RecipientStatus]int, len(b.report.Counts))
+	for status, n := range b.report.Counts {
+		progress.Counts[status] = n
+	}
+
+	if b.opts.OnProgress != nil {
+		b.opts.OnProgress(progress)
+	}
+
+	if b.opts.Progress != nil {
+		select {
+		case b.opts.Progress <- progress:
+		default:
+		}
+	}
+}
+
+func (b *broadcast) snapshot() *BroadcastReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	report := b.report
+	report.Results = append([]RecipientResult(nil), b.report.Results...)
+
+	return &report
+}
+
+// recipientStatus classifies the error of a send to a recipient
+func recipientStatus(err error) RecipientStatus {
+	err = apiError(err)
+
+	switch {
+	case err == nil:
+		return RecipientSent
+	case errors.Is(err, ErrBotBlocked):
+		return RecipientBlocked
+	case errors.Is(err, ErrUserDeactivated):
+		return RecipientDeactivated
+	case errors.Is(err, ErrChatNotFound), errors.Is(err, ErrBotNotMember), errors.Is(err, bot.ErrorNotFound):
+		return RecipientNotFound
+	}
+
+	return RecipientFailed
+}
@@ -0,0 +1,90 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Davincible/tgbot/cache"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcast(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []int64
+	)
+
+	send := func(ctx context.Context, chatID int64) (*models.Message, error) {
+		switch chatID {
+		case 2:
+			return nil, fmt.Errorf("%w, Forbidden: bot was blocked by the user", bot.ErrorForbidden)
+		case 3:
+			return nil, fmt.Errorf("%w, Forbidden: user is deactivated", bot.ErrorForbidden)
+		case 4:
+			return nil, fmt.Errorf("%w, Bad Request: chat not found", bot.ErrorBadRequest)
+		case 5:
+			return nil, errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, chatID)
+
+		return &models.Message{ID: int(chatID) * 10}, nil
+	}
+
+	store := cache.NewMemory[RecipientResult]()
+
+	var updates int
+	opts := BroadcastOptions{
+		ID:          "news",
+		Store:       store,
+		Concurrency: 2,
+		OnProgress:  func(BroadcastProgress) { updates++ },
+	}
+
+	chats := []int64{1, 2, 3, 4, 5, 6}
+	report, err := newBroadcast(len(chats), opts).run(context.Background(), chats, send)
+	require.NoError(t, err)
+	require.Equal(t, 6, report.Done)
+	require.Equal(t, 6, updates)
+	require.Equal(t, map[RecipientStatus]int{
+		RecipientSent:        2,
+		RecipientBlocked:     1,
+		RecipientDeactivated: 1,
+		RecipientNotFound:    1,
+		RecipientFailed:      1,
+	}, report.Counts)
+	require.ElementsMatch(t, []int64{1, 6}, sent)
+
+	// A second run only retries the failed recipient and the new one
+	sent = nil
+	report, err = newBroadcast(7, opts).run(context.Background(), append(chats, 7), send)
+	require.NoError(t, err)
+	require.Equal(t, 5, report.Resumed)
+	require.Equal(t, []int64{7}, sent)
+	require.Equal(t, 1, report.Counts[RecipientFailed])
+
+	result, ok, err := store.Get(context.Background(), "broadcast:news:7")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 70, result.MessageID)
+}
+
+func TestBroadcastCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	send := func(ctx context.Context, chatID int64) (*models.Message, error) {
+		cancel()
+		return nil, ctx.Err()
+	}
+
+	report, err := newBroadcast(3, BroadcastOptions{Concurrency: 1}).run(ctx, []int64{1, 2, 3}, send)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, report.Done)
+}
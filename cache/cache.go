@@ -0,0 +1,29 @@
+// Package cache defines a small key-value cache interface with in-memory, disk
+// and Redis implementations, so deployments can choose where cached data lives.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a key-value cache with per-item expiry. A zero TTL keeps the item
+// until it is deleted or evicted by the backend.
+type Cache[T any] interface {
+	// Get returns the value and true if the key exists and has not expired
+	Get(ctx context.Context, key string) (T, bool, error)
+	// Set stores the value with the given TTL
+	Set(ctx context.Context, key string, value T, ttl time.Duration) error
+	// Delete removes the key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// TTL returns the remaining lifetime of the key, zero for keys without
+	// expiry, and false if the key does not exist
+	TTL(ctx context.Context, key string) (time.Duration, bool, error)
+}
+
+// Sweeper is implemented by caches that remove expired items on request,
+// rather than only when they are read again
+type Sweeper interface {
+	// RemoveExpired removes the expired items
+	RemoveExpired(ctx context.Context) error
+}
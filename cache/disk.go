@@ -0,0 +1,373 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Disk is a cache storing every item in a file of a directory, for large
+// values such as downloaded files that should survive restarts. Expired
+// items are removed when they are read and by RemoveExpired.
+type Disk[T any] struct {
+	dir   string
+	codec Codec[T]
+
+	// maxSize limits the total size of the files of a cache created by
+	// NewLimitedDisk, size is the current total
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+}
+
+var (
+	_ Cache[any] = (*Disk[any])(nil)
+	_ Sweeper    = (*Disk[any])(nil)
+)
+
+// NewDisk creates a cache in dir, creating the directory if needed. A nil
+// codec encodes values as JSON, use BytesCodec for files.
+func NewDisk[T any](dir string, codec Codec[T]) (*Disk[T], error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	if codec == nil {
+		codec = JSONCodec[T]{}
+	}
+
+	return &Disk[T]{dir: dir, codec: codec}, nil
+}
+
+// NewLimitedDisk is NewDisk keeping the files within maxSize bytes, the least
+// recently used files are removed to make room. The directory should only be
+// used by one cache.
+func NewLimitedDisk[T any](dir string, codec Codec[T], maxSize int64) (*Disk[T], error) {
+	d, err := NewDisk(dir, codec)
+	if err != nil {
+		return nil, err
+	}
+
+	d.maxSize = maxSize
+
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		d.size += file.size
+	}
+
+	return d, d.evict()
+}
+
+type diskFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the cache files, temporary files being written are skipped
+func (d *Disk[T]) files() ([]diskFile, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list cache dir: %w", err)
+	}
+
+	files := make([]diskFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, diskFile{
+			path:    filepath.Join(d.dir, entry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	return files, nil
+}
+
+func (d *Disk[T]) limited() bool {
+	return d.maxSize > 0
+}
+
+// remove deletes the file, keeping the total size of a limited cache
+func (d *Disk[T]) remove(path string) error {
+	if !d.limited() {
+		return os.Remove(path)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	d.size -= info.Size()
+
+	return nil
+}
+
+// evict removes the least recently used files until the cache is within its
+// size
+func (d *Disk[T]) evict() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.size <= d.maxSize {
+		return nil
+	}
+
+	files, err := d.files()
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(files, func(a, b diskFile) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	// The total is recounted, files may have been removed by hand
+	d.size = 0
+	for _, file := range files {
+		d.size += file.size
+	}
+
+	for _, file := range files {
+		if d.size <= d.maxSize {
+			break
+		}
+
+		if err := os.Remove(file.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("evict cache file: %w", err)
+		}
+
+		d.size -= file.size
+	}
+
+	return nil
+}
+
+// path returns the file of the key, keys are hashed as they may hold
+// characters not allowed in file names
+func (d *Disk[T]) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+// read returns the contents and expiry of the file of the key, false if it
+// is missing or expired
+func (d *Disk[T]) read(key string) ([]byte, time.Time, bool, error) {
+	path := d.path(key)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("read cache file: %w", err)
+	}
+
+	expires, ok := fileExpiry(data)
+	if !ok || (!expires.IsZero() && time.Now().After(expires)) {
+		_ = d.remove(path)
+		return nil, time.Time{}, false, nil
+	}
+
+	if d.limited() {
+		// The modification time orders the files for eviction
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+	}
+
+	return data[8:], expires, true, nil
+}
+
+// fileExpiry returns the expiry a file starts with in unix nanoseconds, zero
+// for none. Files too short to hold it are invalid.
+func fileExpiry(header []byte) (time.Time, bool) {
+	if len(header) < 8 {
+		return time.Time{}, false
+	}
+
+	if nanos := int64(binary.BigEndian.Uint64(header)); nanos != 0 {
+		return time.Unix(0, nanos), true
+	}
+
+	return time.Time{}, true
+}
+
+// RemoveExpired removes the files of expired items, run it periodically as
+// files that are never read again are not removed otherwise
+func (d *Disk[T]) RemoveExpired(ctx context.Context) error {
+	files, err := d.files()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := readHeader(file.path)
+		if err != nil {
+			continue
+		}
+
+		if expires, ok := fileExpiry(header); !ok || (!expires.IsZero() && now.After(expires)) {
+			if err := d.remove(file.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("delete cache file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// readHeader reads the expiry header of a file, short files return what
+// they hold
+func readHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return header[:n], nil
+}
+
+func (d *Disk[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+
+	data, _, ok, err := d.read(key)
+	if !ok || err != nil {
+		return zero, false, err
+	}
+
+	value, err := d.codec.Unmarshal(data)
+	if err != nil {
+		return zero, false, fmt.Errorf("decode value: %w", err)
+	}
+
+	return value, true, nil
+}
+
+func (d *Disk[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := d.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode value: %w", err)
+	}
+
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	// Written to a temporary file first so readers never see half a value
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	header := binary.BigEndian.AppendUint64(nil, uint64(expires))
+	if _, err := tmp.Write(append(header, data...)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache file: %w", err)
+	}
+
+	if !d.limited() {
+		if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+			return fmt.Errorf("write cache file: %w", err)
+		}
+
+		return nil
+	}
+
+	size := int64(len(header) + len(data))
+	if size > d.maxSize {
+		// Never fits, storing it would only evict everything else
+		return d.Delete(ctx, key)
+	}
+
+	// Set like on reads, the clock of the file system may be coarser
+	now := time.Now()
+	_ = os.Chtimes(tmp.Name(), now, now)
+
+	d.mu.Lock()
+
+	var replaced int64
+	if info, err := os.Stat(d.path(key)); err == nil {
+		replaced = info.Size()
+	}
+
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		d.mu.Unlock()
+		return fmt.Errorf("write cache file: %w", err)
+	}
+
+	d.size += size - replaced
+	d.mu.Unlock()
+
+	return d.evict()
+}
+
+func (d *Disk[T]) Delete(ctx context.Context, key string) error {
+	if err := d.remove(d.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete cache file: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Disk[T]) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	_, expires, ok, err := d.read(key)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+
+	if expires.IsZero() {
+		return 0, true, nil
+	}
+
+	return time.Until(expires), true, nil
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisk(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	c, err := NewDisk[[]byte](dir, BytesCodec{})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "file:a/b", []byte("1"), 0))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Hour))
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	v, ok, err := c.Get(ctx, "file:a/b")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+
+	_, ok, _ = c.Get(ctx, "c")
+	require.False(t, ok)
+
+	ttl, ok, _ := c.TTL(ctx, "file:a/b")
+	require.True(t, ok)
+	require.Zero(t, ttl)
+
+	ttl, ok, _ = c.TTL(ctx, "b")
+	require.True(t, ok)
+	require.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	_, ok, _ = c.TTL(ctx, "missing")
+	require.False(t, ok)
+
+	// Items survive a restart
+	reopened, err := NewDisk[[]byte](dir, BytesCodec{})
+	require.NoError(t, err)
+
+	v, ok, _ = reopened.Get(ctx, "b")
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), v)
+
+	require.NoError(t, c.Delete(ctx, "file:a/b"))
+	require.NoError(t, c.Delete(ctx, "missing"))
+	_, ok, _ = c.Get(ctx, "file:a/b")
+	require.False(t, ok)
+}
+
+func TestLimitedDisk(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Files hold an 8 byte header, each item below takes 12 bytes
+	c, err := NewLimitedDisk[[]byte](dir, BytesCodec{}, 30)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-time.Hour)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1234"), 0))
+	require.NoError(t, os.Chtimes(c.path("a"), old, old))
+	require.NoError(t, c.Set(ctx, "b", []byte("1234"), 0))
+	require.NoError(t, c.Set(ctx, "c", []byte("1234"), 0))
+
+	_, ok, _ := c.Get(ctx, "a")
+	require.False(t, ok)
+	_, ok, _ = c.Get(ctx, "c")
+	require.True(t, ok)
+
+	// Reopening counts the files already there
+	reopened, err := NewLimitedDisk[[]byte](dir, BytesCodec{}, 12)
+	require.NoError(t, err)
+
+	files, err := reopened.files()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Expired files are swept without being read
+	require.NoError(t, reopened.Set(ctx, "d", []byte("1"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, reopened.RemoveExpired(ctx))
+
+	files, err = reopened.files()
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
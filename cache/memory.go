@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cleanupEvery is the number of writes after which expired items are removed
+const cleanupEvery = 1024
+
+type memoryItem[T any] struct {
+	value   T
+	expires time.Time
+	size    int64
+	// elem is the position of the key in the eviction order of a limited
+	// cache
+	elem *list.Element
+}
+
+func (i memoryItem[T]) expired(now time.Time) bool {
+	return !i.expires.IsZero() && now.After(i.expires)
+}
+
+// Memory is an in-memory cache, expired items are removed lazily and by
+// RemoveExpired
+type Memory[T any] struct {
+	mu     sync.RWMutex
+	items  map[string]memoryItem[T]
+	writes int
+
+	// maxSize limits the total size of a cache created by NewLimitedMemory,
+	// the least recently used items are evicted first
+	maxSize int64
+	sizeOf  func(T) int64
+	size    int64
+	order   *list.List
+}
+
+var (
+	_ Cache[any] = (*Memory[any])(nil)
+	_ Sweeper    = (*Memory[any])(nil)
+)
+
+// NewMemory creates a new in-memory cache
+func NewMemory[T any]() *Memory[T] {
+	return &Memory[T]{items: make(map[string]memoryItem[T])}
+}
+
+// NewLimitedMemory creates an in-memory cache holding at most maxSize, the
+// least recently used items are evicted to make room. sizeOf returns the
+// size of a value, nil counts every item as one so maxSize is the number of
+// items.
+func NewLimitedMemory[T any](maxSize int64, sizeOf func(T) int64) *Memory[T] {
+	if sizeOf == nil {
+		sizeOf = func(T) int64 { return 1 }
+	}
+
+	return &Memory[T]{
+		items:   make(map[string]memoryItem[T]),
+		maxSize: maxSize,
+		sizeOf:  sizeOf,
+		order:   list.New(),
+	}
+}
+
+func (m *Memory[T]) limited() bool {
+	return m.order != nil
+}
+
+func (m *Memory[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+
+	if m.limited() {
+		// Reads move the item to the front of the eviction order
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		item, ok := m.items[key]
+		if !ok || item.expired(time.Now()) {
+			return zero, false, nil
+		}
+
+		m.order.MoveToFront(item.elem)
+
+		return item.value, true, nil
+	}
+
+	m.mu.RLock()
+	item, ok := m.items[key]
+	m.mu.RUnlock()
+
+	if !ok || item.expired(time.Now()) {
+		return zero, false, nil
+	}
+
+	return item.value, true, nil
+}
+
+func (m *Memory[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	item := memoryItem[T]{value: value}
+	if ttl > 0 {
+		item.expires = time.Now().Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.limited() {
+		item.size = m.sizeOf(value)
+		if item.size > m.maxSize {
+			// Never fits, storing it would only evict everything else
+			m.remove(key)
+			return nil
+		}
+
+		m.remove(key)
+		item.elem = m.order.PushFront(key)
+		m.size += item.size
+	}
+
+	m.items[key] = item
+
+	m.writes++
+	if m.writes >= cleanupEvery {
+		m.writes = 0
+		m.removeExpired()
+	}
+
+	if m.limited() {
+		m.evict()
+	}
+
+	return nil
+}
+
+func (m *Memory[T]) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(key)
+	return nil
+}
+
+// RemoveExpired removes the expired items, run it periodically for caches
+// with items that are never read again
+func (m *Memory[T]) RemoveExpired(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeExpired()
+	return nil
+}
+
+// remove deletes the item, the lock must be held
+func (m *Memory[T]) remove(key string) {
+	item, ok := m.items[key]
+	if !ok {
+		return
+	}
+
+	if item.elem != nil {
+		m.order.Remove(item.elem)
+		m.size -= item.size
+	}
+
+	delete(m.items, key)
+}
+
+// evict removes the least recently used items until the cache is within its
+// size, the lock must be held
+func (m *Memory[T]) evict() {
+	for m.size > m.maxSize {
+		oldest := m.order.Back()
+		if oldest == nil {
+			return
+		}
+
+		m.remove(oldest.Value.(string))
+	}
+}
+
+func (m *Memory[T]) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	m.mu.RLock()
+	item, ok := m.items[key]
+	m.mu.RUnlock()
+
+	now := time.Now()
+	if !ok || item.expired(now) {
+		return 0, false, nil
+	}
+
+	if item.expires.IsZero() {
+		return 0, true, nil
+	}
+
+	return item.expires.Sub(now), true, nil
+}
+
+// Keys returns the keys with the given prefix that have not expired
+func (m *Memory[T]) Keys(prefix string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+
+	var keys []string
+	for key, item := range m.items {
+		if strings.HasPrefix(key, prefix) && !item.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// Len returns the number of items, including expired items not removed yet
+func (m *Memory[T]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.items)
+}
+
+func (m *Memory[T]) removeExpired() {
+	now := time.Now()
+	for key, item := range m.items {
+		if item.expired(now) {
+			m.remove(key)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory[string]()
+
+	require.NoError(t, c.Set(ctx, "a", "1", 0))
+	require.NoError(t, c.Set(ctx, "b", "2", time.Hour))
+	require.NoError(t, c.Set(ctx, "c", "3", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	v, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "1", v)
+
+	_, ok, _ = c.Get(ctx, "c")
+	require.False(t, ok)
+
+	ttl, ok, _ := c.TTL(ctx, "a")
+	require.True(t, ok)
+	require.Zero(t, ttl)
+
+	ttl, ok, _ = c.TTL(ctx, "b")
+	require.True(t, ok)
+	require.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	_, ok, _ = c.TTL(ctx, "missing")
+	require.False(t, ok)
+
+	require.ElementsMatch(t, []string{"a", "b"}, c.Keys(""))
+
+	require.NoError(t, c.Delete(ctx, "a"))
+	_, ok, _ = c.Get(ctx, "a")
+	require.False(t, ok)
+}
+
+func TestLimitedMemory(t *testing.T) {
+	ctx := context.Background()
+	c := NewLimitedMemory(10, func(v []byte) int64 { return int64(len(v)) })
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1234"), 0))
+	require.NoError(t, c.Set(ctx, "b", []byte("1234"), 0))
+
+	// Reading a makes b the least recently used
+	_, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "c", []byte("1234"), 0))
+	require.ElementsMatch(t, []string{"a", "c"}, c.Keys(""))
+
+	// Replacing an item counts its new size only
+	require.NoError(t, c.Set(ctx, "c", []byte("12"), 0))
+	require.NoError(t, c.Set(ctx, "d", []byte("123"), 0))
+	require.ElementsMatch(t, []string{"a", "c", "d"}, c.Keys(""))
+
+	// Items over the limit are not stored
+	require.NoError(t, c.Set(ctx, "e", []byte("12345678901"), 0))
+	require.Equal(t, 3, c.Len())
+
+	require.NoError(t, c.Set(ctx, "f", []byte("1"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	require.Equal(t, 4, c.Len())
+	require.NoError(t, c.RemoveExpired(ctx))
+	require.Equal(t, 3, c.Len())
+}
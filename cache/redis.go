@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Codec converts cached values to and from bytes for remote backends
+type Codec[T any] interface {
+	Marshal(value T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSONCodec encodes values as JSON
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// BytesCodec stores byte slices as is
+type BytesCodec struct{}
+
+func (BytesCodec) Marshal(value []byte) ([]byte, error) {
+	return value, nil
+}
+
+func (BytesCodec) Unmarshal(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// Redis is a cache backed by Redis, keys are namespaced with a prefix
+type Redis[T any] struct {
+	client redis.UniversalClient
+	prefix string
+	codec  Codec[T]
+}
+
+var _ Cache[any] = (*Redis[any])(nil)
+
+// NewRedis creates a Redis backed cache. A nil codec encodes values as JSON.
+func NewRedis[T any](client redis.UniversalClient, prefix string, codec Codec[T]) *Redis[T] {
+	if codec == nil {
+		codec = JSONCodec[T]{}
+	}
+
+	return &Redis[T]{
+		client: client,
+		prefix: prefix,
+		codec:  codec,
+	}
+}
+
+func (r *Redis[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+
+	data, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return zero, false, nil
+	}
+	if err != nil {
+		return zero, false, fmt.Errorf("redis get: %w", err)
+	}
+
+	value, err := r.codec.Unmarshal(data)
+	if err != nil {
+		return zero, false, fmt.Errorf("decode value: %w", err)
+	}
+
+	return value, true, nil
+}
+
+func (r *Redis[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := r.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode value: %w", err)
+	}
+
+	if err := r.client.Set(ctx, r.prefix+key, data, max(ttl, 0)).Err(); err != nil {
+		return fmt.Errorf("redis set: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis[T]) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, r.prefix+key).Err(); err != nil {
+		return fmt.Errorf("redis del: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis[T]) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, err := r.client.PTTL(ctx, r.prefix+key).Result()
+	if err != nil {
+		return 0, false, fmt.Errorf("redis pttl: %w", err)
+	}
+
+	// PTTL returns -2 for missing keys and -1 for keys without expiry
+	switch {
+	case ttl == -2:
+		return 0, false, nil
+	case ttl < 0:
+		return 0, true, nil
+	}
+
+	return ttl, true, nil
+}
@@ -0,0 +1,257 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	maxCallbackData   = 64
+	callbackSeparator = ":"
+)
+
+var (
+	ErrCallbackDataTooLong = fmt.Errorf("callback data exceeds %d bytes", maxCallbackData)
+	ErrCallbackArg         = errors.New("callback argument missing")
+)
+
+// CallbackData encodes a prefix and arguments as "<prefix><arg>:<arg>", the
+// format CallbackContext decodes. Arguments must not contain a colon.
+func CallbackData(prefix string, args ...any) (string, error) {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		part := fmt.Sprint(arg)
+		if strings.Contains(part, callbackSeparator) {
+			return "", fmt.Errorf("callback argument %q contains %q", part, callbackSeparator)
+		}
+
+		parts = append(parts, part)
+	}
+
+	data := prefix + strings.Join(parts, callbackSeparator)
+	if len(data) > maxCallbackData {
+		return "", ErrCallbackDataTooLong
+	}
+
+	return data, nil
+}
+
+// CallbackHandler handles a decoded callback query
+type CallbackHandler func(ctx context.Context, cb *CallbackContext)
+
+// CallbackContext is a decoded callback query. Queries the handler does not
+// answer are answered without text when it returns, to stop the loading
+// indicator on the button.
+type CallbackContext struct {
+	Query *models.CallbackQuery
+	Bot   *bot.Bot
+
+	UserID int64
+	// ChatID and MessageID are zero for buttons on inline messages, which
+	// have InlineMessageID set instead
+	ChatID          int64
+	MessageID       int
+	InlineMessageID string
+
+	// Data is the payload after the prefix, Args the payload split on colons
+	Data string
+	Args []string
+
+	answered bool
+}
+
+// HandleCallback wraps the handler in a CallBack matching the prefix
+func HandleCallback(prefix string, handler CallbackHandler) CallBack {
+	return CallBack{
+		MatchType: bot.MatchTypePrefix,
+		Handler: func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if update.CallbackQuery == nil {
+				return
+			}
+
+			cb := NewCallbackContext(b, update.CallbackQuery, prefix)
+			handler(ctx, cb)
+
+			if !cb.answered {
+				_ = cb.Answer(ctx, "")
+			}
+		},
+	}
+}
+
+// NewCallbackContext decodes the callback query, stripping the prefix from the data
+func NewCallbackContext(b *bot.Bot, query *models.CallbackQuery, prefix string) *CallbackContext {
+	cb := &CallbackContext{
+		Query:           query,
+		Bot:             b,
+		UserID:          query.From.ID,
+		InlineMessageID: query.InlineMessageID,
+		Data:            strings.TrimPrefix(query.Data, prefix),
+	}
+
+	switch {
+	case query.Message.Message != nil:
+		cb.ChatID = query.Message.Message.Chat.ID
+		cb.MessageID = query.Message.Message.ID
+	case query.Message.InaccessibleMessage != nil:
+		cb.ChatID = query.Message.InaccessibleMessage.Chat.ID
+		cb.MessageID = query.Message.InaccessibleMessage.MessageID
+	}
+
+	if cb.Data != "" {
+		cb.Args = strings.Split(cb.Data, callbackSeparator)
+	}
+
+	return cb
+}
+
+// Arg returns the argument at index i, empty if there is none
+func (c *CallbackContext) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+
+	return c.Args[i]
+}
+
+// Int parses the argument at index i as an integer
+func (c *CallbackContext) Int(i int) (int64, error) {
+	if i < 0 || i >= len(c.Args) {
+		return 0, ErrCallbackArg
+	}
+
+	n, err := strconv.ParseInt(c.Args[i], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse callback argument %d: %w", i, err)
+	}
+
+	return n, nil
+}
+
+// Answer answers the query, the text is shown as a notification at the top
+// of the chat
+func (c *CallbackContext) Answer(ctx context.Context, text string) error {
+	return c.answer(ctx, text, false)
+}
+
+// Alert answers the query with an alert the user has to dismiss
+func (c *CallbackContext) Alert(ctx context.Context, text string) error {
+	return c.answer(ctx, text, true)
+}
+
+func (c *CallbackContext) answer(ctx context.Context, text string, alert bool) error {
+	if c.answered {
+		return nil
+	}
+	c.answered = true
+	markCallbackAnswered(ctx)
+
+	if c.Bot == nil {
+		return nil
+	}
+
+	if _, err := c.Bot.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: c.Query.ID,
+		Text:            text,
+		ShowAlert:       alert,
+	}); err != nil {
+		return fmt.Errorf("answer callback query: %w", err)
+	}
+
+	return nil
+}
+
+type callbackAnsweredKey struct{}
+
+// AnswerOptions holds the answer to a callback query
+type AnswerOptions struct {
+	// Text is shown as a notification at the top of the chat, or as an alert
+	// the user has to dismiss
+	Text  string
+	Alert bool
+	// URL is opened by the client, e.g. a t.me link starting the bot with a
+	// parameter, or the URL of a game
+	URL string
+	// CacheTime is how long the client may cache the answer
+	CacheTime time.Duration
+}
+
+// AnswerCallback answers a callback query, which stops the loading indicator
+// on the button
+func (s *Service) AnswerCallback(callbackID string, opts AnswerOptions) error {
+	return s.AnswerCallbackContext(context.Background(), callbackID, opts)
+}
+
+// AnswerCallbackContext is AnswerCallback with a context
+func (s *Service) AnswerCallbackContext(ctx context.Context, callbackID string, opts AnswerOptions) error {
+	markCallbackAnswered(ctx)
+	if answered, ok := s.callbackAnswers.Load(callbackID); ok {
+		answered.(*atomic.Bool).Store(true)
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackID,
+		Text:            opts.Text,
+		ShowAlert:       opts.Alert,
+		URL:             opts.URL,
+		CacheTime:       int(opts.CacheTime.Seconds()),
+	}); err != nil {
+		return fmt.Errorf("answer callback query: %w", err)
+	}
+
+	return nil
+}
+
+// callbackMiddleware answers callback queries the handlers left unanswered
+// when Config.AutoAnswerCallbacks is set. Answers through AnswerCallback and
+// CallbackContext are tracked, answers made directly with the bot are not and
+// cause a second answer, which Telegram rejects.
+func (s *Service) callbackMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if !s.cfg.AutoAnswerCallbacks || update.CallbackQuery == nil {
+				next(ctx, b, update)
+				return
+			}
+
+			id := update.CallbackQuery.ID
+			answered := new(atomic.Bool)
+
+			s.callbackAnswers.Store(id, answered)
+			defer s.callbackAnswers.Delete(id)
+
+			next(context.WithValue(ctx, callbackAnsweredKey{}, answered), b, update)
+
+			if answered.Load() {
+				return
+			}
+
+			if _, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: id}); err != nil {
+				s.logger.Debug("failed to auto-answer callback query",
+					slog.String("err", err.Error()),
+					slog.String("data", update.CallbackQuery.Data),
+				)
+			}
+		}
+	}
+}
+
+// markCallbackAnswered records on the handler context that the callback query
+// was answered, so the middleware doesn't answer it again
+func markCallbackAnswered(ctx context.Context) {
+	if answered, ok := ctx.Value(callbackAnsweredKey{}).(*atomic.Bool); ok {
+		answered.Store(true)
+	}
+}
@@ -0,0 +1,80 @@
+package tgbot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestCallbackData(t *testing.T) {
+	data, err := CallbackData("snooze:", 12, 30)
+	require.NoError(t, err)
+	require.Equal(t, "snooze:12:30", data)
+
+	_, err = CallbackData("x:", "a:b")
+	require.Error(t, err)
+
+	_, err = CallbackData("x:", strings.Repeat("a", 64))
+	require.ErrorIs(t, err, ErrCallbackDataTooLong)
+}
+
+func TestCallbackContext(t *testing.T) {
+	query := &models.CallbackQuery{
+		ID:   "q",
+		From: models.User{ID: 7},
+		Data: "snooze:12:30",
+		Message: models.MaybeInaccessibleMessage{
+			Message: &models.Message{ID: 5, Chat: models.Chat{ID: 9}},
+		},
+	}
+
+	cb := NewCallbackContext(nil, query, "snooze:")
+	require.Equal(t, int64(7), cb.UserID)
+	require.Equal(t, int64(9), cb.ChatID)
+	require.Equal(t, 5, cb.MessageID)
+	require.Equal(t, []string{"12", "30"}, cb.Args)
+
+	id, err := cb.Int(0)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), id)
+	require.Equal(t, "30", cb.Arg(1))
+	require.Empty(t, cb.Arg(2))
+
+	_, err = cb.Int(2)
+	require.ErrorIs(t, err, ErrCallbackArg)
+}
+
+func TestHandleCallback(t *testing.T) {
+	var got *CallbackContext
+	callback := HandleCallback("done:", func(ctx context.Context, cb *CallbackContext) {
+		got = cb
+	})
+
+	callback.Handler(context.Background(), nil, &models.Update{CallbackQuery: &models.CallbackQuery{Data: "done:3"}})
+	require.NotNil(t, got)
+	require.Equal(t, "3", got.Data)
+	// Unanswered queries are answered when the handler returns
+	require.True(t, got.answered)
+}
+
+func TestCallbackMiddlewareAnswered(t *testing.T) {
+	s := &Service{cfg: &Config{AutoAnswerCallbacks: true}, logger: slog.Default()}
+	update := &models.Update{CallbackQuery: &models.CallbackQuery{ID: "1", Data: "page:2"}}
+
+	var called bool
+	handler := HandleCallback("page:", func(ctx context.Context, cb *CallbackContext) {
+		called = true
+		require.NoError(t, cb.Answer(ctx, "Loading"))
+	})
+
+	// The bot is nil, answering again in the middleware would panic
+	s.callbackMiddleware()(handler.Handler)(context.Background(), nil, update)
+	require.True(t, called)
+
+	_, pending := s.callbackAnswers.Load("1")
+	require.False(t, pending)
+}
@@ -0,0 +1,144 @@
+package tgbot
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// The capability interfaces make up the Bot interface. A bot that only needs
+// some of them implements those and is wrapped with AdaptBot.
+
+// SenderSetter receives the sender once the service is created
+type SenderSetter interface {
+	SetSender(b Sender)
+}
+
+// CommandProvider registers text commands and the command menu
+type CommandProvider interface {
+	Commands() map[string]func(ctx context.Context, b *bot.Bot, update *models.Update)
+	CommandsList() []models.BotCommand
+}
+
+// CallbackProvider registers callback query handlers
+type CallbackProvider interface {
+	CallBacks() map[string]CallBack
+}
+
+// MiddlewareProvider registers middleware that runs after the service middleware
+type MiddlewareProvider interface {
+	Middleware() []bot.Middleware
+}
+
+// DefaultHandlerProvider handles the updates no other handler matched
+type DefaultHandlerProvider interface {
+	DefaultHandler() bot.HandlerFunc
+}
+
+// InlineProvider handles inline queries, the bot needs inline mode enabled
+// with BotFather
+type InlineProvider interface {
+	InlineHandler() bot.HandlerFunc
+}
+
+// AdaptBot turns a value implementing any of the capability interfaces into a
+// Bot, missing capabilities are empty. A Bot is returned as is.
+func AdaptBot(b any) Bot {
+	if full, ok := b.(Bot); ok {
+		return full
+	}
+
+	return &botAdapter{impl: b}
+}
+
+type botAdapter struct {
+	impl any
+}
+
+var _ InlineProvider = (*botAdapter)(nil)
+
+func (a *botAdapter) SetSender(s Sender) {
+	if setter, ok := a.impl.(SenderSetter); ok {
+		setter.SetSender(s)
+	}
+}
+
+func (a *botAdapter) Commands() map[string]func(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if provider, ok := a.impl.(CommandProvider); ok {
+		return provider.Commands()
+	}
+
+	if provider, ok := a.impl.(CommandSetProvider); ok {
+		return provider.CommandSet().Handlers()
+	}
+
+	return nil
+}
+
+func (a *botAdapter) CommandsList() []models.BotCommand {
+	if provider, ok := a.impl.(CommandProvider); ok {
+		return provider.CommandsList()
+	}
+
+	if provider, ok := a.impl.(CommandSetProvider); ok {
+		return provider.CommandSet().List()
+	}
+
+	return nil
+}
+
+func (a *botAdapter) CallBacks() map[string]CallBack {
+	if provider, ok := a.impl.(CallbackProvider); ok {
+		return provider.CallBacks()
+	}
+
+	return nil
+}
+
+func (a *botAdapter) Middleware() []bot.Middleware {
+	if provider, ok := a.impl.(MiddlewareProvider); ok {
+		return provider.Middleware()
+	}
+
+	return nil
+}
+
+func (a *botAdapter) DefaultHandler() bot.HandlerFunc {
+	if provider, ok := a.impl.(DefaultHandlerProvider); ok {
+		return provider.DefaultHandler()
+	}
+
+	return nil
+}
+
+func (a *botAdapter) InlineHandler() bot.HandlerFunc {
+	if provider, ok := a.impl.(InlineProvider); ok {
+		return provider.InlineHandler()
+	}
+
+	return nil
+}
+
+// inlineHandler returns the inline handler of the bot, if it has one
+func inlineHandler(b Bot) bot.HandlerFunc {
+	if provider, ok := b.(InlineProvider); ok {
+		return provider.InlineHandler()
+	}
+
+	return nil
+}
+
+// createInlineMiddleware routes inline queries to the inline handler of the bot
+func createInlineMiddleware(handler bot.HandlerFunc) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if update.InlineQuery != nil {
+				handler(ctx, b, update)
+				return
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
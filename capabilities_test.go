@@ -0,0 +1,60 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+type commandOnlyBot struct {
+	inlineCalls int
+}
+
+func (b *commandOnlyBot) Commands() map[string]func(ctx context.Context, b *bot.Bot, update *models.Update) {
+	return map[string]func(ctx context.Context, b *bot.Bot, update *models.Update){
+		"/ping": func(ctx context.Context, b *bot.Bot, update *models.Update) {},
+	}
+}
+
+func (b *commandOnlyBot) CommandsList() []models.BotCommand {
+	return []models.BotCommand{{Command: "ping", Description: "Ping"}}
+}
+
+func (b *commandOnlyBot) InlineHandler() bot.HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, update *models.Update) {
+		b.inlineCalls++
+	}
+}
+
+func TestAdaptBot(t *testing.T) {
+	impl := &commandOnlyBot{}
+	adapted := AdaptBot(impl)
+
+	require.Len(t, adapted.Commands(), 1)
+	require.Len(t, adapted.CommandsList(), 1)
+	require.Nil(t, adapted.CallBacks())
+	require.Nil(t, adapted.Middleware())
+	require.Nil(t, adapted.DefaultHandler())
+	adapted.SetSender(nil)
+
+	handler := inlineHandler(adapted)
+	require.NotNil(t, handler)
+
+	merger, err := NewBotMerger(MergerConfig{Logger: slog.Default()})
+	require.NoError(t, err)
+	require.NoError(t, merger.MergeBots(adapted))
+
+	require.Same(t, merger, AdaptBot(merger))
+	require.NotPanics(t, func() {
+		merger.DefaultHandler()(context.Background(), nil, &models.Update{})
+	})
+
+	merged := merger.InlineHandler()
+	require.NotNil(t, merged)
+	merged(context.Background(), nil, &models.Update{InlineQuery: &models.InlineQuery{ID: "1"}})
+	require.Equal(t, 1, impl.inlineCalls)
+}
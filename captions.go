@@ -0,0 +1,253 @@
+package tgbot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+// CaptionOverflow is how captions over the limit of 1024 characters are
+// sent
+type CaptionOverflow int
+
+const (
+	// CaptionOverflowFail sends long captions as they are, Telegram rejects
+	// them unless Config.SplitLongMessages splits them
+	CaptionOverflowFail CaptionOverflow = iota
+	// CaptionOverflowFollowUp sends the media without caption and the text
+	// as a reply to it, with its entities and buttons
+	CaptionOverflowFollowUp
+	// CaptionOverflowReadMore trims the caption with an ellipsis and adds a
+	// Read more button sending the full text. Captions with markup, albums
+	// and messages with a reply keyboard are sent as a follow-up instead, as
+	// they can't be trimmed or carry the button.
+	CaptionOverflowReadMore
+)
+
+const (
+	readMoreCallbackPrefix = "tgbot_more:"
+	readMoreTTL            = 30 * 24 * time.Hour
+	defaultReadMoreText    = "Read more"
+	ellipsis               = "…"
+)
+
+// captionOverflows reports whether the caption of the message is over the
+// limit and Config.LongCaptions applies to it
+func (s *Service) captionOverflows(msg Message) bool {
+	if s.cfg.LongCaptions == CaptionOverflowFail {
+		return false
+	}
+
+	caption := msg.hasMedia() || len(msg.Album) > 0 || len(msg.Voice) > 0 || msg.VoiceURL != ""
+
+	return caption && textLength(msg.Text) > maxCaptionLength
+}
+
+// sendLongCaption sends a message whose caption is over the limit, msg is
+// localized and its Markdown converted
+func (s *Service) sendLongCaption(ctx context.Context, chatID int64, msg Message) (*models.Message, error) {
+	readMore := s.cfg.LongCaptions == CaptionOverflowReadMore &&
+		!msg.hasMarkup() && len(msg.Album) == 0 && msg.Keyboard == nil
+
+	if readMore {
+		trimmed, err := s.trimCaption(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.retry(ctx, chatID, func() (*models.Message, error) {
+			return s.send(ctx, chatID, trimmed)
+		})
+	}
+
+	media := msg
+	media.Text, media.Entities = "", nil
+	media.Buttons, media.Keyboard, media.RemoveKeyboard = nil, nil, false
+
+	sent, err := s.retry(ctx, chatID, func() (*models.Message, error) {
+		return s.send(ctx, chatID, media)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	followUp := msg.textOnly()
+	followUp.ReplyTo = sent.ID
+
+	if s.cfg.SplitLongMessages {
+		_, err = s.sendChunks(ctx, chatID, followUp)
+	} else {
+		_, err = s.retry(ctx, chatID, func() (*models.Message, error) {
+			return s.send(ctx, chatID, followUp)
+		})
+	}
+	if err != nil {
+		return sent, fmt.Errorf("send caption follow-up: %w", err)
+	}
+
+	return sent, nil
+}
+
+// trimCaption stores the full text for the Read more button and returns
+// the message with the trimmed caption and the button
+func (s *Service) trimCaption(ctx context.Context, msg Message) (Message, error) {
+	key, err := readMoreKey()
+	if err != nil {
+		return msg, err
+	}
+
+	// The buttons stay on the media
+	full := msg.textOnly()
+	full.Buttons = nil
+
+	if err := s.cfg.ReadMoreStore.Set(ctx, key, full, readMoreTTL); err != nil {
+		return msg, fmt.Errorf("store full caption: %w", err)
+	}
+
+	label := s.cfg.ReadMoreText
+	if label == "" {
+		label = defaultReadMoreText
+	}
+
+	msg.Text, msg.Entities = trimText(msg.Text, msg.Entities, maxCaptionLength)
+	msg.Buttons = append([]InlineButton{{Text: label, CallbackData: readMoreCallbackPrefix + key}}, msg.Buttons...)
+
+	return msg, nil
+}
+
+// hasMarkup reports whether the text holds Markdown or HTML formatting,
+// which can't be cut anywhere
+func (m Message) hasMarkup() bool {
+	return m.TextFormatting && len(m.Entities) == 0 &&
+		(m.ParseMode == ParseModeMarkdown || m.ParseMode == ParseModeHTML)
+}
+
+// textOnly returns the text of the message with its formatting, without
+// media
+func (m Message) textOnly() Message {
+	return Message{
+		Text:                 m.Text,
+		Entities:             m.Entities,
+		TextFormatting:       m.TextFormatting,
+		ParseMode:            m.ParseMode,
+		DisableLinkPreview:   m.DisableLinkPreview,
+		BusinessConnectionID: m.BusinessConnectionID,
+		ThreadID:             m.ThreadID,
+		Buttons:              m.Buttons,
+		Keyboard:             m.Keyboard,
+		RemoveKeyboard:       m.RemoveKeyboard,
+		localized:            m.localized,
+	}
+}
+
+// trimText trims the text to at most limit characters including the
+// ellipsis, between words where it can. Entities are cut at the end of the
+// text and dropped when nothing of them is left.
+func trimText(text string, entities []models.MessageEntity, limit int) (string, []models.MessageEntity) {
+	if textLength(text) <= limit {
+		return text, entities
+	}
+
+	budget := limit - textLength(ellipsis)
+
+	// end is the byte offset of the last rune that fits, space the byte
+	// offset of the last space before it
+	end, space, length := 0, -1, 0
+	for i, r := range text {
+		n := textLength(string(r))
+		if length+n > budget {
+			break
+		}
+
+		if unicode.IsSpace(r) {
+			space = i
+		}
+
+		length += n
+		end = i + len(string(r))
+	}
+
+	// Words are only kept whole when that doesn't cost much of the text
+	if space > end/2 {
+		end = space
+	}
+
+	kept := strings.TrimRightFunc(text[:end], unicode.IsSpace)
+	cut := textLength(kept)
+
+	var trimmed []models.MessageEntity
+	for _, e := range entities {
+		if e.Offset >= cut {
+			continue
+		}
+
+		e.Length = min(e.Length, cut-e.Offset)
+		trimmed = append(trimmed, e)
+	}
+
+	return kept + ellipsis, trimmed
+}
+
+func readMoreKey() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate read more key: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// readMoreMiddleware sends the full caption when a Read more button is
+// pressed
+func (s *Service) readMoreMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			query := update.CallbackQuery
+			if query == nil || !strings.HasPrefix(query.Data, readMoreCallbackPrefix) {
+				next(ctx, b, update)
+				return
+			}
+
+			s.handleReadMore(ctx, b, query)
+		}
+	}
+}
+
+func (s *Service) handleReadMore(ctx context.Context, b *bot.Bot, query *models.CallbackQuery) {
+	answer := &bot.AnswerCallbackQueryParams{CallbackQueryID: query.ID}
+
+	msg, ok, err := s.cfg.ReadMoreStore.Get(ctx, strings.TrimPrefix(query.Data, readMoreCallbackPrefix))
+	if err != nil {
+		s.logger.Error("failed to read full caption", slog.String("err", err.Error()))
+	}
+
+	message := query.Message.Message
+
+	switch {
+	case !ok:
+		answer.Text = "This text is no longer available"
+	case message == nil:
+		answer.Text = "The message is no longer available"
+	default:
+		msg.ReplyTo = message.ID
+
+		if _, err := s.SendContext(ctx, message.Chat.ID, msg); err != nil {
+			s.logger.Error("failed to send full caption", slog.String("err", err.Error()))
+			answer.Text = "Failed to send the text"
+		}
+	}
+
+	markCallbackAnswered(ctx)
+
+	if _, err := b.AnswerCallbackQuery(ctx, answer); err != nil {
+		s.logger.Debug("failed to answer read more callback", slog.String("err", err.Error()))
+	}
+}
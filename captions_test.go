@@ -0,0 +1,133 @@
+package tgbot
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestTrimText(t *testing.T) {
+	text, entities := trimText("short", nil, 10)
+	require.Equal(t, "short", text)
+	require.Nil(t, entities)
+
+	text, entities = trimText("hello brave new world", []models.MessageEntity{
+		{Type: models.MessageEntityTypeBold, Offset: 0, Length: 5},
+		{Type: models.MessageEntityTypeItalic, Offset: 6, Length: 9},
+		{Type: models.MessageEntityTypeCode, Offset: 16, Length: 5},
+	}, 14)
+
+	// Cut between words, the entity crossing the cut is shortened and the
+	// one after it dropped
+	require.Equal(t, "hello brave…", text)
+	require.Equal(t, []models.MessageEntity{
+		{Type: models.MessageEntityTypeBold, Offset: 0, Length: 5},
+		{Type: models.MessageEntityTypeItalic, Offset: 6, Length: 5},
+	}, entities)
+
+	// Emoji take two characters and are not cut in half
+	text, _ = trimText(strings.Repeat("😀", 10), nil, 8)
+	require.Equal(t, strings.Repeat("😀", 3)+"…", text)
+	require.LessOrEqual(t, textLength(text), 8)
+}
+
+func TestLongCaptions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := NewRecorder()
+
+	cfg := &Config{DryRun: recorder, LongCaptions: CaptionOverflowFollowUp}
+	srv, err := NewService(logger, cfg)
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	caption := strings.Repeat("word ", 300)
+	msg := Message{
+		ImageURL: "https://example.com/a.jpg",
+		Text:     caption,
+		Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBold, Offset: 1400, Length: 4}},
+		Buttons:  []InlineButton{{Text: "Open", URL: "https://example.com"}},
+	}
+
+	_, err = srv.Send(42, msg)
+	require.NoError(t, err)
+
+	photos := recorder.Calls("sendPhoto")
+	require.Len(t, photos, 1)
+	require.Empty(t, photos[0].Params["caption"])
+	require.Empty(t, photos[0].Params["reply_markup"])
+
+	texts := recorder.Calls("sendMessage")
+	require.Len(t, texts, 1)
+	require.Equal(t, caption, texts[0].Params["text"])
+	require.Contains(t, texts[0].Params["entities"], `"offset":1400`)
+	require.Contains(t, texts[0].Params["reply_parameters"], `"message_id"`)
+	require.Contains(t, texts[0].Params["reply_markup"], "Open")
+
+	// Read more trims the caption and keeps the full text for the button
+	recorder.Reset()
+	cfg.LongCaptions = CaptionOverflowReadMore
+
+	_, err = srv.Send(42, msg)
+	require.NoError(t, err)
+
+	photos = recorder.Calls("sendPhoto")
+	require.Len(t, photos, 1)
+	require.True(t, strings.HasSuffix(photos[0].Params["caption"], "…"))
+	require.LessOrEqual(t, textLength(photos[0].Params["caption"]), maxCaptionLength)
+	require.NotContains(t, photos[0].Params, "caption_entities")
+	require.Contains(t, photos[0].Params["reply_markup"], readMoreCallbackPrefix)
+	require.Contains(t, photos[0].Params["reply_markup"], "Open")
+	require.Empty(t, recorder.Calls("sendMessage"))
+
+	start := strings.Index(photos[0].Params["reply_markup"], readMoreCallbackPrefix)
+	data := photos[0].Params["reply_markup"][start : start+len(readMoreCallbackPrefix)+16]
+
+	srv.handleReadMore(context.Background(), srv.bot, &models.CallbackQuery{
+		ID:   "1",
+		Data: data,
+		Message: models.MaybeInaccessibleMessage{Message: &models.Message{
+			ID:   7,
+			Chat: models.Chat{ID: 42},
+		}},
+	})
+
+	texts = recorder.Calls("sendMessage")
+	require.Len(t, texts, 1)
+	require.Equal(t, caption, texts[0].Params["text"])
+	require.NotContains(t, texts[0].Params["reply_markup"], "Open")
+	require.Len(t, recorder.Calls("answerCallbackQuery"), 1)
+}
+
+func TestLongCaptionsCommonMark(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := NewRecorder()
+
+	srv, err := NewService(logger, &Config{
+		DryRun:            recorder,
+		LongCaptions:      CaptionOverflowFollowUp,
+		SplitLongMessages: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	// Escaped markup is converted once
+	_, err = srv.Send(42, Message{Text: `\*not bold\*`, ParseMode: ParseModeCommonMark})
+	require.NoError(t, err)
+
+	texts := recorder.Calls("sendMessage")
+	require.Len(t, texts, 1)
+	require.Equal(t, "*not bold*", texts[0].Params["text"])
+	require.NotContains(t, texts[0].Params, "entities")
+
+	// Long texts are still split
+	recorder.Reset()
+
+	_, err = srv.Send(42, Message{Text: strings.Repeat("word ", 1200), ParseMode: ParseModeCommonMark})
+	require.NoError(t, err)
+	require.Len(t, recorder.Calls("sendMessage"), 2)
+}
@@ -0,0 +1,152 @@
+package tgbot
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gammazero/workerpool"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// handlerBatchSize is the number of queued updates a worker handles for a
+// chat before yielding the worker to other chats
+const handlerBatchSize = 5
+
+// chatQueue keeps a queue of updates per chat and handles them over a worker
+// pool of its own, separate from the send pipeline the handlers wait on. A
+// chat is only handled by one worker at a time.
+type chatQueue struct {
+	pool *workerpool.WorkerPool
+
+	mu     sync.Mutex
+	queues map[int64][]func()
+	closed bool
+}
+
+func newChatQueue(workers int) *chatQueue {
+	return &chatQueue{
+		pool:   workerpool.New(workers),
+		queues: make(map[int64][]func()),
+	}
+}
+
+// enqueue adds the job to the queue of the chat and schedules the chat if
+// idle, jobs without a chat run right away. Once stopped the job runs on the
+// calling goroutine, so late updates are still handled.
+func (q *chatQueue) enqueue(chatID int64, job func()) {
+	// The pool is submitted to under the lock, so stop can't stop it in
+	// between
+	q.mu.Lock()
+
+	if q.closed {
+		q.mu.Unlock()
+		job()
+		return
+	}
+
+	if chatID == 0 {
+		q.pool.Submit(job)
+		q.mu.Unlock()
+		return
+	}
+
+	queue, active := q.queues[chatID]
+	q.queues[chatID] = append(queue, job)
+
+	if !active {
+		q.pool.Submit(func() { q.drain(chatID) })
+	}
+
+	q.mu.Unlock()
+}
+
+func (q *chatQueue) drain(chatID int64) {
+	for {
+		for i := 0; i < handlerBatchSize; i++ {
+			job := q.next(chatID)
+			if job == nil {
+				return
+			}
+
+			job()
+		}
+
+		// Yield to other chats, the chat stays marked active so no one else
+		// picks it up. Once stopped the pool takes no more work and the chat
+		// is drained here.
+		q.mu.Lock()
+		if len(q.queues[chatID]) == 0 {
+			delete(q.queues, chatID)
+			q.mu.Unlock()
+			return
+		}
+
+		if !q.closed {
+			q.pool.Submit(func() { q.drain(chatID) })
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+	}
+}
+
+// next pops the next job of the chat queue, the chat is marked idle once empty
+func (q *chatQueue) next(chatID int64) func() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue := q.queues[chatID]
+	if len(queue) == 0 {
+		delete(q.queues, chatID)
+		return nil
+	}
+
+	job := queue[0]
+	queue[0] = nil
+	q.queues[chatID] = queue[1:]
+
+	return job
+}
+
+// stop waits for the queued jobs, it can be called more than once
+func (q *chatQueue) stop() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	q.pool.StopWait()
+}
+
+// chatQueueMiddleware hands updates to the chat queue when
+// Config.ChatWorkers is set, so conversations in different chats are handled
+// in parallel while the updates of one chat stay in order. It runs first, so
+// the panic recovery runs on the worker.
+func (s *Service) chatQueueMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		if s.chatQueue == nil {
+			return next
+		}
+
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			s.chatQueue.enqueue(updateQueueKey(update), func() {
+				next(ctx, b, update)
+			})
+		}
+	}
+}
+
+// updateQueueKey is the chat of the update, or the user for updates outside
+// of chats like inline queries. Private chats have the ID of the user, so
+// these line up with the messages of the user.
+func updateQueueKey(update *models.Update) int64 {
+	if chatID := UpdateChatID(update); chatID != 0 {
+		return chatID
+	}
+
+	if user := UpdateUser(update); user != nil {
+		return user.ID
+	}
+
+	return 0
+}
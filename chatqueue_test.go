@@ -0,0 +1,97 @@
+package tgbot
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatQueueMiddleware(t *testing.T) {
+	s := &Service{chatQueue: newChatQueue(4)}
+
+	var (
+		mu      sync.Mutex
+		handled = map[int64][]int{}
+		running = map[int64]*atomic.Int32{1: {}, 2: {}, 3: {}}
+		wg      sync.WaitGroup
+	)
+
+	handler := s.chatQueueMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		defer wg.Done()
+
+		chatID := update.Message.Chat.ID
+
+		// Updates of one chat never run at the same time
+		require.Equal(t, int32(1), running[chatID].Add(1))
+		time.Sleep(time.Millisecond)
+		running[chatID].Add(-1)
+
+		mu.Lock()
+		handled[chatID] = append(handled[chatID], update.Message.ID)
+		mu.Unlock()
+	})
+
+	for i := 0; i < 20; i++ {
+		for _, chatID := range []int64{1, 2, 3} {
+			wg.Add(1)
+			handler(context.Background(), nil, &models.Update{Message: &models.Message{ID: i, Chat: models.Chat{ID: chatID}}})
+		}
+	}
+
+	wg.Wait()
+	s.chatQueue.stop()
+
+	for chatID, ids := range handled {
+		require.Len(t, ids, 20, "chat %d", chatID)
+		for i, id := range ids {
+			require.Equal(t, i, id, "chat %d out of order", chatID)
+		}
+	}
+}
+
+func TestUpdateQueueKey(t *testing.T) {
+	require.Equal(t, int64(-100), updateQueueKey(&models.Update{Message: &models.Message{Chat: models.Chat{ID: -100}}}))
+	require.Equal(t, int64(7), updateQueueKey(&models.Update{InlineQuery: &models.InlineQuery{From: &models.User{ID: 7}}}))
+	require.Zero(t, updateQueueKey(&models.Update{}))
+}
+
+func TestChatQueueStopWithBacklog(t *testing.T) {
+	q := newChatQueue(1)
+
+	release := make(chan struct{})
+
+	var handled []int
+	for i := 0; i < handlerBatchSize*2; i++ {
+		i := i
+		q.enqueue(1, func() {
+			<-release
+			handled = append(handled, i)
+		})
+	}
+
+	// The chat has more jobs than a batch, they are drained without
+	// submitting to the stopped pool
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	q.stop()
+
+	require.Len(t, handled, handlerBatchSize*2)
+	for i, id := range handled {
+		require.Equal(t, i, id)
+	}
+
+	// Jobs after stop run right away
+	var late bool
+	q.enqueue(1, func() { late = true })
+	require.True(t, late)
+
+	q.stop()
+}
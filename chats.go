@@ -1,7 +1,10 @@
 package tgbot
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -19,3 +22,260 @@ func (s *Service) GetChatMember(chat, user int64) (*models.ChatMember, error) {
 		UserID: user,
 	})
 }
+
+// MemberPermissions are the permissions of a restricted chat member, the zero
+// value mutes the member
+type MemberPermissions struct {
+	SendMessages bool
+	// SendMedia allows audio, documents, photos, videos, video notes and
+	// voice notes
+	SendMedia bool
+	SendPolls bool
+	// SendOther allows stickers, animations, games and inline results
+	SendOther       bool
+	AddLinkPreviews bool
+	ChangeInfo      bool
+	InviteUsers     bool
+	PinMessages     bool
+	ManageTopics    bool
+}
+
+// AdminRights are the rights of a chat administrator, the zero value demotes
+// the member to a regular member
+type AdminRights struct {
+	Anonymous        bool
+	ManageChat       bool
+	DeleteMessages   bool
+	ManageVideoChats bool
+	RestrictMembers  bool
+	PromoteMembers   bool
+	ChangeInfo       bool
+	InviteUsers      bool
+	PinMessages      bool
+	ManageTopics     bool
+	// PostMessages and EditMessages only apply to channels
+	PostMessages  bool
+	EditMessages  bool
+	PostStories   bool
+	EditStories   bool
+	DeleteStories bool
+}
+
+// InviteLinkConfig holds the options of an invite link, zero values don't
+// limit the link
+type InviteLinkConfig struct {
+	Name string
+	// ExpireAt is the time the link expires
+	ExpireAt time.Time
+	// MemberLimit is the number of users that can join with the link, 1-99999
+	MemberLimit int
+	// JoinRequest makes users that join with the link send a join request to
+	// the admins, it can't be combined with a member limit
+	JoinRequest bool
+}
+
+// BanChatMember bans the user from the chat for the duration, zero bans
+// forever. Telegram treats bans under 30 seconds or over 366 days as
+// permanent. Revoke deletes all messages of the user in the chat.
+func (s *Service) BanChatMember(ctx context.Context, chatID, userID int64, duration time.Duration, revoke bool) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.BanChatMember(ctx, &bot.BanChatMemberParams{
+		ChatID:         chatID,
+		UserID:         userID,
+		UntilDate:      untilDate(duration),
+		RevokeMessages: revoke,
+	}); err != nil {
+		return fmt.Errorf("ban chat member: %w", err)
+	}
+
+	return nil
+}
+
+// UnbanChatMember lifts the ban of the user, who can join again through a
+// link. Members that are not banned are left alone.
+func (s *Service) UnbanChatMember(ctx context.Context, chatID, userID int64) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.UnbanChatMember(ctx, &bot.UnbanChatMemberParams{
+		ChatID:       chatID,
+		UserID:       userID,
+		OnlyIfBanned: true,
+	}); err != nil {
+		return fmt.Errorf("unban chat member: %w", err)
+	}
+
+	return nil
+}
+
+// RestrictChatMember sets the permissions of the user in a supergroup for the
+// duration, zero restricts forever
+func (s *Service) RestrictChatMember(ctx context.Context, chatID, userID int64, permissions MemberPermissions, duration time.Duration) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.RestrictChatMember(ctx, &bot.RestrictChatMemberParams{
+		ChatID:      chatID,
+		UserID:      userID,
+		Permissions: permissions.chatPermissions(),
+		UntilDate:   untilDate(duration),
+	}); err != nil {
+		return fmt.Errorf("restrict chat member: %w", err)
+	}
+
+	return nil
+}
+
+// PromoteChatMember sets the admin rights of the user, the bot needs the
+// rights it grants
+func (s *Service) PromoteChatMember(ctx context.Context, chatID, userID int64, rights AdminRights) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.PromoteChatMember(ctx, &bot.PromoteChatMemberParams{
+		ChatID:              chatID,
+		UserID:              userID,
+		IsAnonymous:         rights.Anonymous,
+		CanManageChat:       rights.ManageChat,
+		CanDeleteMessages:   rights.DeleteMessages,
+		CanManageVideoChats: rights.ManageVideoChats,
+		CanRestrictMembers:  rights.RestrictMembers,
+		CanPromoteMembers:   rights.PromoteMembers,
+		CanChangeInfo:       rights.ChangeInfo,
+		CanInviteUsers:      rights.InviteUsers,
+		CanPostMessages:     rights.PostMessages,
+		CanEditMessages:     rights.EditMessages,
+		CanPinMessages:      rights.PinMessages,
+		CanPostStories:      rights.PostStories,
+		CanEditStories:      rights.EditStories,
+		CanDeleteStories:    rights.DeleteStories,
+		CanManageTopics:     rights.ManageTopics,
+	}); err != nil {
+		return fmt.Errorf("promote chat member: %w", err)
+	}
+
+	return nil
+}
+
+// SetChatTitle changes the title of the chat
+func (s *Service) SetChatTitle(ctx context.Context, chatID int64, title string) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.SetChatTitle(ctx, &bot.SetChatTitleParams{
+		ChatID: chatID,
+		Title:  title,
+	}); err != nil {
+		return fmt.Errorf("set chat title: %w", err)
+	}
+
+	return nil
+}
+
+// SetChatDescription changes the description of the chat
+func (s *Service) SetChatDescription(ctx context.Context, chatID int64, description string) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.SetChatDescription(ctx, &bot.SetChatDescriptionParams{
+		ChatID:      chatID,
+		Description: description,
+	}); err != nil {
+		return fmt.Errorf("set chat description: %w", err)
+	}
+
+	return nil
+}
+
+// SetChatPhoto changes the photo of the chat, no photo deletes it
+func (s *Service) SetChatPhoto(ctx context.Context, chatID int64, photo []byte) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if len(photo) == 0 {
+		if _, err := s.bot.DeleteChatPhoto(ctx, &bot.DeleteChatPhotoParams{ChatID: chatID}); err != nil {
+			return fmt.Errorf("delete chat photo: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := s.bot.SetChatPhoto(ctx, &bot.SetChatPhotoParams{
+		ChatID: chatID,
+		Photo:  &models.InputFileUpload{Filename: "photo.jpg", Data: bytes.NewReader(photo)},
+	}); err != nil {
+		return fmt.Errorf("set chat photo: %w", err)
+	}
+
+	return nil
+}
+
+// CreateChatInviteLink creates an additional invite link for the chat
+func (s *Service) CreateChatInviteLink(ctx context.Context, chatID int64, cfg InviteLinkConfig) (*models.ChatInviteLink, error) {
+	params := &bot.CreateChatInviteLinkParams{
+		ChatID:             chatID,
+		Name:               cfg.Name,
+		MemberLimit:        cfg.MemberLimit,
+		CreatesJoinRequest: cfg.JoinRequest,
+	}
+
+	if !cfg.ExpireAt.IsZero() {
+		params.ExpireDate = int(cfg.ExpireAt.Unix())
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	link, err := s.bot.CreateChatInviteLink(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("create chat invite link: %w", err)
+	}
+
+	return link, nil
+}
+
+// RevokeChatInviteLink revokes an invite link created by the bot
+func (s *Service) RevokeChatInviteLink(ctx context.Context, chatID int64, link string) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.RevokeChatInviteLink(ctx, &bot.RevokeChatInviteLinkParams{
+		ChatID:     chatID,
+		InviteLink: link,
+	}); err != nil {
+		return fmt.Errorf("revoke chat invite link: %w", err)
+	}
+
+	return nil
+}
+
+func (p MemberPermissions) chatPermissions() *models.ChatPermissions {
+	return &models.ChatPermissions{
+		CanSendMessages:       p.SendMessages,
+		CanSendAudios:         p.SendMedia,
+		CanSendDocuments:      p.SendMedia,
+		CanSendPhotos:         p.SendMedia,
+		CanSendVideos:         p.SendMedia,
+		CanSendVideoNotes:     p.SendMedia,
+		CanSendVoiceNotes:     p.SendMedia,
+		CanSendPolls:          p.SendPolls,
+		CanSendOtherMessages:  p.SendOther,
+		CanAddWebPagePreviews: p.AddLinkPreviews,
+		CanChangeInfo:         p.ChangeInfo,
+		CanInviteUsers:        p.InviteUsers,
+		CanPinMessages:        p.PinMessages,
+		CanManageTopics:       p.ManageTopics,
+	}
+}
+
+// untilDate converts a duration to the until_date of a ban or restriction,
+// zero is forever
+func untilDate(duration time.Duration) int {
+	if duration <= 0 {
+		return 0
+	}
+
+	return int(time.Now().Add(duration).Unix())
+}
@@ -0,0 +1,26 @@
+package tgbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberPermissions(t *testing.T) {
+	require.Equal(t, &models.ChatPermissions{}, MemberPermissions{}.chatPermissions())
+
+	perms := MemberPermissions{SendMessages: true, SendMedia: true}.chatPermissions()
+	require.True(t, perms.CanSendMessages)
+	require.True(t, perms.CanSendPhotos)
+	require.True(t, perms.CanSendVoiceNotes)
+	require.False(t, perms.CanSendPolls)
+}
+
+func TestUntilDate(t *testing.T) {
+	require.Zero(t, untilDate(0))
+
+	until := untilDate(time.Hour)
+	require.InDelta(t, time.Now().Add(time.Hour).Unix(), until, 2)
+}
@@ -0,0 +1,401 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	helpCommand     = "help"
+	adminOnlyReply  = "⛔ This command is only available to admins."
+	defaultHelpHead = "Available commands:"
+)
+
+// helpHeaders are the localized headers of the /help message
+var helpHeaders = map[string]string{
+	"en": defaultHelpHead,
+	"de": "Verfügbare Befehle:",
+	"es": "Comandos disponibles:",
+	"fr": "Commandes disponibles :",
+	"it": "Comandi disponibili:",
+	"nl": "Beschikbare commando's:",
+	"pt": "Comandos disponíveis:",
+	"ru": "Доступные команды:",
+}
+
+// CommandScope determines in which chats a command is available
+type CommandScope int
+
+const (
+	// ScopeAll makes the command available in every chat
+	ScopeAll CommandScope = iota
+	// ScopePrivate limits the command to private chats
+	ScopePrivate
+	// ScopeGroups limits the command to groups and supergroups
+	ScopeGroups
+)
+
+// CommandMatch determines how message text is matched to a command
+type CommandMatch int
+
+const (
+	// MatchCommand matches the command as a whole word, optionally addressed
+	// to the bot as /name@username and followed by arguments. /start matches
+	// "/start", "/start@bot" and "/start now", but not "/startover".
+	MatchCommand CommandMatch = iota
+	// MatchPrefix matches any text starting with the command
+	MatchPrefix
+	// MatchRegexp matches the text against Command.Pattern
+	MatchRegexp
+)
+
+// Command declares a command with its handler and how it is presented in the
+// command menu and /help
+type Command struct {
+	// Name of the command without the leading slash
+	Name    string
+	Handler bot.HandlerFunc
+	// Description is the default description, Descriptions holds translations
+	// keyed by language code
+	Description  string
+	Descriptions map[string]string
+	// Usage describes the arguments, e.g. "<text> <when>"
+	Usage string
+	// AdminOnly limits the command to Config.Admins, it is only shown to them
+	AdminOnly bool
+	Scope     CommandScope
+	// Hidden commands work but are left out of the menu and /help
+	Hidden bool
+	// Match defaults to MatchCommand, Pattern is used with MatchRegexp
+	Match   CommandMatch
+	Pattern *regexp.Regexp
+}
+
+// CommandSet is a declarative list of commands. Bots implementing
+// CommandSetProvider get their handlers, command menu and /help generated from
+// it, Handlers and List implement CommandProvider for bots that need both.
+type CommandSet []Command
+
+// CommandSetProvider declares the commands of a bot, the service prefers it
+// over CommandProvider
+type CommandSetProvider interface {
+	CommandSet() CommandSet
+}
+
+// Handlers returns the handlers keyed by "/name", as returned by Commands
+func (cs CommandSet) Handlers() map[string]func(ctx context.Context, b *bot.Bot, update *models.Update) {
+	handlers := make(map[string]func(ctx context.Context, b *bot.Bot, update *models.Update), len(cs))
+	for _, cmd := range cs {
+		handlers["/"+cmd.Name] = cmd.Handler
+	}
+
+	return handlers
+}
+
+// List returns the default menu entries, as returned by CommandsList
+func (cs CommandSet) List() []models.BotCommand {
+	return cs.menu("", func(cmd Command) bool {
+		return cmd.Scope == ScopeAll && !cmd.AdminOnly
+	})
+}
+
+// Help renders the command catalog for a user
+func (cs CommandSet) Help(languageCode string, admin, private bool) string {
+	var sb strings.Builder
+
+	header := helpHeaders[baseLanguage(languageCode)]
+	if header == "" {
+		header = defaultHelpHead
+	}
+	sb.WriteString(header)
+
+	for _, cmd := range cs {
+		if cmd.Hidden || (cmd.AdminOnly && !admin) || !cmd.availableIn(private) {
+			continue
+		}
+
+		sb.WriteString("\n/" + cmd.Name)
+		if cmd.Usage != "" {
+			sb.WriteString(" " + cmd.Usage)
+		}
+		if desc := cmd.description(languageCode); desc != "" {
+			sb.WriteString(" - " + desc)
+		}
+		if cmd.AdminOnly {
+			sb.WriteString(" 🔒")
+		}
+	}
+
+	return sb.String()
+}
+
+// has returns true if the set contains the command
+func (cs CommandSet) has(name string) bool {
+	for _, cmd := range cs {
+		if cmd.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cs CommandSet) menu(languageCode string, include func(Command) bool) []models.BotCommand {
+	var list []models.BotCommand
+	for _, cmd := range cs {
+		if cmd.Hidden || !include(cmd) {
+			continue
+		}
+
+		list = append(list, models.BotCommand{
+			Command:     cmd.Name,
+			Description: cmd.description(languageCode),
+		})
+	}
+
+	return list
+}
+
+// languages returns the language codes the set has translations for
+func (cs CommandSet) languages() []string {
+	seen := make(map[string]struct{})
+	for _, cmd := range cs {
+		for lang := range cmd.Descriptions {
+			seen[lang] = struct{}{}
+		}
+	}
+
+	languages := make([]string, 0, len(seen))
+	for lang := range seen {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// description returns the translated description, falling back from e.g.
+// pt-br to pt to the default
+func (c Command) description(languageCode string) string {
+	if desc, ok := c.Descriptions[languageCode]; ok {
+		return desc
+	}
+
+	if desc, ok := c.Descriptions[baseLanguage(languageCode)]; ok {
+		return desc
+	}
+
+	return c.Description
+}
+
+func (c Command) availableIn(private bool) bool {
+	switch c.Scope {
+	case ScopePrivate:
+		return private
+	case ScopeGroups:
+		return !private
+	default:
+		return true
+	}
+}
+
+func baseLanguage(languageCode string) string {
+	base, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	return base
+}
+
+// commandSet returns the declared commands of the bot with the generated
+// /help, nil if the bot does not declare its commands
+func (s *Service) commandSet() CommandSet {
+	provider, ok := s.cfg.Bot.(CommandSetProvider)
+	if !ok {
+		if adapter, isAdapter := s.cfg.Bot.(*botAdapter); isAdapter {
+			provider, ok = adapter.impl.(CommandSetProvider)
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	set := provider.CommandSet()
+	if len(set) == 0 || set.has(helpCommand) {
+		return set
+	}
+
+	return append(set, Command{
+		Name:        helpCommand,
+		Handler:     s.helpHandler(),
+		Description: "Show the available commands",
+	})
+}
+
+// commandRoute matches message text to a command handler
+type commandRoute struct {
+	command string
+	match   CommandMatch
+	pattern *regexp.Regexp
+	handler bot.HandlerFunc
+}
+
+// matches returns true if the text invokes the command, username is the
+// username of the bot, commands addressed to other bots don't match
+func (r commandRoute) matches(text, username string) bool {
+	switch r.match {
+	case MatchPrefix:
+		return strings.HasPrefix(text, r.command)
+	case MatchRegexp:
+		return r.pattern != nil && r.pattern.MatchString(text)
+	}
+
+	word := text
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		word = text[:i]
+	}
+
+	name, target, addressed := strings.Cut(word, "@")
+	if name != r.command {
+		return false
+	}
+
+	return !addressed || username == "" || strings.EqualFold(target, username)
+}
+
+// commandRoutes returns the command routes, declared commands are wrapped with
+// their scope and admin checks. Commands of bots without a command set match
+// with MatchCommand.
+func (s *Service) commandRoutes() []commandRoute {
+	var routes []commandRoute
+
+	if s.commands == nil {
+		if s.cfg.Bot == nil {
+			return nil
+		}
+
+		for command, handler := range s.cfg.Bot.Commands() {
+			routes = append(routes, commandRoute{command: command, handler: handler})
+		}
+
+		return routes
+	}
+
+	for _, cmd := range s.commands {
+		routes = append(routes, commandRoute{
+			command: "/" + cmd.Name,
+			match:   cmd.Match,
+			pattern: cmd.Pattern,
+			handler: s.guardCommand(cmd),
+		})
+	}
+
+	return routes
+}
+
+// guardCommand ignores commands outside their scope and turns away non-admins
+// from admin commands
+func (s *Service) guardCommand(cmd Command) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		msg := UpdateMessage(update)
+		if msg != nil && !cmd.availableIn(msg.Chat.Type == ChatTypePrivate) {
+			return
+		}
+
+		if cmd.AdminOnly {
+			user := UpdateUser(update)
+			if user == nil || !s.IsAdmin(user.ID) {
+				if msg != nil {
+					s.SendAsync(msg.Chat.ID, Message{Text: adminOnlyReply, ReplyTo: msg.ID})
+				}
+				return
+			}
+		}
+
+		cmd.Handler(ctx, b, update)
+	}
+}
+
+func (s *Service) helpHandler() bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		msg := UpdateMessage(update)
+		if msg == nil {
+			return
+		}
+
+		var languageCode string
+		var admin bool
+		if msg.From != nil {
+			languageCode = s.Locale(msg.From.ID).LanguageCode
+			if languageCode == "" {
+				languageCode = msg.From.LanguageCode
+			}
+			admin = s.IsAdmin(msg.From.ID)
+		}
+
+		text := s.commands.Help(languageCode, admin, msg.Chat.Type == ChatTypePrivate)
+		if _, err := s.SendContext(ctx, msg.Chat.ID, Message{Text: text}); err != nil {
+			s.logger.Error("failed to send help",
+				slog.String("err", err.Error()),
+				slog.Int64("chat", msg.Chat.ID),
+			)
+		}
+	}
+}
+
+// setupCommandMenus sets the command menu per scope and language, admins get
+// a menu that includes the admin commands
+func (s *Service) setupCommandMenus() {
+	type menu struct {
+		scope   models.BotCommandScope
+		include func(Command) bool
+	}
+
+	menus := []menu{
+		{&models.BotCommandScopeDefault{}, func(c Command) bool { return c.Scope == ScopeAll && !c.AdminOnly }},
+		{&models.BotCommandScopeAllPrivateChats{}, func(c Command) bool { return c.availableIn(true) && !c.AdminOnly }},
+		{&models.BotCommandScopeAllGroupChats{}, func(c Command) bool { return c.availableIn(false) && !c.AdminOnly }},
+	}
+	for _, admin := range s.cfg.Admins {
+		menus = append(menus, menu{&models.BotCommandScopeChat{ChatID: admin}, func(c Command) bool { return c.availableIn(true) }})
+	}
+
+	languages := append([]string{""}, s.commands.languages()...)
+
+	for _, m := range menus {
+		for _, lang := range languages {
+			commands := s.commands.menu(lang, m.include)
+			if len(commands) == 0 {
+				continue
+			}
+
+			if err := s.setCommands(commands, m.scope, lang); err != nil {
+				s.logger.Error("failed to set bot commands",
+					slog.String("err", err.Error()),
+					slog.String("bot", s.username),
+					slog.String("language", lang),
+				)
+			}
+		}
+	}
+}
+
+func (s *Service) setCommands(commands []models.BotCommand, scope models.BotCommandScope, languageCode string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+		Commands:     commands,
+		Scope:        scope,
+		LanguageCode: languageCode,
+	}); err != nil {
+		return fmt.Errorf("set my commands: %w", err)
+	}
+
+	return nil
+}
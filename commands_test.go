@@ -0,0 +1,94 @@
+package tgbot
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func testCommandSet() CommandSet {
+	noop := func(ctx context.Context, b *bot.Bot, update *models.Update) {}
+
+	return CommandSet{
+		{Name: "start", Handler: noop, Description: "Start", Descriptions: map[string]string{"nl": "Begin"}},
+		{Name: "remind", Handler: noop, Description: "Remind", Usage: "<when> <text>", Scope: ScopePrivate},
+		{Name: "ban", Handler: noop, Description: "Ban", AdminOnly: true, Scope: ScopeGroups},
+		{Name: "debug", Handler: noop, Hidden: true},
+	}
+}
+
+func TestCommandSetList(t *testing.T) {
+	cs := testCommandSet()
+
+	require.Equal(t, []models.BotCommand{{Command: "start", Description: "Start"}}, cs.List())
+	require.Len(t, cs.Handlers(), 4)
+	require.Contains(t, cs.Handlers(), "/debug")
+
+	private := cs.menu("nl-be", func(c Command) bool { return c.availableIn(true) && !c.AdminOnly })
+	require.Equal(t, []models.BotCommand{
+		{Command: "start", Description: "Begin"},
+		{Command: "remind", Description: "Remind"},
+	}, private)
+
+	require.Equal(t, []string{"nl"}, cs.languages())
+}
+
+func TestCommandSetHelp(t *testing.T) {
+	cs := testCommandSet()
+
+	require.Equal(t, "Available commands:\n/start - Start\n/remind <when> <text> - Remind", cs.Help("en", false, true))
+	require.Equal(t, "Beschikbare commando's:\n/start - Begin", cs.Help("nl", false, false))
+	require.Equal(t, "Available commands:\n/start - Start\n/ban - Ban 🔒", cs.Help("", true, false))
+}
+
+func TestGuardCommand(t *testing.T) {
+	var calls int
+	cmd := Command{
+		Name:    "remind",
+		Scope:   ScopePrivate,
+		Handler: func(ctx context.Context, b *bot.Bot, update *models.Update) { calls++ },
+	}
+
+	s := &Service{cfg: &Config{}}
+	handler := s.guardCommand(cmd)
+
+	handler(context.Background(), nil, &models.Update{Message: &models.Message{Chat: models.Chat{Type: ChatTypeGroup}}})
+	require.Zero(t, calls)
+
+	handler(context.Background(), nil, &models.Update{Message: &models.Message{Chat: models.Chat{Type: ChatTypePrivate}}})
+	require.Equal(t, 1, calls)
+}
+
+func TestAdaptBotCommandSet(t *testing.T) {
+	b := AdaptBot(commandSetBot{})
+
+	require.Contains(t, b.Commands(), "/start")
+	require.Equal(t, "start", b.CommandsList()[0].Command)
+}
+
+type commandSetBot struct{}
+
+func (commandSetBot) CommandSet() CommandSet { return testCommandSet() }
+
+func TestCommandRouteMatches(t *testing.T) {
+	route := commandRoute{command: "/start"}
+
+	require.True(t, route.matches("/start", "mybot"))
+	require.True(t, route.matches("/start now", "mybot"))
+	require.True(t, route.matches("/start\nnow", "mybot"))
+	require.True(t, route.matches("/start@MyBot", "mybot"))
+	require.False(t, route.matches("/start@otherbot", "mybot"))
+	require.False(t, route.matches("/startover", "mybot"))
+	require.False(t, route.matches("start", "mybot"))
+
+	prefix := commandRoute{command: "/start", match: MatchPrefix}
+	require.True(t, prefix.matches("/startover", "mybot"))
+
+	re := commandRoute{command: "/go", match: MatchRegexp, pattern: regexp.MustCompile(`^/go_\d+$`)}
+	require.True(t, re.matches("/go_12", "mybot"))
+	require.False(t, re.matches("/go", "mybot"))
+}
@@ -5,6 +5,16 @@ import "errors"
 var (
 	ErrNilLogger = errors.New("logger not provided")
 	ErrNilConfig = errors.New("config not provided")
+	// ErrInvalidConfig wraps the problems found in the configuration
+	ErrInvalidConfig = errors.New("invalid configuration")
+
+	ErrNoMessage  = errors.New("update has no message")
+	ErrNoMedia    = errors.New("message has no media")
+	ErrNoPhoto    = errors.New("message has no photo")
+	ErrNoDocument = errors.New("message has no document")
+
+	ErrUserNotFound   = errors.New("user not found")
+	ErrNoProfilePhoto = errors.New("no photos found")
 )
 
 var (
@@ -35,3 +45,11 @@ var (
 		"removed_chat_boost",
 	}
 )
+
+// Chat types as reported by the Bot API
+const (
+	ChatTypePrivate    = "private"
+	ChatTypeGroup      = "group"
+	ChatTypeSupergroup = "supergroup"
+	ChatTypeChannel    = "channel"
+)
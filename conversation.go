@@ -0,0 +1,234 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Davincible/tgbot/cache"
+)
+
+const defaultConversationTimeout = 10 * time.Minute
+
+var (
+	ErrConversationTimeout  = errors.New("conversation timed out")
+	ErrConversationCanceled = errors.New("conversation canceled")
+)
+
+// ConversationState is the stored state of a conversation with a chat
+type ConversationState struct {
+	// Step is the input the conversation waits for
+	Step string `json:"step"`
+	// Answer is set when the answer arrived while no one was waiting for
+	// it, e.g. after a restart. The next Await of the step returns it.
+	Answer  string    `json:"answer,omitempty"`
+	Started time.Time `json:"started"`
+}
+
+// Conversations tracks the input each chat is asked for. The state lives in
+// a cache, so with a persistent backend pending steps survive restarts and
+// are shared by all conversational modules; waiting for the answer is in
+// memory.
+type Conversations struct {
+	store   cache.Cache[ConversationState]
+	timeout time.Duration
+
+	mu      sync.Mutex
+	waiters map[int64]chan string
+}
+
+// NewConversations creates conversations stored in the cache, which defaults
+// to memory. Steps expire after the timeout, which defaults to ten minutes.
+func NewConversations(store cache.Cache[ConversationState], timeout time.Duration) *Conversations {
+	if store == nil {
+		store = cache.NewMemory[ConversationState]()
+	}
+	if timeout <= 0 {
+		timeout = defaultConversationTimeout
+	}
+
+	return &Conversations{
+		store:   store,
+		timeout: timeout,
+		waiters: make(map[int64]chan string),
+	}
+}
+
+// Await sets the step of the chat and waits for its answer. It returns
+// ErrConversationTimeout when no answer arrives in time and
+// ErrConversationCanceled when the conversation is canceled or another step
+// is awaited for the chat.
+func (c *Conversations) Await(ctx context.Context, chatID int64, step string) (string, error) {
+	state, ok, err := c.store.Get(ctx, conversationKey(chatID))
+	if err != nil {
+		return "", fmt.Errorf("get conversation: %w", err)
+	}
+
+	// Resume a step that was answered while no one was waiting
+	if ok && state.Step == step && state.Answer != "" {
+		if err := c.store.Delete(ctx, conversationKey(chatID)); err != nil {
+			return "", fmt.Errorf("delete conversation: %w", err)
+		}
+
+		return state.Answer, nil
+	}
+
+	state = ConversationState{Step: step, Started: time.Now()}
+	if err := c.store.Set(ctx, conversationKey(chatID), state, c.timeout); err != nil {
+		return "", fmt.Errorf("set conversation: %w", err)
+	}
+
+	answer := make(chan string, 1)
+
+	c.mu.Lock()
+	if previous, ok := c.waiters[chatID]; ok {
+		close(previous)
+	}
+	c.waiters[chatID] = answer
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case text, ok := <-answer:
+		if !ok {
+			return "", ErrConversationCanceled
+		}
+
+		return text, nil
+	case <-timer.C:
+		c.end(chatID, answer)
+		return "", ErrConversationTimeout
+	case <-ctx.Done():
+		c.end(chatID, answer)
+		return "", ctx.Err()
+	}
+}
+
+// Answer hands the answer to the step the chat waits for. It returns false
+// if the chat has no pending step.
+func (c *Conversations) Answer(ctx context.Context, chatID int64, text string) (bool, error) {
+	c.mu.Lock()
+	answer, waiting := c.waiters[chatID]
+	delete(c.waiters, chatID)
+	c.mu.Unlock()
+
+	if waiting {
+		answer <- text
+
+		if err := c.store.Delete(ctx, conversationKey(chatID)); err != nil {
+			return true, fmt.Errorf("delete conversation: %w", err)
+		}
+
+		return true, nil
+	}
+
+	state, ok, err := c.store.Get(ctx, conversationKey(chatID))
+	if err != nil {
+		return false, fmt.Errorf("get conversation: %w", err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	state.Answer = text
+	if err := c.store.Set(ctx, conversationKey(chatID), state, c.timeout); err != nil {
+		return false, fmt.Errorf("set conversation: %w", err)
+	}
+
+	return true, nil
+}
+
+// Step returns the step the chat waits for, empty if there is none
+func (c *Conversations) Step(ctx context.Context, chatID int64) (string, error) {
+	state, ok, err := c.store.Get(ctx, conversationKey(chatID))
+	if err != nil {
+		return "", fmt.Errorf("get conversation: %w", err)
+	}
+
+	if !ok || state.Answer != "" {
+		return "", nil
+	}
+
+	return state.Step, nil
+}
+
+// Waiting returns the steps of the chats with a waiting Await, keyed by chat
+func (c *Conversations) Waiting(ctx context.Context) (map[int64]ConversationState, error) {
+	c.mu.Lock()
+	chatIDs := make([]int64, 0, len(c.waiters))
+	for chatID := range c.waiters {
+		chatIDs = append(chatIDs, chatID)
+	}
+	c.mu.Unlock()
+
+	waiting := make(map[int64]ConversationState, len(chatIDs))
+	for _, chatID := range chatIDs {
+		state, ok, err := c.store.Get(ctx, conversationKey(chatID))
+		if err != nil {
+			return nil, fmt.Errorf("get conversation: %w", err)
+		}
+
+		if ok {
+			waiting[chatID] = state
+		}
+	}
+
+	return waiting, nil
+}
+
+// Cancel ends the conversation of the chat, a waiting Await returns
+// ErrConversationCanceled
+func (c *Conversations) Cancel(ctx context.Context, chatID int64) error {
+	c.mu.Lock()
+	if answer, ok := c.waiters[chatID]; ok {
+		close(answer)
+		delete(c.waiters, chatID)
+	}
+	c.mu.Unlock()
+
+	if err := c.store.Delete(ctx, conversationKey(chatID)); err != nil {
+		return fmt.Errorf("delete conversation: %w", err)
+	}
+
+	return nil
+}
+
+// Close cancels all waiting conversations, their stored steps are kept so
+// answers can still be picked up after a restart
+func (c *Conversations) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for chatID, answer := range c.waiters {
+		close(answer)
+		delete(c.waiters, chatID)
+	}
+}
+
+// end removes the step after a timeout or cancellation, unless another
+// step replaced it in the meantime
+func (c *Conversations) end(chatID int64, answer chan string) {
+	c.mu.Lock()
+	current, ok := c.waiters[chatID]
+	if !ok || current != answer {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.waiters, chatID)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	_ = c.store.Delete(ctx, conversationKey(chatID))
+}
+
+func conversationKey(chatID int64) string {
+	return "conversation:" + strconv.FormatInt(chatID, 10)
+}
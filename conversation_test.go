@@ -0,0 +1,87 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Davincible/tgbot/cache"
+)
+
+func TestConversationsAwait(t *testing.T) {
+	c := NewConversations(nil, time.Minute)
+	ctx := context.Background()
+
+	result := make(chan string)
+	go func() {
+		answer, err := c.Await(ctx, 1, "code")
+		require.NoError(t, err)
+		result <- answer
+	}()
+
+	require.Eventually(t, func() bool {
+		step, _ := c.Step(ctx, 1)
+		return step == "code"
+	}, time.Second, time.Millisecond)
+
+	ok, err := c.Answer(ctx, 1, "12345")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "12345", <-result)
+
+	step, err := c.Step(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, step)
+
+	ok, err = c.Answer(ctx, 1, "late")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestConversationsTimeoutAndCancel(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewConversations(nil, 10*time.Millisecond).Await(ctx, 1, "code")
+	require.ErrorIs(t, err, ErrConversationTimeout)
+
+	c := NewConversations(nil, time.Minute)
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Await(ctx, 1, "phone")
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		step, _ := c.Step(ctx, 1)
+		return step == "phone"
+	}, time.Second, time.Millisecond)
+
+	waiting, err := c.Waiting(ctx)
+	require.NoError(t, err)
+	require.Len(t, waiting, 1)
+	require.Equal(t, "phone", waiting[1].Step)
+
+	require.NoError(t, c.Cancel(ctx, 1))
+	require.ErrorIs(t, <-done, ErrConversationCanceled)
+}
+
+func TestConversationsResume(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemory[ConversationState]()
+
+	// The step was stored by a process that stopped before the answer came
+	require.NoError(t, store.Set(ctx, conversationKey(1), ConversationState{Step: "code"}, time.Minute))
+
+	c := NewConversations(store, time.Minute)
+
+	ok, err := c.Answer(ctx, 1, "12345")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	answer, err := c.Await(ctx, 1, "code")
+	require.NoError(t, err)
+	require.Equal(t, "12345", answer)
+}
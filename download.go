@@ -0,0 +1,139 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/go-telegram/bot"
+)
+
+// ErrFileTooLarge is returned by DownloadFileTo for files over
+// DownloadOptions.MaxSize
+var ErrFileTooLarge = errors.New("file is too large")
+
+// streamClient has no timeout, streamed downloads of large files are bound
+// by their context only
+var streamClient = &http.Client{}
+
+// DownloadOptions configures DownloadFileToContext
+type DownloadOptions struct {
+	// MaxSize rejects files over this many bytes with ErrFileTooLarge, before
+	// downloading when Telegram reports the size and otherwise once the limit
+	// is passed. Zero means no limit.
+	MaxSize int64
+	// Progress is called after every chunk written with the bytes written so
+	// far and the size of the file, zero if unknown
+	Progress func(written, total int64)
+}
+
+// DownloadFileTo streams the file to w instead of holding it in memory, for
+// large documents written to disk or object storage. It returns the number
+// of bytes written. The download is not cached and has no timeout.
+func (s *Service) DownloadFileTo(fileID any, w io.Writer) (int64, error) {
+	return s.DownloadFileToContext(context.Background(), fileID, w, nil)
+}
+
+// DownloadFileToContext is DownloadFileTo with a context and options. On
+// error w may hold part of the file.
+func (s *Service) DownloadFileToContext(ctx context.Context, fileID any, w io.Writer, opts *DownloadOptions) (int64, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+
+	file, err := s.bot.GetFile(ctx, &bot.GetFileParams{
+		FileID: fmt.Sprintf("%v", fileID),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get file: %w", err)
+	}
+
+	if opts.MaxSize > 0 && file.FileSize > opts.MaxSize {
+		return 0, fmt.Errorf("%w: %d bytes, at most %d", ErrFileTooLarge, file.FileSize, opts.MaxSize)
+	}
+
+	body, size, err := s.openFile(ctx, file.FilePath)
+	if err != nil {
+		return 0, fmt.Errorf("download file: %w", err)
+	}
+	defer body.Close()
+
+	if file.FileSize > 0 {
+		size = file.FileSize
+	}
+
+	dst := &downloadWriter{w: w, max: opts.MaxSize, total: size, progress: opts.Progress}
+
+	written, err := io.Copy(dst, body)
+	if err != nil {
+		return written, fmt.Errorf("download file: %w", err)
+	}
+
+	return written, nil
+}
+
+// openFile opens a file returned by getFile for reading, along with its size
+// if known
+func (s *Service) openFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	if path, ok := s.localFile(filePath); ok {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+
+		return f, info.Size(), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.fileURL(ctx, filePath), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, 0, fmt.Errorf("received status code %d from server: %s", resp.StatusCode, body)
+	}
+
+	return resp.Body, max(resp.ContentLength, 0), nil
+}
+
+// downloadWriter counts the bytes written, enforcing the maximum size and
+// reporting progress
+type downloadWriter struct {
+	w        io.Writer
+	written  int64
+	max      int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if d.max > 0 && d.written+int64(len(p)) > d.max {
+		return 0, fmt.Errorf("%w: over %d bytes", ErrFileTooLarge, d.max)
+	}
+
+	n, err := d.w.Write(p)
+	d.written += int64(n)
+
+	if d.progress != nil && n > 0 {
+		d.progress(d.written, d.total)
+	}
+
+	return n, err
+}
@@ -0,0 +1,75 @@
+package tgbot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestDownloadFileTo(t *testing.T) {
+	const token = "123456:test-token"
+
+	content := strings.Repeat("a", 100_000)
+	fileSize := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot" + token + "/getMe":
+			fmt.Fprint(w, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"test_bot"}}`)
+		case "/bot" + token + "/getFile":
+			fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"abc","file_size":%d,"file_path":"documents/a.pdf"}}`, fileSize)
+		case "/file/bot" + token + "/documents/a.pdf":
+			_, _ = w.Write([]byte(content))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	s, err := NewService(logger, &Config{Token: token, SkipGetMe: true, APIServer: &APIServer{URL: srv.URL}})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	var buf bytes.Buffer
+	n, err := s.DownloadFileTo("abc", &buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), n)
+	require.Equal(t, content, buf.String())
+
+	// Progress reports the size Telegram knows
+	fileSize = len(content)
+
+	var last, total int64
+	buf.Reset()
+	_, err = s.DownloadFileToContext(context.Background(), "abc", &buf, &DownloadOptions{
+		Progress: func(written, size int64) { last, total = written, size },
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), last)
+	require.Equal(t, int64(len(content)), total)
+
+	// Known sizes are rejected before downloading
+	buf.Reset()
+	n, err = s.DownloadFileToContext(context.Background(), "abc", &buf, &DownloadOptions{MaxSize: 1000})
+	require.True(t, errors.Is(err, ErrFileTooLarge))
+	require.Zero(t, n)
+	require.Zero(t, buf.Len())
+
+	// Unknown sizes are cut off once the limit is passed
+	fileSize = 0
+	buf.Reset()
+	n, err = s.DownloadFileToContext(context.Background(), "abc", &buf, &DownloadOptions{MaxSize: 1000})
+	require.True(t, errors.Is(err, ErrFileTooLarge))
+	require.LessOrEqual(t, n, int64(1000))
+}
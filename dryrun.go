@@ -0,0 +1,227 @@
+package tgbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// dryRunToken stands in for the token when Config.DryRun is set without one
+const dryRunToken = "0:dry-run"
+
+// RecordedCall is a request to the Bot API the Recorder received instead of
+// Telegram
+type RecordedCall struct {
+	// Method is the Bot API method, e.g. sendMessage or editMessageText
+	Method string
+	// Params are the form values of the request, objects and arrays are JSON
+	// encoded like the Bot API expects them
+	Params map[string]string
+	// Files maps the form fields of uploaded files to their file name
+	Files map[string]string
+	At    time.Time
+}
+
+// ChatID returns the chat_id parameter, zero if there is none
+func (c RecordedCall) ChatID() int64 {
+	id, _ := strconv.ParseInt(c.Params["chat_id"], 10, 64)
+	return id
+}
+
+// Recorder takes the place of the Bot API when set as Config.DryRun. It
+// records every request and answers it without contacting Telegram, so the
+// full bot logic runs in tests and staging without a token.
+//
+// Sends and edits are answered with a message carrying the sent text and a
+// new message ID, getMe with a bot named dry_run_bot and other methods with
+// true. Methods that fetch data, like getChat, have no default answer, set
+// one with Respond.
+type Recorder struct {
+	mu        sync.Mutex
+	calls     []RecordedCall
+	responses map[string]any
+	messageID atomic.Int64
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{responses: make(map[string]any)}
+}
+
+// Respond sets the result returned for a method, the result is JSON encoded
+// as the result field of the Bot API response
+func (r *Recorder) Respond(method string, result any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.responses[method] = result
+}
+
+// Calls returns the recorded requests in the order they were made, only
+// those to the given methods if any are given
+func (r *Recorder) Calls(methods ...string) []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]RecordedCall, 0, len(r.calls))
+	for _, call := range r.calls {
+		if len(methods) == 0 || slices.Contains(methods, call.Method) {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset drops the recorded requests
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
+
+// dryRunOption makes the bot library send its requests to the recorder
+func (s *Service) dryRunOption() bot.Option {
+	return bot.WithHTTPClient(time.Minute, s.cfg.DryRun)
+}
+
+// Do implements bot.HttpClient
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+
+	// Polling waits for updates that never come
+	if method == "getUpdates" {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+
+	call, err := recordCall(req, method)
+	if err != nil {
+		return nil, fmt.Errorf("dry run %s: %w", method, err)
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	result, ok := r.responses[method]
+	r.mu.Unlock()
+
+	if !ok {
+		result, ok = r.defaultResult(call)
+	}
+	if !ok {
+		return dryRunResponse(map[string]any{
+			"ok":          false,
+			"error_code":  http.StatusBadRequest,
+			"description": fmt.Sprintf("Bad Request: dry run has no response for %s, set one with Recorder.Respond", method),
+		})
+	}
+
+	return dryRunResponse(map[string]any{"ok": true, "result": result})
+}
+
+func (r *Recorder) defaultResult(call RecordedCall) (any, bool) {
+	switch {
+	case call.Method == "getMe":
+		return models.User{ID: 1, IsBot: true, FirstName: "Dry Run", Username: "dry_run_bot"}, true
+	case call.Method == "sendMediaGroup":
+		var media []json.RawMessage
+		_ = json.Unmarshal([]byte(call.Params["media"]), &media)
+
+		messages := make([]models.Message, 0, len(media))
+		for range media {
+			messages = append(messages, r.message(call, r.messageID.Add(1)))
+		}
+
+		return messages, true
+	case call.Method == "sendChatAction", call.Method == "sendGift":
+		return true, true
+	case strings.HasPrefix(call.Method, "send"), call.Method == "copyMessage", call.Method == "forwardMessage":
+		return r.message(call, r.messageID.Add(1)), true
+	case strings.HasPrefix(call.Method, "editMessage"):
+		msgID, _ := strconv.ParseInt(call.Params["message_id"], 10, 64)
+		return r.message(call, msgID), true
+	case strings.HasPrefix(call.Method, "get"):
+		return nil, false
+	default:
+		return true, true
+	}
+}
+
+// message is the answer to a send or edit, carrying what was sent
+func (r *Recorder) message(call RecordedCall, msgID int64) models.Message {
+	msg := models.Message{
+		ID:      int(msgID),
+		Date:    int(call.At.Unix()),
+		Chat:    models.Chat{ID: call.ChatID()},
+		Text:    call.Params["text"],
+		Caption: call.Params["caption"],
+	}
+
+	if thread, err := strconv.Atoi(call.Params["message_thread_id"]); err == nil {
+		msg.MessageThreadID = thread
+	}
+
+	return msg
+}
+
+// recordCall reads the form the bot library sends, it sends multipart forms
+// or no body at all
+func recordCall(req *http.Request, method string) (RecordedCall, error) {
+	call := RecordedCall{
+		Method: method,
+		Params: make(map[string]string),
+		Files:  make(map[string]string),
+		At:     time.Now(),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return call, nil
+	}
+
+	if err := req.ParseMultipartForm(32 << 20); err != nil {
+		return call, fmt.Errorf("parse form: %w", err)
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	for key, values := range req.MultipartForm.Value {
+		if len(values) > 0 {
+			call.Params[key] = values[0]
+		}
+	}
+
+	for key, files := range req.MultipartForm.File {
+		if len(files) > 0 {
+			call.Files[key] = files[0].Filename
+		}
+	}
+
+	return call, nil
+}
+
+func dryRunResponse(body map[string]any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encode dry run response: %w", err)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package tgbot
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestDryRun(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := NewRecorder()
+
+	srv, err := NewService(logger, &Config{DryRun: recorder})
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	require.Equal(t, "dry_run_bot", srv.username)
+
+	msg, err := srv.Send(42, Message{Text: "hello"})
+	require.NoError(t, err)
+	require.Equal(t, "hello", msg.Text)
+	require.Equal(t, int64(42), msg.Chat.ID)
+
+	edited, err := srv.EditMessage(42, msg.ID, Message{Text: "edited"})
+	require.NoError(t, err)
+	require.Equal(t, msg.ID, edited.ID)
+	require.Equal(t, "edited", edited.Text)
+
+	require.NoError(t, srv.DeleteMessage(42, msg.ID))
+	require.NoError(t, srv.SendTyping(42))
+
+	calls := recorder.Calls("sendMessage", "editMessageText", "deleteMessage")
+	require.Len(t, calls, 3)
+	require.Equal(t, "sendMessage", calls[0].Method)
+	require.Equal(t, int64(42), calls[0].ChatID())
+	require.Equal(t, "hello", calls[0].Params["text"])
+	require.Equal(t, "edited", calls[1].Params["text"])
+	require.Equal(t, "deleteMessage", calls[2].Method)
+
+	// Methods fetching data need a response
+	_, err = srv.bot.GetChat(context.Background(), &bot.GetChatParams{ChatID: 42})
+	require.ErrorIs(t, err, bot.ErrorBadRequest)
+
+	recorder.Respond("getChat", models.ChatFullInfo{ID: 42, Type: "private"})
+	chat, err := srv.bot.GetChat(context.Background(), &bot.GetChatParams{ChatID: 42})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), chat.ID)
+
+	recorder.Reset()
+	require.Empty(t, recorder.Calls())
+}
+
+func TestDryRunEditMediaUpload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := NewRecorder()
+
+	srv, err := NewService(logger, &Config{DryRun: recorder})
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	_, err = srv.EditMessage(42, 7, Message{Text: "chart", Image: []byte("png")})
+	require.NoError(t, err)
+
+	_, err = srv.EditMessage(42, 7, Message{DocumentURL: "https://example.com/report.pdf"})
+	require.NoError(t, err)
+
+	calls := recorder.Calls("editMessageMedia")
+	require.Len(t, calls, 2)
+
+	// Media from memory is attached to the request
+	require.Equal(t, "image.jpg", calls[0].Files["image.jpg"])
+	require.Contains(t, calls[0].Params["media"], `"media":"attach://image.jpg"`)
+	require.Contains(t, calls[0].Params["media"], `"caption":"chart"`)
+
+	require.Empty(t, calls[1].Files)
+	require.Contains(t, calls[1].Params["media"], `"media":"https://example.com/report.pdf"`)
+}
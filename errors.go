@@ -0,0 +1,96 @@
+package tgbot
+
+import (
+	"errors"
+	"strings"
+)
+
+// Telegram API failures, Send, Edit and Delete return them wrapped in an
+// APIError so callers can branch on them with errors.Is. Flood waits are
+// returned as FloodWaitError, which matches ErrFloodWait.
+var (
+	ErrMessageTooLong     = errors.New("message is too long")
+	ErrMessageNotModified = errors.New("message is not modified")
+	// ErrMessageNotFound is returned for edits and deletes of messages that
+	// don't exist (anymore)
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrMessageCantBeDeleted is returned for messages the bot is not
+	// allowed to delete, e.g. messages older than 48 hours
+	ErrMessageCantBeDeleted = errors.New("message can't be deleted")
+	ErrBotBlocked           = errors.New("bot was blocked by the user")
+	ErrUserDeactivated      = errors.New("user is deactivated")
+	ErrChatNotFound         = errors.New("chat not found")
+	// ErrBotNotMember is returned for chats the bot was removed from
+	ErrBotNotMember    = errors.New("bot is not a member of the chat")
+	ErrNotEnoughRights = errors.New("not enough rights")
+
+	// errNoTextToEdit is returned when editing the text of a media message,
+	// its caption has to be edited instead
+	errNoTextToEdit = errors.New("no text to edit")
+)
+
+// APIError is a failed request to the Bot API. It matches its kind, one of
+// the errors above, and the errors of the bot library with errors.Is.
+type APIError struct {
+	// Kind is the error the failure is classified as, nil if unknown
+	Kind error
+	Err  error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+// apiErrorKinds maps the descriptions of the Bot API to their error, the
+// descriptions are matched lowercased
+var apiErrorKinds = []struct {
+	descriptions []string
+	kind         error
+}{
+	{[]string{"message is too long", "text is too long", "caption is too long", "_too_long"}, ErrMessageTooLong},
+	{[]string{"message is not modified"}, ErrMessageNotModified},
+	{[]string{"message to edit not found", "message to delete not found", "message_id_invalid"}, ErrMessageNotFound},
+	{[]string{"message can't be deleted"}, ErrMessageCantBeDeleted},
+	{[]string{"there is no text in the message to edit"}, errNoTextToEdit},
+	{[]string{"bot was blocked by the user"}, ErrBotBlocked},
+	{[]string{"user is deactivated"}, ErrUserDeactivated},
+	{[]string{"chat not found"}, ErrChatNotFound},
+	{[]string{"user not found"}, ErrUserNotFound},
+	{[]string{"bot was kicked", "bot is not a member"}, ErrBotNotMember},
+	{[]string{"not enough rights", "need administrator rights", "chat_admin_required"}, ErrNotEnoughRights},
+}
+
+// apiError classifies an error of the bot library. Errors it doesn't know are
+// wrapped without a kind, so they still match the library errors.
+func apiError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		apiErr *APIError
+		flood  *FloodWaitError
+	)
+	if errors.As(err, &apiErr) || errors.As(err, &flood) {
+		return err
+	}
+
+	description := strings.ToLower(err.Error())
+	for _, k := range apiErrorKinds {
+		for _, d := range k.descriptions {
+			if strings.Contains(description, d) {
+				return &APIError{Kind: k.kind, Err: err}
+			}
+		}
+	}
+
+	return &APIError{Err: err}
+}
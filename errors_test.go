@@ -0,0 +1,54 @@
+package tgbot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	require.NoError(t, apiError(nil))
+
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{fmt.Errorf("%w, Bad Request: message is too long", bot.ErrorBadRequest), ErrMessageTooLong},
+		{fmt.Errorf("%w, Bad Request: MEDIA_CAPTION_TOO_LONG", bot.ErrorBadRequest), ErrMessageTooLong},
+		{fmt.Errorf("%w, Bad Request: message is not modified: specified new message content and reply markup are exactly the same", bot.ErrorBadRequest), ErrMessageNotModified},
+		{fmt.Errorf("%w, Bad Request: message to delete not found", bot.ErrorBadRequest), ErrMessageNotFound},
+		{fmt.Errorf("%w, Bad Request: message can't be deleted", bot.ErrorBadRequest), ErrMessageCantBeDeleted},
+		{fmt.Errorf("%w, Forbidden: bot was blocked by the user", bot.ErrorForbidden), ErrBotBlocked},
+		{fmt.Errorf("%w, Forbidden: user is deactivated", bot.ErrorForbidden), ErrUserDeactivated},
+		{fmt.Errorf("%w, Bad Request: chat not found", bot.ErrorBadRequest), ErrChatNotFound},
+		{fmt.Errorf("%w, Forbidden: bot was kicked from the supergroup chat", bot.ErrorForbidden), ErrBotNotMember},
+		{fmt.Errorf("%w, Bad Request: not enough rights to send text messages to the chat", bot.ErrorBadRequest), ErrNotEnoughRights},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("send: %w", apiError(tt.err))
+
+		require.ErrorIs(t, err, tt.kind, tt.err.Error())
+		// The library errors still match
+		require.ErrorIs(t, err, tt.err)
+
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, tt.kind, apiErr.Kind)
+	}
+
+	// Unknown errors have no kind
+	err := apiError(fmt.Errorf("%w, Bad Request: something new", bot.ErrorBadRequest))
+	require.ErrorIs(t, err, bot.ErrorBadRequest)
+	require.Nil(t, err.(*APIError).Kind)
+
+	// Classified errors are not wrapped twice
+	require.Same(t, err, apiError(err))
+
+	flood := &FloodWaitError{RetryAfter: time.Minute, Err: errors.New("too many requests")}
+	require.Same(t, flood, apiError(flood))
+}
@@ -0,0 +1,143 @@
+package tgbot
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// ExportFilter selects the exported profiles, zero values match everything
+type ExportFilter struct {
+	// ActiveSince only exports profiles seen at or after the time
+	ActiveSince time.Time
+	// Languages only exports users with one of the language codes
+	Languages []string
+	// Blocked only exports users that did (true) or did not (false) block
+	// the bot, and chats the bot did or did not leave
+	Blocked *bool
+}
+
+var (
+	userExportHeader = []string{"id", "username", "first_name", "last_name", "language_code", "is_premium", "first_seen", "last_seen", "blocked"}
+	chatExportHeader = []string{"id", "type", "title", "username", "first_seen", "last_seen", "left"}
+)
+
+// ExportUsers writes the users of the store matching the filter and returns
+// the number of exported users
+func ExportUsers(store ProfileStore, w io.Writer, format ExportFormat, filter ExportFilter) (int, error) {
+	users, err := store.Users()
+	if err != nil {
+		return 0, fmt.Errorf("list users: %w", err)
+	}
+
+	var rows []UserProfile
+	for _, user := range users {
+		if filter.matchUser(user) {
+			rows = append(rows, user)
+		}
+	}
+
+	return len(rows), writeExport(w, format, userExportHeader, rows, func(u UserProfile) []string {
+		return []string{
+			strconv.FormatInt(u.ID, 10), u.Username, u.FirstName, u.LastName, u.LanguageCode,
+			strconv.FormatBool(u.IsPremium), formatExportTime(u.FirstSeen), formatExportTime(u.LastSeen),
+			strconv.FormatBool(u.Blocked),
+		}
+	})
+}
+
+// ExportChats writes the chats of the store matching the filter and returns
+// the number of exported chats, the language filter does not apply to chats
+func ExportChats(store ProfileStore, w io.Writer, format ExportFormat, filter ExportFilter) (int, error) {
+	chats, err := store.Chats()
+	if err != nil {
+		return 0, fmt.Errorf("list chats: %w", err)
+	}
+
+	var rows []ChatProfile
+	for _, chat := range chats {
+		if filter.matchChat(chat) {
+			rows = append(rows, chat)
+		}
+	}
+
+	return len(rows), writeExport(w, format, chatExportHeader, rows, func(c ChatProfile) []string {
+		return []string{
+			strconv.FormatInt(c.ID, 10), c.Type, c.Title, c.Username,
+			formatExportTime(c.FirstSeen), formatExportTime(c.LastSeen), strconv.FormatBool(c.Left),
+		}
+	})
+}
+
+func (f ExportFilter) matchUser(u UserProfile) bool {
+	if !f.ActiveSince.IsZero() && u.LastSeen.Before(f.ActiveSince) {
+		return false
+	}
+
+	if len(f.Languages) > 0 && !slices.Contains(f.Languages, u.LanguageCode) &&
+		!slices.Contains(f.Languages, baseLanguage(u.LanguageCode)) {
+		return false
+	}
+
+	return f.Blocked == nil || *f.Blocked == u.Blocked
+}
+
+func (f ExportFilter) matchChat(c ChatProfile) bool {
+	if !f.ActiveSince.IsZero() && c.LastSeen.Before(f.ActiveSince) {
+		return false
+	}
+
+	return f.Blocked == nil || *f.Blocked == c.Left
+}
+
+func writeExport[T any](w io.Writer, format ExportFormat, header []string, rows []T, record func(T) []string) error {
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+
+		for _, row := range rows {
+			if err := cw.Write(record(row)); err != nil {
+				return fmt.Errorf("write csv: %w", err)
+			}
+		}
+
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	case ExportNDJSON:
+		enc := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return fmt.Errorf("write ndjson: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	return nil
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,69 @@
+package tgbot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestRecordProfiles(t *testing.T) {
+	store := NewMemoryProfileStore()
+	s := &Service{cfg: &Config{Profiles: store}, logger: slog.Default()}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	user := &models.User{ID: 1, Username: "ann", LanguageCode: "nl"}
+	s.recordProfiles(&models.Update{Message: &models.Message{
+		From: user,
+		Chat: models.Chat{ID: 1, Type: ChatTypePrivate},
+	}}, now)
+
+	s.recordProfiles(&models.Update{MyChatMember: &models.ChatMemberUpdated{
+		Chat:          models.Chat{ID: 1, Type: ChatTypePrivate},
+		From:          *user,
+		NewChatMember: models.ChatMember{Type: models.ChatMemberTypeBanned},
+	}}, now.Add(time.Hour))
+
+	profile, ok, err := store.GetUser(1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, profile.Blocked)
+	require.Equal(t, now, profile.FirstSeen)
+
+	chat, _, _ := store.GetChat(1)
+	require.True(t, chat.Left)
+}
+
+func TestExportUsers(t *testing.T) {
+	store := NewMemoryProfileStore()
+	seen := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.SaveUser(UserProfile{ID: 2, Username: "bob", LanguageCode: "en", LastSeen: seen}))
+	require.NoError(t, store.SaveUser(UserProfile{ID: 1, Username: "ann", LanguageCode: "nl-be", LastSeen: seen, Blocked: true}))
+	require.NoError(t, store.SaveUser(UserProfile{ID: 3, LanguageCode: "nl", LastSeen: seen.Add(-48 * time.Hour)}))
+
+	var buf bytes.Buffer
+	n, err := ExportUsers(store, &buf, ExportCSV, ExportFilter{Languages: []string{"nl"}, ActiveSince: seen.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, strings.Join(userExportHeader, ","), lines[0])
+	require.Equal(t, "1,ann,,,nl-be,false,,2024-05-01T12:00:00Z,true", lines[1])
+
+	buf.Reset()
+	notBlocked := false
+	n, err = ExportUsers(store, &buf, ExportNDJSON, ExportFilter{Blocked: &notBlocked})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	require.Contains(t, buf.String(), `"username":"bob"`)
+
+	_, err = ExportUsers(store, &buf, "xml", ExportFilter{})
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package tgbot
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot/cache"
+)
+
+const (
+	defaultFileCacheTTL       = 24 * time.Hour
+	defaultFileCacheMaxSize   = 5 << 20
+	defaultFileCacheTotalSize = 64 << 20
+	fileCacheSweepInterval    = 10 * time.Minute
+)
+
+// fileCacheKey is the key of a Telegram file, the download URL holds the
+// bot token and is not used
+func fileCacheKey(fileID string) string {
+	return "file:" + fileID
+}
+
+// uniqueFileCacheKey is the key of a file by its unique ID, which stays the
+// same for a file while its file ID may change
+func uniqueFileCacheKey(uniqueID string) string {
+	return "file_unique:" + uniqueID
+}
+
+// cachedFile returns the cached file, failing caches count as a miss
+func (s *Service) cachedFile(ctx context.Context, key string) ([]byte, bool) {
+	if s.fileCache == nil || s.cfg.FileCacheMaxSize < 0 {
+		return nil, false
+	}
+
+	file, ok, err := s.fileCache.Get(ctx, key)
+	if err != nil {
+		s.logger.Warn("failed to read file cache", slog.String("err", err.Error()))
+		return nil, false
+	}
+
+	return file, ok
+}
+
+// cacheFile caches the file unless it is over Config.FileCacheMaxSize
+func (s *Service) cacheFile(ctx context.Context, key string, file []byte) {
+	if s.fileCache == nil || s.cfg.FileCacheMaxSize < 0 || int64(len(file)) > s.cfg.FileCacheMaxSize {
+		return
+	}
+
+	if err := s.fileCache.Set(ctx, key, file, s.cfg.FileCacheTTL); err != nil {
+		s.logger.Warn("failed to write file cache", slog.String("err", err.Error()))
+	}
+}
+
+// sweepFileCache removes the expired files until Close, files that are
+// never requested again would stay in the cache otherwise
+func (s *Service) sweepFileCache(sweeper cache.Sweeper) {
+	ticker := time.NewTicker(fileCacheSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closeCtx.Done():
+			return
+		case <-ticker.C:
+			if err := sweeper.RemoveExpired(s.closeCtx); err != nil && s.closeCtx.Err() == nil {
+				s.logger.Warn("failed to sweep file cache", slog.String("err", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Davincible/tgbot/cache"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestFileCache(t *testing.T) {
+	const token = "123456:test-token"
+
+	var calls atomic.Int32
+	photos := `[[{"file_id":"small","file_unique_id":"u-small","width":100,"height":100},{"file_id":"big","file_unique_id":"u-big","width":640,"height":640}]]`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/bot"+token+"/")
+		if method != "getMe" {
+			calls.Add(1)
+		}
+
+		switch method {
+		case "getMe":
+			fmt.Fprint(w, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"test_bot"}}`)
+		case "getUserProfilePhotos":
+			fmt.Fprintf(w, `{"ok":true,"result":{"total_count":1,"photos":%s}}`, photos)
+		case "getFile":
+			_ = r.ParseMultipartForm(1 << 20)
+			fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"x","file_path":"photos/%s.jpg"}}`, r.FormValue("file_id"))
+		default:
+			_, _ = w.Write([]byte(strings.Repeat("p", 100)))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	files := cache.NewMemory[[]byte]()
+
+	s, err := NewService(logger, &Config{Token: token, SkipGetMe: true, APIServer: &APIServer{URL: srv.URL}, FileCache: files})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	// The first fetch looks up the photo, gets the file and downloads it
+	photo, err := s.GetProfilePhoto(42)
+	require.NoError(t, err)
+	require.Len(t, photo, 100)
+	require.Equal(t, int32(3), calls.Load())
+
+	// The photo is looked up again, the file comes from the cache
+	photo, err = s.GetProfilePhoto(42)
+	require.NoError(t, err)
+	require.Len(t, photo, 100)
+	require.Equal(t, int32(4), calls.Load())
+
+	// Files are keyed by their unique ID and no key holds the bot token
+	require.ElementsMatch(t, []string{"file_unique:u-big"}, files.Keys(""))
+
+	// A changed photo is downloaded right away
+	photos = `[[{"file_id":"new","file_unique_id":"u-new","width":640,"height":640}]]`
+	_, err = s.GetProfilePhoto(42)
+	require.NoError(t, err)
+	require.Equal(t, int32(7), calls.Load())
+
+	// A removed photo shows right away too
+	photos = `[]`
+	_, err = s.GetProfilePhoto(42)
+	require.ErrorIs(t, err, ErrNoProfilePhoto)
+	_, err = s.GetProfilePhoto(42)
+	require.ErrorIs(t, err, ErrNoProfilePhoto)
+	require.Equal(t, int32(9), calls.Load())
+
+	// Files over the maximum size are not cached
+	s.cfg.FileCacheMaxSize = 10
+	_, err = s.DownloadFile("other")
+	require.NoError(t, err)
+	_, err = s.DownloadFile("other")
+	require.NoError(t, err)
+	require.Equal(t, int32(13), calls.Load())
+}
+
+func TestDefaultFileCacheLimit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	s, err := NewService(logger, &Config{DryRun: NewRecorder(), FileCacheTotalSize: 250})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c"} {
+		s.cacheFile(ctx, fileCacheKey(id), []byte(strings.Repeat("x", 100)))
+	}
+
+	_, ok := s.cachedFile(ctx, fileCacheKey("a"))
+	require.False(t, ok)
+
+	_, ok = s.cachedFile(ctx, fileCacheKey("c"))
+	require.True(t, ok)
+
+	require.Implements(t, (*cache.Sweeper)(nil), s.fileCache)
+}
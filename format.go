@@ -1,117 +1,277 @@
 package tgbot
 
 import (
-	"bytes"
-	"crypto/md5"
-	"encoding/hex"
-	"regexp"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
+)
+
+const (
+	escapeChars           = "_*[]()~>#+-=|{}.!"
+	escapeCharsFormatting = "()~>#+-=|{}.!"
 
-	"github.com/google/uuid"
+	// specialCharPairs are the characters that need to be balanced, a
+	// trailing unpaired occurrence is escaped
+	specialCharPairs = "*_~|[]()`"
 )
 
-var (
-	escapeChars           = regexp.MustCompile(`([_\*\[\]\(\)~>#\+\-=|{}\.!])`)
-	escapeCharsFormatting = regexp.MustCompile(`([\(\)~>#\+\-=|{}\.!])`)
-	smallCodeBlocks       = regexp.MustCompile("`[^`]*`")
-	urlMention            = regexp.MustCompile(`\[[^\]]*\]\([^\)]*\)`) // Regex to identify URL mentions
+// span marks a [start, end) range of a line that must not be escaped
+type span struct {
+	start, end int
+}
 
-	specialCharPairs = []rune{'*', '_', '~', '|', '[', ']', '(', ')', '`'}
+// ParseMode is how Telegram parses the text of a message
+type ParseMode int
+
+const (
+	// ParseModeMarkdown parses the text as MarkdownV2, the default
+	ParseModeMarkdown ParseMode = iota
+	// ParseModeHTML parses the text as HTML, see EscapeHTML
+	ParseModeHTML
+	// ParseModeNone sends the text as it is, formatting only applies
+	// through entities
+	ParseModeNone
+	// ParseModeCommonMark converts standard Markdown to entities before
+	// sending, see ParseMarkdown. Nothing has to be escaped.
+	ParseModeCommonMark
 )
 
+var parseModeNames = []string{"markdown", "html", "none", "commonmark"}
+
+// MarshalJSON encodes the parse mode as its name
+func (p ParseMode) MarshalJSON() ([]byte, error) {
+	if p < 0 || int(p) >= len(parseModeNames) {
+		return nil, fmt.Errorf("unknown parse mode %d", p)
+	}
+
+	return json.Marshal(parseModeNames[p])
+}
+
+// UnmarshalJSON decodes the parse mode from its name, case-insensitively,
+// or its number
+func (p *ParseMode) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("parse mode must be a name or number: %s", data)
+		}
+
+		name = strconv.Itoa(n)
+	}
+
+	for i, known := range parseModeNames {
+		if strings.EqualFold(name, known) || name == strconv.Itoa(i) {
+			*p = ParseMode(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown parse mode %q", name)
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeHTML escapes the characters Telegram requires to be escaped in HTML
+// texts, use it for values inserted into HTML templates.
+func EscapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
 // EscapeMarkdown escapes markdown characters for Telegram.
 func EscapeMarkdown(text string, allowFormatting ...bool) string {
-	var buf bytes.Buffer
-
-	inCodeBlock := false
-	lines := strings.Split(text, "\n")
+	var buf strings.Builder
+	buf.Grow(len(text) + len(text)/8)
 
 	escapeSet := escapeChars
 	if len(allowFormatting) > 0 && allowFormatting[0] {
-		// return text
-
 		escapeSet = escapeCharsFormatting
 	}
 
-	for _, line := range lines {
-		if strings.Contains(line, "```") {
+	inCodeBlock := false
+	for rest, more := text, true; more; {
+		var line string
+		line, rest, more = strings.Cut(rest, "\n")
+
+		switch {
+		case strings.Contains(line, "```"):
 			inCodeBlock = !inCodeBlock
-		} else if !inCodeBlock {
-			matches := smallCodeBlocks.FindAllString(line, -1)
-
-			orig := map[string]string{}
-			for _, match := range matches {
-				placeholder := md5Hash(uuid.NewString())
-				line = strings.Replace(line, match, placeholder, 1)
-				orig[placeholder] = match
-			}
+			buf.WriteString(line)
+		case inCodeBlock:
+			buf.WriteString(line)
+		default:
+			escapeLine(&buf, line, escapeSet)
+		}
 
-			// Replace URL mentions temporarily
-			urlMatches := urlMention.FindAllString(line, -1)
-			urlPlaceholders := make(map[string]string)
-			for _, match := range urlMatches {
-				placeholder := md5Hash(uuid.NewString())
-				line = strings.Replace(line, match, placeholder, 1)
-				urlPlaceholders[placeholder] = match
-			}
+		buf.WriteByte('\n')
+	}
 
-			// Escape all special characters outside of the small code block and URL mentions
-			line = escapeSet.ReplaceAllString(line, `\$1`)
-			line = escapeSingularSpecialChars(line, specialCharPairs)
+	return strings.TrimSpace(buf.String())
+}
 
-			// Restore the URL mentions and code blocks
-			for ori, match := range orig {
-				line = strings.Replace(line, ori, match, 1)
-			}
-			for ori, match := range urlPlaceholders {
-				line = strings.Replace(line, ori, match, 1)
-			}
+// escapeLine writes the escaped line to buf. Inline code and URL mentions
+// are copied as is, all characters of the escape set are escaped, and of
+// every special character that appears an odd number of times the last
+// unescaped occurrence is escaped.
+func escapeLine(buf *strings.Builder, line, escapeSet string) {
+	protected := protectedSpans(line)
+
+	// First pass, find the last unescaped occurrence of unbalanced special chars
+	var (
+		counts [len(specialCharPairs)]int
+		last   [len(specialCharPairs)]int
+	)
+
+	var prev byte
+	walkLine(line, protected, func(i int, c byte, spanEnd int) {
+		if spanEnd > 0 {
+			prev = 0
+			return
+		}
+
+		if strings.IndexByte(escapeSet, c) >= 0 {
+			prev = c
+			return
+		}
+
+		if idx := strings.IndexByte(specialCharPairs, c); idx >= 0 && prev != '\\' {
+			counts[idx]++
+			last[idx] = i
 		}
 
-		buf.WriteString(line)
-		buf.WriteString("\n")
+		prev = c
+	})
+
+	for idx, count := range counts {
+		if count%2 == 0 {
+			last[idx] = -1
+		}
 	}
 
-	return strings.TrimSpace(buf.String())
+	// Second pass, write the escaped line
+	walkLine(line, protected, func(i int, c byte, spanEnd int) {
+		if spanEnd > 0 {
+			buf.WriteString(line[i:spanEnd])
+			return
+		}
+
+		if strings.IndexByte(escapeSet, c) >= 0 {
+			buf.WriteByte('\\')
+		} else if idx := strings.IndexByte(specialCharPairs, c); idx >= 0 && last[idx] == i {
+			buf.WriteByte('\\')
+		}
+
+		buf.WriteByte(c)
+	})
+}
+
+// walkLine calls fn for every byte outside the protected spans, and once
+// at the start of every protected span with the end of the span.
+func walkLine(line string, protected []span, fn func(i int, c byte, spanEnd int)) {
+	next := 0
+	for i := 0; i < len(line); i++ {
+		if next < len(protected) && protected[next].start == i {
+			fn(i, 0, protected[next].end)
+			i = protected[next].end - 1
+			next++
+			continue
+		}
+
+		fn(i, line[i], 0)
+	}
 }
 
-func md5Hash(str string) string {
-	hash := md5.Sum([]byte(str))
-	return hex.EncodeToString(hash[:])
+// protectedSpans returns the inline code blocks and URL mentions of the line,
+// sorted and non-overlapping.
+func protectedSpans(line string) []span {
+	code := codeSpans(line)
+	links := linkSpans(line, code)
+
+	if len(links) == 0 {
+		return code
+	}
+
+	// Code blocks inside a URL mention are part of the mention
+	spans := make([]span, 0, len(code)+len(links))
+	c := 0
+	for _, link := range links {
+		for c < len(code) && code[c].start < link.start {
+			spans = append(spans, code[c])
+			c++
+		}
+		for c < len(code) && code[c].end <= link.end {
+			c++
+		}
+		spans = append(spans, link)
+	}
+
+	return append(spans, code[c:]...)
 }
 
-func escapeSingularSpecialChars(input string, specialChars []rune) string {
-	lines := strings.Split(input, "\n")
-	escapedLines := make([]string, len(lines))
-
-	for i, line := range lines {
-		escapedLine := line
-		for _, char := range specialChars {
-			// Escape the special character for use in the regular expression
-			escChar := regexp.QuoteMeta(string(char))
-
-			// Regular expression to find the special character not preceded by '\'
-			re := regexp.MustCompile(`(?m)([^\\]|^)` + escChar)
-
-			// Find all matches for the current character
-			matches := re.FindAllStringIndex(escapedLine, -1)
-
-			// Determine action based on count of matches
-			count := len(matches)
-			if count%2 != 0 {
-				// Escape the last unescaped occurrence of the character
-				lastMatch := matches[count-1]
-				if lastMatch[0] == 0 {
-					// If the match is at the start of the line, add a backslash at the start
-					escapedLine = "\\" + escapedLine
-				} else {
-					escapedLine = escapedLine[:lastMatch[0]+1] + "\\" + escapedLine[lastMatch[0]+1:]
-				}
+// codeSpans finds the `inline code` blocks of a line
+func codeSpans(line string) []span {
+	var spans []span
+
+	for i := 0; i < len(line); i++ {
+		if line[i] != '`' {
+			continue
+		}
+
+		end := strings.IndexByte(line[i+1:], '`')
+		if end < 0 {
+			break
+		}
+
+		end += i + 2
+		spans = append(spans, span{start: i, end: end})
+		i = end - 1
+	}
+
+	return spans
+}
+
+// linkSpans finds the [text](url) mentions of a line, code spans are opaque
+// and can be part of a mention but can't start or end one.
+func linkSpans(line string, code []span) []span {
+	var spans []span
+
+	// indexSkip returns the index of c at or after from, outside of code spans
+	indexSkip := func(c byte, from int) int {
+		next := 0
+		for i := from; i < len(line); i++ {
+			for next < len(code) && code[next].end <= i {
+				next++
+			}
+			if next < len(code) && code[next].start <= i {
+				i = code[next].end - 1
+				continue
+			}
+			if line[i] == c {
+				return i
 			}
-			// No change for even counts
 		}
-		escapedLines[i] = escapedLine
+		return -1
+	}
+
+	for i := indexSkip('[', 0); i >= 0; i = indexSkip('[', i+1) {
+		closing := indexSkip(']', i+1)
+		if closing < 0 {
+			break
+		}
+
+		if closing+1 >= len(line) || line[closing+1] != '(' {
+			continue
+		}
+
+		end := indexSkip(')', closing+2)
+		if end < 0 {
+			continue
+		}
+
+		spans = append(spans, span{start: i, end: end + 1})
+		i = end
 	}
 
-	return strings.Join(escapedLines, "\n")
+	return spans
 }
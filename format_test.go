@@ -0,0 +1,95 @@
+package tgbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		formatting bool
+		want       string
+	}{
+		{"plain", "Hello world!", false, `Hello world\!`},
+		{"inline code", "use `a_b*c` here.", false, "use `a_b*c` here\\."},
+		{"url mention", "see [the docs](https://x.com/a_b) now.", false, `see [the docs](https://x.com/a_b) now\.`},
+		{"code in url mention", "[`x`](u)", false, "[`x`](u)"},
+		{"escape formatting", "*bold* and _it_", false, `\*bold\* and \_it\_`},
+		{"keep formatting", "*bold* and _it_", true, "*bold* and _it_"},
+		{"code block", "```\ncode_here *\n```\nafter.", false, "```\ncode_here *\n```\nafter\\."},
+		{"unpaired star", "price 5*3 = 15", true, `price 5\*3 \= 15`},
+		{"unpaired star after char", "a*", true, `a\*`},
+		{"paired stars", "**", true, "**"},
+		{"last unpaired star", "*a* b*", true, `*a* b\*`},
+		{"unterminated code", "tricky `unterminated", true, "tricky \\`unterminated"},
+		{"unterminated mention", "[a](b) [c](d", false, `[a](b) \[c\]\(d`},
+		{"already escaped", `a\*`, true, `a\*`},
+		{"multi line", "1. item\n2. item", false, "1\\. item\n2\\. item"},
+		{"trim", "  hi.\n\n", false, `hi\.`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, EscapeMarkdown(tt.in, tt.formatting))
+		})
+	}
+}
+
+var benchMarkdown = "Hello *world*, check [the docs](https://example.com/a_b) and run `go test ./...`.\n" +
+	"Prices: 5-10 (approx.) | done!\n```\nfunc main() {}\n```\nBye_bye."
+
+func BenchmarkEscapeMarkdown(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		EscapeMarkdown(benchMarkdown)
+	}
+}
+
+func BenchmarkEscapeMarkdownFormatting(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		EscapeMarkdown(benchMarkdown, true)
+	}
+}
+
+func BenchmarkEscapeMarkdownLong(b *testing.B) {
+	text := strings.Repeat(benchMarkdown+"\n", 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		EscapeMarkdown(text, true)
+	}
+}
+
+func TestEscapeHTML(t *testing.T) {
+	require.Equal(t, "a &lt;b&gt; &amp; *c*", EscapeHTML("a <b> & *c*"))
+}
+
+func TestMessageParseMode(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		mode models.ParseMode
+		text string
+	}{
+		{"markdown", Message{Text: "a.b"}, models.ParseModeMarkdown, `a\.b`},
+		{"html", Message{Text: "<b>x</b>.", ParseMode: ParseModeHTML}, models.ParseModeHTML, "&lt;b&gt;x&lt;/b&gt;."},
+		{"html formatting", Message{Text: "<b>x</b>.", ParseMode: ParseModeHTML, TextFormatting: true}, models.ParseModeHTML, "<b>x</b>."},
+		{"none", Message{Text: "*a* <b>", ParseMode: ParseModeNone}, "", "*a* <b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.mode, tt.msg.parseMode())
+			require.Equal(t, tt.text, tt.msg.escapeText())
+		})
+	}
+}
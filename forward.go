@@ -0,0 +1,236 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// maxBulkMessages is the number of messages ForwardMessages and CopyMessages
+// take per request
+const maxBulkMessages = 100
+
+var ErrNoMessages = errors.New("no messages to forward or copy")
+
+// Relayer is implemented by senders that can forward and copy messages.
+// Relay and mirror bots can type assert their Sender to it.
+type Relayer interface {
+	Forward(fromChat, toChat int64, msgID int) (*models.Message, error)
+	Copy(fromChat, toChat int64, msgID int) (int, error)
+	ForwardMessages(ctx context.Context, fromChat, toChat int64, msgIDs []int, opts ForwardOptions) ([]int, error)
+	CopyMessages(ctx context.Context, fromChat, toChat int64, msgIDs []int, opts ForwardOptions) ([]int, error)
+}
+
+var _ Relayer = (*Service)(nil)
+
+// ForwardOptions holds the options of forwarded and copied messages
+type ForwardOptions struct {
+	// ThreadID is the forum topic in the target chat
+	ThreadID int
+	// Silent sends the messages without notification
+	Silent bool
+	// Protect prevents the messages from being forwarded and saved
+	Protect bool
+	// RemoveCaption drops the captions of media copied with CopyMessages
+	RemoveCaption bool
+}
+
+// Forward forwards a message, the copy shows the original sender
+func (s *Service) Forward(fromChat, toChat int64, msgID int) (*models.Message, error) {
+	return s.ForwardContext(context.Background(), fromChat, toChat, msgID, ForwardOptions{})
+}
+
+// ForwardContext is Forward with a context and options, the message is sent
+// through the send pipeline of the target chat
+func (s *Service) ForwardContext(ctx context.Context, fromChat, toChat int64, msgID int, opts ForwardOptions) (*models.Message, error) {
+	return s.relay(ctx, toChat, func(ctx context.Context) (*models.Message, error) {
+		msg, err := s.bot.ForwardMessage(ctx, &bot.ForwardMessageParams{
+			ChatID:              toChat,
+			MessageThreadID:     opts.ThreadID,
+			FromChatID:          strconv.FormatInt(fromChat, 10),
+			MessageID:           msgID,
+			DisableNotification: opts.Silent,
+			ProtectContent:      opts.Protect,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("forward message: %w", err)
+		}
+
+		return msg, nil
+	})
+}
+
+// Copy copies a message without a link to the original and returns the ID of
+// the copy
+func (s *Service) Copy(fromChat, toChat int64, msgID int) (int, error) {
+	return s.CopyContext(context.Background(), fromChat, toChat, msgID, ForwardOptions{})
+}
+
+// CopyContext is Copy with a context and options
+func (s *Service) CopyContext(ctx context.Context, fromChat, toChat int64, msgID int, opts ForwardOptions) (int, error) {
+	msg, err := s.relay(ctx, toChat, func(ctx context.Context) (*models.Message, error) {
+		id, err := s.bot.CopyMessage(ctx, &bot.CopyMessageParams{
+			ChatID:              toChat,
+			MessageThreadID:     opts.ThreadID,
+			FromChatID:          strconv.FormatInt(fromChat, 10),
+			MessageID:           msgID,
+			DisableNotification: opts.Silent,
+			ProtectContent:      opts.Protect,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("copy message: %w", err)
+		}
+
+		return copiedMessage(toChat, id.ID), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return msg.ID, nil
+}
+
+// ForwardMessages forwards the messages in order of their IDs and returns the
+// IDs of the forwarded messages. Albums stay grouped, messages that can't be
+// forwarded are skipped. Batches of 100 messages are sent per request.
+func (s *Service) ForwardMessages(ctx context.Context, fromChat, toChat int64, msgIDs []int, opts ForwardOptions) ([]int, error) {
+	return s.relayBulk(ctx, toChat, msgIDs, func(ctx context.Context, batch []int) ([]models.MessageID, error) {
+		ids, err := s.bot.ForwardMessages(ctx, &bot.ForwardMessagesParams{
+			ChatID:              toChat,
+			MessageThreadID:     opts.ThreadID,
+			FromChatID:          strconv.FormatInt(fromChat, 10),
+			MessageIDs:          batch,
+			DisableNotification: opts.Silent,
+			ProtectContent:      opts.Protect,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("forward messages: %w", err)
+		}
+
+		return ids, nil
+	})
+}
+
+// CopyMessages copies the messages in order of their IDs and returns the IDs
+// of the copies, like ForwardMessages
+func (s *Service) CopyMessages(ctx context.Context, fromChat, toChat int64, msgIDs []int, opts ForwardOptions) ([]int, error) {
+	return s.relayBulk(ctx, toChat, msgIDs, func(ctx context.Context, batch []int) ([]models.MessageID, error) {
+		ids, err := s.bot.CopyMessages(ctx, &bot.CopyMessagesParams{
+			ChatID:              toChat,
+			MessageThreadID:     opts.ThreadID,
+			FromChatID:          strconv.FormatInt(fromChat, 10),
+			MessageIDs:          batch,
+			DisableNotification: opts.Silent,
+			ProtectContent:      opts.Protect,
+			RemoveCaption:       opts.RemoveCaption,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("copy messages: %w", err)
+		}
+
+		return ids, nil
+	})
+}
+
+// relay runs the request on the send pipeline of the target chat, so relayed
+// messages stay in order with the messages sent with Send
+func (s *Service) relay(ctx context.Context, toChat int64, run func(ctx context.Context) (*models.Message, error)) (*models.Message, error) {
+	result := s.pipeline.enqueue(toChat, func() (*models.Message, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		msg, err := s.retry(ctx, toChat, func() (*models.Message, error) {
+			s.ratelimit.take(toChat)
+
+			ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+			defer cancel()
+
+			return run(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s.tracker.track(toChat, msg)
+
+		return msg, nil
+	})
+
+	select {
+	case res := <-result:
+		return res.Message, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Service) relayBulk(ctx context.Context, toChat int64, msgIDs []int, run func(ctx context.Context, batch []int) ([]models.MessageID, error)) ([]int, error) {
+	batches := messageBatches(msgIDs)
+	if len(batches) == 0 {
+		return nil, ErrNoMessages
+	}
+
+	var sent []int
+	for _, batch := range batches {
+		// The IDs are only read once the job has finished, a job abandoned
+		// on cancellation may still write them
+		var batchIDs []int
+
+		_, err := s.relay(ctx, toChat, func(ctx context.Context) (*models.Message, error) {
+			ids, err := run(ctx, batch)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, id := range ids {
+				batchIDs = append(batchIDs, id.ID)
+				s.tracker.track(toChat, copiedMessage(toChat, id.ID))
+			}
+
+			return nil, nil
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		sent = append(sent, batchIDs...)
+	}
+
+	return sent, nil
+}
+
+// messageBatches sorts and deduplicates the IDs, which Telegram requires to
+// be strictly increasing, and splits them in batches of 100
+func messageBatches(msgIDs []int) [][]int {
+	ids := slices.Clone(msgIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var batches [][]int
+	for len(ids) > maxBulkMessages {
+		batches = append(batches, ids[:maxBulkMessages])
+		ids = ids[maxBulkMessages:]
+	}
+
+	if len(ids) > 0 {
+		batches = append(batches, ids)
+	}
+
+	return batches
+}
+
+// copiedMessage stands in for copies, Telegram only returns their ID
+func copiedMessage(chatID int64, id int) *models.Message {
+	return &models.Message{
+		ID:   id,
+		Chat: models.Chat{ID: chatID},
+		Date: int(time.Now().Unix()),
+	}
+}
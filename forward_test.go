@@ -0,0 +1,26 @@
+package tgbot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBatches(t *testing.T) {
+	require.Empty(t, messageBatches(nil))
+
+	batches := messageBatches([]int{5, 3, 3, 1})
+	require.Equal(t, [][]int{{1, 3, 5}}, batches)
+
+	ids := make([]int, 250)
+	for i := range ids {
+		ids[i] = len(ids) - i
+	}
+
+	batches = messageBatches(ids)
+	require.Len(t, batches, 3)
+	require.Len(t, batches[0], 100)
+	require.Len(t, batches[2], 50)
+	require.Equal(t, 1, batches[0][0])
+	require.Equal(t, 250, batches[2][49])
+}
@@ -0,0 +1,234 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// maxGiftText is the maximum length of the text sent with a gift
+const maxGiftText = 128
+
+var (
+	ErrGiftText       = fmt.Errorf("gift text exceeds %d characters", maxGiftText)
+	ErrPremiumMonths  = errors.New("premium can be gifted for 3, 6 or 12 months")
+	ErrGiftRecipients = errors.New("gift needs either a user or a chat")
+)
+
+// premiumStarCost is the price in stars of gifting premium, per month count
+var premiumStarCost = map[int]int{3: 1000, 6: 1500, 12: 2500}
+
+// Gift is a gift the bot can send, paid from its Telegram Stars balance
+type Gift struct {
+	ID      string          `json:"id"`
+	Sticker *models.Sticker `json:"sticker"`
+	// StarCount is the price of the gift
+	StarCount int `json:"star_count"`
+	// UpgradeStarCount is the price of upgrading it to a unique gift
+	UpgradeStarCount int `json:"upgrade_star_count,omitempty"`
+	// TotalCount and RemainingCount are set for limited gifts
+	TotalCount     int `json:"total_count,omitempty"`
+	RemainingCount int `json:"remaining_count,omitempty"`
+}
+
+// GiftConfig describes a gift to send, to either a user or a channel chat
+type GiftConfig struct {
+	GiftID string
+	UserID int64
+	ChatID int64
+	// Text is shown with the gift, up to 128 characters
+	Text string
+	// PayForUpgrade pays the upgrade to a unique gift for the receiver
+	PayForUpgrade bool
+}
+
+// GiveawayEvent is a giveaway message, one of the fields is set. Messages
+// about received gifts are not decoded by the bot library and can't be
+// handled yet.
+type GiveawayEvent struct {
+	ChatID    int64
+	MessageID int
+	Created   *models.GiveawayCreated
+	Giveaway  *models.Giveaway
+	Winners   *models.GiveawayWinners
+	Completed *models.GiveawayCompleted
+}
+
+// GiveawayHandler receives giveaway service messages
+type GiveawayHandler func(ctx context.Context, event *GiveawayEvent)
+
+// AvailableGifts returns the gifts the bot can send
+func (s *Service) AvailableGifts(ctx context.Context) ([]Gift, error) {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var result struct {
+		Gifts []Gift `json:"gifts"`
+	}
+
+	if err := s.apiRequest(ctx, "getAvailableGifts", nil, nil, &result); err != nil {
+		return nil, fmt.Errorf("get available gifts: %w", err)
+	}
+
+	return result.Gifts, nil
+}
+
+// SendGift sends a gift, paid from the Telegram Stars balance of the bot
+func (s *Service) SendGift(ctx context.Context, gift GiftConfig) error {
+	fields, err := gift.fields()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var ok bool
+	if err := s.apiRequest(ctx, "sendGift", fields, nil, &ok); err != nil {
+		return fmt.Errorf("send gift: %w", err)
+	}
+
+	return nil
+}
+
+// GiftPremium gifts a Telegram Premium subscription of 3, 6 or 12 months to
+// the user, paid from the Telegram Stars balance of the bot
+func (s *Service) GiftPremium(ctx context.Context, userID int64, months int, text string) error {
+	stars, ok := premiumStarCost[months]
+	if !ok {
+		return ErrPremiumMonths
+	}
+
+	if utf8.RuneCountInString(text) > maxGiftText {
+		return ErrGiftText
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	fields := map[string]string{
+		"user_id":     strconv.FormatInt(userID, 10),
+		"month_count": strconv.Itoa(months),
+		"star_count":  strconv.Itoa(stars),
+	}
+	if text != "" {
+		fields["text"] = text
+	}
+
+	var result bool
+	if err := s.apiRequest(ctx, "giftPremiumSubscription", fields, nil, &result); err != nil {
+		return fmt.Errorf("gift premium subscription: %w", err)
+	}
+
+	return nil
+}
+
+// OnGiveaway registers a handler for giveaway service messages and returns a
+// function that removes it
+func (s *Service) OnGiveaway(handler GiveawayHandler) func() {
+	s.giveaways.mu.Lock()
+	defer s.giveaways.mu.Unlock()
+
+	if s.giveaways.handlers == nil {
+		s.giveaways.handlers = make(map[int]GiveawayHandler)
+	}
+
+	id := s.giveaways.nextID
+	s.giveaways.nextID++
+	s.giveaways.handlers[id] = handler
+
+	return func() {
+		s.giveaways.mu.Lock()
+		defer s.giveaways.mu.Unlock()
+
+		delete(s.giveaways.handlers, id)
+	}
+}
+
+// giveawayMiddleware hands giveaway messages to the giveaway handlers, the
+// update is still passed on to the bot
+func (s *Service) giveawayMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			msg := update.Message
+			if msg == nil {
+				msg = update.ChannelPost
+			}
+
+			if event := newGiveawayEvent(msg); event != nil {
+				s.giveaways.mu.RLock()
+				handlers := make([]GiveawayHandler, 0, len(s.giveaways.handlers))
+				for _, handler := range s.giveaways.handlers {
+					handlers = append(handlers, handler)
+				}
+				s.giveaways.mu.RUnlock()
+
+				for _, handler := range handlers {
+					handler(ctx, event)
+				}
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func newGiveawayEvent(msg *models.Message) *GiveawayEvent {
+	if msg == nil {
+		return nil
+	}
+
+	if msg.GiveawayCreated == nil && msg.Giveaway == nil && msg.GiveawayWinners == nil && msg.GiveawayCompleted == nil {
+		return nil
+	}
+
+	return &GiveawayEvent{
+		ChatID:    msg.Chat.ID,
+		MessageID: msg.ID,
+		Created:   msg.GiveawayCreated,
+		Giveaway:  msg.Giveaway,
+		Winners:   msg.GiveawayWinners,
+		Completed: msg.GiveawayCompleted,
+	}
+}
+
+func (g GiftConfig) fields() (map[string]string, error) {
+	if (g.UserID == 0) == (g.ChatID == 0) {
+		return nil, ErrGiftRecipients
+	}
+
+	if utf8.RuneCountInString(g.Text) > maxGiftText {
+		return nil, ErrGiftText
+	}
+
+	fields := map[string]string{"gift_id": g.GiftID}
+
+	if g.UserID != 0 {
+		fields["user_id"] = strconv.FormatInt(g.UserID, 10)
+	} else {
+		fields["chat_id"] = strconv.FormatInt(g.ChatID, 10)
+	}
+
+	if g.Text != "" {
+		fields["text"] = g.Text
+	}
+
+	if g.PayForUpgrade {
+		fields["pay_for_upgrade"] = "true"
+	}
+
+	return fields, nil
+}
+
+type giveawaySubscriptions struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]GiveawayHandler
+}
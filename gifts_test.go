@@ -0,0 +1,65 @@
+package tgbot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGiftConfigFields(t *testing.T) {
+	_, err := GiftConfig{GiftID: "1"}.fields()
+	require.ErrorIs(t, err, ErrGiftRecipients)
+
+	_, err = GiftConfig{GiftID: "1", UserID: 1, ChatID: 2}.fields()
+	require.ErrorIs(t, err, ErrGiftRecipients)
+
+	_, err = GiftConfig{GiftID: "1", UserID: 1, Text: strings.Repeat("a", 129)}.fields()
+	require.ErrorIs(t, err, ErrGiftText)
+
+	fields, err := GiftConfig{GiftID: "1", ChatID: -100, Text: "Thanks!", PayForUpgrade: true}.fields()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"gift_id":         "1",
+		"chat_id":         "-100",
+		"text":            "Thanks!",
+		"pay_for_upgrade": "true",
+	}, fields)
+}
+
+func TestGiftPremiumMonths(t *testing.T) {
+	s := &Service{}
+	require.ErrorIs(t, s.GiftPremium(context.Background(), 1, 2, ""), ErrPremiumMonths)
+}
+
+func TestGiveawayMiddleware(t *testing.T) {
+	s := &Service{}
+
+	var events []*GiveawayEvent
+	remove := s.OnGiveaway(func(ctx context.Context, event *GiveawayEvent) {
+		events = append(events, event)
+	})
+
+	var passed int
+	handler := s.giveawayMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		passed++
+	})
+
+	handler(context.Background(), nil, &models.Update{ChannelPost: &models.Message{
+		ID:              5,
+		Chat:            models.Chat{ID: -100},
+		GiveawayWinners: &models.GiveawayWinners{WinnerCount: 3},
+	}})
+	handler(context.Background(), nil, &models.Update{Message: &models.Message{Text: "hi"}})
+
+	require.Len(t, events, 1)
+	require.Equal(t, 3, events[0].Winners.WinnerCount)
+	require.Equal(t, 2, passed)
+
+	remove()
+	handler(context.Background(), nil, &models.Update{Message: &models.Message{GiveawayCreated: &models.GiveawayCreated{}}})
+	require.Len(t, events, 1)
+}
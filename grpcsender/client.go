@@ -0,0 +1,62 @@
+package grpcsender
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/Davincible/tgbot"
+)
+
+// Client calls the service of a Server, for Go services sharing a bot
+// process
+type Client struct {
+	conn  grpc.ClientConnInterface
+	token string
+}
+
+// NewClient creates a client on the connection, token is the Config.Token
+// of the server
+func NewClient(conn grpc.ClientConnInterface, token string) *Client {
+	return &Client{conn: conn, token: token}
+}
+
+// Send sends the message and returns its ID
+func (c *Client) Send(ctx context.Context, chatID int64, msg tgbot.Message) (int, error) {
+	var resp MessageResponse
+	if err := c.invoke(ctx, "Send", &SendRequest{ChatID: chatID, Message: msg}, &resp); err != nil {
+		return 0, err
+	}
+
+	return resp.MessageID, nil
+}
+
+// Edit replaces the message
+func (c *Client) Edit(ctx context.Context, chatID int64, msgID int, msg tgbot.Message) error {
+	return c.invoke(ctx, "Edit", &EditRequest{ChatID: chatID, MessageID: msgID, Message: msg}, &MessageResponse{})
+}
+
+// Delete deletes the message
+func (c *Client) Delete(ctx context.Context, chatID int64, msgID int) error {
+	return c.invoke(ctx, "Delete", &DeleteRequest{ChatID: chatID, MessageID: msgID}, &DeleteResponse{})
+}
+
+// Broadcast sends the message to all chats of the request, the call returns
+// when the broadcast is done
+func (c *Client) Broadcast(ctx context.Context, req *BroadcastRequest) (*BroadcastResponse, error) {
+	var resp BroadcastResponse
+	if err := c.invoke(ctx, "Broadcast", req, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp any) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+
+	// The codec is forced rather than looked up, so clients don't depend on
+	// RegisterCodec
+	return c.conn.Invoke(ctx, "/"+serviceName+"/"+method, req, resp, grpc.ForceCodec(jsonCodec{}))
+}
@@ -0,0 +1,324 @@
+// Package grpcsender exposes Send, Edit, Delete and Broadcast of a running
+// tgbot.Service over gRPC, so services in other languages share one bot
+// process with its rate limiting and retries.
+//
+// The service is tgbot.Sender with the unary methods Send, Edit, Delete and
+// Broadcast. Messages are not protobuf but JSON, with the content subtype
+// "tgbot-json" (content-type application/grpc+tgbot-json) and the schema of
+// the JSON tags of tgbot.Message and the request types of this package.
+// Clients in other languages send the JSON bytes with a pass-through codec
+// of that name. Clients authenticate with the metadata
+// "authorization: Bearer <token>".
+//
+// The server decodes the calls with the codec registered by RegisterCodec,
+// call it once at startup before serving.
+package grpcsender
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Davincible/tgbot"
+	"github.com/Davincible/tgbot/cache"
+)
+
+const (
+	serviceName = "tgbot.Sender"
+	minToken    = 16
+
+	// CodecName is the content subtype of the calls, it is distinct from the
+	// generic "json" so other JSON codecs of the process are left alone
+	CodecName = "tgbot-json"
+)
+
+// RegisterCodec registers the JSON codec of the service with gRPC under
+// CodecName. Like encoding.RegisterCodec it is not safe for concurrent use,
+// call it during initialization before serving. Clients of this package
+// don't need it.
+func RegisterCodec() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec encodes the messages as JSON, registered under its own content
+// subtype it leaves protobuf services on the same server alone
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+// Sender is the part of tgbot.Service the server calls
+type Sender interface {
+	SendContext(ctx context.Context, chatID int64, msg tgbot.Message) (*models.Message, error)
+	EditMessageContext(ctx context.Context, chatID int64, msgID int, msg tgbot.Message) (*models.Message, error)
+	DeleteMessageContext(ctx context.Context, chatID int64, msgID int) error
+	BroadcastContext(ctx context.Context, chatIDs []int64, msg tgbot.Message, opts tgbot.BroadcastOptions) (*tgbot.BroadcastReport, error)
+}
+
+var _ Sender = (*tgbot.Service)(nil)
+
+type SendRequest struct {
+	ChatID  int64         `json:"chatId"`
+	Message tgbot.Message `json:"message"`
+}
+
+type EditRequest struct {
+	ChatID    int64         `json:"chatId"`
+	MessageID int           `json:"messageId"`
+	Message   tgbot.Message `json:"message"`
+}
+
+type DeleteRequest struct {
+	ChatID    int64 `json:"chatId"`
+	MessageID int   `json:"messageId"`
+}
+
+// BroadcastRequest sends the message to all chats. A broadcast with an ID
+// resumes where an earlier one stopped when the server has a
+// BroadcastStore.
+type BroadcastRequest struct {
+	ChatIDs     []int64       `json:"chatIds"`
+	Message     tgbot.Message `json:"message"`
+	ID          string        `json:"id,omitempty"`
+	Concurrency int           `json:"concurrency,omitempty"`
+}
+
+// MessageResponse is the answer of Send and Edit
+type MessageResponse struct {
+	MessageID int `json:"messageId"`
+}
+
+type DeleteResponse struct{}
+
+// BroadcastResponse is the outcome of a broadcast per recipient
+type BroadcastResponse struct {
+	Total   int                           `json:"total"`
+	Counts  map[tgbot.RecipientStatus]int `json:"counts"`
+	Resumed int                           `json:"resumed,omitempty"`
+	Results []tgbot.RecipientResult       `json:"results"`
+}
+
+// Config configures the server
+type Config struct {
+	// Token authenticates clients, at least 16 characters
+	Token string
+	// BroadcastStore records the outcome of broadcasts with an ID so they
+	// resume after a restart, broadcasts are not resumable without it
+	BroadcastStore cache.Cache[tgbot.RecipientResult]
+}
+
+// Server implements the tgbot.Sender gRPC service
+type Server struct {
+	sender Sender
+	cfg    Config
+}
+
+// NewServer creates the service, register it on a gRPC server with
+// Register. RegisterCodec must have been called. The default gRPC message limit of 4 MB applies to uploads, raise
+// it with grpc.MaxRecvMsgSize for larger media.
+func NewServer(sender Sender, cfg Config) (*Server, error) {
+	if len(cfg.Token) < minToken {
+		return nil, fmt.Errorf("grpcsender: Token must be at least %d characters", minToken)
+	}
+
+	if encoding.GetCodec(CodecName) == nil {
+		return nil, errors.New("grpcsender: codec not registered, call RegisterCodec at startup")
+	}
+
+	return &Server{sender: sender, cfg: cfg}, nil
+}
+
+// Register adds the service to the gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, s)
+}
+
+func (s *Server) Send(ctx context.Context, req *SendRequest) (*MessageResponse, error) {
+	if err := validate(req.ChatID, req.Message); err != nil {
+		return nil, err
+	}
+
+	sent, err := s.sender.SendContext(ctx, req.ChatID, req.Message)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &MessageResponse{MessageID: sent.ID}, nil
+}
+
+func (s *Server) Edit(ctx context.Context, req *EditRequest) (*MessageResponse, error) {
+	if req.MessageID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "messageId is required")
+	}
+
+	if err := validate(req.ChatID, req.Message); err != nil {
+		return nil, err
+	}
+
+	edited, err := s.sender.EditMessageContext(ctx, req.ChatID, req.MessageID, req.Message)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &MessageResponse{MessageID: edited.ID}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	if req.ChatID == 0 || req.MessageID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "chatId and messageId are required")
+	}
+
+	if err := s.sender.DeleteMessageContext(ctx, req.ChatID, req.MessageID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &DeleteResponse{}, nil
+}
+
+func (s *Server) Broadcast(ctx context.Context, req *BroadcastRequest) (*BroadcastResponse, error) {
+	if len(req.ChatIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "chatIds is required")
+	}
+
+	if err := tgbot.ValidateMessage(req.Message); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	opts := tgbot.BroadcastOptions{ID: req.ID, Concurrency: req.Concurrency}
+	if req.ID != "" {
+		opts.Store = s.cfg.BroadcastStore
+	}
+
+	report, err := s.sender.BroadcastContext(ctx, req.ChatIDs, req.Message, opts)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &BroadcastResponse{
+		Total:   report.Total,
+		Counts:  report.Counts,
+		Resumed: report.Resumed,
+		Results: report.Results,
+	}, nil
+}
+
+// authorize checks the bearer token in the metadata of the call
+func (s *Server) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid token")
+}
+
+func validate(chatID int64, msg tgbot.Message) error {
+	if chatID == 0 {
+		return status.Error(codes.InvalidArgument, "chatId is required")
+	}
+
+	if err := tgbot.ValidateMessage(msg); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+// toStatus maps the errors of the service to gRPC status codes
+func toStatus(err error) error {
+	code := codes.Unavailable
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, tgbot.ErrInvalidMessage), errors.Is(err, tgbot.ErrMessageTooLong):
+		code = codes.InvalidArgument
+	case errors.Is(err, tgbot.ErrChatNotFound), errors.Is(err, tgbot.ErrMessageNotFound):
+		code = codes.NotFound
+	case errors.Is(err, tgbot.ErrBotBlocked), errors.Is(err, tgbot.ErrUserDeactivated),
+		errors.Is(err, tgbot.ErrBotNotMember), errors.Is(err, tgbot.ErrNotEnoughRights),
+		errors.Is(err, tgbot.ErrMessageCantBeDeleted):
+		code = codes.PermissionDenied
+	case errors.Is(err, tgbot.ErrMessageNotModified):
+		code = codes.FailedPrecondition
+	case errors.Is(err, tgbot.ErrFloodWait):
+		code = codes.ResourceExhausted
+	}
+
+	return status.Error(code, err.Error())
+}
+
+// service is the interface the gRPC server checks Server against
+type service interface {
+	Send(ctx context.Context, req *SendRequest) (*MessageResponse, error)
+	Edit(ctx context.Context, req *EditRequest) (*MessageResponse, error)
+	Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error)
+	Broadcast(ctx context.Context, req *BroadcastRequest) (*BroadcastResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*service)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("Send", (*Server).Send),
+		unary("Edit", (*Server).Edit),
+		unary("Delete", (*Server).Delete),
+		unary("Broadcast", (*Server).Broadcast),
+	},
+	Metadata: "grpcsender",
+}
+
+// unary describes a method, decoding the request and checking the token
+// before calling it
+func unary[Req, Resp any](name string, call func(*Server, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			s := srv.(*Server)
+
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, req any) (any, error) {
+				if err := s.authorize(ctx); err != nil {
+					return nil, err
+				}
+
+				return call(s, ctx, req.(*Req))
+			}
+
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/" + name}
+
+			return interceptor(ctx, req, info, handler)
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package grpcsender
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Davincible/tgbot"
+	"github.com/Davincible/tgbot/cache"
+)
+
+func TestMain(m *testing.M) {
+	RegisterCodec()
+	os.Exit(m.Run())
+}
+
+func TestServer(t *testing.T) {
+	const token = "0123456789abcdef"
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := tgbot.NewRecorder()
+
+	bot, err := tgbot.NewService(logger, &tgbot.Config{DryRun: recorder})
+	require.NoError(t, err)
+	t.Cleanup(bot.Close)
+
+	_, err = NewServer(bot, Config{Token: "short"})
+	require.Error(t, err)
+
+	srv, err := NewServer(bot, Config{Token: token, BroadcastStore: cache.NewMemory[tgbot.RecipientResult]()})
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	srv.Register(grpcServer)
+
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	client := NewClient(conn, token)
+
+	msgID, err := client.Send(ctx, 42, tgbot.Message{Text: "hello", Buttons: []tgbot.InlineButton{{Text: "Open", URL: "https://example.com"}}})
+	require.NoError(t, err)
+	require.NotZero(t, msgID)
+
+	require.NoError(t, client.Edit(ctx, 42, msgID, tgbot.Message{Text: "edited"}))
+	require.NoError(t, client.Delete(ctx, 42, msgID))
+
+	calls := recorder.Calls("sendMessage", "editMessageText", "deleteMessage")
+	require.Len(t, calls, 3)
+	require.Equal(t, "hello", calls[0].Params["text"])
+	require.Equal(t, "edited", calls[1].Params["text"])
+
+	report, err := client.Broadcast(ctx, &BroadcastRequest{ChatIDs: []int64{1, 2, 3}, Message: tgbot.Message{Text: "news"}, ID: "news"})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Total)
+	require.Equal(t, 3, report.Counts[tgbot.RecipientSent])
+	require.Len(t, report.Results, 3)
+
+	// The same broadcast again resumes from the store
+	report, err = client.Broadcast(ctx, &BroadcastRequest{ChatIDs: []int64{1, 2, 3}, Message: tgbot.Message{Text: "news"}, ID: "news"})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Resumed)
+
+	_, err = client.Send(ctx, 42, tgbot.Message{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = NewClient(conn, "wrong-token-0123").Send(ctx, 42, tgbot.Message{Text: "hello"})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	require.Len(t, recorder.Calls("sendMessage"), 4)
+}
+
+func TestToStatus(t *testing.T) {
+	require.Equal(t, codes.NotFound, status.Code(toStatus(&tgbot.APIError{Kind: tgbot.ErrChatNotFound, Err: tgbot.ErrChatNotFound})))
+	require.Equal(t, codes.PermissionDenied, status.Code(toStatus(&tgbot.APIError{Kind: tgbot.ErrBotBlocked, Err: tgbot.ErrBotBlocked})))
+	require.Equal(t, codes.ResourceExhausted, status.Code(toStatus(&tgbot.FloodWaitError{Err: tgbot.ErrFloodWait})))
+	require.Equal(t, codes.DeadlineExceeded, status.Code(toStatus(context.DeadlineExceeded)))
+}
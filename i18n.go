@@ -0,0 +1,84 @@
+package tgbot
+
+import (
+	"fmt"
+)
+
+const defaultLanguage = "en"
+
+// Catalog resolves message keys to text in a language
+type Catalog interface {
+	Lookup(languageCode, key string) (string, bool)
+}
+
+// MapCatalog is a Catalog of texts keyed by language code and message key
+type MapCatalog map[string]map[string]string
+
+var _ Catalog = MapCatalog(nil)
+
+// Lookup returns the text of the key, falling back from e.g. pt-br to pt
+func (c MapCatalog) Lookup(languageCode, key string) (string, bool) {
+	if text, ok := c[languageCode][key]; ok {
+		return text, true
+	}
+
+	text, ok := c[baseLanguage(languageCode)][key]
+	return text, ok
+}
+
+// localize resolves the text of the message in the language of the chat.
+// Localized texts take precedence, otherwise a Text that is a key in
+// Config.Catalog is replaced. TextArgs are applied to the resolved text,
+// messages are only localized once.
+func (s *Service) localize(chatID int64, msg Message) Message {
+	if msg.localized || (len(msg.Localized) == 0 && s.cfg.Catalog == nil) {
+		return msg
+	}
+
+	languages := []string{s.Locale(chatID).LanguageCode, s.defaultLanguage()}
+
+	if text, ok := lookupLocalized(msg.Localized, languages); ok {
+		msg.Text = text
+	} else if s.cfg.Catalog != nil && msg.Text != "" {
+		for _, lang := range languages {
+			if text, ok := s.cfg.Catalog.Lookup(lang, msg.Text); ok {
+				msg.Text = text
+				break
+			}
+		}
+	}
+
+	if len(msg.TextArgs) > 0 {
+		msg.Text = fmt.Sprintf(msg.Text, msg.TextArgs...)
+	}
+
+	msg.localized = true
+
+	return msg
+}
+
+func (s *Service) defaultLanguage() string {
+	if s.cfg.DefaultLanguage != "" {
+		return s.cfg.DefaultLanguage
+	}
+
+	return defaultLanguage
+}
+
+func lookupLocalized(texts map[string]string, languages []string) (string, bool) {
+	for _, lang := range languages {
+		if lang == "" {
+			continue
+		}
+
+		if text, ok := texts[lang]; ok {
+			return text, true
+		}
+
+		if text, ok := texts[baseLanguage(lang)]; ok {
+			return text, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,79 @@
+package tgbot
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestLocalize(t *testing.T) {
+	locales := NewMemoryLocaleStore()
+	require.NoError(t, locales.SetLocale(1, Locale{LanguageCode: "nl"}))
+	require.NoError(t, locales.SetLocale(2, Locale{LanguageCode: "pt-br"}))
+
+	s := &Service{cfg: &Config{
+		Locales: locales,
+		Catalog: MapCatalog{
+			"en": {"greeting": "Hello %s"},
+			"nl": {"greeting": "Hallo %s"},
+		},
+	}}
+
+	msg := s.localize(1, Message{Text: "greeting", TextArgs: []any{"Ann"}})
+	require.Equal(t, "Hallo Ann", msg.Text)
+
+	// Unknown languages fall back to the default language
+	msg = s.localize(3, Message{Text: "greeting", TextArgs: []any{"Ann"}})
+	require.Equal(t, "Hello Ann", msg.Text)
+
+	// Text that is not a key is sent as is
+	require.Equal(t, "plain", s.localize(1, Message{Text: "plain"}).Text)
+
+	localized := Message{Localized: map[string]string{"en": "Bye", "pt": "Tchau"}}
+	require.Equal(t, "Tchau", s.localize(2, localized).Text)
+	require.Equal(t, "Bye", s.localize(1, localized).Text)
+
+	// Translations that are keys themselves are not looked up again
+	s.cfg.Catalog = MapCatalog{"en": {"start": "welcome", "welcome": "Welcome %s"}}
+
+	msg = s.localize(3, Message{Text: "start"})
+	require.Equal(t, "welcome", s.localize(3, msg).Text)
+}
+
+func TestSendLocalizesOnce(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := NewRecorder()
+
+	srv, err := NewService(logger, &Config{
+		DryRun:            recorder,
+		LongCaptions:      CaptionOverflowFollowUp,
+		SplitLongMessages: true,
+		Catalog: MapCatalog{"en": {
+			"start":   "welcome",
+			"welcome": "wrong",
+			"long":    strings.Repeat("word ", 1200) + "%s",
+		}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	_, err = srv.Send(42, Message{Text: "start"})
+	require.NoError(t, err)
+
+	texts := recorder.Calls("sendMessage")
+	require.Len(t, texts, 1)
+	require.Equal(t, "welcome", texts[0].Params["text"])
+
+	// Long texts are split after localizing
+	recorder.Reset()
+
+	_, err = srv.Send(42, Message{Text: "long", TextArgs: []any{"start"}})
+	require.NoError(t, err)
+
+	texts = recorder.Calls("sendMessage")
+	require.Len(t, texts, 2)
+	require.True(t, strings.HasSuffix(texts[1].Params["text"], "word start"))
+}
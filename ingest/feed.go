@@ -0,0 +1,218 @@
+package ingest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// maxFeedSize caps the size of a fetched feed
+const maxFeedSize = 10 << 20
+
+type rssFeed struct {
+	Items []struct {
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Author      string `xml:"author"`
+		Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+}
+
+type atomFeed struct {
+	Entries []struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Author    string `xml:"author>name"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// ParseFeed parses an RSS 2.0 or Atom feed into items, oldest first
+func ParseFeed(data []byte) ([]Item, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+
+	var items []Item
+
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("parse rss: %w", err)
+		}
+
+		for _, entry := range feed.Items {
+			item := Item{
+				ID:        firstNonEmpty(entry.GUID, entry.Link, entry.Title),
+				Title:     strings.TrimSpace(entry.Title),
+				Link:      strings.TrimSpace(entry.Link),
+				Summary:   strings.TrimSpace(entry.Description),
+				Author:    firstNonEmpty(entry.Creator, entry.Author),
+				Published: parseFeedTime(entry.PubDate),
+			}
+			items = append(items, item)
+		}
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("parse atom: %w", err)
+		}
+
+		for _, entry := range feed.Entries {
+			var link string
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+
+			item := Item{
+				ID:        firstNonEmpty(entry.ID, link, entry.Title),
+				Title:     strings.TrimSpace(entry.Title),
+				Link:      link,
+				Summary:   strings.TrimSpace(firstNonEmpty(entry.Summary, entry.Content)),
+				Author:    entry.Author,
+				Published: parseFeedTime(firstNonEmpty(entry.Published, entry.Updated)),
+			}
+			items = append(items, item)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed type: %s", root.XMLName.Local)
+	}
+
+	// Feeds list the newest entries first, post them in chronological order
+	slices.SortStableFunc(items, func(a, b Item) int {
+		return a.Published.Compare(b.Published)
+	})
+
+	return items, nil
+}
+
+func (b *Bridge) pollFeed(ctx context.Context, feed Feed) {
+	interval := feed.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	first := true
+
+	for {
+		if err := b.checkFeed(ctx, feed, first && !feed.PostExisting); err != nil {
+			b.logger.Error("failed to check feed",
+				slog.String("err", err.Error()),
+				slog.String("feed", feed.Name),
+			)
+		} else {
+			first = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkFeed fetches the feed and posts the new items, or only marks them
+// as seen if skip is set.
+func (b *Bridge) checkFeed(ctx context.Context, feed Feed, skip bool) error {
+	items, err := b.fetchFeed(ctx, feed.URL)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if skip {
+			if err := b.seen.MarkSeen(feed.Name + ":" + item.ID); err != nil {
+				return fmt.Errorf("mark seen: %w", err)
+			}
+			continue
+		}
+
+		if _, err := b.Publish(ctx, feed.Route, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bridge) fetchFeed(ctx context.Context, url string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := b.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch feed: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("read feed: %w", err)
+	}
+
+	return ParseFeed(data)
+}
+
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			return v
+		}
+	}
+
+	return ""
+}
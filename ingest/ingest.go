@@ -0,0 +1,213 @@
+// Package ingest bridges external content, RSS/Atom feeds and JSON webhooks,
+// into Telegram chats through a tgbot.MessageSender.
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/ratelimit"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+const (
+	defaultTemplate     = "{{.Title}}\n\n{{.Link}}"
+	defaultPollInterval = 10 * time.Minute
+	defaultRate         = 20
+)
+
+var ErrNoRoute = errors.New("no route configured")
+
+// Item is a single piece of content to post, either a feed entry or a webhook payload
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Author    string
+	Published time.Time
+	// Fields holds the raw JSON payload of webhook items
+	Fields map[string]any
+}
+
+// Route determines where and how items are posted
+type Route struct {
+	// Name identifies the route in logs and dedupe keys
+	Name   string
+	ChatID int64
+	// Template is a text/template executed with the Item, defaults to the
+	// title followed by the link
+	Template           string
+	TextFormatting     bool
+	DisableLinkPreview bool
+}
+
+// Feed is an RSS or Atom feed that is polled for new entries
+type Feed struct {
+	Route
+	URL string
+	// Interval between two polls, defaults to ten minutes
+	Interval time.Duration
+	// PostExisting posts the entries present on the first poll, by default
+	// they are only marked as seen
+	PostExisting bool
+}
+
+type Config struct {
+	Feeds []Feed
+	// Rate is the maximum number of posts per minute over all routes
+	Rate int
+	// Seen stores the IDs of posted items, defaults to memory
+	Seen SeenStore
+	// HTTPClient is used to fetch the feeds
+	HTTPClient *http.Client
+}
+
+// Bridge posts items from feeds and webhooks to Telegram
+type Bridge struct {
+	logger *slog.Logger
+	sender tgbot.MessageSender
+	cfg    Config
+	seen   SeenStore
+	limit  ratelimit.Limiter
+
+	mu        sync.Mutex
+	templates map[string]*template.Template
+}
+
+// New creates a new ingestion bridge, call Run to start polling the feeds
+func New(logger *slog.Logger, sender tgbot.MessageSender, cfg Config) (*Bridge, error) {
+	if cfg.Rate <= 0 {
+		cfg.Rate = defaultRate
+	}
+
+	if cfg.Seen == nil {
+		cfg.Seen = NewMemorySeenStore(0)
+	}
+
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	b := &Bridge{
+		logger:    logger,
+		sender:    sender,
+		cfg:       cfg,
+		seen:      cfg.Seen,
+		limit:     ratelimit.New(cfg.Rate, ratelimit.Per(time.Minute), ratelimit.WithoutSlack),
+		templates: make(map[string]*template.Template),
+	}
+
+	for _, feed := range cfg.Feeds {
+		if _, err := b.template(feed.Route); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// Run polls all feeds until the context is canceled
+func (b *Bridge) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, feed := range b.cfg.Feeds {
+		wg.Add(1)
+		go func(feed Feed) {
+			defer wg.Done()
+			b.pollFeed(ctx, feed)
+		}(feed)
+	}
+
+	wg.Wait()
+}
+
+// Publish posts an item to the route, unless it was posted before. It
+// returns false if the item was a duplicate.
+func (b *Bridge) Publish(ctx context.Context, route Route, item Item) (bool, error) {
+	if route.ChatID == 0 {
+		return false, ErrNoRoute
+	}
+
+	key := route.Name + ":" + item.ID
+
+	seen, err := b.seen.Seen(key)
+	if err != nil {
+		return false, fmt.Errorf("check seen: %w", err)
+	}
+
+	if seen {
+		return false, nil
+	}
+
+	text, err := b.render(route, item)
+	if err != nil {
+		return false, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	b.limit.Take()
+
+	if _, err := b.sender.Send(route.ChatID, tgbot.Message{
+		Text:               text,
+		TextFormatting:     route.TextFormatting,
+		DisableLinkPreview: route.DisableLinkPreview,
+	}); err != nil {
+		return false, fmt.Errorf("send item: %w", err)
+	}
+
+	if err := b.seen.MarkSeen(key); err != nil {
+		return true, fmt.Errorf("mark seen: %w", err)
+	}
+
+	return true, nil
+}
+
+func (b *Bridge) render(route Route, item Item) (string, error) {
+	tmpl, err := b.template(route)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, item); err != nil {
+		return "", fmt.Errorf("execute template %q: %w", route.Name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// template returns the parsed template of the route, templates are parsed once
+func (b *Bridge) template(route Route) (*template.Template, error) {
+	text := route.Template
+	if len(text) == 0 {
+		text = defaultTemplate
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if tmpl, ok := b.templates[text]; ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New(route.Name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", route.Name, err)
+	}
+
+	b.templates[text] = tmpl
+
+	return tmpl, nil
+}
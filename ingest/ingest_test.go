@@ -0,0 +1,108 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot"
+)
+
+// fakeSender records the sent texts per chat
+type fakeSender struct {
+	sent map[int64][]string
+}
+
+func (f *fakeSender) Send(chatID int64, msg tgbot.Message) (*models.Message, error) {
+	f.sent[chatID] = append(f.sent[chatID], msg.Text)
+	return &models.Message{ID: len(f.sent[chatID])}, nil
+}
+
+func (f *fakeSender) SendTyping(int64) error {
+	return nil
+}
+
+const rssSample = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>x</title>
+<item><guid>2</guid><title>Second</title><link>https://x.com/2</link><pubDate>Tue, 02 Jan 2024 10:00:00 +0000</pubDate></item>
+<item><guid>1</guid><title>First</title><link>https://x.com/1</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+
+const atomSample = `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<entry><id>urn:1</id><title>Hello</title><link rel="alternate" href="https://x.com/a"/><updated>2024-01-01T10:00:00Z</updated><summary>Sum</summary></entry>
+</feed>`
+
+func TestParseFeed(t *testing.T) {
+	items, err := ParseFeed([]byte(rssSample))
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, "First", items[0].Title)
+	require.Equal(t, "2", items[1].ID)
+
+	items, err = ParseFeed([]byte(atomSample))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "urn:1", items[0].ID)
+	require.Equal(t, "https://x.com/a", items[0].Link)
+	require.Equal(t, "Sum", items[0].Summary)
+
+	_, err = ParseFeed([]byte("<html></html>"))
+	require.Error(t, err)
+}
+
+func TestMemorySeenStore(t *testing.T) {
+	store := NewMemorySeenStore(2)
+
+	require.NoError(t, store.MarkSeen("a"))
+	require.NoError(t, store.MarkSeen("b"))
+	require.NoError(t, store.MarkSeen("c"))
+
+	seen, _ := store.Seen("a")
+	require.False(t, seen)
+
+	seen, _ = store.Seen("c")
+	require.True(t, seen)
+}
+
+func TestWebhookItem(t *testing.T) {
+	item, err := webhookItem([]byte(`{"id": 42, "title": "Deploy", "url": "https://x.com"}`), "id")
+	require.NoError(t, err)
+	require.Equal(t, "42", item.ID)
+	require.Equal(t, "Deploy", item.Title)
+	require.Equal(t, "https://x.com", item.Link)
+
+	item, err = webhookItem([]byte(`{"title": "Deploy"}`), "id")
+	require.NoError(t, err)
+	require.Len(t, item.ID, 64)
+
+	_, err = webhookItem([]byte(`not json`), "")
+	require.Error(t, err)
+}
+
+func TestPublish(t *testing.T) {
+	sender := &fakeSender{sent: make(map[int64][]string)}
+
+	b, err := New(slog.Default(), sender, Config{})
+	require.NoError(t, err)
+
+	route := Route{Name: "news", ChatID: 42}
+	item := Item{ID: "1", Title: "Hello", Link: "https://x.com/1"}
+
+	posted, err := b.Publish(context.Background(), route, item)
+	require.NoError(t, err)
+	require.True(t, posted)
+
+	// Duplicates are skipped
+	posted, err = b.Publish(context.Background(), route, item)
+	require.NoError(t, err)
+	require.False(t, posted)
+
+	require.Equal(t, []string{"Hello\n\nhttps://x.com/1"}, sender.sent[42])
+
+	_, err = b.Publish(context.Background(), Route{Name: "none"}, item)
+	require.ErrorIs(t, err, ErrNoRoute)
+}
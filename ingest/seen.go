@@ -0,0 +1,96 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Davincible/tgbot/cache"
+)
+
+const defaultSeenSize = 10000
+
+// SeenStore remembers which items were posted, implement it to dedupe
+// across restarts
+type SeenStore interface {
+	Seen(key string) (bool, error)
+	MarkSeen(key string) error
+}
+
+// MemorySeenStore remembers the most recent item keys in memory
+type MemorySeenStore struct {
+	mu   sync.Mutex
+	size int
+	keys map[string]struct{}
+	ring []string
+	next int
+}
+
+var _ SeenStore = (*MemorySeenStore)(nil)
+
+// NewMemorySeenStore creates a seen store that remembers up to size keys,
+// zero uses the default of 10000
+func NewMemorySeenStore(size int) *MemorySeenStore {
+	if size <= 0 {
+		size = defaultSeenSize
+	}
+
+	return &MemorySeenStore{
+		size: size,
+		keys: make(map[string]struct{}, size),
+		ring: make([]string, 0, size),
+	}
+}
+
+func (m *MemorySeenStore) Seen(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.keys[key]
+	return ok, nil
+}
+
+func (m *MemorySeenStore) MarkSeen(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.keys[key]; ok {
+		return nil
+	}
+
+	if len(m.ring) < m.size {
+		m.ring = append(m.ring, key)
+	} else {
+		delete(m.keys, m.ring[m.next])
+		m.ring[m.next] = key
+		m.next = (m.next + 1) % m.size
+	}
+
+	m.keys[key] = struct{}{}
+
+	return nil
+}
+
+// CacheSeenStore remembers item keys in a cache for a dedupe window, so
+// several bridge instances can share their dedupe state through Redis
+type CacheSeenStore struct {
+	cache  cache.Cache[bool]
+	window time.Duration
+}
+
+var _ SeenStore = (*CacheSeenStore)(nil)
+
+// NewCacheSeenStore creates a seen store that forgets items after the window,
+// zero keeps them until the cache evicts them
+func NewCacheSeenStore(c cache.Cache[bool], window time.Duration) *CacheSeenStore {
+	return &CacheSeenStore{cache: c, window: window}
+}
+
+func (c *CacheSeenStore) Seen(key string) (bool, error) {
+	_, ok, err := c.cache.Get(context.Background(), key)
+	return ok, err
+}
+
+func (c *CacheSeenStore) MarkSeen(key string) error {
+	return c.cache.Set(context.Background(), key, true, c.window)
+}
@@ -0,0 +1,108 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/exp/slog"
+)
+
+// maxWebhookSize caps the size of a webhook payload
+const maxWebhookSize = 1 << 20
+
+// Webhook configures an endpoint that accepts JSON payloads
+type Webhook struct {
+	Route
+	// IDField is the payload field used for dedupe, the payload hash is used if empty
+	IDField string
+	// Secret is compared to the X-Webhook-Secret header if set
+	Secret string
+}
+
+// WebhookHandler returns an http.Handler that posts JSON payloads to the
+// route. The payload is available to the template as .Fields, the title,
+// link and summary fields are mapped onto the item as well.
+func (b *Bridge) WebhookHandler(hook Webhook) (http.Handler, error) {
+	if _, err := b.template(hook.Route); err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if len(hook.Secret) > 0 && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Webhook-Secret")), []byte(hook.Secret)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookSize))
+		if err != nil {
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+
+		item, err := webhookItem(data, hook.IDField)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		posted, err := b.Publish(r.Context(), hook.Route, item)
+		if err != nil {
+			b.logger.Error("failed to publish webhook item",
+				slog.String("err", err.Error()),
+				slog.String("route", hook.Name),
+			)
+			http.Error(w, "publish failed", http.StatusBadGateway)
+			return
+		}
+
+		if !posted {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}), nil
+}
+
+func webhookItem(data []byte, idField string) (Item, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Item{}, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+
+	item := Item{
+		Title:   stringField(fields, "title"),
+		Link:    firstNonEmpty(stringField(fields, "link"), stringField(fields, "url")),
+		Summary: firstNonEmpty(stringField(fields, "summary"), stringField(fields, "text")),
+		Author:  stringField(fields, "author"),
+		Fields:  fields,
+	}
+
+	if len(idField) > 0 {
+		if v, ok := fields[idField]; ok {
+			item.ID = fmt.Sprint(v)
+		}
+	}
+
+	if len(item.ID) == 0 {
+		sum := sha256.Sum256(data)
+		item.ID = hex.EncodeToString(sum[:])
+	}
+
+	return item, nil
+}
+
+func stringField(fields map[string]any, key string) string {
+	v, _ := fields[key].(string)
+	return v
+}
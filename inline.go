@@ -0,0 +1,350 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/Davincible/tgbot/cache"
+)
+
+const (
+	defaultInlineCacheTTL = 5 * time.Minute
+	// maxInlineResults is the maximum number of results allowed per inline answer
+	maxInlineResults = 50
+	// defaultVideoMimeType is used for inline videos without a mime type
+	defaultVideoMimeType = "video/mp4"
+)
+
+var ErrInlineResults = errors.New("inline answers take at most 50 results")
+
+// InlineSearchFunc computes all results for an inline query, the InlineCache
+// takes care of slicing them into pages.
+type InlineSearchFunc func(ctx context.Context, query *models.InlineQuery) ([]models.InlineQueryResult, error)
+
+// InlineCacheConfig holds the configuration of the inline results cache
+type InlineCacheConfig struct {
+	// TTL of the cached results, defaults to five minutes
+	TTL time.Duration
+	// PageSize is the number of results per answer, capped at 50
+	PageSize int
+	// Personal caches the results per user instead of per query text
+	Personal bool
+}
+
+// InlineCache caches the full result set of inline queries, so scrolling
+// through the pages or retyping a query doesn't recompute the results.
+type InlineCache struct {
+	cfg   InlineCacheConfig
+	cache *cache.Memory[[]models.InlineQueryResult]
+}
+
+// NewInlineCache creates a new inline results cache. Results are kept in
+// memory, inline result types can't be decoded from a remote cache.
+func NewInlineCache(cfg InlineCacheConfig) (*InlineCache, error) {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultInlineCacheTTL
+	}
+
+	if cfg.PageSize <= 0 || cfg.PageSize > maxInlineResults {
+		cfg.PageSize = maxInlineResults
+	}
+
+	return &InlineCache{cfg: cfg, cache: cache.NewMemory[[]models.InlineQueryResult]()}, nil
+}
+
+// Page returns the page of results requested by the query offset and the
+// offset of the next page, which is empty on the last page. The search
+// function is only called if the query is not cached yet.
+func (c *InlineCache) Page(ctx context.Context, query *models.InlineQuery, search InlineSearchFunc) ([]models.InlineQueryResult, string, error) {
+	key := c.key(query)
+
+	results, ok, _ := c.cache.Get(ctx, key)
+	if !ok {
+		var err error
+		if results, err = search(ctx, query); err != nil {
+			return nil, "", fmt.Errorf("search inline results: %w", err)
+		}
+
+		if err := c.cache.Set(ctx, key, results, c.cfg.TTL); err != nil {
+			return nil, "", fmt.Errorf("cache inline results: %w", err)
+		}
+	}
+
+	page, next := InlinePage(results, query.Offset, c.cfg.PageSize)
+
+	return page, next, nil
+}
+
+// Invalidate removes the cached results of a query text, for all users if
+// the cache is personal.
+func (c *InlineCache) Invalidate(query string) {
+	query = normalizeInlineQuery(query)
+
+	for _, key := range c.cache.Keys("") {
+		if key == query || (c.cfg.Personal && strings.HasSuffix(key, ":"+query)) {
+			c.cache.Delete(context.Background(), key)
+		}
+	}
+}
+
+// Personal returns true if results are cached per user, which should be
+// passed on as is_personal when answering the query.
+func (c *InlineCache) Personal() bool {
+	return c.cfg.Personal
+}
+
+// TTL returns the cache TTL, which can be used as cache_time of the answer
+func (c *InlineCache) TTL() time.Duration {
+	return c.cfg.TTL
+}
+
+func (c *InlineCache) key(query *models.InlineQuery) string {
+	text := normalizeInlineQuery(query.Query)
+	if c.cfg.Personal {
+		return strconv.FormatInt(query.From.ID, 10) + ":" + text
+	}
+
+	return text
+}
+
+func normalizeInlineQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// InlinePage slices a page out of the results, starting at the offset sent
+// by Telegram. It returns the next_offset to answer with, which is empty if
+// there are no more results.
+func InlinePage(results []models.InlineQueryResult, offset string, size int) ([]models.InlineQueryResult, string) {
+	if size <= 0 || size > maxInlineResults {
+		size = maxInlineResults
+	}
+
+	start := ParseInlineOffset(offset)
+	if start >= len(results) {
+		return []models.InlineQueryResult{}, ""
+	}
+
+	end := start + size
+	if end >= len(results) {
+		return results[start:], ""
+	}
+
+	return results[start:end], InlineOffset(end)
+}
+
+// ParseInlineOffset parses the offset of an inline query, invalid or empty
+// offsets start at the first result.
+func ParseInlineOffset(offset string) int {
+	n, err := strconv.Atoi(offset)
+	if err != nil || n < 0 {
+		return 0
+	}
+
+	return n
+}
+
+// InlineOffset formats a result index as next_offset
+func InlineOffset(n int) string {
+	return strconv.Itoa(n)
+}
+
+// InlineArticle builds an article result, which sends Text when chosen
+type InlineArticle struct {
+	ID          string
+	Title       string
+	Description string
+	Text        string
+	// TextFormatting parses Text as markdown
+	TextFormatting bool
+	ThumbnailURL   string
+	URL            string
+	Buttons        []InlineButton
+}
+
+// Result returns the article as inline query result
+func (a InlineArticle) Result() models.InlineQueryResult {
+	content := &models.InputTextMessageContent{}
+	content.MessageText, content.ParseMode = inlineText(a.Text, a.TextFormatting)
+
+	return &models.InlineQueryResultArticle{
+		ID:                  a.ID,
+		Title:               a.Title,
+		Description:         a.Description,
+		InputMessageContent: content,
+		ReplyMarkup:         createInlineKeyboard(Message{Buttons: a.Buttons}),
+		URL:                 a.URL,
+		ThumbnailURL:        a.ThumbnailURL,
+	}
+}
+
+// InlinePhoto builds a photo result. PhotoURL must be a JPEG, the thumbnail
+// defaults to the photo.
+type InlinePhoto struct {
+	ID           string
+	PhotoURL     string
+	ThumbnailURL string
+	Width        int
+	Height       int
+	Title        string
+	Description  string
+	Caption      string
+	// TextFormatting parses Caption as markdown
+	TextFormatting bool
+	Buttons        []InlineButton
+}
+
+// Result returns the photo as inline query result
+func (p InlinePhoto) Result() models.InlineQueryResult {
+	result := &models.InlineQueryResultPhoto{
+		ID:           p.ID,
+		PhotoURL:     p.PhotoURL,
+		ThumbnailURL: p.ThumbnailURL,
+		PhotoWidth:   p.Width,
+		PhotoHeight:  p.Height,
+		Title:        p.Title,
+		Description:  p.Description,
+		ReplyMarkup:  createInlineKeyboard(Message{Buttons: p.Buttons}),
+	}
+
+	result.Caption, result.ParseMode = inlineText(p.Caption, p.TextFormatting)
+
+	if result.ThumbnailURL == "" {
+		result.ThumbnailURL = p.PhotoURL
+	}
+
+	return result
+}
+
+// InlineVideo builds a video result. VideoURL is an MP4 file, or an embedded
+// player page with MimeType "text/html".
+type InlineVideo struct {
+	ID           string
+	VideoURL     string
+	MimeType     string
+	ThumbnailURL string
+	Title        string
+	Description  string
+	Caption      string
+	// TextFormatting parses Caption as markdown
+	TextFormatting bool
+	Width          int
+	Height         int
+	Duration       time.Duration
+	Buttons        []InlineButton
+}
+
+// Result returns the video as inline query result
+func (v InlineVideo) Result() models.InlineQueryResult {
+	result := &models.InlineQueryResultVideo{
+		ID:            v.ID,
+		VideoURL:      v.VideoURL,
+		MimeType:      v.MimeType,
+		ThumbnailURL:  v.ThumbnailURL,
+		Title:         v.Title,
+		Description:   v.Description,
+		VideoWidth:    v.Width,
+		VideoHeight:   v.Height,
+		VideoDuration: int(v.Duration.Seconds()),
+		ReplyMarkup:   createInlineKeyboard(Message{Buttons: v.Buttons}),
+	}
+
+	result.Caption, result.ParseMode = inlineText(v.Caption, v.TextFormatting)
+
+	if result.MimeType == "" {
+		result.MimeType = defaultVideoMimeType
+	}
+
+	return result
+}
+
+// inlineText escapes the text of a result with formatting the same way as
+// the text of a Message, texts without formatting are sent as plain text
+func inlineText(text string, formatting bool) (string, models.ParseMode) {
+	if !formatting {
+		return text, ""
+	}
+
+	msg := Message{Text: text, TextFormatting: true}
+
+	return msg.escapeText(), msg.parseMode()
+}
+
+// InlineAnswer is the answer to an inline query
+type InlineAnswer struct {
+	Results []models.InlineQueryResult
+	// NextOffset is sent back as offset when the user scrolls to the end of
+	// the results, empty if there are no more results
+	NextOffset string
+	// CacheTime is how long Telegram may cache the answer, zero uses the
+	// Telegram default of 300 seconds
+	CacheTime time.Duration
+	// Personal only caches the answer for the user that sent the query
+	Personal bool
+
+	// ButtonText shows a button above the results, which opens a private chat
+	// with the bot with StartParameter, or a web app at WebAppURL
+	ButtonText     string
+	StartParameter string
+	WebAppURL      string
+}
+
+// AnswerInlineQuery answers an inline query
+func (s *Service) AnswerInlineQuery(ctx context.Context, queryID string, answer InlineAnswer) error {
+	if len(answer.Results) > maxInlineResults {
+		return ErrInlineResults
+	}
+
+	params := &bot.AnswerInlineQueryParams{
+		InlineQueryID: queryID,
+		Results:       answer.Results,
+		CacheTime:     int(answer.CacheTime.Seconds()),
+		IsPersonal:    answer.Personal,
+		NextOffset:    answer.NextOffset,
+	}
+
+	// Telegram rejects an empty results array encoded as null
+	if params.Results == nil {
+		params.Results = []models.InlineQueryResult{}
+	}
+
+	if answer.ButtonText != "" {
+		params.Button = &models.InlineQueryResultsButton{
+			Text:           answer.ButtonText,
+			StartParameter: answer.StartParameter,
+			WebApp:         createWebAppInfo(answer.WebAppURL),
+		}
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.AnswerInlineQuery(ctx, params); err != nil {
+		return fmt.Errorf("answer inline query: %w", err)
+	}
+
+	return nil
+}
+
+// AnswerInlinePage answers the query with the page of results requested by
+// its offset. Results come from the cache, which also sets the cache time and
+// whether the answer is personal.
+func (s *Service) AnswerInlinePage(ctx context.Context, query *models.InlineQuery, c *InlineCache, search InlineSearchFunc) error {
+	page, next, err := c.Page(ctx, query, search)
+	if err != nil {
+		return err
+	}
+
+	return s.AnswerInlineQuery(ctx, query.ID, InlineAnswer{
+		Results:    page,
+		NextOffset: next,
+		CacheTime:  c.TTL(),
+		Personal:   c.Personal(),
+	})
+}
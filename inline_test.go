@@ -0,0 +1,130 @@
+package tgbot
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func inlineResults(n int) []models.InlineQueryResult {
+	results := make([]models.InlineQueryResult, n)
+	for i := range results {
+		results[i] = &models.InlineQueryResultArticle{ID: strconv.Itoa(i)}
+	}
+
+	return results
+}
+
+func TestInlinePage(t *testing.T) {
+	results := inlineResults(120)
+
+	page, next := InlinePage(results, "", 50)
+	require.Len(t, page, 50)
+	require.Equal(t, "50", next)
+
+	page, next = InlinePage(results, next, 50)
+	require.Len(t, page, 50)
+	require.Equal(t, "100", next)
+
+	page, next = InlinePage(results, next, 50)
+	require.Len(t, page, 20)
+	require.Empty(t, next)
+
+	page, next = InlinePage(results, "500", 50)
+	require.Empty(t, page)
+	require.Empty(t, next)
+
+	page, _ = InlinePage(results, "garbage", 10)
+	require.Equal(t, "0", page[0].(*models.InlineQueryResultArticle).ID)
+}
+
+func TestInlineCache(t *testing.T) {
+	c, err := NewInlineCache(InlineCacheConfig{PageSize: 10})
+	require.NoError(t, err)
+
+	var calls int
+	search := func(ctx context.Context, query *models.InlineQuery) ([]models.InlineQueryResult, error) {
+		calls++
+		return inlineResults(25), nil
+	}
+
+	query := &models.InlineQuery{Query: "Foo  bar"}
+	page, next, err := c.Page(context.Background(), query, search)
+	require.NoError(t, err)
+	require.Len(t, page, 10)
+	require.Equal(t, "10", next)
+
+	query = &models.InlineQuery{Query: "foo bar", Offset: "20"}
+	page, next, err = c.Page(context.Background(), query, search)
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	require.Empty(t, next)
+	require.Equal(t, 1, calls)
+
+	c.Invalidate("FOO bar")
+	_, _, err = c.Page(context.Background(), query, search)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
+
+func TestInlineResults(t *testing.T) {
+	article := InlineArticle{ID: "1", Title: "Title", Text: "*bold*", TextFormatting: true}.Result().(*models.InlineQueryResultArticle)
+	content := article.InputMessageContent.(*models.InputTextMessageContent)
+	require.Equal(t, "*bold*", content.MessageText)
+	require.Equal(t, models.ParseModeMarkdown, content.ParseMode)
+	require.Nil(t, article.ReplyMarkup)
+
+	photo := InlinePhoto{ID: "2", PhotoURL: "https://example.com/a.jpg"}.Result().(*models.InlineQueryResultPhoto)
+	require.Equal(t, photo.PhotoURL, photo.ThumbnailURL)
+	require.Empty(t, photo.ParseMode)
+
+	video := InlineVideo{
+		ID:       "3",
+		VideoURL: "https://example.com/a.mp4",
+		Duration: 90 * time.Second,
+		Buttons:  []InlineButton{{Text: "Open", URL: "https://example.com"}},
+	}.Result().(*models.InlineQueryResultVideo)
+	require.Equal(t, "video/mp4", video.MimeType)
+	require.Equal(t, 90, video.VideoDuration)
+	require.Len(t, video.ReplyMarkup.(models.InlineKeyboardMarkup).InlineKeyboard, 1)
+}
+
+func TestInlineResultsEscape(t *testing.T) {
+	const (
+		text    = "*Sale* ends 1.5.2024 - (really)!"
+		escaped = `*Sale* ends 1\.5\.2024 \- \(really\)\!`
+	)
+
+	article := InlineArticle{ID: "1", Text: text, TextFormatting: true}.Result().(*models.InlineQueryResultArticle)
+	content := article.InputMessageContent.(*models.InputTextMessageContent)
+	require.Equal(t, escaped, content.MessageText)
+	require.Equal(t, models.ParseModeMarkdown, content.ParseMode)
+
+	photo := InlinePhoto{ID: "2", Caption: text, TextFormatting: true}.Result().(*models.InlineQueryResultPhoto)
+	require.Equal(t, escaped, photo.Caption)
+	require.Equal(t, models.ParseModeMarkdown, photo.ParseMode)
+
+	video := InlineVideo{ID: "3", Caption: text, TextFormatting: true}.Result().(*models.InlineQueryResultVideo)
+	require.Equal(t, escaped, video.Caption)
+	require.Equal(t, models.ParseModeMarkdown, video.ParseMode)
+
+	// Without formatting the text is sent as is
+	article = InlineArticle{ID: "4", Text: text}.Result().(*models.InlineQueryResultArticle)
+	content = article.InputMessageContent.(*models.InputTextMessageContent)
+	require.Equal(t, text, content.MessageText)
+	require.Empty(t, content.ParseMode)
+
+	video = InlineVideo{ID: "5", Caption: text}.Result().(*models.InlineQueryResultVideo)
+	require.Equal(t, text, video.Caption)
+	require.Empty(t, video.ParseMode)
+}
+
+func TestAnswerInlineQueryLimit(t *testing.T) {
+	s := &Service{}
+	err := s.AnswerInlineQuery(context.Background(), "1", InlineAnswer{Results: inlineResults(51)})
+	require.ErrorIs(t, err, ErrInlineResults)
+}
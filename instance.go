@@ -0,0 +1,86 @@
+package tgbot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+type instanceKey struct{}
+
+// BotInstance identifies the bot a handler runs for. When one codebase is
+// deployed as several white-label bots, each with its own Service, handlers
+// read it from the context to branch per deployment.
+type BotInstance struct {
+	// ID is the bot user ID, taken from the token
+	ID int64
+	// Username is empty when Config.SkipGetMe is set
+	Username string
+	// Name is Config.InstanceName
+	Name string
+	// Values is Config.InstanceValues, it must not be modified
+	Values map[string]any
+}
+
+// Value returns the instance value for the key
+func (i *BotInstance) Value(key string) (any, bool) {
+	if i == nil {
+		return nil, false
+	}
+
+	v, ok := i.Values[key]
+	return v, ok
+}
+
+// String returns the instance value for the key if it is a string
+func (i *BotInstance) String(key string) string {
+	v, _ := i.Value(key)
+	s, _ := v.(string)
+	return s
+}
+
+// WithBotInstance returns a context carrying the instance
+func WithBotInstance(ctx context.Context, instance *BotInstance) context.Context {
+	return context.WithValue(ctx, instanceKey{}, instance)
+}
+
+// BotInstanceFromContext returns the instance the handler runs for, nil
+// outside of handlers
+func BotInstanceFromContext(ctx context.Context) *BotInstance {
+	instance, _ := ctx.Value(instanceKey{}).(*BotInstance)
+	return instance
+}
+
+// Instance returns the bot instance of the service
+func (s *Service) Instance() *BotInstance {
+	id, _ := strconv.ParseInt(strings.Split(s.cfg.Token, ":")[0], 10, 64)
+
+	return &BotInstance{
+		ID:       id,
+		Username: s.username,
+		Name:     s.cfg.InstanceName,
+		Values:   s.cfg.InstanceValues,
+	}
+}
+
+// instanceMiddleware puts the bot instance on the context of every handler
+func (s *Service) instanceMiddleware() bot.Middleware {
+	var cached atomic.Pointer[BotInstance]
+
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			// The username is only known after the bot is created
+			instance := cached.Load()
+			if instance == nil || instance.Username != s.username {
+				instance = s.Instance()
+				cached.Store(instance)
+			}
+
+			next(WithBotInstance(ctx, instance), b, update)
+		}
+	}
+}
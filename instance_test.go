@@ -0,0 +1,37 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceMiddleware(t *testing.T) {
+	s := &Service{
+		cfg: &Config{
+			Token:          "123456:secret",
+			InstanceName:   "acme",
+			InstanceValues: map[string]any{"brand": "Acme"},
+		},
+		username: "acme_bot",
+	}
+
+	var got *BotInstance
+	handler := s.instanceMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		got = BotInstanceFromContext(ctx)
+	})
+	handler(context.Background(), nil, &models.Update{})
+
+	require.NotNil(t, got)
+	require.Equal(t, int64(123456), got.ID)
+	require.Equal(t, "acme_bot", got.Username)
+	require.Equal(t, "acme", got.Name)
+	require.Equal(t, "Acme", got.String("brand"))
+	require.Empty(t, got.String("missing"))
+
+	require.Nil(t, BotInstanceFromContext(context.Background()))
+	require.Empty(t, BotInstanceFromContext(context.Background()).String("brand"))
+}
@@ -0,0 +1,78 @@
+package tgbot
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// SendOp is the kind of request a SendCall makes
+type SendOp string
+
+const (
+	SendOpSend SendOp = "send"
+	SendOpEdit SendOp = "edit"
+)
+
+// SendCall is an outgoing message passing the send interceptors
+type SendCall struct {
+	Op     SendOp
+	ChatID int64
+	// MessageID is the edited message, zero for sends
+	MessageID int
+	// Message is sent as it is after the interceptors changed it
+	Message *Message
+	// Result is the sent or edited message, set once next returned
+	Result *models.Message
+}
+
+// SendFunc makes the request of the call
+type SendFunc func(ctx context.Context, call *SendCall) error
+
+// SendInterceptor wraps every Send and Edit, like middleware wraps update
+// handlers. It may change the message before calling next, inspect the
+// result and error after it, or return an error without calling next to
+// stop the message.
+type SendInterceptor func(next SendFunc) SendFunc
+
+// UseSendInterceptor adds an interceptor, the first one added is the
+// outermost. Retries and splitting of long messages happen inside next.
+func (s *Service) UseSendInterceptor(interceptor SendInterceptor) {
+	s.interceptors.mu.Lock()
+	defer s.interceptors.mu.Unlock()
+
+	s.interceptors.chain = append(s.interceptors.chain, interceptor)
+}
+
+type sendInterceptors struct {
+	mu    sync.RWMutex
+	chain []SendInterceptor
+}
+
+// intercept runs the call through the interceptors, send makes the request
+// with the message as the interceptors left it
+func (s *Service) intercept(ctx context.Context, call *SendCall, send func(ctx context.Context, msg Message) (*models.Message, error)) (*models.Message, error) {
+	s.interceptors.mu.RLock()
+	chain := s.interceptors.chain
+	s.interceptors.mu.RUnlock()
+
+	if len(chain) == 0 {
+		return send(ctx, *call.Message)
+	}
+
+	var next SendFunc = func(ctx context.Context, call *SendCall) error {
+		result, err := send(ctx, *call.Message)
+		call.Result = result
+
+		return err
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		next = chain[i](next)
+	}
+
+	err := next(ctx, call)
+
+	return call.Result, err
+}
@@ -0,0 +1,75 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendInterceptors(t *testing.T) {
+	s := &Service{}
+	ctx := context.Background()
+
+	send := func(ctx context.Context, msg Message) (*models.Message, error) {
+		return &models.Message{ID: 1, Text: msg.Text}, nil
+	}
+
+	// Without interceptors the message is sent as is
+	sent, err := s.intercept(ctx, &SendCall{Op: SendOpSend, ChatID: 1, Message: &Message{Text: "hi"}}, send)
+	require.NoError(t, err)
+	require.Equal(t, "hi", sent.Text)
+
+	var order []string
+
+	s.UseSendInterceptor(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, call *SendCall) error {
+			order = append(order, "outer")
+			call.Message.Text += " outer"
+
+			err := next(ctx, call)
+			if err == nil {
+				require.Equal(t, "hi outer inner", call.Result.Text)
+			}
+			order = append(order, "outer done")
+
+			return err
+		}
+	})
+	s.UseSendInterceptor(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, call *SendCall) error {
+			order = append(order, "inner")
+			call.Message.Text += " inner"
+
+			return next(ctx, call)
+		}
+	})
+
+	sent, err = s.intercept(ctx, &SendCall{Op: SendOpSend, ChatID: 1, Message: &Message{Text: "hi"}}, send)
+	require.NoError(t, err)
+	require.Equal(t, "hi outer inner", sent.Text)
+	require.Equal(t, []string{"outer", "inner", "outer done"}, order)
+
+	// An interceptor stops the message by not calling next
+	errBlocked := errors.New("blocked")
+	s.UseSendInterceptor(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, call *SendCall) error {
+			if call.Op == SendOpEdit {
+				return errBlocked
+			}
+
+			return next(ctx, call)
+		}
+	})
+
+	var called bool
+	_, err = s.intercept(ctx, &SendCall{Op: SendOpEdit, ChatID: 1, MessageID: 1, Message: &Message{Text: "hi"}},
+		func(ctx context.Context, msg Message) (*models.Message, error) {
+			called = true
+			return send(ctx, msg)
+		})
+	require.ErrorIs(t, err, errBlocked)
+	require.False(t, called)
+}
@@ -0,0 +1,288 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// joinCaptchaPrefix is the callback data prefix of the captcha button
+	joinCaptchaPrefix     = "joincaptcha:"
+	defaultCaptchaTimeout = 5 * time.Minute
+)
+
+// JoinDecision is what a JoinRequestPolicy decides for a join request
+type JoinDecision int
+
+const (
+	// JoinPending leaves the request open, to be approved or declined later
+	JoinPending JoinDecision = iota
+	JoinApprove
+	JoinDecline
+)
+
+// JoinRequestHandler receives join requests of chats the bot administrates
+type JoinRequestHandler func(ctx context.Context, req *models.ChatJoinRequest)
+
+// JoinRequestPolicy decides on join requests, the bot needs the invite users
+// right in the chat
+type JoinRequestPolicy func(ctx context.Context, req *models.ChatJoinRequest) JoinDecision
+
+// JoinCaptchaConfig configures the captcha policy
+type JoinCaptchaConfig struct {
+	// Text is sent to the user in a private chat, defaults to an English prompt
+	Text string
+	// Button is the text of the button that approves the request
+	Button string
+	// Timeout after which unanswered requests are declined, defaults to five
+	// minutes as the bot can only message the user for five minutes
+	Timeout time.Duration
+	// Approved is sent after pressing the button
+	Approved string
+}
+
+// ApproveJoinRequest approves the join request of the user
+func (s *Service) ApproveJoinRequest(ctx context.Context, chatID, userID int64) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.ApproveChatJoinRequest(ctx, &bot.ApproveChatJoinRequestParams{
+		ChatID: chatID,
+		UserID: userID,
+	}); err != nil {
+		return fmt.Errorf("approve chat join request: %w", err)
+	}
+
+	return nil
+}
+
+// DeclineJoinRequest declines the join request of the user
+func (s *Service) DeclineJoinRequest(ctx context.Context, chatID, userID int64) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.DeclineChatJoinRequest(ctx, &bot.DeclineChatJoinRequestParams{
+		ChatID: chatID,
+		UserID: userID,
+	}); err != nil {
+		return fmt.Errorf("decline chat join request: %w", err)
+	}
+
+	return nil
+}
+
+// OnJoinRequest registers a handler for join requests and returns a function
+// that removes it
+func (s *Service) OnJoinRequest(handler JoinRequestHandler) func() {
+	s.joins.mu.Lock()
+	defer s.joins.mu.Unlock()
+
+	if s.joins.handlers == nil {
+		s.joins.handlers = make(map[int]JoinRequestHandler)
+	}
+
+	id := s.joins.nextID
+	s.joins.nextID++
+	s.joins.handlers[id] = handler
+
+	return func() {
+		s.joins.mu.Lock()
+		defer s.joins.mu.Unlock()
+
+		delete(s.joins.handlers, id)
+	}
+}
+
+// SetJoinRequestPolicy sets the policy deciding on join requests, nil leaves
+// all requests to the handlers
+func (s *Service) SetJoinRequestPolicy(policy JoinRequestPolicy) {
+	s.joins.mu.Lock()
+	defer s.joins.mu.Unlock()
+
+	s.joins.policy = policy
+}
+
+// JoinCaptchaPolicy returns a policy that asks the user to press a button in a
+// private chat with the bot, the request is approved when they do and
+// declined after the timeout
+func (s *Service) JoinCaptchaPolicy(cfg JoinCaptchaConfig) JoinRequestPolicy {
+	if cfg.Text == "" {
+		cfg.Text = "Please confirm you are human to join the chat."
+	}
+	if cfg.Button == "" {
+		cfg.Button = "I'm human"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultCaptchaTimeout
+	}
+
+	s.joins.mu.Lock()
+	s.joins.captcha = cfg
+	s.joins.mu.Unlock()
+
+	return func(ctx context.Context, req *models.ChatJoinRequest) JoinDecision {
+		data, err := CallbackData(joinCaptchaPrefix, req.Chat.ID)
+		if err != nil {
+			s.logger.Error("failed to encode join captcha", slog.String("err", err.Error()))
+			return JoinPending
+		}
+
+		key := joinKey{chatID: req.Chat.ID, userID: req.From.ID}
+		chatID, userID := req.Chat.ID, req.From.ID
+
+		s.joins.addPending(key, time.AfterFunc(cfg.Timeout, func() {
+			if !s.joins.takePending(key) {
+				return
+			}
+
+			if err := s.DeclineJoinRequest(context.Background(), chatID, userID); err != nil {
+				s.logger.Error("failed to decline unanswered join request", slog.String("err", err.Error()))
+			}
+		}))
+
+		if _, err := s.SendContext(ctx, req.UserChatID, Message{
+			Text:    cfg.Text,
+			Buttons: []InlineButton{{Text: cfg.Button, CallbackData: data}},
+		}); err != nil {
+			s.logger.Error("failed to send join captcha", slog.String("err", err.Error()))
+		}
+
+		return JoinPending
+	}
+}
+
+// joinRequestMiddleware hands join requests to the handlers and the policy,
+// and handles presses of the captcha button
+func (s *Service) joinRequestMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if req := update.ChatJoinRequest; req != nil {
+				s.handleJoinRequest(ctx, req)
+			}
+
+			if query := update.CallbackQuery; query != nil && strings.HasPrefix(query.Data, joinCaptchaPrefix) {
+				s.handleJoinCaptcha(ctx, NewCallbackContext(b, query, joinCaptchaPrefix))
+				return
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func (s *Service) handleJoinRequest(ctx context.Context, req *models.ChatJoinRequest) {
+	s.joins.mu.RLock()
+	policy := s.joins.policy
+	handlers := make([]JoinRequestHandler, 0, len(s.joins.handlers))
+	for _, handler := range s.joins.handlers {
+		handlers = append(handlers, handler)
+	}
+	s.joins.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, req)
+	}
+
+	if policy == nil {
+		return
+	}
+
+	var err error
+	switch policy(ctx, req) {
+	case JoinApprove:
+		err = s.ApproveJoinRequest(ctx, req.Chat.ID, req.From.ID)
+	case JoinDecline:
+		err = s.DeclineJoinRequest(ctx, req.Chat.ID, req.From.ID)
+	}
+
+	if err != nil {
+		s.logger.Error("failed to apply join request policy",
+			slog.String("err", err.Error()),
+			slog.Int64("chat", req.Chat.ID),
+			slog.Int64("user", req.From.ID),
+		)
+	}
+}
+
+func (s *Service) handleJoinCaptcha(ctx context.Context, cb *CallbackContext) {
+	chatID, err := cb.Int(0)
+	if err != nil {
+		_ = cb.Answer(ctx, "")
+		return
+	}
+
+	if !s.joins.takePending(joinKey{chatID: chatID, userID: cb.UserID}) {
+		_ = cb.Answer(ctx, "This request has expired")
+		return
+	}
+
+	if err := s.ApproveJoinRequest(ctx, chatID, cb.UserID); err != nil {
+		s.logger.Error("failed to approve join request", slog.String("err", err.Error()))
+		_ = cb.Alert(ctx, "Something went wrong, please request to join again")
+		return
+	}
+
+	_ = cb.Answer(ctx, "")
+
+	s.joins.mu.RLock()
+	approved := s.joins.captcha.Approved
+	s.joins.mu.RUnlock()
+
+	if approved != "" && cb.ChatID != 0 {
+		if _, err := s.EditMessageContext(ctx, cb.ChatID, cb.MessageID, Message{Text: approved}); err != nil {
+			s.logger.Error("failed to edit join captcha", slog.String("err", err.Error()))
+		}
+	}
+}
+
+type joinKey struct {
+	chatID int64
+	userID int64
+}
+
+type joinState struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]JoinRequestHandler
+	policy   JoinRequestPolicy
+	captcha  JoinCaptchaConfig
+	pending  map[joinKey]*time.Timer
+}
+
+func (j *joinState) addPending(key joinKey, timer *time.Timer) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.pending == nil {
+		j.pending = make(map[joinKey]*time.Timer)
+	}
+
+	if old, ok := j.pending[key]; ok {
+		old.Stop()
+	}
+
+	j.pending[key] = timer
+}
+
+// takePending removes the pending captcha, it returns false if there was none
+func (j *joinState) takePending(key joinKey) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	timer, ok := j.pending[key]
+	if !ok {
+		return false
+	}
+
+	timer.Stop()
+	delete(j.pending, key)
+
+	return true
+}
@@ -0,0 +1,64 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinRequestMiddleware(t *testing.T) {
+	s := &Service{}
+
+	var requests []*models.ChatJoinRequest
+	remove := s.OnJoinRequest(func(ctx context.Context, req *models.ChatJoinRequest) {
+		requests = append(requests, req)
+	})
+
+	var decided int
+	s.SetJoinRequestPolicy(func(ctx context.Context, req *models.ChatJoinRequest) JoinDecision {
+		decided++
+		return JoinPending
+	})
+
+	var passed int
+	handler := s.joinRequestMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		passed++
+	})
+
+	handler(context.Background(), nil, &models.Update{ChatJoinRequest: &models.ChatJoinRequest{
+		Chat: models.Chat{ID: -100},
+		From: models.User{ID: 7},
+	}})
+	handler(context.Background(), nil, &models.Update{Message: &models.Message{Text: "hi"}})
+
+	require.Len(t, requests, 1)
+	require.Equal(t, int64(7), requests[0].From.ID)
+	require.Equal(t, 1, decided)
+	require.Equal(t, 2, passed)
+
+	remove()
+	handler(context.Background(), nil, &models.Update{ChatJoinRequest: &models.ChatJoinRequest{}})
+	require.Len(t, requests, 1)
+	require.Equal(t, 2, decided)
+}
+
+func TestJoinPending(t *testing.T) {
+	var j joinState
+
+	key := joinKey{chatID: -100, userID: 7}
+	require.False(t, j.takePending(key))
+
+	first := time.AfterFunc(time.Hour, func() {})
+	j.addPending(key, first)
+
+	// A new request of the same user replaces the pending captcha
+	j.addPending(key, time.AfterFunc(time.Hour, func() {}))
+	require.False(t, first.Stop())
+
+	require.True(t, j.takePending(key))
+	require.False(t, j.takePending(key))
+}
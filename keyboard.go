@@ -0,0 +1,95 @@
+package tgbot
+
+import (
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// KeyboardButton is a button of a reply keyboard, pressing it sends its text,
+// or the contact or location of the user when requested. Like InlineButton,
+// every button is a row unless Row is set.
+type KeyboardButton struct {
+	Text            string `json:"text,omitempty"`
+	RequestContact  bool   `json:"requestContact,omitempty"`
+	RequestLocation bool   `json:"requestLocation,omitempty"`
+	WebAppURL       string `json:"webAppUrl,omitempty"`
+
+	Row []KeyboardButton `json:"row,omitempty"`
+}
+
+// ReplyKeyboard is a custom keyboard shown in place of the system keyboard
+type ReplyKeyboard struct {
+	Buttons []KeyboardButton `json:"buttons,omitempty"`
+	// Resize fits the keyboard to the buttons instead of the system keyboard height
+	Resize bool `json:"resize,omitempty"`
+	// OneTime hides the keyboard after a button is pressed
+	OneTime bool `json:"oneTime,omitempty"`
+	// Persistent keeps the keyboard shown when the system keyboard is hidden
+	Persistent bool `json:"persistent,omitempty"`
+	// Placeholder is shown in the input field while the keyboard is active
+	Placeholder string `json:"placeholder,omitempty"`
+	// Selective only shows the keyboard to users mentioned in the text or
+	// the sender of the message replied to
+	Selective bool `json:"selective,omitempty"`
+}
+
+// KeyboardRow returns a row of plain text buttons
+func KeyboardRow(texts ...string) KeyboardButton {
+	row := make([]KeyboardButton, 0, len(texts))
+	for _, text := range texts {
+		row = append(row, KeyboardButton{Text: text})
+	}
+
+	return KeyboardButton{Row: row}
+}
+
+// createReplyMarkup returns the markup of a sent message. A message has one
+// markup, inline buttons take precedence over a reply keyboard.
+func createReplyMarkup(msg Message) any {
+	switch {
+	case len(msg.Buttons) > 0:
+		return createInlineKeyboard(msg)
+	case msg.Keyboard != nil && len(msg.Keyboard.Buttons) > 0:
+		return createReplyKeyboard(msg.Keyboard)
+	case msg.RemoveKeyboard:
+		return &models.ReplyKeyboardRemove{RemoveKeyboard: true}
+	}
+
+	return nil
+}
+
+func createReplyKeyboard(k *ReplyKeyboard) *models.ReplyKeyboardMarkup {
+	var rows [][]models.KeyboardButton
+
+	for _, button := range k.Buttons {
+		if len(button.Row) > 0 {
+			row := make([]models.KeyboardButton, 0, len(button.Row))
+			for _, btn := range button.Row {
+				row = append(row, keyboardButton(btn))
+			}
+
+			rows = append(rows, row)
+		} else {
+			rows = append(rows, []models.KeyboardButton{keyboardButton(button)})
+		}
+	}
+
+	return &models.ReplyKeyboardMarkup{
+		Keyboard:              rows,
+		IsPersistent:          k.Persistent,
+		ResizeKeyboard:        k.Resize,
+		OneTimeKeyboard:       k.OneTime,
+		InputFieldPlaceholder: k.Placeholder,
+		Selective:             k.Selective,
+	}
+}
+
+func keyboardButton(b KeyboardButton) models.KeyboardButton {
+	return models.KeyboardButton{
+		Text:            strings.TrimSpace(b.Text),
+		RequestContact:  b.RequestContact,
+		RequestLocation: b.RequestLocation,
+		WebApp:          createWebAppInfo(b.WebAppURL),
+	}
+}
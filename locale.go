@@ -0,0 +1,328 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// DefaultTimeLayout is the layout used by FormatTime
+	DefaultTimeLayout = "2 Jan 2006 15:04 MST"
+
+	timezoneCommand        = "/timezone"
+	timezoneCallbackPrefix = "tgbot_tz:"
+	timezonePromptText     = "🌍 Pick your timezone, or reply with its name (e.g. Europe/Amsterdam) or UTC offset (e.g. UTC+2)."
+)
+
+// ErrInvalidTimezone is returned for timezones that are not an IANA name or UTC offset
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// promptTimezones are the timezones offered as buttons by the timezone prompt
+var promptTimezones = []string{
+	"America/Los_Angeles", "America/New_York", "America/Sao_Paulo",
+	"Europe/London", "Europe/Berlin", "Europe/Moscow",
+	"Asia/Dubai", "Asia/Kolkata", "Asia/Singapore",
+	"Asia/Tokyo", "Australia/Sydney", "UTC",
+}
+
+// Locale holds the language and timezone of a user
+type Locale struct {
+	LanguageCode string
+	Timezone     string
+}
+
+// LocaleStore persists the locale per user. Locales are keyed by user ID,
+// which equals the chat ID for private chats.
+type LocaleStore interface {
+	GetLocale(userID int64) (Locale, bool, error)
+	SetLocale(userID int64, locale Locale) error
+}
+
+// MemoryLocaleStore is an in-memory LocaleStore
+type MemoryLocaleStore struct {
+	mu      sync.RWMutex
+	locales map[int64]Locale
+}
+
+var _ LocaleStore = (*MemoryLocaleStore)(nil)
+
+// NewMemoryLocaleStore creates a new in-memory locale store
+func NewMemoryLocaleStore() *MemoryLocaleStore {
+	return &MemoryLocaleStore{locales: make(map[int64]Locale)}
+}
+
+func (m *MemoryLocaleStore) GetLocale(userID int64) (Locale, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	locale, ok := m.locales[userID]
+	return locale, ok, nil
+}
+
+func (m *MemoryLocaleStore) SetLocale(userID int64, locale Locale) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.locales[userID] = locale
+	return nil
+}
+
+// localeState tracks the chats that were prompted for their timezone
+type localeState struct {
+	mu      sync.Mutex
+	pending map[int64]struct{}
+}
+
+// Locale returns the stored locale of a user
+func (s *Service) Locale(userID int64) Locale {
+	locale, _, err := s.cfg.Locales.GetLocale(userID)
+	if err != nil {
+		s.logger.Error("failed to get locale",
+			slog.String("err", err.Error()),
+			slog.Int64("user", userID),
+		)
+	}
+
+	return locale
+}
+
+// SetTimezone validates and stores the timezone of a user, either an IANA
+// name like Europe/Amsterdam or a UTC offset like UTC+2 or +05:30.
+func (s *Service) SetTimezone(userID int64, timezone string) error {
+	timezone = strings.TrimSpace(timezone)
+	if _, err := ParseTimezone(timezone); err != nil {
+		return err
+	}
+
+	locale, _, err := s.cfg.Locales.GetLocale(userID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	locale.Timezone = timezone
+	if err := s.cfg.Locales.SetLocale(userID, locale); err != nil {
+		return fmt.Errorf("set locale: %w", err)
+	}
+
+	return nil
+}
+
+// Location returns the time location of a chat, UTC if no timezone is known
+func (s *Service) Location(chatID int64) *time.Location {
+	locale := s.Locale(chatID)
+	if len(locale.Timezone) == 0 {
+		return time.UTC
+	}
+
+	loc, err := ParseTimezone(locale.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// FormatTime renders the time in the timezone of the chat
+func (s *Service) FormatTime(chatID int64, t time.Time) string {
+	return t.In(s.Location(chatID)).Format(DefaultTimeLayout)
+}
+
+// PromptTimezone asks the user to pick a timezone, the answer is handled by
+// the locale middleware. Users can also start the prompt with /timezone if
+// Config.TimezonePrompt is enabled.
+func (s *Service) PromptTimezone(chatID int64) error {
+	var buttons []InlineButton
+	for i := 0; i < len(promptTimezones); i += 3 {
+		row := InlineButton{}
+		for _, tz := range promptTimezones[i:min(i+3, len(promptTimezones))] {
+			row.Row = append(row.Row, InlineButton{
+				Text:         tz[strings.LastIndex(tz, "/")+1:],
+				CallbackData: timezoneCallbackPrefix + tz,
+			})
+		}
+		buttons = append(buttons, row)
+	}
+
+	s.locale.mu.Lock()
+	if s.locale.pending == nil {
+		s.locale.pending = make(map[int64]struct{})
+	}
+	s.locale.pending[chatID] = struct{}{}
+	s.locale.mu.Unlock()
+
+	if _, err := s.Send(chatID, Message{Text: timezonePromptText, Buttons: buttons}); err != nil {
+		return fmt.Errorf("send timezone prompt: %w", err)
+	}
+
+	return nil
+}
+
+// ParseTimezone parses an IANA timezone name or a UTC offset
+func ParseTimezone(timezone string) (*time.Location, error) {
+	offset := strings.TrimPrefix(strings.ToUpper(timezone), "UTC")
+	offset = strings.TrimPrefix(offset, "GMT")
+
+	if len(offset) == 0 || (offset[0] != '+' && offset[0] != '-') {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil || len(timezone) == 0 || timezone == "Local" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+		}
+
+		return loc, nil
+	}
+
+	hours, minutes, hasMinutes := strings.Cut(offset[1:], ":")
+
+	// Atoi accepts a sign, which would allow offsets like "+-3"
+	h, err := strconv.Atoi(hours)
+	if err != nil || !isDigits(hours) || h > 14 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+	}
+
+	var m int
+	if hasMinutes {
+		if m, err = strconv.Atoi(minutes); err != nil || !isDigits(minutes) || m >= 60 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+		}
+	}
+
+	seconds := h*3600 + m*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+
+	return time.FixedZone("UTC"+offset, seconds), nil
+}
+
+// isDigits reports whether s only contains ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// localeMiddleware records the language of users and handles the timezone prompt
+func (s *Service) localeMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if user := UpdateUser(update); user != nil && !user.IsBot {
+				s.recordLanguage(user)
+			}
+
+			if s.handleTimezone(ctx, b, update) {
+				return
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func (s *Service) recordLanguage(user *models.User) {
+	if len(user.LanguageCode) == 0 {
+		return
+	}
+
+	locale, _, err := s.cfg.Locales.GetLocale(user.ID)
+	if err != nil || locale.LanguageCode == user.LanguageCode {
+		return
+	}
+
+	locale.LanguageCode = user.LanguageCode
+	if err := s.cfg.Locales.SetLocale(user.ID, locale); err != nil {
+		s.logger.Error("failed to store locale",
+			slog.String("err", err.Error()),
+			slog.Int64("user", user.ID),
+		)
+	}
+}
+
+// handleTimezone handles the timezone prompt, it returns true if the update
+// was consumed.
+func (s *Service) handleTimezone(ctx context.Context, b *bot.Bot, update *models.Update) bool {
+	if query := update.CallbackQuery; query != nil {
+		timezone, ok := strings.CutPrefix(query.Data, timezoneCallbackPrefix)
+		if !ok {
+			return false
+		}
+
+		text := "Timezone set to " + timezone
+		if err := s.SetTimezone(query.From.ID, timezone); err != nil {
+			text = "Invalid timezone"
+		}
+
+		s.clearTimezonePrompt(query.From.ID)
+
+		if _, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            text,
+		}); err != nil {
+			s.logger.Error("failed to answer timezone callback",
+				slog.String("err", err.Error()),
+			)
+		}
+
+		return true
+	}
+
+	msg := update.Message
+	if msg == nil || msg.From == nil || msg.Chat.Type != ChatTypePrivate {
+		return false
+	}
+
+	if s.cfg.TimezonePrompt && msg.Text == timezoneCommand {
+		if err := s.PromptTimezone(msg.Chat.ID); err != nil {
+			s.logger.Error("failed to prompt timezone",
+				slog.String("err", err.Error()),
+			)
+		}
+		return true
+	}
+
+	s.locale.mu.Lock()
+	_, pending := s.locale.pending[msg.Chat.ID]
+	s.locale.mu.Unlock()
+
+	if !pending || isCommand(msg.Text) {
+		return false
+	}
+
+	if err := s.SetTimezone(msg.From.ID, msg.Text); err != nil {
+		if _, err := s.Send(msg.Chat.ID, Message{Text: "Unknown timezone, try a name like Europe/Amsterdam or an offset like UTC+2."}); err != nil {
+			s.logger.Error("failed to send timezone reply",
+				slog.String("err", err.Error()),
+			)
+		}
+		return true
+	}
+
+	s.clearTimezonePrompt(msg.Chat.ID)
+
+	if _, err := s.Send(msg.Chat.ID, Message{Text: "Timezone set, it is now " + s.FormatTime(msg.From.ID, time.Now())}); err != nil {
+		s.logger.Error("failed to send timezone reply",
+			slog.String("err", err.Error()),
+		)
+	}
+
+	return true
+}
+
+func (s *Service) clearTimezonePrompt(chatID int64) {
+	s.locale.mu.Lock()
+	defer s.locale.mu.Unlock()
+
+	delete(s.locale.pending, chatID)
+}
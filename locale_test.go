@@ -0,0 +1,45 @@
+package tgbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimezone(t *testing.T) {
+	tests := []struct {
+		in     string
+		offset int
+		err    bool
+	}{
+		{"UTC", 0, false},
+		{"Asia/Tokyo", 9 * 3600, false},
+		{"UTC+2", 2 * 3600, false},
+		{"utc-03:30", -(3*3600 + 30*60), false},
+		{"+05:30", 5*3600 + 30*60, false},
+		{"", 0, true},
+		{"Local", 0, true},
+		{"Mars/Olympus", 0, true},
+		{"UTC+20", 0, true},
+		{"+-3", 0, true},
+		{"UTC+-3", 0, true},
+		{"-+3", 0, true},
+		{"+3:-30", 0, true},
+		{"+3:", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			loc, err := ParseTimezone(tt.in)
+			if tt.err {
+				require.ErrorIs(t, err, ErrInvalidTimezone)
+				return
+			}
+
+			require.NoError(t, err)
+			_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone()
+			require.Equal(t, tt.offset, offset)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	minLivePeriod = time.Minute
+	maxLivePeriod = 24 * time.Hour
+
+	// LivePeriodForever keeps a live location active until it is stopped
+	LivePeriodForever = time.Duration(0x7FFFFFFF) * time.Second
+)
+
+// Location is a point on the map, a live location when LivePeriod is set
+type Location struct {
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	// Accuracy is the radius of uncertainty in meters, 0 to 1500
+	Accuracy float64 `json:"accuracy,omitempty"`
+	// LivePeriod makes the location live for 1 minute to 24 hours, or
+	// LivePeriodForever. On edits it extends the period of the live location.
+	LivePeriod time.Duration `json:"livePeriod,omitempty"`
+	// Heading is the direction of movement in degrees, 1 to 360, live only
+	Heading int `json:"heading,omitempty"`
+	// ProximityAlertRadius in meters, live only
+	ProximityAlertRadius int `json:"proximityAlertRadius,omitempty"`
+}
+
+// Venue is a named place with an address
+type Venue struct {
+	Location Location `json:"location"`
+	Title    string   `json:"title,omitempty"`
+	Address  string   `json:"address,omitempty"`
+
+	FoursquareID    string `json:"foursquareId,omitempty"`
+	FoursquareType  string `json:"foursquareType,omitempty"`
+	GooglePlaceID   string `json:"googlePlaceId,omitempty"`
+	GooglePlaceType string `json:"googlePlaceType,omitempty"`
+}
+
+// Contact is a phone contact, VCard holds additional data in vCard format
+type Contact struct {
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+	FirstName   string `json:"firstName,omitempty"`
+	LastName    string `json:"lastName,omitempty"`
+	VCard       string `json:"vCard,omitempty"`
+}
+
+// EditLiveLocation moves a live location sent by the bot
+func (s *Service) EditLiveLocation(ctx context.Context, chatID int64, msgID int, loc Location) (*models.Message, error) {
+	s.ratelimit.take(chatID)
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	msg, err := s.bot.EditMessageLiveLocation(ctx, &bot.EditMessageLiveLocationParams{
+		ChatID:               chatID,
+		MessageID:            msgID,
+		Latitude:             loc.Latitude,
+		Longitude:            loc.Longitude,
+		LivePeriod:           loc.livePeriod(),
+		HorizontalAccuracy:   loc.Accuracy,
+		Heading:              loc.Heading,
+		ProximityAlertRadius: loc.ProximityAlertRadius,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("edit live location: %w", err)
+	}
+
+	return msg, nil
+}
+
+// StopLiveLocation stops updating a live location before its period ends
+func (s *Service) StopLiveLocation(ctx context.Context, chatID int64, msgID int) (*models.Message, error) {
+	s.ratelimit.take(chatID)
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	msg, err := s.bot.StopMessageLiveLocation(ctx, &bot.StopMessageLiveLocationParams{
+		ChatID:    chatID,
+		MessageID: msgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("stop live location: %w", err)
+	}
+
+	return msg, nil
+}
+
+func (l Location) validate() error {
+	if l.Latitude < -90 || l.Latitude > 90 || l.Longitude < -180 || l.Longitude > 180 {
+		return fmt.Errorf("invalid coordinates %f, %f", l.Latitude, l.Longitude)
+	}
+
+	if l.LivePeriod != 0 && l.LivePeriod != LivePeriodForever &&
+		(l.LivePeriod < minLivePeriod || l.LivePeriod > maxLivePeriod) {
+		return fmt.Errorf("live period %s out of range", l.LivePeriod)
+	}
+
+	return nil
+}
+
+func (l Location) livePeriod() int {
+	return int(l.LivePeriod / time.Second)
+}
@@ -0,0 +1,106 @@
+package tgbot
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const defaultMaintenanceMessage = "🛠 The bot is under maintenance, please try again in a few minutes."
+
+type maintenanceState struct {
+	mu      sync.RWMutex
+	on      bool
+	message string
+}
+
+// SetMaintenance turns maintenance mode on or off. While on, all updates are
+// short-circuited with the maintenance message, except for the admins listed
+// in Config.Admins. An empty message uses the default maintenance message.
+func (s *Service) SetMaintenance(on bool, message string) {
+	s.maintenance.mu.Lock()
+	defer s.maintenance.mu.Unlock()
+
+	if len(message) == 0 {
+		message = defaultMaintenanceMessage
+	}
+
+	s.maintenance.on = on
+	s.maintenance.message = message
+
+	s.logger.Info("maintenance mode changed",
+		slog.Bool("on", on),
+		slog.String("bot", s.username),
+	)
+}
+
+// InMaintenance returns true if maintenance mode is on
+func (s *Service) InMaintenance() bool {
+	s.maintenance.mu.RLock()
+	defer s.maintenance.mu.RUnlock()
+
+	return s.maintenance.on
+}
+
+// IsAdmin returns true if the user is listed in Config.Admins
+func (s *Service) IsAdmin(userID int64) bool {
+	return slices.Contains(s.cfg.Admins, userID)
+}
+
+func (s *Service) maintenanceMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			s.maintenance.mu.RLock()
+			on, message := s.maintenance.on, s.maintenance.message
+			s.maintenance.mu.RUnlock()
+
+			if !on {
+				next(ctx, b, update)
+				return
+			}
+
+			if user := UpdateUser(update); user != nil && s.IsAdmin(user.ID) {
+				next(ctx, b, update)
+				return
+			}
+
+			s.replyMaintenance(ctx, b, update, message)
+		}
+	}
+}
+
+// replyMaintenance answers private messages, commands and button presses with
+// the maintenance message, other updates are dropped silently.
+func (s *Service) replyMaintenance(ctx context.Context, b *bot.Bot, update *models.Update, message string) {
+	if update.CallbackQuery != nil {
+		if _, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: update.CallbackQuery.ID,
+			Text:            message,
+			ShowAlert:       true,
+		}); err != nil {
+			s.logger.Error("failed to answer callback in maintenance mode",
+				slog.String("err", err.Error()),
+			)
+		}
+		return
+	}
+
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	if msg.Chat.Type != ChatTypePrivate && !isCommand(msg.Text) {
+		return
+	}
+
+	if _, err := s.Send(msg.Chat.ID, Message{Text: message, ReplyTo: msg.ID}); err != nil {
+		s.logger.Error("failed to send maintenance message",
+			slog.String("err", err.Error()),
+		)
+	}
+}
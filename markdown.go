@@ -0,0 +1,383 @@
+package tgbot
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot/models"
+)
+
+const markdownBullet = "• "
+
+// ParseMarkdown converts standard Markdown, as written by people, templates
+// and language models, to plain text with entities. Unlike MarkdownV2 nothing
+// has to be escaped, text that isn't valid formatting stays as it is instead
+// of failing the message.
+//
+// Supported are bold, italic, strikethrough (~~), spoilers (||), code spans,
+// fenced code blocks, links, headings (bold), blockquotes and bullet lists.
+// Inline formatting does not span lines.
+func ParseMarkdown(markdown string) *TextBuilder {
+	b := NewText()
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if i > 0 {
+			b.Plain("\n")
+		}
+
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		switch {
+		case indent < 4 && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			i = markdownCodeBlock(b, lines, i, trimmed)
+
+		case markdownHeading(trimmed) > 0:
+			text := strings.TrimSpace(trimmed[markdownHeading(trimmed):])
+			b.span(func() { markdownInline(b, text) }, models.MessageEntity{Type: models.MessageEntityTypeBold})
+
+		case strings.HasPrefix(trimmed, ">"):
+			i = markdownQuote(b, lines, i)
+
+		case markdownRule(trimmed):
+			b.Plain("———")
+
+		case len(trimmed) > 1 && strings.IndexByte("-*+", trimmed[0]) >= 0 && trimmed[1] == ' ':
+			b.Plain(line[:indent] + markdownBullet)
+			markdownInline(b, strings.TrimLeft(trimmed[2:], " "))
+
+		default:
+			markdownInline(b, line)
+		}
+	}
+
+	return b
+}
+
+// markdownCodeBlock writes the fenced code block starting at line i and
+// returns the index of its closing fence, an unclosed block runs to the end
+func markdownCodeBlock(b *TextBuilder, lines []string, i int, opening string) int {
+	fence := opening[:runLength(opening, 0, opening[0])]
+	language := strings.TrimSpace(opening[len(fence):])
+
+	var code []string
+
+	end := i + 1
+	for ; end < len(lines); end++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[end]), fence) {
+			break
+		}
+
+		code = append(code, lines[end])
+	}
+
+	b.Pre(strings.Join(code, "\n"), language)
+
+	return min(end, len(lines)-1)
+}
+
+// markdownQuote writes the blockquote starting at line i and returns the
+// index of its last line
+func markdownQuote(b *TextBuilder, lines []string, i int) int {
+	end := i
+
+	b.span(func() {
+		for ; end < len(lines); end++ {
+			line := strings.TrimLeft(lines[end], " ")
+			if !strings.HasPrefix(line, ">") {
+				break
+			}
+
+			if end > i {
+				b.Plain("\n")
+			}
+
+			line = strings.TrimPrefix(line[1:], " ")
+			markdownInline(b, strings.TrimRight(line, " \t"))
+		}
+	}, models.MessageEntity{Type: entityTypeBlockquote})
+
+	return end - 1
+}
+
+// markdownHeading returns the length of the heading marker of the line, zero
+// if it is no heading
+func markdownHeading(line string) int {
+	n := runLength(line, 0, '#')
+	if n == 0 || n > 6 {
+		return 0
+	}
+
+	if n < len(line) && line[n] != ' ' {
+		return 0
+	}
+
+	return n
+}
+
+// markdownRule reports whether the line is a horizontal rule like --- or ***
+func markdownRule(line string) bool {
+	line = strings.ReplaceAll(line, " ", "")
+	if len(line) < 3 || strings.IndexByte("-*_", line[0]) < 0 {
+		return false
+	}
+
+	return runLength(line, 0, line[0]) == len(line)
+}
+
+// markdownInline writes the text with its inline formatting
+func markdownInline(b *TextBuilder, s string) {
+	var plain strings.Builder
+
+	flush := func() {
+		if plain.Len() > 0 {
+			b.Plain(plain.String())
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			plain.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			n := runLength(s, i, '`')
+
+			if end := codeSpanEnd(s, i+n, n); end >= 0 {
+				flush()
+				b.Code(trimCodeSpan(s[i+n : end]))
+				i = end + n
+				continue
+			}
+
+			plain.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case c == '[' || (c == '!' && strings.HasPrefix(s[i+1:], "[")):
+			start := i
+			if c == '!' {
+				start++
+			}
+
+			if text, url, end, ok := markdownLink(s, start); ok {
+				flush()
+				b.span(func() { markdownInline(b, text) }, models.MessageEntity{Type: models.MessageEntityTypeTextLink, URL: url})
+				i = end
+				continue
+			}
+
+		case c == '<':
+			// Autolinks are written as they are, Telegram detects the URL
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				link := s[i+1 : i+end]
+				if !strings.ContainsAny(link, " <") && (strings.Contains(link, "://") || strings.HasPrefix(link, "mailto:")) {
+					plain.WriteString(link)
+					i += end + 1
+					continue
+				}
+			}
+
+		case strings.IndexByte("*_~|", c) >= 0:
+			n := runLength(s, i, c)
+
+			if entity, size, end, ok := markdownEmphasis(s, i, n); ok {
+				flush()
+				b.span(func() { markdownInline(b, s[i+size:end]) }, models.MessageEntity{Type: entity})
+				i = end + size
+				continue
+			}
+
+			plain.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+
+		plain.WriteByte(c)
+		i++
+	}
+
+	flush()
+}
+
+// markdownEmphasis matches the delimiter run of n characters at i with its
+// closing run. It returns the entity, the size of the delimiter and the
+// start of the closing delimiter.
+func markdownEmphasis(s string, i, n int) (models.MessageEntityType, int, int, bool) {
+	c := s[i]
+
+	var (
+		entity models.MessageEntityType
+		size   = min(n, 2)
+	)
+
+	switch {
+	case c == '~' && n >= 2:
+		entity = models.MessageEntityTypeStrikethrough
+	case c == '|' && n >= 2:
+		entity = entityTypeSpoiler
+	case (c == '*' || c == '_') && size == 2:
+		entity = models.MessageEntityTypeBold
+	case c == '*' || c == '_':
+		entity = models.MessageEntityTypeItalic
+	default:
+		return "", 0, 0, false
+	}
+
+	// The opener must be followed by text, underscores within words like
+	// snake_case are no emphasis
+	if i+size >= len(s) || isSpace(s, i+size) || (c == '_' && i > 0 && isWordChar(s, i-1)) {
+		return "", 0, 0, false
+	}
+
+	start := i + size
+	for k := start; k < len(s); {
+		switch s[k] {
+		case '\\':
+			k += 2
+			continue
+		case '`':
+			m := runLength(s, k, '`')
+			if end := codeSpanEnd(s, k+m, m); end >= 0 {
+				k = end + m
+				continue
+			}
+
+			k += m
+			continue
+		case c:
+		default:
+			k++
+			continue
+		}
+
+		m := runLength(s, k, c)
+
+		// Runs of the other emphasis, like ** within *italic*, are nested
+		if m < size || (m == 2 && size == 1) || (c != '*' && c != '_' && m != size) {
+			k += m
+			continue
+		}
+
+		end := k + m - size
+		if end > start && !isSpace(s, end-1) && (c != '_' || end+size >= len(s) || !isWordChar(s, end+size)) {
+			return entity, size, end, true
+		}
+
+		k += m
+	}
+
+	return "", 0, 0, false
+}
+
+// markdownLink parses [text](url) starting at the opening bracket, it returns
+// the text, URL and the end of the link
+func markdownLink(s string, start int) (string, string, int, bool) {
+	closing := matchingBracket(s, start, '[', ']')
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return "", "", 0, false
+	}
+
+	end := matchingBracket(s, closing+1, '(', ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+
+	// Titles like [a](https://x.com "title") are dropped
+	url := strings.TrimSpace(s[closing+2 : end])
+	if j := strings.IndexAny(url, " \t"); j >= 0 {
+		url = url[:j]
+	}
+	url = strings.TrimSuffix(strings.TrimPrefix(url, "<"), ">")
+
+	if url == "" {
+		return "", "", 0, false
+	}
+
+	return s[start+1 : closing], url, end + 1, true
+}
+
+// matchingBracket returns the index of the bracket closing the one at start,
+// -1 if it isn't closed
+func matchingBracket(s string, start int, open, closing byte) int {
+	depth := 0
+
+	for k := start; k < len(s); k++ {
+		switch s[k] {
+		case '\\':
+			k++
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return k
+			}
+		}
+	}
+
+	return -1
+}
+
+// codeSpanEnd returns the start of the backtick run of length n closing a
+// code span, -1 if there is none
+func codeSpanEnd(s string, from, n int) int {
+	for k := from; k < len(s); {
+		if s[k] != '`' {
+			k++
+			continue
+		}
+
+		m := runLength(s, k, '`')
+		if m == n {
+			return k
+		}
+
+		k += m
+	}
+
+	return -1
+}
+
+// trimCodeSpan strips the spaces that pad code starting or ending with a
+// backtick
+func trimCodeSpan(code string) string {
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+		return code[1 : len(code)-1]
+	}
+
+	return code
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+
+	return n
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isSpace(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsSpace(r)
+}
+
+// isWordChar reports whether the character ending at byte i is a letter or
+// digit
+func isWordChar(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i+1])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
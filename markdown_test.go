@@ -0,0 +1,79 @@
+package tgbot
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	bold := func(offset, length int) models.MessageEntity {
+		return models.MessageEntity{Type: models.MessageEntityTypeBold, Offset: offset, Length: length}
+	}
+	italic := func(offset, length int) models.MessageEntity {
+		return models.MessageEntity{Type: models.MessageEntityTypeItalic, Offset: offset, Length: length}
+	}
+
+	tests := []struct {
+		name     string
+		in       string
+		text     string
+		entities []models.MessageEntity
+	}{
+		{"plain", "Hello world. 1 + 1 = 2!", "Hello world. 1 + 1 = 2!", nil},
+		{"bold and italic", "**bold** and *it* or _it_", "bold and it or it", []models.MessageEntity{bold(0, 4), italic(9, 2), italic(15, 2)}},
+		{"nested", "**a *b* c**", "a b c", []models.MessageEntity{bold(0, 5), italic(2, 1)}},
+		{"bold italic", "***x***", "x", []models.MessageEntity{bold(0, 1), italic(0, 1)}},
+		{"snake case", "use snake_case_names", "use snake_case_names", nil},
+		{"unclosed", "price 5*3 and **open", "price 5*3 and **open", nil},
+		{"escaped", `\*not italic\*`, "*not italic*", nil},
+		{"code", "run `a_b *c*` now", "run a_b *c* now", []models.MessageEntity{{Type: models.MessageEntityTypeCode, Offset: 4, Length: 7}}},
+		{"link", "see [the **docs**](https://x.com/a_b \"title\")", "see the docs", []models.MessageEntity{
+			{Type: models.MessageEntityTypeTextLink, Offset: 4, Length: 8, URL: "https://x.com/a_b"},
+			bold(8, 4),
+		}},
+		{"autolink", "<https://x.com>", "https://x.com", nil},
+		{"strike and spoiler", "~~old~~ ||secret||", "old secret", []models.MessageEntity{
+			{Type: models.MessageEntityTypeStrikethrough, Offset: 0, Length: 3},
+			{Type: entityTypeSpoiler, Offset: 4, Length: 6},
+		}},
+		{"heading and list", "# Title\n- one\n  * two", "Title\n• one\n  • two", []models.MessageEntity{bold(0, 5)}},
+		{"code block", "```go\nx := *y\n```\nafter", "x := *y\nafter", []models.MessageEntity{
+			{Type: models.MessageEntityTypePre, Offset: 0, Length: 7, Language: "go"},
+		}},
+		{"quote", "> a\n> **b**\nc", "a\nb\nc", []models.MessageEntity{
+			{Type: entityTypeBlockquote, Offset: 0, Length: 3},
+			bold(2, 1),
+		}},
+		{"rule", "a\n---\nb", "a\n———\nb", nil},
+		{"utf16", "😀 **x**", "😀 x", []models.MessageEntity{bold(3, 1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := ParseMarkdown(tt.in)
+			require.Equal(t, tt.text, text.String())
+
+			entities := text.Entities()
+			if len(tt.entities) == 0 {
+				require.Empty(t, entities)
+				return
+			}
+			require.Equal(t, tt.entities, entities)
+		})
+	}
+}
+
+func TestConvertMarkdown(t *testing.T) {
+	msg := Message{Text: "**hi**.", ParseMode: ParseModeCommonMark}.convertMarkdown()
+
+	require.Equal(t, "hi.", msg.Text)
+	require.Len(t, msg.Entities, 1)
+	require.Equal(t, "hi.", msg.escapeText())
+	require.Equal(t, models.ParseMode(""), msg.parseMode())
+
+	// Without formatting the text is still not escaped
+	msg = Message{Text: "a.b", ParseMode: ParseModeCommonMark}.convertMarkdown()
+	require.Equal(t, "a.b", msg.escapeText())
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/go-telegram/bot"
@@ -13,76 +12,198 @@ import (
 )
 
 func (s *Service) DownloadFile(fileID any) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	return s.DownloadFileContext(context.Background(), fileID)
+}
+
+// DownloadFileContext is DownloadFile with a context
+func (s *Service) DownloadFileContext(ctx context.Context, fileID any) ([]byte, error) {
+	ctx, cancel := withDefaultTimeout(ctx, time.Second*15)
 	defer cancel()
 
+	return s.downloadFileByID(ctx, fmt.Sprintf("%v", fileID))
+}
+
+// DownloadLargestPhoto downloads the highest resolution version of the photo
+// attached to the message of the update.
+func (s *Service) DownloadLargestPhoto(ctx context.Context, update *models.Update) ([]byte, error) {
+	msg := UpdateMessage(update)
+	if msg == nil {
+		return nil, ErrNoMessage
+	}
+
+	photo := LargestPhoto(msg.Photo)
+	if photo == nil {
+		return nil, ErrNoPhoto
+	}
+
+	return s.downloadFileByID(ctx, photo.FileID)
+}
+
+// DownloadDocument downloads the document attached to the message of the update.
+func (s *Service) DownloadDocument(ctx context.Context, update *models.Update) ([]byte, error) {
+	msg := UpdateMessage(update)
+	if msg == nil {
+		return nil, ErrNoMessage
+	}
+
+	if msg.Document == nil {
+		return nil, ErrNoDocument
+	}
+
+	return s.downloadFileByID(ctx, msg.Document.FileID)
+}
+
+// DownloadMedia downloads whichever media is attached to the message of the
+// update, picking the largest photo size for photos.
+func (s *Service) DownloadMedia(ctx context.Context, update *models.Update) ([]byte, error) {
+	msg := UpdateMessage(update)
+	if msg == nil {
+		return nil, ErrNoMessage
+	}
+
+	fileID := MediaFileID(msg)
+	if len(fileID) == 0 {
+		return nil, ErrNoMedia
+	}
+
+	return s.downloadFileByID(ctx, fileID)
+}
+
+// MediaFileID returns the file ID of the media attached to the message, or an
+// empty string if the message has no media.
+func MediaFileID(msg *models.Message) string {
+	switch {
+	case msg == nil:
+		return ""
+	case len(msg.Photo) > 0:
+		return LargestPhoto(msg.Photo).FileID
+	case msg.Document != nil:
+		return msg.Document.FileID
+	case msg.Video != nil:
+		return msg.Video.FileID
+	case msg.Audio != nil:
+		return msg.Audio.FileID
+	case msg.Voice != nil:
+		return msg.Voice.FileID
+	case msg.VideoNote != nil:
+		return msg.VideoNote.FileID
+	case msg.Animation != nil:
+		return msg.Animation.FileID
+	case msg.Sticker != nil:
+		return msg.Sticker.FileID
+	}
+
+	return ""
+}
+
+// LargestPhoto returns the photo size with the highest resolution, or nil if
+// there are no photo sizes.
+func LargestPhoto(photos []models.PhotoSize) *models.PhotoSize {
+	var best *models.PhotoSize
+	for i := range photos {
+		if best == nil || photos[i].Width*photos[i].Height > best.Width*best.Height {
+			best = &photos[i]
+		}
+	}
+
+	return best
+}
+
+func (s *Service) downloadFileByID(ctx context.Context, fileID string) ([]byte, error) {
+	return s.downloadCachedFile(ctx, fileID, fileCacheKey(fileID))
+}
+
+// downloadCachedFile downloads the file and caches it under key
+func (s *Service) downloadCachedFile(ctx context.Context, fileID, key string) ([]byte, error) {
+	if body, ok := s.cachedFile(ctx, key); ok {
+		return body, nil
+	}
+
 	file, err := s.bot.GetFile(ctx, &bot.GetFileParams{
-		FileID: fmt.Sprintf("%v", fileID),
+		FileID: fileID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("get file: %w", err)
 	}
 
-	body, err := s.downloadFile(fmt.Sprintf("https://api.telegram.org/file/bot%s/%s", s.cfg.Token, file.FilePath))
+	body, err := s.fetchFile(ctx, file.FilePath)
 	if err != nil {
 		return nil, fmt.Errorf("download file: %w", err)
 	}
 
+	s.cacheFile(ctx, key, body)
+
 	return body, nil
 }
 
 func (s *Service) GetProfilePhoto(chatID int64) ([]byte, error) {
-	var fileID string
-	p, err := s.bot.GetUserProfilePhotos(context.Background(), &bot.GetUserProfilePhotosParams{
+	return s.GetProfilePhotoContext(context.Background(), chatID)
+}
+
+// GetProfilePhotoContext is GetProfilePhoto with a context. The current
+// photo is looked up every time, so changes show right away, only the file
+// is cached.
+func (s *Service) GetProfilePhotoContext(ctx context.Context, chatID int64) ([]byte, error) {
+	fileID, uniqueID, err := s.lookupProfilePhoto(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file ID of the same photo changes, its unique ID doesn't
+	key := fileCacheKey(fileID)
+	if uniqueID != "" {
+		key = uniqueFileCacheKey(uniqueID)
+	}
+
+	return s.downloadCachedFile(ctx, fileID, key)
+}
+
+// lookupProfilePhoto returns the file ID and unique file ID of the current
+// profile photo of the chat
+func (s *Service) lookupProfilePhoto(ctx context.Context, chatID int64) (string, string, error) {
+	var fileID, uniqueID string
+	p, err := s.bot.GetUserProfilePhotos(ctx, &bot.GetUserProfilePhotosParams{
 		UserID: chatID,
 		Limit:  1,
 	})
 	if err != nil {
-		if strings.Contains(strings.ToLower(err.Error()), "user not found") {
-			return nil, errors.New("user not found")
+		if errors.Is(apiError(err), ErrUserNotFound) {
+			return "", "", ErrUserNotFound
 		}
 
-		// return nil, fmt.Errorf("get user profile photos: %w", err)
+		// return "", fmt.Errorf("get user profile photos: %w", err)
 		s.logger.Warn("Failed to get user profile photos", slog.String("err", err.Error()))
 		chat, err := s.GetChat(chatID)
 		if err != nil {
-			return nil, fmt.Errorf("get chat: %w", err)
+			return "", "", fmt.Errorf("get chat: %w", err)
 		}
 
 		if chat.Photo == nil {
-			return nil, errors.New("no photos found")
+			return "", "", ErrNoProfilePhoto
 		}
 
-		fileID = chat.Photo.BigFileID
+		fileID, uniqueID = chat.Photo.BigFileID, chat.Photo.BigFileUniqueID
 	} else {
 		if len(p.Photos) == 0 || len(p.Photos[0]) == 0 {
-			return nil, errors.New("no photos found")
+			return "", "", ErrNoProfilePhoto
 		}
 
-		getBestPhoto := func(photos [][]models.PhotoSize) *models.PhotoSize {
-			var best *models.PhotoSize
-			for _, photo := range photos[0] {
-				if best == nil || photo.Width > best.Width {
-					best = &photo
-				}
-			}
-			return best
-		}
-
-		best := getBestPhoto(p.Photos)
-		fileID = best.FileID
+		largest := LargestPhoto(p.Photos[0])
+		fileID, uniqueID = largest.FileID, largest.FileUniqueID
 	}
 
 	if len(fileID) == 0 {
-		return nil, errors.New("no picture found")
+		return "", "", ErrNoProfilePhoto
 	}
 
-	return s.DownloadFile(fileID)
+	return fileID, uniqueID, nil
 }
 
 func (s *Service) downloadURLs(msg Message) error {
+	ctx := context.Background()
+
 	if len(msg.VideoURL) > 0 {
-		video, err := s.downloadFile(msg.VideoURL)
+		video, err := s.downloadFile(ctx, msg.VideoURL)
 		if err != nil {
 			return fmt.Errorf("download video: %w", err)
 		}
@@ -92,7 +213,7 @@ func (s *Service) downloadURLs(msg Message) error {
 	}
 
 	if len(msg.AudioURL) > 0 {
-		audio, err := s.downloadFile(msg.AudioURL)
+		audio, err := s.downloadFile(ctx, msg.AudioURL)
 		if err != nil {
 			return fmt.Errorf("download audio: %w", err)
 		}
@@ -102,7 +223,7 @@ func (s *Service) downloadURLs(msg Message) error {
 	}
 
 	if len(msg.ImageURL) > 0 {
-		image, err := s.downloadFile(msg.ImageURL)
+		image, err := s.downloadFile(ctx, msg.ImageURL)
 		if err != nil {
 			return fmt.Errorf("download image: %w", err)
 		}
@@ -112,7 +233,7 @@ func (s *Service) downloadURLs(msg Message) error {
 	}
 
 	if len(msg.DocumentURL) > 0 {
-		doc, err := s.downloadFile(msg.DocumentURL)
+		doc, err := s.downloadFile(ctx, msg.DocumentURL)
 		if err != nil {
 			return fmt.Errorf("download document: %w", err)
 		}
@@ -0,0 +1,158 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestLargestPhoto(t *testing.T) {
+	tests := []struct {
+		name   string
+		photos []models.PhotoSize
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"empty", []models.PhotoSize{}, ""},
+		{"single", []models.PhotoSize{{FileID: "a", Width: 90, Height: 90}}, "a"},
+		{"ascending", []models.PhotoSize{
+			{FileID: "s", Width: 90, Height: 90},
+			{FileID: "m", Width: 320, Height: 320},
+			{FileID: "l", Width: 800, Height: 800},
+		}, "l"},
+		{"largest first", []models.PhotoSize{
+			{FileID: "l", Width: 800, Height: 600},
+			{FileID: "s", Width: 90, Height: 60},
+		}, "l"},
+		{"by area", []models.PhotoSize{
+			{FileID: "wide", Width: 1000, Height: 10},
+			{FileID: "square", Width: 200, Height: 200},
+		}, "square"},
+		{"equal sizes keep the first", []models.PhotoSize{
+			{FileID: "first", Width: 320, Height: 320},
+			{FileID: "second", Width: 320, Height: 320},
+		}, "first"},
+		{"equal area", []models.PhotoSize{
+			{FileID: "portrait", Width: 300, Height: 400},
+			{FileID: "landscape", Width: 400, Height: 300},
+		}, "portrait"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LargestPhoto(tt.photos)
+			if tt.want == "" {
+				require.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			require.Equal(t, tt.want, got.FileID)
+		})
+	}
+
+	// The result points into the slice
+	photos := []models.PhotoSize{{FileID: "a", Width: 1, Height: 1}}
+	require.Same(t, &photos[0], LargestPhoto(photos))
+}
+
+func TestMediaFileID(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *models.Message
+		want string
+	}{
+		{"nil", nil, ""},
+		{"text", &models.Message{Text: "hello"}, ""},
+		{"empty photo", &models.Message{Photo: []models.PhotoSize{}}, ""},
+		{"photo", &models.Message{Photo: []models.PhotoSize{
+			{FileID: "photo-s", Width: 90, Height: 90},
+			{FileID: "photo-l", Width: 800, Height: 800},
+		}}, "photo-l"},
+		{"document", &models.Message{Document: &models.Document{FileID: "document"}}, "document"},
+		{"video", &models.Message{Video: &models.Video{FileID: "video"}}, "video"},
+		{"audio", &models.Message{Audio: &models.Audio{FileID: "audio"}}, "audio"},
+		{"voice", &models.Message{Voice: &models.Voice{FileID: "voice"}}, "voice"},
+		{"video note", &models.Message{VideoNote: &models.VideoNote{FileID: "video-note"}}, "video-note"},
+		{"animation", &models.Message{Animation: &models.Animation{FileID: "animation"}}, "animation"},
+		{"sticker", &models.Message{Sticker: &models.Sticker{FileID: "sticker"}}, "sticker"},
+		// Telegram sends animations with the document set too
+		{"animation with document", &models.Message{
+			Animation: &models.Animation{FileID: "animation"},
+			Document:  &models.Document{FileID: "document"},
+		}, "document"},
+		{"photo with caption", &models.Message{
+			Caption: "caption",
+			Photo:   []models.PhotoSize{{FileID: "photo", Width: 1, Height: 1}},
+		}, "photo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, MediaFileID(tt.msg))
+		})
+	}
+}
+
+func TestDownloadMedia(t *testing.T) {
+	const token = "123456:test-token"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/bot"+token+"/") {
+		case "getMe":
+			fmt.Fprint(w, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"test_bot"}}`)
+		case "getFile":
+			_ = r.ParseMultipartForm(1 << 20)
+			fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"x","file_path":"files/%s"}}`, r.FormValue("file_id"))
+		default:
+			// The file body is its ID
+			_, _ = w.Write([]byte(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	s, err := NewService(logger, &Config{Token: token, SkipGetMe: true, APIServer: &APIServer{URL: srv.URL}})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	tests := []struct {
+		name   string
+		update *models.Update
+		want   string
+		err    error
+	}{
+		{"nil update", nil, "", ErrNoMessage},
+		{"no message", &models.Update{CallbackQuery: &models.CallbackQuery{}}, "", ErrNoMessage},
+		{"no media", &models.Update{Message: &models.Message{Text: "hello"}}, "", ErrNoMedia},
+		{"message", &models.Update{Message: &models.Message{Video: &models.Video{FileID: "video"}}}, "video", nil},
+		{"edited message", &models.Update{EditedMessage: &models.Message{Voice: &models.Voice{FileID: "voice"}}}, "voice", nil},
+		{"channel post", &models.Update{ChannelPost: &models.Message{Photo: []models.PhotoSize{
+			{FileID: "photo-s", Width: 90, Height: 90},
+			{FileID: "photo-l", Width: 800, Height: 800},
+		}}}, "photo-l", nil},
+		{"business message", &models.Update{BusinessMessage: &models.Message{Sticker: &models.Sticker{FileID: "sticker"}}}, "sticker", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := s.DownloadMedia(context.Background(), tt.update)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(body))
+		})
+	}
+}
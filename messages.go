@@ -1,10 +1,11 @@
 package tgbot
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"io"
 	"time"
 
 	"github.com/go-telegram/bot"
@@ -21,22 +22,89 @@ type InlineButton struct {
 	Row []InlineButton `json:"row,omitempty"`
 }
 
+// Message is a message to send or edit. It round-trips through JSON with
+// lowerCamel field names, which match the Go names case-insensitively so
+// messages stored before decode too. Uploads are base64 in JSON, Duration
+// and LivePeriod are nanoseconds and ParseMode is its name, e.g. "html".
+// TextArgs come back with JSON types.
 type Message struct {
-	Text               string
-	VideoURL           string
-	AudioURL           string
-	ImageURL           string
-	DocumentType       string
-	DocumentURL        string
-	Document           []byte
-	Image              []byte
-	Audio              []byte
-	Video              []byte
-	Entities           []models.MessageEntity
-	Buttons            []InlineButton
-	ReplyTo            int
-	TextFormatting     bool
-	DisableLinkPreview bool
+	Text               string                 `json:"text,omitempty"`
+	VideoURL           string                 `json:"videoUrl,omitempty"`
+	AudioURL           string                 `json:"audioUrl,omitempty"`
+	ImageURL           string                 `json:"imageUrl,omitempty"`
+	DocumentType       string                 `json:"documentType,omitempty"`
+	DocumentURL        string                 `json:"documentUrl,omitempty"`
+	Document           []byte                 `json:"document,omitempty"`
+	Image              []byte                 `json:"image,omitempty"`
+	Audio              []byte                 `json:"audio,omitempty"`
+	Video              []byte                 `json:"video,omitempty"`
+	Entities           []models.MessageEntity `json:"entities,omitempty"`
+	Buttons            []InlineButton         `json:"buttons,omitempty"`
+	ReplyTo            int                    `json:"replyTo,omitempty"`
+	TextFormatting     bool                   `json:"textFormatting,omitempty"`
+	DisableLinkPreview bool                   `json:"disableLinkPreview,omitempty"`
+
+	// ParseMode is how Telegram parses Text, defaults to Markdown. Text is
+	// escaped for the parse mode, TextFormatting keeps the formatting of
+	// the parse mode unescaped.
+	ParseMode ParseMode `json:"parseMode,omitempty"`
+
+	// BusinessConnectionID sends the message on behalf of a connected business account
+	BusinessConnectionID string `json:"businessConnectionId,omitempty"`
+
+	// Text is a message key when Config.Catalog is set. Localized holds the
+	// text per language code and replaces Text with the text in the language
+	// of the chat when there is one. TextArgs are formatted into the result.
+	Localized map[string]string `json:"localized,omitempty"`
+	TextArgs  []any             `json:"textArgs,omitempty"`
+
+	// ThreadID sends the message to a forum topic of a supergroup. Edits
+	// address the message by ID and don't need it.
+	ThreadID int `json:"threadId,omitempty"`
+
+	// Album sends 2 to 10 media items as a single album with Text as the
+	// caption, other media fields are ignored
+	Album []MediaItem `json:"album,omitempty"`
+
+	// StickerFileID or Sticker sends a sticker, Text is ignored as stickers
+	// have no caption. StickerEmoji is the emoji of an uploaded sticker.
+	StickerFileID string `json:"stickerFileId,omitempty"`
+	Sticker       []byte `json:"sticker,omitempty"`
+	StickerEmoji  string `json:"stickerEmoji,omitempty"`
+
+	// Voice sends a voice message, it must be OGG encoded with Opus for
+	// Telegram to show it as a voice message with a waveform. The waveform is
+	// generated by Telegram, the Bot API does not accept one.
+	Voice    []byte `json:"voice,omitempty"`
+	VoiceURL string `json:"voiceUrl,omitempty"`
+	// VideoNote sends a round video message of up to a minute, it must be
+	// square. URLs are not supported for video notes, VideoNoteURL only
+	// accepts file IDs. Text is ignored as video notes have no caption.
+	VideoNote    []byte `json:"videoNote,omitempty"`
+	VideoNoteURL string `json:"videoNoteUrl,omitempty"`
+	// VideoNoteLength is the diameter of the video note in pixels
+	VideoNoteLength int `json:"videoNoteLength,omitempty"`
+	// Duration of the voice message or video note
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Location, Venue and Contact send a map point, place or phone contact,
+	// Text is ignored as they have no caption
+	Location *Location `json:"location,omitempty"`
+	Venue    *Venue    `json:"venue,omitempty"`
+	Contact  *Contact  `json:"contact,omitempty"`
+
+	// Keyboard shows a reply keyboard with the message, ignored when Buttons
+	// are set. RemoveKeyboard hides a reply keyboard sent before. Both only
+	// apply to sent messages, edits can only change inline buttons.
+	Keyboard       *ReplyKeyboard `json:"keyboard,omitempty"`
+	RemoveKeyboard bool           `json:"removeKeyboard,omitempty"`
+
+	// converted marks CommonMark text already converted to entities, so
+	// escaped markup isn't parsed a second time
+	converted bool
+	// localized marks the text as resolved, so a translation isn't looked
+	// up in the catalog again
+	localized bool
 }
 
 // hasMedia returns true if the message has any media attachments.
@@ -46,40 +114,66 @@ func (m Message) hasMedia() bool {
 		len(m.Video) > 0 || m.DocumentURL != "" || m.DocumentType != ""
 }
 
-// createInputMedia
+// hasSticker returns true if the message is a sticker
+func (m Message) hasSticker() bool {
+	return m.StickerFileID != "" || len(m.Sticker) > 0
+}
+
+// hasFixedMedia returns true for media that can't be replaced by an edit,
+// only the buttons and caption of these messages can change
+func (m Message) hasFixedMedia() bool {
+	return m.hasSticker() || len(m.Voice) > 0 || m.VoiceURL != "" ||
+		len(m.VideoNote) > 0 || m.VideoNoteURL != "" ||
+		m.Location != nil || m.Venue != nil || m.Contact != nil
+}
+
+// createInputFile returns the media of an edit, media from memory is
+// uploaded as an attach:// attachment of the request
 func (m Message) createInputFile() models.InputMedia {
 	if len(m.Image) > 0 || m.ImageURL != "" {
+		ref, attachment := inputMediaRef("image.jpg", m.Image, m.ImageURL)
+
 		return &models.InputMediaPhoto{
-			Media:           m.ImageURL,
-			Caption:         EscapeMarkdown(m.Text, m.TextFormatting),
-			ParseMode:       getParseMode(m.TextFormatting),
+			Media:           ref,
+			MediaAttachment: attachment,
+			Caption:         m.escapeText(),
+			ParseMode:       m.parseMode(),
 			CaptionEntities: m.Entities,
 		}
 	}
 
 	if len(m.Video) > 0 || m.VideoURL != "" {
+		ref, attachment := inputMediaRef("video.mp4", m.Video, m.VideoURL)
+
 		return &models.InputMediaVideo{
-			Media:           m.VideoURL,
-			Caption:         EscapeMarkdown(m.Text, m.TextFormatting),
-			ParseMode:       getParseMode(m.TextFormatting),
+			Media:           ref,
+			MediaAttachment: attachment,
+			Caption:         m.escapeText(),
+			ParseMode:       m.parseMode(),
 			CaptionEntities: m.Entities,
 		}
 	}
 
 	if len(m.Audio) > 0 || m.AudioURL != "" {
+		ref, attachment := inputMediaRef("audio.mp3", m.Audio, m.AudioURL)
+
 		return &models.InputMediaAudio{
-			Media:           m.AudioURL,
-			Caption:         EscapeMarkdown(m.Text, m.TextFormatting),
-			ParseMode:       getParseMode(m.TextFormatting),
+			Media:           ref,
+			MediaAttachment: attachment,
+			Caption:         m.escapeText(),
+			ParseMode:       m.parseMode(),
 			CaptionEntities: m.Entities,
 		}
 	}
 
 	if len(m.Document) > 0 || m.DocumentURL != "" {
+		ref, attachment := inputMediaRef("file."+m.DocumentType, m.Document, m.DocumentURL)
+
 		return &models.InputMediaDocument{
-			Media:           m.DocumentURL,
-			Caption:         EscapeMarkdown(m.Text, m.TextFormatting),
-			ParseMode:       getParseMode(m.TextFormatting),
+			Media:           ref,
+			MediaAttachment: attachment,
+			Caption:         m.escapeText(),
+			ParseMode:       m.parseMode(),
 			CaptionEntities: m.Entities,
 		}
 	}
@@ -87,24 +181,103 @@ func (m Message) createInputFile() models.InputMedia {
 	return nil
 }
 
+// inputMediaRef returns the media reference of input media, data is
+// attached under the file name and takes precedence over the URL
+func inputMediaRef(filename string, data []byte, url string) (string, io.Reader) {
+	if len(data) > 0 {
+		return "attach://" + filename, bytes.NewReader(data)
+	}
+
+	return url, nil
+}
+
+// Send queues the message on the send pipeline and waits until it has been sent
 func (s *Service) Send(chatID int64, msg Message) (*models.Message, error) {
-	s.ratelimit.Take()
+	return s.SendContext(context.Background(), chatID, msg)
+}
+
+// SendContext is Send with a context, a message still queued when the
+// context is done is not sent
+func (s *Service) SendContext(ctx context.Context, chatID int64, msg Message) (*models.Message, error) {
+	select {
+	case res := <-s.SendAsyncContext(ctx, chatID, msg):
+		return res.Message, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendAsync queues the message on the send pipeline and returns a channel
+// that receives the result once the message has been sent. Messages to the
+// same chat are sent in order, messages to different chats in parallel.
+func (s *Service) SendAsync(chatID int64, msg Message) <-chan SendResult {
+	return s.SendAsyncContext(context.Background(), chatID, msg)
+}
+
+// SendAsyncContext is SendAsync with a context
+func (s *Service) SendAsyncContext(ctx context.Context, chatID int64, msg Message) <-chan SendResult {
+	return s.pipeline.enqueue(chatID, func() (*models.Message, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		call := &SendCall{Op: SendOpSend, ChatID: chatID, Message: &msg}
+
+		return s.intercept(ctx, call, func(ctx context.Context, msg Message) (*models.Message, error) {
+			if s.cfg.LongCaptions != CaptionOverflowFail {
+				msg = s.localize(chatID, msg)
+
+				// The length is checked on the converted text, the text is
+				// only split before converting as entities can't be split
+				if converted := msg.convertMarkdown(); s.captionOverflows(converted) {
+					return s.sendLongCaption(ctx, chatID, converted)
+				}
+			}
+
+			if s.cfg.SplitLongMessages {
+				sent, err := s.sendChunks(ctx, chatID, msg)
+				if len(sent) == 0 {
+					return nil, err
+				}
+
+				return sent[0], err
+			}
+
+			return s.retry(ctx, chatID, func() (*models.Message, error) {
+				return s.send(ctx, chatID, msg)
+			})
+		})
+	})
+}
+
+// QueueDepth returns the number of messages waiting on the send pipeline
+func (s *Service) QueueDepth() int {
+	return s.pipeline.depth()
+}
+
+func (s *Service) send(ctx context.Context, chatID int64, msg Message) (*models.Message, error) {
+	s.ratelimit.take(chatID)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	msg = s.localize(chatID, msg).convertMarkdown()
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Helper function to handle errors and log them
 	handleErr := func(msgType string, err error) error {
 		if err != nil {
+			err = apiError(err)
+
 			s.logger.Error("Error sending message",
 				slog.String("err", err.Error()),
 				slog.String("type", msgType),
-				slog.String("text", EscapeMarkdown(msg.Text, msg.TextFormatting)),
+				slog.String("text", msg.escapeText()),
 			)
 
-			if strings.Contains(err.Error(), "too long") {
-				s.Send(chatID, Message{
-					Text: "Message is too long, try a shorter message or without attachment",
+			if errors.Is(err, ErrMessageTooLong) {
+				s.send(ctx, chatID, Message{
+					Text:     "Message is too long, try a shorter message or without attachment",
+					ThreadID: msg.ThreadID,
 				})
 			}
 		}
@@ -128,51 +301,165 @@ func (s *Service) Send(chatID int64, msg Message) (*models.Message, error) {
 	var err error
 
 	switch {
+	case len(msg.Album) > 0:
+		sent, err := s.sendAlbum(ctx, chatID, msg)
+		if err != nil {
+			return nil, handleErr("album", err)
+		}
+		if len(sent) == 0 {
+			return nil, nil
+		}
+
+		return sent[0], nil
+	case msg.hasSticker():
+		if returnMsg, err = s.bot.SendSticker(ctx, &bot.SendStickerParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Sticker:              createInputFile("sticker.webp", msg.Sticker, msg.StickerFileID),
+			Emoji:                msg.StickerEmoji,
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+		}); err != nil {
+			return returnMsg, handleErr("sticker", err)
+		}
+	case msg.Location != nil:
+		if err := msg.Location.validate(); err != nil {
+			return nil, err
+		}
+
+		if returnMsg, err = s.bot.SendLocation(ctx, &bot.SendLocationParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Latitude:             msg.Location.Latitude,
+			Longitude:            msg.Location.Longitude,
+			HorizontalAccuracy:   msg.Location.Accuracy,
+			LivePeriod:           msg.Location.livePeriod(),
+			Heading:              msg.Location.Heading,
+			ProximityAlertRadius: msg.Location.ProximityAlertRadius,
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+		}); err != nil {
+			return returnMsg, handleErr("location", err)
+		}
+	case msg.Venue != nil:
+		if err := msg.Venue.Location.validate(); err != nil {
+			return nil, err
+		}
+
+		if returnMsg, err = s.bot.SendVenue(ctx, &bot.SendVenueParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Latitude:             msg.Venue.Location.Latitude,
+			Longitude:            msg.Venue.Location.Longitude,
+			Title:                msg.Venue.Title,
+			Address:              msg.Venue.Address,
+			FoursquareID:         msg.Venue.FoursquareID,
+			FoursquareType:       msg.Venue.FoursquareType,
+			GooglePlaceID:        msg.Venue.GooglePlaceID,
+			GooglePlaceType:      msg.Venue.GooglePlaceType,
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+		}); err != nil {
+			return returnMsg, handleErr("venue", err)
+		}
+	case msg.Contact != nil:
+		if returnMsg, err = s.bot.SendContact(ctx, &bot.SendContactParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			PhoneNumber:          msg.Contact.PhoneNumber,
+			FirstName:            msg.Contact.FirstName,
+			LastName:             msg.Contact.LastName,
+			VCard:                msg.Contact.VCard,
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+		}); err != nil {
+			return returnMsg, handleErr("contact", err)
+		}
+	case len(msg.Voice) > 0 || msg.VoiceURL != "":
+		if returnMsg, err = s.bot.SendVoice(ctx, &bot.SendVoiceParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Voice:                createInputFile("voice.ogg", msg.Voice, msg.VoiceURL),
+			Caption:              msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			CaptionEntities:      msg.Entities,
+			Duration:             int(msg.Duration.Seconds()),
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+		}); err != nil {
+			return returnMsg, handleErr("voice", err)
+		}
+	case len(msg.VideoNote) > 0 || msg.VideoNoteURL != "":
+		if returnMsg, err = s.bot.SendVideoNote(ctx, &bot.SendVideoNoteParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			VideoNote:            createInputFile("video_note.mp4", msg.VideoNote, msg.VideoNoteURL),
+			Duration:             int(msg.Duration.Seconds()),
+			Length:               msg.VideoNoteLength,
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+		}); err != nil {
+			return returnMsg, handleErr("video note", err)
+		}
 	case len(msg.Image) > 0 || msg.ImageURL != "":
 		if returnMsg, err = s.bot.SendPhoto(ctx, &bot.SendPhotoParams{
-			ChatID:          chatID,
-			Photo:           createInputFile("image.jpg", msg.Image, msg.ImageURL),
-			Caption:         EscapeMarkdown(msg.Text, msg.TextFormatting),
-			ParseMode:       getParseMode(msg.TextFormatting),
-			ReplyMarkup:     createInlineKeyboard(msg),
-			ReplyParameters: replyParams,
-			CaptionEntities: msg.Entities,
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Photo:                createInputFile("image.jpg", msg.Image, msg.ImageURL),
+			Caption:              msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+			CaptionEntities:      msg.Entities,
 		}); err != nil {
 			return returnMsg, handleErr("image", err)
 		}
 	case len(msg.Video) > 0 || msg.VideoURL != "":
 		if returnMsg, err = s.bot.SendVideo(ctx, &bot.SendVideoParams{
-			ChatID:          chatID,
-			Video:           createInputFile("video.mp4", msg.Video, msg.VideoURL),
-			Caption:         EscapeMarkdown(msg.Text, msg.TextFormatting),
-			ParseMode:       getParseMode(msg.TextFormatting),
-			ReplyMarkup:     createInlineKeyboard(msg),
-			ReplyParameters: replyParams,
-			CaptionEntities: msg.Entities,
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Video:                createInputFile("video.mp4", msg.Video, msg.VideoURL),
+			Caption:              msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+			CaptionEntities:      msg.Entities,
 		}); err != nil {
 			return returnMsg, handleErr("video", err)
 		}
 	case len(msg.Audio) > 0 || msg.AudioURL != "":
 		if returnMsg, err = s.bot.SendAudio(ctx, &bot.SendAudioParams{
-			ChatID:          chatID,
-			Audio:           createInputFile("audio.mp3", msg.Audio, msg.AudioURL),
-			Caption:         EscapeMarkdown(msg.Text, msg.TextFormatting),
-			ParseMode:       getParseMode(msg.TextFormatting),
-			ReplyMarkup:     createInlineKeyboard(msg),
-			ReplyParameters: replyParams,
-			CaptionEntities: msg.Entities,
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Audio:                createInputFile("audio.mp3", msg.Audio, msg.AudioURL),
+			Caption:              msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+			CaptionEntities:      msg.Entities,
 		}); err != nil {
 			return returnMsg, handleErr("audio", err)
 		}
 	case msg.DocumentURL != "" || len(msg.Document) > 0:
 		if returnMsg, err = s.bot.SendDocument(ctx, &bot.SendDocumentParams{
-			ChatID:          chatID,
-			Document:        createInputFile("file."+msg.DocumentType, msg.Document, msg.DocumentURL),
-			Caption:         EscapeMarkdown(msg.Text, msg.TextFormatting),
-			ParseMode:       getParseMode(msg.TextFormatting),
-			ReplyMarkup:     createInlineKeyboard(msg),
-			ReplyParameters: replyParams,
-			CaptionEntities: msg.Entities,
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Document:             createInputFile("file."+msg.DocumentType, msg.Document, msg.DocumentURL),
+			Caption:              msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+			CaptionEntities:      msg.Entities,
 		}); err != nil {
 			return returnMsg, handleErr("document", err)
 		}
@@ -186,13 +473,15 @@ func (s *Service) Send(chatID int64, msg Message) (*models.Message, error) {
 		}
 
 		if returnMsg, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:             chatID,
-			Text:               EscapeMarkdown(msg.Text, msg.TextFormatting),
-			ParseMode:          getParseMode(msg.TextFormatting),
-			ReplyMarkup:        createInlineKeyboard(msg),
-			ReplyParameters:    replyParams,
-			Entities:           msg.Entities,
-			LinkPreviewOptions: previewOpts,
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageThreadID:      msg.ThreadID,
+			Text:                 msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			ReplyMarkup:          createReplyMarkup(msg),
+			ReplyParameters:      replyParams,
+			Entities:             msg.Entities,
+			LinkPreviewOptions:   previewOpts,
 		}); err != nil {
 			return returnMsg, handleErr("text", err)
 		}
@@ -200,13 +489,32 @@ func (s *Service) Send(chatID int64, msg Message) (*models.Message, error) {
 		return returnMsg, errors.New("unsupported message type")
 	}
 
+	if len(msg.BusinessConnectionID) == 0 {
+		s.tracker.track(chatID, returnMsg)
+	}
+
 	return returnMsg, nil
 }
 
 func (s *Service) EditMessage(chatID int64, msgID int, msg Message) (*models.Message, error) {
-	s.ratelimit.Take()
+	return s.EditMessageContext(context.Background(), chatID, msgID, msg)
+}
+
+// EditMessageContext is EditMessage with a context
+func (s *Service) EditMessageContext(ctx context.Context, chatID int64, msgID int, msg Message) (*models.Message, error) {
+	call := &SendCall{Op: SendOpEdit, ChatID: chatID, MessageID: msgID, Message: &msg}
+
+	return s.intercept(ctx, call, func(ctx context.Context, msg Message) (*models.Message, error) {
+		return s.editMessage(ctx, chatID, msgID, msg)
+	})
+}
+
+func (s *Service) editMessage(ctx context.Context, chatID int64, msgID int, msg Message) (*models.Message, error) {
+	s.ratelimit.take(chatID)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	msg = s.localize(chatID, msg).convertMarkdown()
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	var previewOpts *models.LinkPreviewOptions
@@ -224,39 +532,55 @@ func (s *Service) EditMessage(chatID int64, msgID int, msg Message) (*models.Mes
 	var returnMsg *models.Message
 	var err error
 
-	if msg.hasMedia() {
+	if msg.hasFixedMedia() && msg.Text == "" {
+		// Stickers, voice messages, video notes, locations and contacts can't
+		// be replaced, only the buttons below them. Live locations are moved
+		// with EditLiveLocation.
+		returnMsg, err = s.bot.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageID:            msgID,
+			ReplyMarkup:          createInlineKeyboard(msg),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("edit Telegram buttons: %w", apiError(err))
+		}
+	} else if msg.hasMedia() {
 		returnMsg, err = s.bot.EditMessageMedia(ctx, &bot.EditMessageMediaParams{
-			ChatID:      chatID,
-			MessageID:   int(msgID),
-			Media:       msg.createInputFile(),
-			ReplyMarkup: createInlineKeyboard(msg),
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageID:            int(msgID),
+			Media:                msg.createInputFile(),
+			ReplyMarkup:          createInlineKeyboard(msg),
 		})
 		if err != nil {
-			return nil, fmt.Errorf("edit Telegram media: %w", err)
+			return nil, fmt.Errorf("edit Telegram media: %w", apiError(err))
 		}
 	} else if len(msg.Text) > 0 {
 		returnMsg, err = s.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
-			ChatID:             chatID,
-			MessageID:          int(msgID),
-			Text:               EscapeMarkdown(msg.Text, msg.TextFormatting),
-			ParseMode:          getParseMode(msg.TextFormatting),
-			ReplyMarkup:        createInlineKeyboard(msg),
-			Entities:           msg.Entities,
-			LinkPreviewOptions: previewOpts,
+			ChatID:               chatID,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			MessageID:            int(msgID),
+			Text:                 msg.escapeText(),
+			ParseMode:            msg.parseMode(),
+			ReplyMarkup:          createInlineKeyboard(msg),
+			Entities:             msg.Entities,
+			LinkPreviewOptions:   previewOpts,
 		})
 		if err != nil {
-			if strings.Contains(err.Error(), "there is no text in the message to edit") {
+			if err = apiError(err); errors.Is(err, errNoTextToEdit) {
 				returnMsg, err = s.bot.EditMessageCaption(ctx, &bot.EditMessageCaptionParams{
 					ChatID:                chatID,
+					BusinessConnectionID:  msg.BusinessConnectionID,
 					MessageID:             int(msgID),
-					Caption:               EscapeMarkdown(msg.Text, msg.TextFormatting),
-					ParseMode:             getParseMode(msg.TextFormatting),
+					Caption:               msg.escapeText(),
+					ParseMode:             msg.parseMode(),
 					CaptionEntities:       msg.Entities,
 					DisableWebPagePreview: msg.DisableLinkPreview,
 					ReplyMarkup:           createInlineKeyboard(msg),
 				})
 				if err != nil {
-					return nil, fmt.Errorf("edit Telegram caption: %w", err)
+					return nil, fmt.Errorf("edit Telegram caption: %w", apiError(err))
 				}
 			} else {
 				return nil, fmt.Errorf("edit Telegram message: %w", err)
@@ -268,7 +592,12 @@ func (s *Service) EditMessage(chatID int64, msgID int, msg Message) (*models.Mes
 }
 
 func (s *Service) DeleteMessage(chatID int64, msgID int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	return s.DeleteMessageContext(context.Background(), chatID, msgID)
+}
+
+// DeleteMessageContext is DeleteMessage with a context
+func (s *Service) DeleteMessageContext(ctx context.Context, chatID int64, msgID int) error {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	deleted, err := s.bot.DeleteMessage(ctx, &bot.DeleteMessageParams{
@@ -276,7 +605,7 @@ func (s *Service) DeleteMessage(chatID int64, msgID int) error {
 		MessageID: msgID,
 	})
 	if err != nil {
-		return fmt.Errorf("delete message: %w", err)
+		return fmt.Errorf("delete message: %w", apiError(err))
 	}
 
 	if !deleted {
@@ -0,0 +1,61 @@
+package tgbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageHasFixedMedia(t *testing.T) {
+	require.True(t, Message{StickerFileID: "abc"}.hasFixedMedia())
+	require.True(t, Message{Voice: []byte("ogg")}.hasFixedMedia())
+	require.True(t, Message{VideoNoteURL: "file-id"}.hasFixedMedia())
+	require.False(t, Message{ImageURL: "https://example.com/a.jpg"}.hasFixedMedia())
+
+	// Voice messages and video notes can't be edited with editMessageMedia
+	require.False(t, Message{Voice: []byte("ogg")}.hasMedia())
+	require.False(t, Message{VideoNote: []byte("mp4")}.hasMedia())
+}
+
+func TestLocationValidate(t *testing.T) {
+	require.NoError(t, Location{Latitude: 52.37, Longitude: 4.89}.validate())
+	require.NoError(t, Location{Latitude: 52.37, Longitude: 4.89, LivePeriod: LivePeriodForever}.validate())
+	require.Error(t, Location{Latitude: 91}.validate())
+	require.Error(t, Location{LivePeriod: 30 * time.Second}.validate())
+
+	require.Equal(t, 3600, Location{LivePeriod: time.Hour}.livePeriod())
+	require.Equal(t, 0x7FFFFFFF, Location{LivePeriod: LivePeriodForever}.livePeriod())
+	require.True(t, Message{Contact: &Contact{PhoneNumber: "+31600000000"}}.hasFixedMedia())
+}
+
+func TestCreateReplyMarkup(t *testing.T) {
+	require.Nil(t, createReplyMarkup(Message{Text: "hi"}))
+
+	markup := createReplyMarkup(Message{Keyboard: &ReplyKeyboard{
+		Buttons: []KeyboardButton{
+			KeyboardRow("Yes", "No"),
+			{Text: "Share location", RequestLocation: true},
+		},
+		Resize:      true,
+		Placeholder: "Pick one",
+	}})
+
+	keyboard, ok := markup.(*models.ReplyKeyboardMarkup)
+	require.True(t, ok)
+	require.Len(t, keyboard.Keyboard, 2)
+	require.Len(t, keyboard.Keyboard[0], 2)
+	require.True(t, keyboard.Keyboard[1][0].RequestLocation)
+	require.True(t, keyboard.ResizeKeyboard)
+	require.Equal(t, "Pick one", keyboard.InputFieldPlaceholder)
+
+	markup = createReplyMarkup(Message{
+		Buttons:  []InlineButton{{Text: "Open", URL: "https://example.com"}},
+		Keyboard: &ReplyKeyboard{Buttons: []KeyboardButton{{Text: "Yes"}}},
+	})
+	require.IsType(t, models.InlineKeyboardMarkup{}, markup)
+
+	markup = createReplyMarkup(Message{RemoveKeyboard: true})
+	require.Equal(t, &models.ReplyKeyboardRemove{RemoveKeyboard: true}, markup)
+}
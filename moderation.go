@@ -0,0 +1,234 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	defaultMuteDuration = time.Hour
+	defaultWarnText     = "⚠️ Your message violates the rules of this chat"
+)
+
+// ModerationAction is a set of actions to take on a flagged message
+type ModerationAction int
+
+const (
+	// ModerationAllow lets the message through
+	ModerationAllow ModerationAction = 0
+	// ModerationWarn replies to the message with a warning
+	ModerationWarn ModerationAction = 1 << iota
+	// ModerationDelete deletes the message
+	ModerationDelete
+	// ModerationMute restricts the sender for ModerationConfig.MuteDuration
+	ModerationMute
+	// ModerationReport reports the message to the admin chat
+	ModerationReport
+)
+
+// Has returns true if the action set contains the action
+func (a ModerationAction) Has(action ModerationAction) bool {
+	return a&action != 0
+}
+
+// Verdict is the result of a classifier
+type Verdict struct {
+	Action ModerationAction
+	// Reason is shown in warnings and reports
+	Reason string
+}
+
+// ModerationContent is the content of an incoming message passed to the classifiers
+type ModerationContent struct {
+	Message *models.Message
+	// Text is the text or caption of the message
+	Text string
+	// FileID is the ID of the attached media, if any
+	FileID string
+	// Download fetches the attached media
+	Download func() ([]byte, error)
+}
+
+// Classifier inspects message content, e.g. with regex lists or an external
+// ML API. Errors are logged and treated as allow.
+type Classifier interface {
+	Classify(ctx context.Context, content ModerationContent) (Verdict, error)
+}
+
+// ClassifierFunc adapts a function to the Classifier interface
+type ClassifierFunc func(ctx context.Context, content ModerationContent) (Verdict, error)
+
+func (f ClassifierFunc) Classify(ctx context.Context, content ModerationContent) (Verdict, error) {
+	return f(ctx, content)
+}
+
+// NewRegexClassifier flags messages whose text matches any of the patterns
+func NewRegexClassifier(action ModerationAction, reason string, patterns ...string) (Classifier, error) {
+	expressions := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile pattern %q: %w", pattern, err)
+		}
+		expressions = append(expressions, re)
+	}
+
+	return ClassifierFunc(func(ctx context.Context, content ModerationContent) (Verdict, error) {
+		for _, re := range expressions {
+			if re.MatchString(content.Text) {
+				return Verdict{Action: action, Reason: reason}, nil
+			}
+		}
+
+		return Verdict{}, nil
+	}), nil
+}
+
+// ModerationConfig holds the configuration of the moderation middleware
+type ModerationConfig struct {
+	// Classifiers are run in order, the actions of all verdicts are combined
+	Classifiers []Classifier
+	// MuteDuration defaults to an hour
+	MuteDuration time.Duration
+	// WarnText is prefixed to the reason in warnings
+	WarnText string
+	// ModerateAdmins also moderates the users listed in Config.Admins
+	ModerateAdmins bool
+}
+
+// moderationMiddleware runs the messages in groups through the classifiers
+func (s *Service) moderationMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			msg := update.Message
+			if msg == nil {
+				msg = update.EditedMessage
+			}
+
+			if len(s.cfg.Moderation.Classifiers) == 0 || msg == nil || msg.From == nil ||
+				msg.Chat.Type == ChatTypePrivate || (!s.cfg.Moderation.ModerateAdmins && s.IsAdmin(msg.From.ID)) {
+				next(ctx, b, update)
+				return
+			}
+
+			verdict := s.classify(ctx, msg)
+			if verdict.Action == ModerationAllow {
+				next(ctx, b, update)
+				return
+			}
+
+			s.moderate(ctx, b, msg, verdict)
+
+			if !verdict.Action.Has(ModerationDelete) {
+				next(ctx, b, update)
+			}
+		}
+	}
+}
+
+// classify combines the verdicts of all classifiers
+func (s *Service) classify(ctx context.Context, msg *models.Message) Verdict {
+	content := ModerationContent{
+		Message: msg,
+		Text:    messageText(msg),
+		FileID:  MediaFileID(msg),
+	}
+
+	if len(content.FileID) > 0 {
+		content.Download = func() ([]byte, error) {
+			return s.downloadFileByID(ctx, content.FileID)
+		}
+	}
+
+	var verdict Verdict
+	var reasons []string
+
+	for _, classifier := range s.cfg.Moderation.Classifiers {
+		v, err := classifier.Classify(ctx, content)
+		if err != nil {
+			s.logger.Error("classifier failed",
+				slog.String("err", err.Error()),
+				slog.Int64("chat", msg.Chat.ID),
+			)
+			continue
+		}
+
+		if v.Action == ModerationAllow {
+			continue
+		}
+
+		verdict.Action |= v.Action
+		if len(v.Reason) > 0 {
+			reasons = append(reasons, v.Reason)
+		}
+	}
+
+	verdict.Reason = strings.Join(reasons, ", ")
+
+	return verdict
+}
+
+func (s *Service) moderate(ctx context.Context, b *bot.Bot, msg *models.Message, verdict Verdict) {
+	cfg := s.cfg.Moderation
+
+	s.logger.Info("moderating message",
+		slog.Int64("chat", msg.Chat.ID),
+		slog.Int64("user", msg.From.ID),
+		slog.Int("action", int(verdict.Action)),
+		slog.String("reason", verdict.Reason),
+	)
+
+	if verdict.Action.Has(ModerationReport) {
+		s.notifyAdminAsync(slog.LevelWarn, "Flagged message",
+			slog.String("chat", msg.Chat.Title),
+			slog.Int64("chat_id", msg.Chat.ID),
+			slog.String("user", userDisplayName(msg.From)),
+			slog.Int64("user_id", msg.From.ID),
+			slog.String("reason", verdict.Reason),
+			slog.String("text", truncate(messageText(msg), 200)),
+		)
+	}
+
+	if verdict.Action.Has(ModerationWarn) {
+		text := cfg.WarnText
+		if len(text) == 0 {
+			text = defaultWarnText
+		}
+		if len(verdict.Reason) > 0 {
+			text += ": " + verdict.Reason
+		}
+
+		warning := Message{Text: text}
+		if !verdict.Action.Has(ModerationDelete) {
+			warning.ReplyTo = msg.ID
+		}
+
+		if _, err := s.Send(msg.Chat.ID, warning); err != nil {
+			s.logger.Error("failed to send moderation warning", slog.String("err", err.Error()))
+		}
+	}
+
+	if verdict.Action.Has(ModerationDelete) {
+		if err := s.DeleteMessage(msg.Chat.ID, msg.ID); err != nil {
+			s.logger.Error("failed to delete flagged message", slog.String("err", err.Error()))
+		}
+	}
+
+	if verdict.Action.Has(ModerationMute) {
+		duration := cfg.MuteDuration
+		if duration <= 0 {
+			duration = defaultMuteDuration
+		}
+
+		if err := s.RestrictChatMember(ctx, msg.Chat.ID, msg.From.ID, MemberPermissions{}, duration); err != nil {
+			s.logger.Error("failed to mute user", slog.String("err", err.Error()))
+		}
+	}
+}
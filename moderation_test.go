@@ -0,0 +1,38 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestClassify(t *testing.T) {
+	spam, err := NewRegexClassifier(ModerationDelete|ModerationReport, "spam", `(?i)free crypto`)
+	require.NoError(t, err)
+
+	links, err := NewRegexClassifier(ModerationWarn, "links", `https?://`)
+	require.NoError(t, err)
+
+	failing := ClassifierFunc(func(ctx context.Context, content ModerationContent) (Verdict, error) {
+		return Verdict{Action: ModerationMute}, errors.New("api down")
+	})
+
+	s := &Service{
+		logger: slog.Default(),
+		cfg:    &Config{Moderation: ModerationConfig{Classifiers: []Classifier{spam, links, failing}}},
+	}
+
+	verdict := s.classify(context.Background(), &models.Message{Text: "hello"})
+	require.Equal(t, ModerationAllow, verdict.Action)
+
+	verdict = s.classify(context.Background(), &models.Message{Caption: "FREE CRYPTO at https://x.com"})
+	require.True(t, verdict.Action.Has(ModerationDelete))
+	require.True(t, verdict.Action.Has(ModerationReport))
+	require.True(t, verdict.Action.Has(ModerationWarn))
+	require.False(t, verdict.Action.Has(ModerationMute))
+	require.Equal(t, "spam, links", verdict.Reason)
+}
@@ -0,0 +1,89 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// EmojiStatus is the custom emoji shown next to the name of the account,
+// setting it requires Telegram Premium
+type EmojiStatus struct {
+	// DocumentID is the ID of the custom emoji
+	DocumentID int64
+	// Until removes the status at the given time, zero keeps it
+	Until time.Time
+}
+
+// AccentColor is an accent color of the account
+type AccentColor struct {
+	// Color is the ID of a color palette, see https://core.telegram.org/api/colors.
+	// A negative ID resets the color to the default.
+	Color int
+	// BackgroundEmojiID is the custom emoji of the background pattern, zero
+	// for none
+	BackgroundEmojiID int64
+}
+
+// SetEmojiStatus sets the emoji status of the account
+func (c *Client) SetEmojiStatus(ctx context.Context, status EmojiStatus) error {
+	var emoji tg.EmojiStatusClass = &tg.EmojiStatus{DocumentID: status.DocumentID}
+	if !status.Until.IsZero() {
+		emoji = &tg.EmojiStatusUntil{DocumentID: status.DocumentID, Until: int(status.Until.Unix())}
+	}
+
+	return c.updateEmojiStatus(ctx, emoji)
+}
+
+// ClearEmojiStatus removes the emoji status of the account
+func (c *Client) ClearEmojiStatus(ctx context.Context) error {
+	return c.updateEmojiStatus(ctx, &tg.EmojiStatusEmpty{})
+}
+
+func (c *Client) updateEmojiStatus(ctx context.Context, emoji tg.EmojiStatusClass) error {
+	if c.api() == nil {
+		return ErrNotInitialized
+	}
+
+	c.takeRequest()
+
+	if _, err := c.api().AccountUpdateEmojiStatus(ctx, emoji); err != nil {
+		return fmt.Errorf("update emoji status: %w", err)
+	}
+
+	return nil
+}
+
+// SetNameColor sets the accent color of the name in messages and replies
+func (c *Client) SetNameColor(ctx context.Context, color AccentColor) error {
+	return c.updateColor(ctx, color, false)
+}
+
+// SetProfileColor sets the accent color of the profile page
+func (c *Client) SetProfileColor(ctx context.Context, color AccentColor) error {
+	return c.updateColor(ctx, color, true)
+}
+
+func (c *Client) updateColor(ctx context.Context, color AccentColor, profile bool) error {
+	if c.api() == nil {
+		return ErrNotInitialized
+	}
+
+	req := &tg.AccountUpdateColorRequest{ForProfile: profile}
+	if color.Color >= 0 {
+		req.SetColor(color.Color)
+	}
+	if color.BackgroundEmojiID != 0 {
+		req.SetBackgroundEmojiID(color.BackgroundEmojiID)
+	}
+
+	c.takeRequest()
+
+	if _, err := c.api().AccountUpdateColor(ctx, req); err != nil {
+		return fmt.Errorf("update color: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package mtproto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/test-go/testify/require"
+)
+
+func TestAppearance(t *testing.T) {
+	premium := true
+
+	invoker := &stubInvoker{FakeBackend: NewFakeBackend()}
+	invoker.respond = func(input bin.Encoder) (bin.Encoder, error) {
+		switch input.(type) {
+		case *tg.AccountUpdateEmojiStatusRequest, *tg.AccountUpdateColorRequest:
+			if !premium {
+				return nil, tgerr.New(400, "PREMIUM_ACCOUNT_REQUIRED")
+			}
+
+			return &tg.BoolTrue{}, nil
+		}
+
+		return nil, nil
+	}
+
+	client := NewTestClient(logger, invoker, nil)
+	ctx := context.Background()
+	until := time.Unix(1800000000, 0)
+
+	require.NoError(t, client.SetEmojiStatus(ctx, EmojiStatus{DocumentID: 7}))
+	require.NoError(t, client.SetEmojiStatus(ctx, EmojiStatus{DocumentID: 7, Until: until}))
+	require.NoError(t, client.ClearEmojiStatus(ctx))
+
+	var statuses []tg.EmojiStatusClass
+	for _, req := range requestsOf[*tg.AccountUpdateEmojiStatusRequest](invoker) {
+		statuses = append(statuses, req.EmojiStatus)
+	}
+	require.Equal(t, []tg.EmojiStatusClass{
+		&tg.EmojiStatus{DocumentID: 7},
+		&tg.EmojiStatusUntil{DocumentID: 7, Until: 1800000000},
+		&tg.EmojiStatusEmpty{},
+	}, statuses)
+
+	require.NoError(t, client.SetNameColor(ctx, AccentColor{Color: 3, BackgroundEmojiID: 9}))
+	require.NoError(t, client.SetProfileColor(ctx, AccentColor{Color: -1}))
+
+	colors := requestsOf[*tg.AccountUpdateColorRequest](invoker)
+	require.Len(t, colors, 2)
+
+	require.False(t, colors[0].ForProfile)
+	color, ok := colors[0].GetColor()
+	require.True(t, ok)
+	require.Equal(t, 3, color)
+	emoji, ok := colors[0].GetBackgroundEmojiID()
+	require.True(t, ok)
+	require.Equal(t, int64(9), emoji)
+
+	// A negative color resets it
+	require.True(t, colors[1].ForProfile)
+	_, ok = colors[1].GetColor()
+	require.False(t, ok)
+	_, ok = colors[1].GetBackgroundEmojiID()
+	require.False(t, ok)
+
+	premium = false
+	require.True(t, tgerr.Is(client.SetEmojiStatus(ctx, EmojiStatus{DocumentID: 7}), "PREMIUM_ACCOUNT_REQUIRED"))
+	require.True(t, tgerr.Is(client.SetNameColor(ctx, AccentColor{Color: 1}), "PREMIUM_ACCOUNT_REQUIRED"))
+}
@@ -2,13 +2,19 @@ package mtproto
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gotd/td/tg"
 	"golang.org/x/exp/slog"
 )
 
+// peerCacheTTL is how long resolved usernames are cached, usernames can be
+// transferred to another channel
+const peerCacheTTL = 24 * time.Hour
+
 // inputChannel, err := s.getChannelInput(name)
 // if err != nil {
 // 	return nil, fmt.Errorf("get channel input: %w", err)
@@ -28,8 +34,10 @@ import (
 // 	Info: info,
 // }
 
-// GetChannelMembers retrieves members of a Telegram channel
-func (c *Client) GetChannelMembers(ctx context.Context, channelUsername string, opts *ChannelMembersOptions) ([]*tg.User, error) {
+// GetChannelMembers retrieves members of a Telegram channel. When a page
+// fails after the retries, the members fetched so far are returned with a
+// *ResumableError.
+func (c *Client) GetChannelMembers(ctx context.Context, channelUsername string, opts *ChannelMembersOptions) ([]*Member, error) {
 	if opts == nil {
 		opts = &ChannelMembersOptions{
 			RetryCount: 3,
@@ -42,7 +50,7 @@ func (c *Client) GetChannelMembers(ctx context.Context, channelUsername string,
 		return nil, err
 	}
 
-	var users []*tg.User
+	var users []*Member
 	offset := opts.Offset
 	attempt := 0
 
@@ -51,9 +59,11 @@ func (c *Client) GetChannelMembers(ctx context.Context, channelUsername string,
 			return nil, ctx.Err()
 		}
 
-		participants, err := c.client.API().ChannelsGetParticipants(ctx, &tg.ChannelsGetParticipantsRequest{
+		c.takeRequest()
+
+		participants, err := c.api().ChannelsGetParticipants(ctx, &tg.ChannelsGetParticipantsRequest{
 			Channel: channel,
-			Filter:  &tg.ChannelParticipantsRecent{},
+			Filter:  participantsFilter(opts.Filter),
 			Offset:  offset,
 			Limit:   100,
 		})
@@ -65,6 +75,14 @@ func (c *Client) GetChannelMembers(ctx context.Context, channelUsername string,
 				continue
 			}
 
+			if len(users) > 0 {
+				return users, &ResumableError{
+					Err:     fmt.Errorf("get participants: %w", err),
+					Offset:  offset,
+					Fetched: len(users),
+				}
+			}
+
 			return nil, fmt.Errorf("get participants: %w", err)
 		}
 
@@ -78,15 +96,37 @@ func (c *Client) GetChannelMembers(ctx context.Context, channelUsername string,
 			break
 		}
 
+		var page []*Member
+		byID := make(map[int64]*Member, len(rawUsers))
 		for _, item := range rawUsers {
 			if user, ok := item.AsNotEmpty(); ok {
 				if opts.ActiveOnly && user.Deleted {
 					continue
 				}
-				users = append(users, user)
+
+				member := convertUser(user)
+				byID[member.ID] = member
+				page = append(page, member)
 			}
 		}
 
+		if opts.IncludeParticipant {
+			for _, participant := range details.Participants {
+				applyParticipant(byID, participant)
+			}
+		}
+
+		users = append(users, page...)
+
+		if err := writeSink(ctx, opts.Sink, SinkEvent{
+			Kind:    SinkMembers,
+			ChatID:  channel.ChannelID,
+			Channel: channelUsername,
+			Members: page,
+		}); err != nil {
+			return nil, err
+		}
+
 		if (opts.MaxPages > 0 && len(users)/100 >= opts.MaxPages) ||
 			(opts.MaxUsers > 0 && len(users) >= opts.MaxUsers) ||
 			len(users) >= details.Count {
@@ -106,6 +146,18 @@ type ChannelMessagesOptions struct {
 	BatchSize   int       // Number of messages per batch (max 100)
 	Sleep       time.Duration
 	Hook        func(msg *tg.Message) bool
+	// SinceLastCheckpoint fetches all messages newer than the channel checkpoint,
+	// MinMessages is ignored once a checkpoint exists. The checkpoint is moved
+	// to the newest message after a successful run.
+	SinceLastCheckpoint bool
+	// Sink receives every batch as converted messages
+	Sink Sink
+	// OffsetID starts the fetch at the messages older than this ID, used to
+	// resume from a ResumableError
+	OffsetID int
+	// MaxDate skips the messages sent after it, the fetch starts at the
+	// message found by FindMessageIDAtDate instead of the newest message
+	MaxDate time.Time
 }
 
 // Default options when none are provided
@@ -115,8 +167,71 @@ var defaultChannelMessagesOptions = ChannelMessagesOptions{
 	Sleep:       time.Millisecond * 500,
 }
 
-// GetChannelMessages fetches messages from a channel according to provided options
+// GetChannelMessages fetches messages from a channel according to provided
+// options. When a batch fails, the messages fetched so far are returned with a
+// *ResumableError.
 func (c *Client) GetChannelMessages(chatID int64, opts *ChannelMessagesOptions) ([]*tg.Message, error) {
+	var allMessages []*tg.Message
+
+	err := c.fetchChannelMessages(context.Background(), chatID, opts, func(batch []*tg.Message) (bool, error) {
+		var done bool
+
+		if opts != nil && opts.Hook != nil {
+			for _, msg := range batch {
+				if opts.Hook(msg) {
+					done = true
+					break
+				}
+			}
+		}
+
+		allMessages = append(allMessages, batch...)
+
+		return done, nil
+	})
+
+	var resumable *ResumableError
+	if errors.As(err, &resumable) && len(allMessages) > 0 {
+		return allMessages, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return allMessages, nil
+}
+
+// IterChannelMessages calls fn for each message of a channel from newest to
+// oldest, without keeping all messages in memory. Return ErrStopIteration
+// from fn to stop early. With SinceLastCheckpoint the checkpoint is only
+// saved when all new messages were processed, so messages are processed at
+// least once.
+func (c *Client) IterChannelMessages(ctx context.Context, chatID int64, opts *ChannelMessagesOptions, fn func(msg *tg.Message) error) error {
+	err := c.fetchChannelMessages(ctx, chatID, opts, func(batch []*tg.Message) (bool, error) {
+		for _, msg := range batch {
+			if err := fn(msg); err != nil {
+				return true, err
+			}
+
+			if opts != nil && opts.Hook != nil && opts.Hook(msg) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+	if errors.Is(err, ErrStopIteration) {
+		return nil
+	}
+
+	return err
+}
+
+// fetchChannelMessages pages through the channel history and hands each batch
+// to fn, which returns true to stop. A failed batch after the first returns a
+// *ResumableError.
+func (c *Client) fetchChannelMessages(ctx context.Context, chatID int64, opts *ChannelMessagesOptions, fn func(batch []*tg.Message) (bool, error)) error {
 	// Use default options if none provided
 	if opts == nil {
 		opts = &defaultChannelMessagesOptions
@@ -132,16 +247,60 @@ func (c *Client) GetChannelMessages(chatID int64, opts *ChannelMessagesOptions)
 	}
 
 	var (
-		allMessages []*tg.Message
-		offsetID    int
+		checkpoint  int
+		newest      int
+		collected   int
+		offsetID    = opts.OffsetID
 		done        bool
+		stopped     bool
 		lastMsgDate time.Time
 	)
 
+	if opts.SinceLastCheckpoint {
+		checkpointer := c.Checkpointer()
+		if checkpointer == nil {
+			return ErrNoCheckpointer
+		}
+
+		var err error
+		if checkpoint, err = checkpointer.LastMessageID(ctx, chatID); err != nil {
+			return fmt.Errorf("get checkpoint: %w", err)
+		}
+	}
+
+	if !opts.MaxDate.IsZero() {
+		id, err := c.FindMessageIDAtDate(ctx, chatID, opts.MaxDate)
+		if err != nil {
+			return err
+		}
+
+		// Nothing was sent before MaxDate
+		if id == 0 {
+			return nil
+		}
+
+		// The offset excludes its own ID
+		if offsetID == 0 || id+1 < offsetID {
+			offsetID = id + 1
+		}
+	}
+
 	for !done {
-		messages, total, err := c.getChannelMessagesBatch(chatID, offsetID, opts.BatchSize)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		messages, total, err := c.getChannelMessagesBatch(ctx, chatID, offsetID, checkpoint, opts.BatchSize)
 		if err != nil {
-			return nil, fmt.Errorf("get messages batch: %w", err)
+			if collected > 0 {
+				return &ResumableError{
+					Err:     fmt.Errorf("get messages batch: %w", err),
+					Offset:  offsetID,
+					Fetched: collected,
+				}
+			}
+
+			return fmt.Errorf("get messages batch: %w", err)
 		}
 		var filtered []*tg.Message
 
@@ -153,62 +312,129 @@ func (c *Client) GetChannelMessages(chatID int64, opts *ChannelMessagesOptions)
 				break
 			}
 
+			if checkpoint > 0 && msg.ID <= checkpoint {
+				done = true
+				break
+			}
+
+			newest = max(newest, msg.ID)
 			filtered = append(filtered, msg)
 		}
 
-		if opts.Hook != nil {
-			for _, msg := range filtered {
-				if opts.Hook(msg) {
-					done = true
-					break
-				}
+		stop, err := fn(filtered)
+		if err != nil {
+			return err
+		}
+
+		if opts.Sink != nil {
+			if err := writeSink(ctx, opts.Sink, SinkEvent{
+				Kind:     SinkMessages,
+				ChatID:   chatID,
+				Messages: ConvertMessages(filtered),
+			}); err != nil {
+				return err
 			}
 		}
+		if stop {
+			done = true
+			stopped = true
+		}
 
-		allMessages = append(allMessages, filtered...)
+		collected += len(filtered)
 
 		// Update logging
-		c.logger.Debug("Fetched message batch",
+		c.log().Debug("Fetched message batch",
 			slog.Int("batchSize", len(messages)),
-			slog.Int("totalCollected", len(allMessages)),
+			slog.Int("totalCollected", collected),
 			slog.Int("targetMin", opts.MinMessages),
 			slog.Int("totalAvailable", total),
 			slog.Time("minDate", opts.MinDate),
+			slog.Int("checkpoint", checkpoint),
 		)
 
 		// Determine if we should continue
 		if done ||
 			len(messages) == 0 || // No more messages available
-			len(allMessages) >= total || // Got all available messages
-			(len(allMessages) >= opts.MinMessages && opts.MinDate.IsZero()) { // Got minimum required messages
-			done = true
+			collected >= total || // Got all available messages
+			(checkpoint == 0 && collected >= opts.MinMessages && opts.MinDate.IsZero()) { // Got minimum required messages
 			break
 		}
 
 		// Update offset for next batch
-		if len(messages) > 0 {
-			offsetID = messages[len(messages)-1].ID
-		}
+		offsetID = messages[len(messages)-1].ID
 
 		time.Sleep(opts.Sleep) // Respect rate limits
 	}
 
-	return allMessages, nil
+	// A run stopped early skipped older new messages, keep the checkpoint so
+	// the next run picks them up
+	if opts.SinceLastCheckpoint && !stopped && newest > checkpoint {
+		if err := c.Checkpointer().SaveCheckpoint(ctx, chatID, newest); err != nil {
+			return fmt.Errorf("save checkpoint: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindMessageIDAtDate returns the ID of the newest message of the channel
+// sent at or before t, zero when there is none. Telegram looks the date up
+// in the history, so this is a single request however long the history is.
+func (c *Client) FindMessageIDAtDate(ctx context.Context, chatID int64, t time.Time) (int, error) {
+	if c.api() == nil {
+		return 0, ErrNotInitialized
+	}
+
+	inputChannel, err := c.getChannelInputByChatID(chatID)
+	if err != nil {
+		return 0, fmt.Errorf("get channel input: %w", err)
+	}
+
+	c.takeRequest()
+
+	// The offset date excludes messages sent at it, one second later
+	// includes them
+	resp, err := c.api().MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+		Peer: &tg.InputPeerChannel{
+			ChannelID:  chatID,
+			AccessHash: inputChannel.AccessHash,
+		},
+		OffsetDate: int(t.Unix()) + 1,
+		Limit:      1,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get message at date: %w", err)
+	}
+
+	msgs, ok := resp.AsModified()
+	if !ok {
+		return 0, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	if messages := msgs.GetMessages(); len(messages) > 0 {
+		return messages[0].GetID(), nil
+	}
+
+	return 0, nil
 }
 
-// getChannelMessagesBatch fetches a single batch of messages from a channel
-func (c *Client) getChannelMessagesBatch(chatID int64, offsetID, limit int) ([]*tg.Message, int, error) {
+// getChannelMessagesBatch fetches a single batch of messages from a channel,
+// minID excludes messages up to and including that ID
+func (c *Client) getChannelMessagesBatch(ctx context.Context, chatID int64, offsetID, minID, limit int) ([]*tg.Message, int, error) {
 	inputChannel, err := c.getChannelInputByChatID(chatID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("get channel input: %w", err)
 	}
 
-	resp, err := c.client.API().MessagesGetHistory(context.Background(), &tg.MessagesGetHistoryRequest{
+	c.takeRequest()
+
+	resp, err := c.api().MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
 		Peer: &tg.InputPeerChannel{
 			ChannelID:  chatID,
 			AccessHash: inputChannel.AccessHash,
 		},
 		OffsetID: offsetID,
+		MinID:    minID,
 		Limit:    limit,
 	})
 	if err != nil {
@@ -220,6 +446,13 @@ func (c *Client) getChannelMessagesBatch(chatID int64, offsetID, limit int) ([]*
 		return nil, 0, fmt.Errorf("unexpected response type: %T", resp)
 	}
 
+	for _, chat := range msgs.Chats {
+		c.peers.addChat(chat)
+	}
+	for _, user := range msgs.Users {
+		c.peers.addUser(user)
+	}
+
 	var messages []*tg.Message
 	for _, item := range msgs.Messages {
 		if msg, ok := item.(*tg.Message); ok {
@@ -236,7 +469,7 @@ func (c *Client) resolveChannelByName(name string) (*tg.ChannelFull, error) {
 		return nil, fmt.Errorf("resolve channel: %w", err)
 	}
 
-	res, err := c.client.API().ChannelsGetFullChannel(context.Background(), channel)
+	res, err := c.api().ChannelsGetFullChannel(context.Background(), channel)
 	if err != nil {
 		return nil, fmt.Errorf("get full channel: %w", err)
 	}
@@ -249,11 +482,26 @@ func (c *Client) resolveChannelByName(name string) (*tg.ChannelFull, error) {
 	return info, nil
 }
 
+// usernameKey is the peer cache key of a username
+func usernameKey(name string) string {
+	return "username:" + strings.ToLower(name)
+}
+
 func (c *Client) getChannelInputByUsername(name string) (*tg.InputChannel, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
 	defer cancel()
 
-	peer, err := c.client.API().ContactsResolveUsername(ctx, name)
+	key := usernameKey(name)
+
+	cached, ok, err := c.cfg.PeerCache.Get(ctx, key)
+	if err != nil {
+		c.log().Warn("failed to read peer cache", slog.String("err", err.Error()))
+	}
+	if ok {
+		return &cached, nil
+	}
+
+	peer, err := c.api().ContactsResolveUsername(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("resolve username: %w", err)
 	}
@@ -267,16 +515,22 @@ func (c *Client) getChannelInputByUsername(name string) (*tg.InputChannel, error
 		return nil, fmt.Errorf("unexpected peer type: %T", peer.Chats[0])
 	}
 
-	return &tg.InputChannel{
+	input := tg.InputChannel{
 		ChannelID:  channel.ID,
 		AccessHash: channel.AccessHash,
-	}, nil
+	}
+
+	if err := c.cfg.PeerCache.Set(ctx, key, input, peerCacheTTL); err != nil {
+		c.log().Warn("failed to write peer cache", slog.String("err", err.Error()))
+	}
+
+	return &input, nil
 }
 
 func (c *Client) getChannelInputByChatID(chatID int64) (*tg.InputChannel, error) {
 	ctx := context.Background()
 
-	result, err := c.client.API().ChannelsGetChannels(ctx, []tg.InputChannelClass{
+	result, err := c.api().ChannelsGetChannels(ctx, []tg.InputChannelClass{
 		&tg.InputChannel{
 			ChannelID: chatID,
 			// We use 0 as a temporary access hash - the API will still return channel info
@@ -0,0 +1,196 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+)
+
+// ErrInvalidChatlistLink is returned for links that are not a chatlist invite
+var ErrInvalidChatlistLink = errors.New("invalid chatlist link")
+
+// ChatlistInvite is an exported invite link of a shared folder
+type ChatlistInvite struct {
+	// URL is the invite link, https://t.me/addlist/<slug>
+	URL      string
+	Title    string
+	FolderID int
+	// ChannelIDs are the channels the invite subscribes to
+	ChannelIDs []int64
+}
+
+// ChatlistImport is the outcome of importing a shared folder
+type ChatlistImport struct {
+	Title string
+	// FolderID is the local folder the channels were added to, zero when the
+	// folder is new and Telegram did not report it
+	FolderID int
+	// Joined are the channels joined by the import
+	Joined []int64
+	// AlreadyJoined are the channels of the folder the account was already in
+	AlreadyJoined []int64
+}
+
+// ExportChatlist creates an invite link for a folder of the account. The
+// folder must exist, channelIDs selects the channels of the folder shared by
+// the link.
+func (c *Client) ExportChatlist(ctx context.Context, folderID int, title string, channelIDs []int64) (*ChatlistInvite, error) {
+	if c.api() == nil {
+		return nil, ErrNotInitialized
+	}
+
+	peers := make([]tg.InputPeerClass, 0, len(channelIDs))
+	for _, id := range channelIDs {
+		peer, err := c.channelPeer(id)
+		if err != nil {
+			return nil, err
+		}
+
+		peers = append(peers, peer)
+	}
+
+	c.takeRequest()
+
+	exported, err := c.api().ChatlistsExportChatlistInvite(ctx, &tg.ChatlistsExportChatlistInviteRequest{
+		Chatlist: tg.InputChatlistDialogFilter{FilterID: folderID},
+		Title:    title,
+		Peers:    peers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export chatlist invite: %w", err)
+	}
+
+	return &ChatlistInvite{
+		URL:        exported.Invite.URL,
+		Title:      exported.Invite.Title,
+		FolderID:   folderID,
+		ChannelIDs: peerChannelIDs(exported.Invite.Peers),
+	}, nil
+}
+
+// ImportChatlist subscribes the account to all channels of a shared folder
+// with one request, instead of joining them one by one. The link is a
+// t.me/addlist link or its slug. Importing a folder the account already has
+// joins the channels added to it since.
+func (c *Client) ImportChatlist(ctx context.Context, link string) (*ChatlistImport, error) {
+	if c.api() == nil {
+		return nil, ErrNotInitialized
+	}
+
+	slug, err := chatlistSlug(link)
+	if err != nil {
+		return nil, err
+	}
+
+	c.takeRequest()
+
+	invite, err := c.api().ChatlistsCheckChatlistInvite(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("check chatlist invite: %w", err)
+	}
+
+	result := &ChatlistImport{}
+
+	var (
+		missing []tg.PeerClass
+		chats   []tg.ChatClass
+	)
+
+	switch invite := invite.(type) {
+	case *tg.ChatlistsChatlistInvite:
+		result.Title = invite.Title
+		missing, chats = invite.Peers, invite.Chats
+	case *tg.ChatlistsChatlistInviteAlready:
+		result.FolderID = invite.FilterID
+		result.AlreadyJoined = peerChannelIDs(invite.AlreadyPeers)
+		missing, chats = invite.MissingPeers, invite.Chats
+	default:
+		return nil, fmt.Errorf("unexpected chatlist invite type: %T", invite)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	peers := inputChannelPeers(missing, chats)
+
+	c.takeRequest()
+
+	if _, err := c.api().ChatlistsJoinChatlistInvite(ctx, &tg.ChatlistsJoinChatlistInviteRequest{
+		Slug:  slug,
+		Peers: peers,
+	}); err != nil {
+		return nil, fmt.Errorf("join chatlist invite: %w", err)
+	}
+
+	for _, peer := range peers {
+		if channel, ok := peer.(*tg.InputPeerChannel); ok {
+			result.Joined = append(result.Joined, channel.ChannelID)
+		}
+	}
+
+	return result, nil
+}
+
+// chatlistSlug returns the slug of a t.me/addlist link, a slug is returned as
+// it is
+func chatlistSlug(link string) (string, error) {
+	slug := strings.TrimSpace(link)
+
+	if i := strings.Index(slug, "addlist/"); i >= 0 {
+		slug = slug[i+len("addlist/"):]
+	} else if strings.Contains(slug, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidChatlistLink, link)
+	}
+
+	slug, _, _ = strings.Cut(slug, "?")
+	slug = strings.TrimSuffix(slug, "/")
+
+	if slug == "" || strings.Contains(slug, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidChatlistLink, link)
+	}
+
+	return slug, nil
+}
+
+// inputChannelPeers resolves the channel peers with the access hashes of the
+// chats of the same response
+func inputChannelPeers(peers []tg.PeerClass, chats []tg.ChatClass) []tg.InputPeerClass {
+	hashes := make(map[int64]int64, len(chats))
+	for _, chat := range chats {
+		if channel, ok := chat.(*tg.Channel); ok {
+			hashes[channel.ID] = channel.AccessHash
+		}
+	}
+
+	input := make([]tg.InputPeerClass, 0, len(peers))
+	for _, peer := range peers {
+		channel, ok := peer.(*tg.PeerChannel)
+		if !ok {
+			continue
+		}
+
+		hash, ok := hashes[channel.ChannelID]
+		if !ok {
+			continue
+		}
+
+		input = append(input, &tg.InputPeerChannel{ChannelID: channel.ChannelID, AccessHash: hash})
+	}
+
+	return input
+}
+
+func peerChannelIDs(peers []tg.PeerClass) []int64 {
+	var ids []int64
+	for _, peer := range peers {
+		if channel, ok := peer.(*tg.PeerChannel); ok {
+			ids = append(ids, channel.ChannelID)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,45 @@
+package mtproto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestChatlistSlug(t *testing.T) {
+	for _, link := range []string{
+		"https://t.me/addlist/AbC_123",
+		"t.me/addlist/AbC_123/",
+		"tg://addlist/AbC_123?x=1",
+		" AbC_123 ",
+	} {
+		slug, err := chatlistSlug(link)
+		require.NoError(t, err, link)
+		require.Equal(t, "AbC_123", slug, link)
+	}
+
+	for _, link := range []string{"", "https://t.me/joinchat/abc", "https://t.me/addlist/"} {
+		_, err := chatlistSlug(link)
+		require.True(t, errors.Is(err, ErrInvalidChatlistLink), link)
+	}
+}
+
+func TestInputChannelPeers(t *testing.T) {
+	peers := []tg.PeerClass{
+		&tg.PeerChannel{ChannelID: 1},
+		&tg.PeerUser{UserID: 2},
+		&tg.PeerChannel{ChannelID: 3},
+	}
+	chats := []tg.ChatClass{
+		&tg.Channel{ID: 1, AccessHash: 11},
+	}
+
+	// Channels without an access hash can't be joined
+	require.Equal(t, []tg.InputPeerClass{
+		&tg.InputPeerChannel{ChannelID: 1, AccessHash: 11},
+	}, inputChannelPeers(peers, chats))
+
+	require.Equal(t, []int64{1, 3}, peerChannelIDs(peers))
+}
@@ -0,0 +1,118 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const checkpointTable = "message_checkpoints"
+
+// Checkpointer records the last processed message ID per channel, so polling
+// jobs can continue where they stopped after a restart
+type Checkpointer interface {
+	// LastMessageID returns the last processed message ID, zero if none
+	LastMessageID(ctx context.Context, channelID int64) (int, error)
+	// SaveCheckpoint records the message ID, IDs lower than the stored one are ignored
+	SaveCheckpoint(ctx context.Context, channelID int64, messageID int) error
+}
+
+// MessageCheckpoint is the database row of a channel checkpoint
+type MessageCheckpoint struct {
+	ChannelID int64 `gorm:"primaryKey;autoIncrement:false"`
+	MessageID int
+	UpdatedAt time.Time
+}
+
+// DBCheckpointer stores checkpoints in the client database
+type DBCheckpointer struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ Checkpointer = (*DBCheckpointer)(nil)
+
+// NewDBCheckpointer creates a checkpointer using the given database, the
+// checkpoint table is created if it does not exist
+func NewDBCheckpointer(db *gorm.DB, tablePrefix string) (*DBCheckpointer, error) {
+	c := &DBCheckpointer{
+		db:    db,
+		table: tablePrefix + checkpointTable,
+	}
+
+	if err := db.Table(c.table).AutoMigrate(&MessageCheckpoint{}); err != nil {
+		return nil, fmt.Errorf("migrate checkpoints: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *DBCheckpointer) LastMessageID(ctx context.Context, channelID int64) (int, error) {
+	var checkpoint MessageCheckpoint
+
+	err := c.db.WithContext(ctx).Table(c.table).
+		Where("channel_id = ?", channelID).
+		Take(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get checkpoint: %w", err)
+	}
+
+	return checkpoint.MessageID, nil
+}
+
+func (c *DBCheckpointer) SaveCheckpoint(ctx context.Context, channelID int64, messageID int) error {
+	checkpoint := MessageCheckpoint{
+		ChannelID: channelID,
+		MessageID: messageID,
+		UpdatedAt: time.Now(),
+	}
+
+	current := clause.Column{Table: c.table, Name: "message_id"}
+
+	// Only move the checkpoint forward, concurrent jobs may finish out of order
+	err := c.db.WithContext(ctx).Table(c.table).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "channel_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"message_id": gorm.Expr("CASE WHEN excluded.message_id > ? THEN excluded.message_id ELSE ? END", current, current),
+			"updated_at": checkpoint.UpdatedAt,
+		}),
+	}).Create(&checkpoint).Error
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// Checkpointer returns the checkpointer used by SinceLastCheckpoint, nil
+// before the client is initialized
+func (c *Client) Checkpointer() Checkpointer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.checkpointer
+}
+
+func (c *Client) setupCheckpointer(db *gorm.DB) error {
+	checkpointer := c.cfg.Checkpointer
+	if checkpointer == nil {
+		var err error
+		checkpointer, err = NewDBCheckpointer(db, c.cfg.DatabaseConfig.TablePrefix)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.checkpointer = checkpointer
+	c.mu.Unlock()
+
+	return nil
+}
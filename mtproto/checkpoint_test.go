@@ -0,0 +1,97 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+type memoryCheckpointer struct {
+	mu  sync.Mutex
+	ids map[int64]int
+}
+
+func (m *memoryCheckpointer) LastMessageID(_ context.Context, channelID int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.ids[channelID], nil
+}
+
+func (m *memoryCheckpointer) SaveCheckpoint(_ context.Context, channelID int64, messageID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ids[channelID] = max(m.ids[channelID], messageID)
+
+	return nil
+}
+
+func TestSinceLastCheckpoint(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	for id := 1; id <= 5; id++ {
+		backend.AddMessages(100, &tg.Message{ID: id, Message: "message", Date: 1700000000 + id})
+	}
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	checkpointer := &memoryCheckpointer{ids: make(map[int64]int)}
+	client := NewTestClient(logger, invoker, &Config{Checkpointer: checkpointer})
+
+	opts := func() *ChannelMessagesOptions {
+		return &ChannelMessagesOptions{SinceLastCheckpoint: true, BatchSize: 2}
+	}
+
+	// Without a checkpoint the whole history is fetched
+	messages, err := client.GetChannelMessages(100, opts())
+	require.NoError(t, err)
+	require.Len(t, messages, 5)
+	require.Equal(t, 5, checkpointer.ids[100])
+
+	for _, req := range requestsOf[*tg.MessagesGetHistoryRequest](invoker) {
+		require.Zero(t, req.MinID)
+		require.Equal(t, 2, req.Limit)
+	}
+
+	// The next run asks for the messages after the checkpoint only
+	backend.AddMessages(100,
+		&tg.Message{ID: 6, Message: "message", Date: 1700000006},
+		&tg.Message{ID: 7, Message: "message", Date: 1700000007},
+	)
+	invoker.requests = nil
+
+	messages, err = client.GetChannelMessages(100, opts())
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Equal(t, 7, messages[0].ID)
+	require.Equal(t, 6, messages[1].ID)
+	require.Equal(t, 7, checkpointer.ids[100])
+
+	requests := requestsOf[*tg.MessagesGetHistoryRequest](invoker)
+	require.NotEmpty(t, requests)
+	for _, req := range requests {
+		require.Equal(t, 5, req.MinID)
+		require.Equal(t, &tg.InputPeerChannel{ChannelID: 100, AccessHash: 100 ^ 0x5f5f5f5f}, req.Peer)
+	}
+
+	// A run stopped early keeps the checkpoint
+	backend.AddMessages(100, &tg.Message{ID: 8, Message: "message", Date: 1700000008})
+
+	var seen []int
+	err = client.IterChannelMessages(context.Background(), 100, opts(), func(msg *tg.Message) error {
+		seen = append(seen, msg.ID)
+		return ErrStopIteration
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{8}, seen)
+	require.Equal(t, 7, checkpointer.ids[100])
+
+	// Checkpoints need a checkpointer
+	_, err = NewTestClient(logger, backend, nil).GetChannelMessages(100, opts())
+	require.True(t, errors.Is(err, ErrNoCheckpointer))
+}
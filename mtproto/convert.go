@@ -0,0 +1,309 @@
+package mtproto
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gotd/td/tg"
+)
+
+// Media types set on MessageMedia.Type
+const (
+	MediaTypePhoto    = "photo"
+	MediaTypeVideo    = "video"
+	MediaTypeAudio    = "audio"
+	MediaTypeVoice    = "voice"
+	MediaTypeSticker  = "sticker"
+	MediaTypeDocument = "document"
+	MediaTypeWebPage  = "webpage"
+	MediaTypeGeo      = "geo"
+	MediaTypeContact  = "contact"
+	MediaTypePoll     = "poll"
+	MediaTypePaid     = "paid"
+	MediaTypeOther    = "other"
+)
+
+// ConvertMessage maps a raw message to the Message model, nil stays nil
+func ConvertMessage(msg *tg.Message) *Message {
+	if msg == nil {
+		return nil
+	}
+
+	m := &Message{
+		ID:        int64(msg.ID),
+		Text:      msg.Message,
+		PeerID:    peerID(msg.PeerID),
+		Timestamp: time.Unix(int64(msg.Date), 0),
+		Post:      msg.Post,
+		Pinned:    msg.Pinned,
+		Raw:       msg,
+	}
+
+	if from, ok := msg.GetFromID(); ok {
+		m.FromID = peerID(from)
+	}
+
+	if date, ok := msg.GetEditDate(); ok {
+		edited := time.Unix(int64(date), 0)
+		m.EditedAt = &edited
+	}
+
+	m.Views, _ = msg.GetViews()
+	m.Forwards, _ = msg.GetForwards()
+	m.PostAuthor, _ = msg.GetPostAuthor()
+	m.GroupedID, _ = msg.GetGroupedID()
+
+	if replies, ok := msg.GetReplies(); ok {
+		m.Replies = replies.Replies
+	}
+
+	if entities, ok := msg.GetEntities(); ok {
+		m.Entities = convertEntities(entities)
+	}
+
+	if header, ok := msg.GetReplyTo(); ok {
+		m.ReplyTo = convertReply(header)
+	}
+
+	if fwd, ok := msg.GetFwdFrom(); ok {
+		m.Forward = convertForward(fwd)
+	}
+
+	if media, ok := msg.GetMedia(); ok {
+		m.Media = convertMedia(media)
+	}
+
+	if reactions, ok := msg.GetReactions(); ok {
+		m.Reactions = convertReactions(reactions)
+	}
+
+	return m
+}
+
+// ConvertMessages maps raw messages to the Message model
+func ConvertMessages(msgs []*tg.Message) []*Message {
+	converted := make([]*Message, 0, len(msgs))
+	for _, msg := range msgs {
+		converted = append(converted, ConvertMessage(msg))
+	}
+
+	return converted
+}
+
+// GetChannelMessagesConverted is GetChannelMessages returning Message models
+func (c *Client) GetChannelMessagesConverted(chatID int64, opts *ChannelMessagesOptions) ([]*Message, error) {
+	msgs, err := c.GetChannelMessages(chatID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ConvertMessages(msgs), nil
+}
+
+func peerID(peer tg.PeerClass) int64 {
+	switch p := peer.(type) {
+	case *tg.PeerUser:
+		return p.UserID
+	case *tg.PeerChat:
+		return p.ChatID
+	case *tg.PeerChannel:
+		return p.ChannelID
+	}
+
+	return 0
+}
+
+func peerKind(peer tg.PeerClass) ForwardKind {
+	switch peer.(type) {
+	case *tg.PeerChannel:
+		return ForwardFromChannel
+	case *tg.PeerChat:
+		return ForwardFromChat
+	}
+
+	return ForwardFromUser
+}
+
+func convertEntities(entities []tg.MessageEntityClass) []MessageEntity {
+	converted := make([]MessageEntity, 0, len(entities))
+
+	for _, entity := range entities {
+		e := MessageEntity{
+			Type:   entityType(entity),
+			Offset: entity.GetOffset(),
+			Length: entity.GetLength(),
+		}
+
+		switch v := entity.(type) {
+		case *tg.MessageEntityTextURL:
+			e.URL = v.URL
+		case *tg.MessageEntityMentionName:
+			e.UserID = v.UserID
+		case *tg.MessageEntityPre:
+			e.Language = v.Language
+		}
+
+		converted = append(converted, e)
+	}
+
+	return converted
+}
+
+// entityType turns the TL name into a short type, messageEntityTextUrl becomes textUrl
+func entityType(entity tg.MessageEntityClass) string {
+	name := strings.TrimPrefix(entity.TypeName(), "messageEntity")
+
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+func convertReply(header tg.MessageReplyHeaderClass) *MessageReply {
+	h, ok := header.(*tg.MessageReplyHeader)
+	if !ok {
+		return nil
+	}
+
+	reply := &MessageReply{
+		MessageID: h.ReplyToMsgID,
+		TopID:     h.ReplyToTopID,
+		Quote:     h.QuoteText,
+	}
+
+	if peer, ok := h.GetReplyToPeerID(); ok {
+		reply.PeerID = peerID(peer)
+	}
+
+	return reply
+}
+
+func convertForward(fwd tg.MessageFwdHeader) *MessageForward {
+	forward := &MessageForward{
+		Kind:           ForwardHidden,
+		FromName:       fwd.FromName,
+		Date:           time.Unix(int64(fwd.Date), 0),
+		ChannelPostID:  fwd.ChannelPost,
+		PostAuthor:     fwd.PostAuthor,
+		SavedFromMsgID: fwd.SavedFromMsgID,
+		Imported:       fwd.Imported,
+	}
+
+	if from, ok := fwd.GetFromID(); ok {
+		forward.FromID = peerID(from)
+		forward.Kind = peerKind(from)
+	}
+
+	if saved, ok := fwd.GetSavedFromPeer(); ok {
+		forward.SavedFromPeerID = peerID(saved)
+	}
+
+	return forward
+}
+
+func convertMedia(media tg.MessageMediaClass) *MessageMedia {
+	switch v := media.(type) {
+	case *tg.MessageMediaPhoto:
+		photo, ok := v.Photo.(*tg.Photo)
+		if !ok {
+			return &MessageMedia{Type: MediaTypePhoto}
+		}
+
+		m := &MessageMedia{Type: MediaTypePhoto, ID: photo.ID}
+		for _, size := range photo.Sizes {
+			// Sizes are ordered from small to large, keep the largest
+			if s, ok := size.(*tg.PhotoSize); ok {
+				m.Width, m.Height, m.Size = s.W, s.H, int64(s.Size)
+			}
+		}
+
+		return m
+
+	case *tg.MessageMediaDocument:
+		doc, ok := v.Document.(*tg.Document)
+		if !ok {
+			return &MessageMedia{Type: MediaTypeDocument}
+		}
+
+		return convertDocument(doc)
+
+	case *tg.MessageMediaWebPage:
+		m := &MessageMedia{Type: MediaTypeWebPage}
+		if page, ok := v.Webpage.(*tg.WebPage); ok {
+			m.ID = page.ID
+			m.URL = page.URL
+			m.Title = page.Title
+		}
+
+		return m
+
+	case *tg.MessageMediaGeo, *tg.MessageMediaGeoLive, *tg.MessageMediaVenue:
+		return &MessageMedia{Type: MediaTypeGeo}
+
+	case *tg.MessageMediaContact:
+		return &MessageMedia{Type: MediaTypeContact}
+
+	case *tg.MessageMediaPoll:
+		return &MessageMedia{Type: MediaTypePoll}
+
+	case *tg.MessageMediaPaidMedia:
+		// The items are fetched with GetPaidMedia
+		return &MessageMedia{Type: MediaTypePaid}
+	}
+
+	return &MessageMedia{Type: MediaTypeOther}
+}
+
+func convertDocument(doc *tg.Document) *MessageMedia {
+	m := &MessageMedia{
+		Type:     MediaTypeDocument,
+		ID:       doc.ID,
+		MimeType: doc.MimeType,
+		Size:     doc.Size,
+	}
+
+	for _, attr := range doc.Attributes {
+		switch a := attr.(type) {
+		case *tg.DocumentAttributeFilename:
+			m.FileName = a.FileName
+		case *tg.DocumentAttributeVideo:
+			m.Type = MediaTypeVideo
+			m.Width, m.Height = a.W, a.H
+			m.Duration = time.Duration(a.Duration * float64(time.Second))
+		case *tg.DocumentAttributeAudio:
+			m.Type = MediaTypeAudio
+			if a.Voice {
+				m.Type = MediaTypeVoice
+			}
+			m.Title = a.Title
+			m.Duration = time.Duration(a.Duration) * time.Second
+		case *tg.DocumentAttributeSticker:
+			m.Type = MediaTypeSticker
+		case *tg.DocumentAttributeImageSize:
+			m.Width, m.Height = a.W, a.H
+		}
+	}
+
+	return m
+}
+
+func convertReactions(reactions tg.MessageReactions) []MessageReaction {
+	converted := make([]MessageReaction, 0, len(reactions.Results))
+
+	for _, result := range reactions.Results {
+		r := MessageReaction{Count: result.Count}
+
+		switch v := result.Reaction.(type) {
+		case *tg.ReactionEmoji:
+			r.Emoji = v.Emoticon
+		case *tg.ReactionCustomEmoji:
+			r.CustomEmojiID = v.DocumentID
+		case *tg.ReactionPaid:
+			r.Paid = true
+		}
+
+		converted = append(converted, r)
+	}
+
+	return converted
+}
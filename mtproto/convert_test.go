@@ -0,0 +1,109 @@
+package mtproto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestGetChannelMessagesConverted(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	backend.AddMessages(100,
+		&tg.Message{
+			ID:       1,
+			Message:  "see example.com",
+			Date:     1700000000,
+			Post:     true,
+			FromID:   &tg.PeerUser{UserID: 7},
+			EditDate: 1700000060,
+			Views:    120,
+			Forwards: 3,
+			Entities: []tg.MessageEntityClass{
+				&tg.MessageEntityTextURL{Offset: 4, Length: 11, URL: "https://example.com"},
+				&tg.MessageEntityPre{Offset: 0, Length: 3, Language: "go"},
+			},
+			ReplyTo: &tg.MessageReplyHeader{ReplyToMsgID: 9, ReplyToTopID: 2, QuoteText: "quote"},
+			Reactions: tg.MessageReactions{Results: []tg.ReactionCount{
+				{Reaction: &tg.ReactionEmoji{Emoticon: "👍"}, Count: 5},
+				{Reaction: &tg.ReactionCustomEmoji{DocumentID: 42}, Count: 1},
+			}},
+		},
+		&tg.Message{
+			ID:      2,
+			Message: "video",
+			Date:    1700000100,
+			Media: &tg.MessageMediaDocument{Document: &tg.Document{
+				ID:       5,
+				MimeType: "video/mp4",
+				Size:     2048,
+				Attributes: []tg.DocumentAttributeClass{
+					&tg.DocumentAttributeVideo{W: 640, H: 480, Duration: 1.5},
+					&tg.DocumentAttributeFilename{FileName: "clip.mp4"},
+				},
+			}},
+		},
+		&tg.Message{
+			ID:      3,
+			Message: "photo",
+			Date:    1700000200,
+			Media: &tg.MessageMediaPhoto{Photo: &tg.Photo{
+				ID: 6,
+				Sizes: []tg.PhotoSizeClass{
+					&tg.PhotoStrippedSize{Type: "i"},
+					&tg.PhotoSize{Type: "m", W: 320, H: 240, Size: 100},
+					&tg.PhotoSize{Type: "y", W: 1280, H: 960, Size: 900},
+				},
+			}},
+		},
+	)
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	client := NewTestClient(logger, invoker, nil)
+
+	messages, err := client.GetChannelMessagesConverted(100, &ChannelMessagesOptions{MinMessages: 3})
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	// The raw messages went through their wire encoding
+	photo, video, text := messages[0], messages[1], messages[2]
+
+	require.Equal(t, int64(1), text.ID)
+	require.Equal(t, int64(100), text.PeerID)
+	require.Equal(t, int64(7), text.FromID)
+	require.True(t, text.Post)
+	require.Equal(t, time.Unix(1700000000, 0), text.Timestamp)
+	require.Equal(t, time.Unix(1700000060, 0), *text.EditedAt)
+	require.Equal(t, 120, text.Views)
+	require.Equal(t, 3, text.Forwards)
+	require.Equal(t, []MessageEntity{
+		{Type: "textUrl", Offset: 4, Length: 11, URL: "https://example.com"},
+		{Type: "pre", Offset: 0, Length: 3, Language: "go"},
+	}, text.Entities)
+	require.Equal(t, &MessageReply{MessageID: 9, TopID: 2, Quote: "quote"}, text.ReplyTo)
+	require.Equal(t, []MessageReaction{
+		{Emoji: "👍", Count: 5},
+		{CustomEmojiID: 42, Count: 1},
+	}, text.Reactions)
+	require.Nil(t, text.Media)
+	require.Equal(t, 1, text.Raw.ID)
+
+	require.Equal(t, &MessageMedia{
+		Type:     MediaTypeVideo,
+		ID:       5,
+		MimeType: "video/mp4",
+		FileName: "clip.mp4",
+		Size:     2048,
+		Width:    640,
+		Height:   480,
+		Duration: 1500 * time.Millisecond,
+	}, video.Media)
+	require.Nil(t, video.EditedAt)
+
+	require.Equal(t, &MessageMedia{Type: MediaTypePhoto, ID: 6, Width: 1280, Height: 960, Size: 900}, photo.Media)
+
+	require.Len(t, requestsOf[*tg.MessagesGetHistoryRequest](invoker), 1)
+}
@@ -0,0 +1,242 @@
+package mtproto
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/celestix/gotgproto/storage"
+	"gorm.io/gorm"
+)
+
+// sealedSessionPrefix marks encrypted session data, sessions stored before
+// encryption was enabled are read as is and encrypted on the next write
+var sealedSessionPrefix = []byte("tgenc1:")
+
+var ErrSessionDecrypt = errors.New("decrypt session: wrong key or corrupted data")
+
+// SessionEncryption configures AES-GCM encryption of the stored session,
+// which holds the auth key of the account. Set either Key or KeyFunc.
+type SessionEncryption struct {
+	// Key is a 16, 24 or 32 byte AES key
+	Key []byte
+	// KeyFunc fetches the key, e.g. from a KMS, when the client initializes
+	KeyFunc func(ctx context.Context) ([]byte, error)
+}
+
+func (e SessionEncryption) enabled() bool {
+	return len(e.Key) > 0 || e.KeyFunc != nil
+}
+
+func (e SessionEncryption) problems() []error {
+	var problems []error
+
+	if len(e.Key) > 0 && e.KeyFunc != nil {
+		problems = append(problems, errors.New("SessionEncryption needs either Key or KeyFunc, not both"))
+	}
+
+	if n := len(e.Key); n > 0 && n != 16 && n != 24 && n != 32 {
+		problems = append(problems, fmt.Errorf("SessionEncryption.Key is %d bytes, it must be 16, 24 or 32 bytes", n))
+	}
+
+	return problems
+}
+
+func (e SessionEncryption) aead(ctx context.Context) (cipher.AEAD, error) {
+	key := e.Key
+	if e.KeyFunc != nil {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		var err error
+		if key, err = e.KeyFunc(ctx); err != nil {
+			return nil, fmt.Errorf("get session key: %w", err)
+		}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create session cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create session cipher: %w", err)
+	}
+
+	return aead, nil
+}
+
+// sessionDialector wraps the dialector handed to the session storage, to
+// encrypt the session data on writes and decrypt it on reads
+type sessionDialector struct {
+	gorm.Dialector
+	aead cipher.AEAD
+}
+
+func (d sessionDialector) Initialize(db *gorm.DB) error {
+	if err := d.Dialector.Initialize(db); err != nil {
+		return err
+	}
+
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().Before("gorm:create").Register("mtproto:seal_session", d.seal); err != nil {
+		return fmt.Errorf("register session callback: %w", err)
+	}
+	if err := callbacks.Create().After("gorm:create").Register("mtproto:open_session", d.open); err != nil {
+		return fmt.Errorf("register session callback: %w", err)
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("mtproto:seal_session", d.seal); err != nil {
+		return fmt.Errorf("register session callback: %w", err)
+	}
+	if err := callbacks.Update().After("gorm:update").Register("mtproto:open_session", d.open); err != nil {
+		return fmt.Errorf("register session callback: %w", err)
+	}
+	if err := callbacks.Query().After("gorm:query").Register("mtproto:open_session", d.open); err != nil {
+		return fmt.Errorf("register session callback: %w", err)
+	}
+
+	return nil
+}
+
+// seal encrypts the session before it is written, open restores it after
+func (d sessionDialector) seal(db *gorm.DB) {
+	eachSession(db, func(session *storage.Session) error {
+		sealed, err := sealSession(d.aead, session.Data)
+		if err != nil {
+			return err
+		}
+
+		session.Data = sealed
+
+		return nil
+	})
+}
+
+func (d sessionDialector) open(db *gorm.DB) {
+	eachSession(db, func(session *storage.Session) error {
+		data, err := openSession(d.aead, session.Data)
+		if err != nil {
+			return err
+		}
+
+		session.Data = data
+
+		return nil
+	})
+}
+
+// eachSession calls fn for the sessions in the statement, statements on
+// other tables are left alone
+func eachSession(db *gorm.DB, fn func(session *storage.Session) error) {
+	if db.Error != nil || !db.Statement.ReflectValue.IsValid() {
+		return
+	}
+
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	sessionType := reflect.TypeOf(storage.Session{})
+
+	apply := func(v reflect.Value) {
+		v = reflect.Indirect(v)
+		if v.Type() != sessionType || !v.CanAddr() {
+			return
+		}
+
+		if err := fn(v.Addr().Interface().(*storage.Session)); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		apply(value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			apply(value.Index(i))
+		}
+	}
+}
+
+func sealSession(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) == 0 || bytes.HasPrefix(data, sealedSessionPrefix) {
+		return data, nil
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("encrypt session: %w", err)
+	}
+
+	sealed := append([]byte{}, sealedSessionPrefix...)
+	sealed = append(sealed, nonce...)
+
+	return aead.Seal(sealed, nonce, data, sealedSessionPrefix), nil
+}
+
+func openSession(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, sealedSessionPrefix) {
+		return data, nil
+	}
+
+	sealed := data[len(sealedSessionPrefix):]
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrSessionDecrypt
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, ciphertext, sealedSessionPrefix)
+	if err != nil {
+		return nil, ErrSessionDecrypt
+	}
+
+	return plain, nil
+}
+
+// sessionDialector returns the dialector of the session storage, wrapped to
+// encrypt the session when SessionEncryption is set
+func (c *Client) sessionDialector(db *gorm.DB) (gorm.Dialector, error) {
+	if !c.cfg.SessionEncryption.enabled() {
+		return db.Dialector, nil
+	}
+
+	aead, err := c.cfg.SessionEncryption.aead(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The session storage ignores read errors, a session it can't decrypt
+	// would start a new login and overwrite it
+	if err := verifySessionKey(c.ctx, db, aead); err != nil {
+		return nil, err
+	}
+
+	return sessionDialector{Dialector: db.Dialector, aead: aead}, nil
+}
+
+func verifySessionKey(ctx context.Context, db *gorm.DB, aead cipher.AEAD) error {
+	if !db.Migrator().HasTable(&storage.Session{}) {
+		return nil
+	}
+
+	var sessions []storage.Session
+	if err := db.WithContext(ctx).Find(&sessions).Error; err != nil {
+		return fmt.Errorf("read session: %w", err)
+	}
+
+	for _, session := range sessions {
+		if _, err := openSession(aead, session.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package mtproto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestix/gotgproto/storage"
+	"github.com/test-go/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSessionEncryption(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "session.db")
+	key := bytes.Repeat([]byte{1}, 32)
+
+	aead, err := SessionEncryption{Key: key}.aead(context.Background())
+	require.NoError(t, err)
+
+	db, err := gorm.Open(sessionDialector{Dialector: sqlite.Open(dsn), aead: aead}, &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&storage.Session{}))
+
+	data := []byte(`{"Version":1,"Data":{"AuthKey":"secret"}}`)
+	require.NoError(t, db.Save(&storage.Session{Version: storage.LatestVersion, Data: data}).Error)
+
+	session := &storage.Session{Version: storage.LatestVersion}
+	require.NoError(t, db.Model(&storage.Session{}).Find(&session).Error)
+	require.Equal(t, data, session.Data)
+
+	// The stored data is encrypted
+	plain, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	var stored storage.Session
+	require.NoError(t, plain.First(&stored).Error)
+	require.True(t, bytes.HasPrefix(stored.Data, sealedSessionPrefix))
+	require.False(t, bytes.Contains(stored.Data, []byte("secret")))
+
+	require.NoError(t, verifySessionKey(context.Background(), plain, aead))
+
+	wrong, err := SessionEncryption{Key: bytes.Repeat([]byte{2}, 32)}.aead(context.Background())
+	require.NoError(t, err)
+	require.True(t, errors.Is(verifySessionKey(context.Background(), plain, wrong), ErrSessionDecrypt))
+}
+
+func TestOpenPlainSession(t *testing.T) {
+	aead, err := SessionEncryption{Key: bytes.Repeat([]byte{1}, 16)}.aead(context.Background())
+	require.NoError(t, err)
+
+	// Sessions stored before encryption was enabled are read as is
+	data, err := openSession(aead, []byte(`{"Version":1}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"Version":1}`), data)
+
+	require.Len(t, SessionEncryption{Key: []byte("short")}.problems(), 1)
+}
@@ -0,0 +1,209 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+)
+
+const (
+	engagementTable           = "post_engagement"
+	defaultEngagementInterval = 15 * time.Minute
+	defaultEngagementWindow   = 48 * time.Hour
+	defaultEngagementPosts    = 100
+)
+
+// PostEngagement is a recorded engagement sample of a post
+type PostEngagement struct {
+	ID        uint  `gorm:"primaryKey"`
+	ChannelID int64 `gorm:"index:idx_post_engagement,priority:1"`
+	MessageID int   `gorm:"index:idx_post_engagement,priority:2"`
+	Views     int
+	Forwards  int
+	Replies   int
+	// Reactions is the total reaction count
+	Reactions int
+	// ReactionCounts lists the counts per reaction as "emoji:count", comma separated
+	ReactionCounts string
+	PostedAt       time.Time
+	CreatedAt      time.Time `gorm:"index:idx_post_engagement,priority:3"`
+}
+
+// EngagementCollectorConfig configures the engagement collector
+type EngagementCollectorConfig struct {
+	// Channels are the chat IDs of the monitored channels
+	Channels []int64
+	// Interval between collection rounds, defaults to 15 minutes
+	Interval time.Duration
+	// Window is how long posts are revisited after posting, defaults to 48 hours
+	Window time.Duration
+	// MaxPosts is the maximum number of recent posts sampled per channel,
+	// defaults to 100
+	MaxPosts int
+}
+
+// EngagementCollector samples the views, forwards and reactions of recent
+// posts on a schedule, producing an engagement curve per post
+type EngagementCollector struct {
+	client *Client
+	cfg    EngagementCollectorConfig
+	db     *gorm.DB
+	table  string
+}
+
+// NewEngagementCollector creates a collector, the client must be initialized
+func (c *Client) NewEngagementCollector(cfg EngagementCollectorConfig) (*EngagementCollector, error) {
+	if c.db == nil {
+		return nil, ErrNotInitialized
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultEngagementInterval
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultEngagementWindow
+	}
+	if cfg.MaxPosts <= 0 {
+		cfg.MaxPosts = defaultEngagementPosts
+	}
+
+	e := &EngagementCollector{
+		client: c,
+		cfg:    cfg,
+		db:     c.db,
+		table:  c.cfg.DatabaseConfig.TablePrefix + engagementTable,
+	}
+
+	if err := e.db.Table(e.table).AutoMigrate(&PostEngagement{}); err != nil {
+		return nil, fmt.Errorf("migrate engagement: %w", err)
+	}
+
+	return e, nil
+}
+
+// Run collects the configured channels every interval until the context is done
+func (e *EngagementCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, chatID := range e.cfg.Channels {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if _, err := e.Collect(ctx, chatID); err != nil {
+				e.client.log().Error("failed to collect engagement",
+					slog.Int64("chatID", chatID),
+					slog.String("err", err.Error()),
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect records a sample of every post within the window and returns the
+// number of samples recorded
+func (e *EngagementCollector) Collect(ctx context.Context, chatID int64) (int, error) {
+	now := time.Now()
+
+	var samples []PostEngagement
+
+	opts := &ChannelMessagesOptions{
+		MinMessages: e.cfg.MaxPosts,
+		MinDate:     now.Add(-e.cfg.Window),
+		BatchSize:   min(e.cfg.MaxPosts, 100),
+	}
+
+	err := e.client.fetchChannelMessages(ctx, chatID, opts, func(batch []*tg.Message) (bool, error) {
+		for _, msg := range batch {
+			samples = append(samples, engagementSample(chatID, msg, now))
+		}
+
+		return len(samples) >= e.cfg.MaxPosts, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(samples) > e.cfg.MaxPosts {
+		samples = samples[:e.cfg.MaxPosts]
+	}
+
+	if len(samples) == 0 {
+		return 0, nil
+	}
+
+	if err := e.db.WithContext(ctx).Table(e.table).Create(&samples).Error; err != nil {
+		return 0, fmt.Errorf("save engagement: %w", err)
+	}
+
+	return len(samples), nil
+}
+
+// Curve returns the engagement samples of a post, oldest first
+func (e *EngagementCollector) Curve(ctx context.Context, channelID int64, messageID int) ([]PostEngagement, error) {
+	var samples []PostEngagement
+
+	err := e.db.WithContext(ctx).Table(e.table).
+		Where("channel_id = ? AND message_id = ?", channelID, messageID).
+		Order("created_at ASC").
+		Find(&samples).Error
+	if err != nil {
+		return nil, fmt.Errorf("list engagement: %w", err)
+	}
+
+	return samples, nil
+}
+
+func engagementSample(chatID int64, msg *tg.Message, now time.Time) PostEngagement {
+	sample := PostEngagement{
+		ChannelID: chatID,
+		MessageID: msg.ID,
+		PostedAt:  time.Unix(int64(msg.Date), 0),
+		CreatedAt: now,
+	}
+
+	sample.Views, _ = msg.GetViews()
+	sample.Forwards, _ = msg.GetForwards()
+
+	if replies, ok := msg.GetReplies(); ok {
+		sample.Replies = replies.Replies
+	}
+
+	if reactions, ok := msg.GetReactions(); ok {
+		counts := make([]string, 0, len(reactions.Results))
+
+		for _, r := range convertReactions(reactions) {
+			sample.Reactions += r.Count
+
+			key := r.Emoji
+			switch {
+			case r.CustomEmojiID != 0:
+				key = strconv.FormatInt(r.CustomEmojiID, 10)
+			case r.Paid:
+				key = "paid"
+			}
+
+			counts = append(counts, key+":"+strconv.Itoa(r.Count))
+		}
+
+		sort.Strings(counts)
+		sample.ReactionCounts = strings.Join(counts, ",")
+	}
+
+	return sample
+}
@@ -0,0 +1,93 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestEngagementCollector(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	now := time.Now()
+
+	post := &tg.Message{
+		ID:       3,
+		Message:  "recent",
+		Date:     int(now.Add(-time.Hour).Unix()),
+		Views:    10,
+		Forwards: 1,
+		Replies:  tg.MessageReplies{Replies: 2},
+		Reactions: tg.MessageReactions{Results: []tg.ReactionCount{
+			{Reaction: &tg.ReactionEmoji{Emoticon: "🔥"}, Count: 4},
+			{Reaction: &tg.ReactionCustomEmoji{DocumentID: 42}, Count: 1},
+			{Reaction: &tg.ReactionPaid{}, Count: 2},
+		}},
+	}
+
+	backend.AddMessages(100,
+		&tg.Message{ID: 1, Message: "outside the window", Date: int(now.Add(-72 * time.Hour).Unix()), Views: 1000},
+		&tg.Message{ID: 2, Message: "older", Date: int(now.Add(-2 * time.Hour).Unix()), Views: 50},
+		post,
+	)
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	client := NewTestClient(logger, invoker, nil)
+
+	_, err := client.NewEngagementCollector(EngagementCollectorConfig{})
+	require.True(t, errors.Is(err, ErrNotInitialized))
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	client.db = db
+
+	collector, err := client.NewEngagementCollector(EngagementCollectorConfig{MaxPosts: 10})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	n, err := collector.Collect(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	requests := requestsOf[*tg.MessagesGetHistoryRequest](invoker)
+	require.Len(t, requests, 1)
+	require.Equal(t, 10, requests[0].Limit)
+
+	post.Views = 25
+
+	n, err = collector.Collect(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	curve, err := collector.Curve(ctx, 100, 3)
+	require.NoError(t, err)
+	require.Len(t, curve, 2)
+	require.Equal(t, 10, curve[0].Views)
+	require.Equal(t, 25, curve[1].Views)
+	require.Equal(t, 1, curve[1].Forwards)
+	require.Equal(t, 2, curve[1].Replies)
+	require.Equal(t, 7, curve[1].Reactions)
+	require.Equal(t, "42:1,paid:2,🔥:4", curve[1].ReactionCounts)
+	require.Equal(t, int64(post.Date), curve[1].PostedAt.Unix())
+
+	// Posts outside the window aren't sampled
+	curve, err = collector.Curve(ctx, 100, 1)
+	require.NoError(t, err)
+	require.Empty(t, curve)
+
+	// MaxPosts bounds the samples per round
+	collector, err = client.NewEngagementCollector(EngagementCollectorConfig{MaxPosts: 1})
+	require.NoError(t, err)
+
+	n, err = collector.Collect(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
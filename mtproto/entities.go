@@ -0,0 +1,138 @@
+package mtproto
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gotd/td/tg"
+)
+
+// Entity kinds returned by ExtractEntities
+const (
+	EntityURL     = "url"
+	EntityMention = "mention"
+	EntityHashtag = "hashtag"
+	EntityCashtag = "cashtag"
+)
+
+// ExtractedEntity is a normalized entity with byte offsets into the message
+// text, Telegram counts offsets in UTF-16 code units
+type ExtractedEntity struct {
+	Kind string
+	// Value is the normalized value: an absolute URL, a lowercase username
+	// or hashtag without prefix, or an uppercase cashtag without prefix
+	Value string
+	// Text is the entity as it appears in the message
+	Text string
+	// Start and End are byte offsets into the message text
+	Start int
+	End   int
+	// UserID is set for mentions of users without a username
+	UserID int64
+}
+
+// ExtractedEntities groups the extracted entities of a message by kind
+type ExtractedEntities struct {
+	URLs     []ExtractedEntity
+	Mentions []ExtractedEntity
+	Hashtags []ExtractedEntity
+	Cashtags []ExtractedEntity
+}
+
+// ExtractEntities returns the links, mentions, hashtags and cashtags of a message
+func ExtractEntities(msg *tg.Message) ExtractedEntities {
+	var result ExtractedEntities
+	if msg == nil {
+		return result
+	}
+
+	text := msg.Message
+	offsets := utf16ByteOffsets(text)
+
+	for _, entity := range msg.Entities {
+		start, end, ok := entityRange(offsets, entity.GetOffset(), entity.GetLength())
+		if !ok {
+			continue
+		}
+
+		e := ExtractedEntity{Text: text[start:end], Start: start, End: end}
+
+		switch v := entity.(type) {
+		case *tg.MessageEntityURL:
+			e.Kind, e.Value = EntityURL, NormalizeURL(e.Text)
+			result.URLs = append(result.URLs, e)
+		case *tg.MessageEntityTextURL:
+			e.Kind, e.Value = EntityURL, NormalizeURL(v.URL)
+			result.URLs = append(result.URLs, e)
+		case *tg.MessageEntityMention:
+			e.Kind, e.Value = EntityMention, strings.ToLower(strings.TrimPrefix(e.Text, "@"))
+			result.Mentions = append(result.Mentions, e)
+		case *tg.MessageEntityMentionName:
+			e.Kind, e.Value, e.UserID = EntityMention, e.Text, v.UserID
+			result.Mentions = append(result.Mentions, e)
+		case *tg.MessageEntityHashtag:
+			e.Kind, e.Value = EntityHashtag, strings.ToLower(strings.TrimPrefix(e.Text, "#"))
+			result.Hashtags = append(result.Hashtags, e)
+		case *tg.MessageEntityCashtag:
+			e.Kind, e.Value = EntityCashtag, strings.ToUpper(strings.TrimPrefix(e.Text, "$"))
+			result.Cashtags = append(result.Cashtags, e)
+		}
+	}
+
+	return result
+}
+
+// NormalizeURL adds a missing scheme and lowercases the scheme and host,
+// invalid URLs are returned trimmed but otherwise unchanged
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return raw
+	}
+
+	if !strings.Contains(raw, "://") && !strings.HasPrefix(raw, "mailto:") && !strings.HasPrefix(raw, "tg:") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	switch {
+	case u.Scheme == "http" && strings.HasSuffix(u.Host, ":80"):
+		u.Host = strings.TrimSuffix(u.Host, ":80")
+	case u.Scheme == "https" && strings.HasSuffix(u.Host, ":443"):
+		u.Host = strings.TrimSuffix(u.Host, ":443")
+	}
+
+	return u.String()
+}
+
+// utf16ByteOffsets maps every UTF-16 offset of the text to its byte offset,
+// the last element is the length of the text
+func utf16ByteOffsets(text string) []int {
+	offsets := make([]int, 0, len(text)+1)
+
+	for i, r := range text {
+		offsets = append(offsets, i)
+		if r >= 0x10000 {
+			// Offsets inside a surrogate pair point to the start of the rune
+			offsets = append(offsets, i)
+		}
+	}
+
+	return append(offsets, len(text))
+}
+
+func entityRange(offsets []int, offset, length int) (int, int, bool) {
+	end := offset + length
+	if offset < 0 || length <= 0 || end >= len(offsets) {
+		return 0, 0, false
+	}
+
+	return offsets[offset], offsets[end], true
+}
@@ -0,0 +1,51 @@
+package mtproto
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestExtractEntities(t *testing.T) {
+	// The emoji takes two UTF-16 code units and four bytes
+	text := "😀 @Alice see Example.com/Path #GoLang $btc"
+
+	msg := &tg.Message{
+		Message: text,
+		Entities: []tg.MessageEntityClass{
+			&tg.MessageEntityMention{Offset: 3, Length: 6},
+			&tg.MessageEntityURL{Offset: 14, Length: 16},
+			&tg.MessageEntityHashtag{Offset: 31, Length: 7},
+			&tg.MessageEntityCashtag{Offset: 39, Length: 4},
+			&tg.MessageEntityTextURL{Offset: 10, Length: 3, URL: "HTTPS://Docs.Example.com:443/x"},
+		},
+	}
+
+	entities := ExtractEntities(msg)
+
+	require.Len(t, entities.Mentions, 1)
+	require.Equal(t, "@Alice", entities.Mentions[0].Text)
+	require.Equal(t, "alice", entities.Mentions[0].Value)
+	require.Equal(t, "@Alice", text[entities.Mentions[0].Start:entities.Mentions[0].End])
+
+	require.Len(t, entities.URLs, 2)
+	require.Equal(t, "https://example.com/Path", entities.URLs[0].Value)
+	require.Equal(t, "see", entities.URLs[1].Text)
+	require.Equal(t, "https://docs.example.com/x", entities.URLs[1].Value)
+
+	require.Len(t, entities.Hashtags, 1)
+	require.Equal(t, "golang", entities.Hashtags[0].Value)
+
+	require.Len(t, entities.Cashtags, 1)
+	require.Equal(t, "BTC", entities.Cashtags[0].Value)
+}
+
+func TestExtractEntitiesOutOfRange(t *testing.T) {
+	msg := &tg.Message{
+		Message:  "short",
+		Entities: []tg.MessageEntityClass{&tg.MessageEntityURL{Offset: 2, Length: 10}},
+	}
+
+	require.Empty(t, ExtractEntities(msg).URLs)
+}
@@ -0,0 +1,273 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/ratelimit"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot/cache"
+)
+
+// ErrFakeUnsupported is returned by FakeBackend for requests it has no
+// fixtures for
+var ErrFakeUnsupported = errors.New("request not supported by the fake backend")
+
+// NewTestClient creates a client that sends its requests to invoker instead
+// of Telegram, for unit tests without an account. Use a FakeBackend or any
+// tg.Invoker replaying recorded responses. cfg is optional, its PeerCache,
+// Checkpointer and RateLimit are used and its Middlewares wrap the invoker
+// like they wrap a connection.
+func NewTestClient(logger *slog.Logger, invoker tg.Invoker, cfg *Config) *Client {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	if cfg.PeerCache == nil {
+		cfg.PeerCache = cache.NewMemory[tg.InputChannel]()
+	}
+
+	// The first middleware is the outermost
+	for i := len(cfg.Middlewares) - 1; i >= 0; i-- {
+		invoker = cfg.Middlewares[i].Handle(invoker)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	client := &Client{
+		cfg:          cfg,
+		ctx:          ctx,
+		cancel:       cancel,
+		testAPI:      tg.NewClient(invoker),
+		checkpointer: cfg.Checkpointer,
+		started:      true,
+	}
+
+	client.logger.Store(logger.With(slog.String("account", client.AccountLabel())))
+
+	if cfg.RateLimit.RequestsPerMinute > 0 {
+		client.limiter = ratelimit.New(cfg.RateLimit.RequestsPerMinute, ratelimit.Per(time.Minute))
+	}
+
+	return client
+}
+
+// FakeBackend is an in-memory Telegram for NewTestClient. It answers the
+// requests the client makes to resolve and search channels, read their
+// history and members and send messages from the fixtures added to it.
+// Other requests fail with ErrFakeUnsupported.
+type FakeBackend struct {
+	mu sync.Mutex
+
+	channels map[int64]*tg.Channel
+	messages map[int64][]*tg.Message
+	members  map[int64][]*tg.User
+	sent     []*tg.MessagesSendMessageRequest
+	nextID   int
+}
+
+// NewFakeBackend creates an empty FakeBackend
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		channels: make(map[int64]*tg.Channel),
+		messages: make(map[int64][]*tg.Message),
+		members:  make(map[int64][]*tg.User),
+	}
+}
+
+// AddChannel adds a channel that resolves by its ID and username
+func (f *FakeBackend) AddChannel(id int64, username, title string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	channel := &tg.Channel{
+		ID:         id,
+		AccessHash: id ^ 0x5f5f5f5f,
+		Title:      title,
+		Photo:      &tg.ChatPhotoEmpty{},
+		Date:       int(time.Now().Unix()),
+		Broadcast:  true,
+	}
+	channel.SetUsername(username)
+
+	f.channels[id] = channel
+}
+
+// AddMessages adds messages to the history of a channel, their peer is set
+// to the channel
+func (f *FakeBackend) AddMessages(channelID int64, msgs ...*tg.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	history := f.messages[channelID]
+	for _, msg := range msgs {
+		msg.PeerID = &tg.PeerChannel{ChannelID: channelID}
+		history = append(history, msg)
+	}
+
+	// The history is returned newest first, like Telegram does
+	sort.Slice(history, func(i, j int) bool { return history[i].ID > history[j].ID })
+	f.messages[channelID] = history
+}
+
+// AddMembers adds members to a channel
+func (f *FakeBackend) AddMembers(channelID int64, users ...*tg.User) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.members[channelID] = append(f.members[channelID], users...)
+}
+
+// Sent returns the messages sent through the backend
+func (f *FakeBackend) Sent() []*tg.MessagesSendMessageRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*tg.MessagesSendMessageRequest(nil), f.sent...)
+}
+
+// Invoke implements tg.Invoker
+func (f *FakeBackend) Invoke(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	result, err := f.handle(input)
+	f.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	// The result is passed through its wire encoding, like a real response
+	var buf bin.Buffer
+	if err := result.Encode(&buf); err != nil {
+		return fmt.Errorf("encode fake %T: %w", result, err)
+	}
+
+	return output.Decode(&buf)
+}
+
+func (f *FakeBackend) handle(input bin.Encoder) (bin.Encoder, error) {
+	switch req := input.(type) {
+	case *tg.ContactsResolveUsernameRequest:
+		for _, channel := range f.channels {
+			if username, ok := channel.GetUsername(); ok && strings.EqualFold(username, req.Username) {
+				return &tg.ContactsResolvedPeer{
+					Peer:  &tg.PeerChannel{ChannelID: channel.ID},
+					Chats: []tg.ChatClass{channel},
+				}, nil
+			}
+		}
+
+		return nil, tgerr.New(400, "USERNAME_NOT_OCCUPIED")
+
+	case *tg.ContactsSearchRequest:
+		found := &tg.ContactsFound{}
+		query := strings.ToLower(req.Q)
+
+		ids := make([]int64, 0, len(f.channels))
+		for id := range f.channels {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for _, id := range ids {
+			channel := f.channels[id]
+			if len(found.Results) >= req.Limit {
+				break
+			}
+
+			if strings.Contains(strings.ToLower(channel.Title), query) ||
+				strings.Contains(strings.ToLower(channel.Username), query) {
+				found.Results = append(found.Results, &tg.PeerChannel{ChannelID: channel.ID})
+				found.Chats = append(found.Chats, channel)
+			}
+		}
+
+		return found, nil
+
+	case *tg.ChannelsGetChannelsRequest:
+		var chats []tg.ChatClass
+		for _, input := range req.ID {
+			if input, ok := input.(*tg.InputChannel); ok {
+				if channel, ok := f.channels[input.ChannelID]; ok {
+					chats = append(chats, channel)
+				}
+			}
+		}
+
+		return &tg.MessagesChats{Chats: chats}, nil
+
+	case *tg.MessagesGetHistoryRequest:
+		peer, ok := req.Peer.(*tg.InputPeerChannel)
+		if !ok || f.channels[peer.ChannelID] == nil {
+			return nil, tgerr.New(400, "CHANNEL_INVALID")
+		}
+
+		history := f.messages[peer.ChannelID]
+
+		var page []tg.MessageClass
+		for _, msg := range history {
+			if len(page) >= req.Limit {
+				break
+			}
+			if (req.OffsetID > 0 && msg.ID >= req.OffsetID) || msg.ID <= req.MinID ||
+				(req.OffsetDate > 0 && msg.Date >= req.OffsetDate) {
+				continue
+			}
+
+			page = append(page, msg)
+		}
+
+		return &tg.MessagesChannelMessages{
+			Count:    len(history),
+			Messages: page,
+			Chats:    []tg.ChatClass{f.channels[peer.ChannelID]},
+		}, nil
+
+	case *tg.ChannelsGetParticipantsRequest:
+		input, ok := req.Channel.(*tg.InputChannel)
+		if !ok || f.channels[input.ChannelID] == nil {
+			return nil, tgerr.New(400, "CHANNEL_INVALID")
+		}
+
+		members := f.members[input.ChannelID]
+		start := min(req.Offset, len(members))
+		end := min(start+req.Limit, len(members))
+
+		result := &tg.ChannelsChannelParticipants{Count: len(members)}
+		for _, user := range members[start:end] {
+			result.Participants = append(result.Participants, &tg.ChannelParticipant{UserID: user.ID})
+			result.Users = append(result.Users, user)
+		}
+
+		return result, nil
+
+	case *tg.MessagesSendMessageRequest:
+		f.sent = append(f.sent, req)
+		f.nextID++
+
+		return &tg.UpdateShortSentMessage{
+			Out:  true,
+			ID:   f.nextID,
+			Date: int(time.Now().Unix()),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrFakeUnsupported, input)
+}
@@ -0,0 +1,192 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+// stubInvoker records the requests sent to the fake backend and answers the
+// ones respond returns a result or an error for, the others are passed on
+type stubInvoker struct {
+	*FakeBackend
+	respond func(input bin.Encoder) (bin.Encoder, error)
+
+	mu       sync.Mutex
+	requests []bin.Encoder
+}
+
+func (s *stubInvoker) Invoke(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+	s.mu.Lock()
+	s.requests = append(s.requests, input)
+	s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if s.respond != nil {
+		result, err := s.respond(input)
+		if err != nil {
+			return err
+		}
+
+		if result != nil {
+			var buf bin.Buffer
+			if err := result.Encode(&buf); err != nil {
+				return err
+			}
+
+			return output.Decode(&buf)
+		}
+	}
+
+	return s.FakeBackend.Invoke(ctx, input, output)
+}
+
+// requestsOf returns the recorded requests of type T
+func requestsOf[T bin.Encoder](s *stubInvoker) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []T
+	for _, input := range s.requests {
+		if req, ok := input.(T); ok {
+			requests = append(requests, req)
+		}
+	}
+
+	return requests
+}
+
+func TestFakeBackend(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	for id := 1; id <= 5; id++ {
+		backend.AddMessages(100, &tg.Message{ID: id, Message: "message", Date: 1700000000 + id})
+	}
+
+	backend.AddMembers(100,
+		&tg.User{ID: 1, FirstName: "Alice"},
+		&tg.User{ID: 2, FirstName: "Bob"},
+	)
+
+	client := NewTestClient(logger, backend, nil)
+
+	messages, err := client.GetChannelMessages(100, &ChannelMessagesOptions{MinMessages: 3, BatchSize: 2})
+	require.NoError(t, err)
+	require.Len(t, messages, 4)
+	require.Equal(t, 5, messages[0].ID)
+	require.Equal(t, 2, messages[3].ID)
+
+	members, err := client.GetChannelMembers(context.Background(), "news", nil)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.Equal(t, int64(2), members[1].ID)
+
+	_, err = client.GetChannelMembers(context.Background(), "unknown", nil)
+	require.Error(t, err)
+
+	sent, err := client.SendMessage(42, "hello", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, sent.ID)
+	require.Equal(t, "hello", sent.Message)
+	require.Len(t, backend.Sent(), 1)
+
+	// Requests without fixtures fail
+	err = client.SetEmojiStatus(context.Background(), EmojiStatus{DocumentID: 1})
+	require.True(t, errors.Is(err, ErrFakeUnsupported))
+}
+
+func TestFindMessageIDAtDate(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	// One message per hour
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for id := 1; id <= 48; id++ {
+		backend.AddMessages(100, &tg.Message{ID: id, Message: "message", Date: int(start.Add(time.Duration(id) * time.Hour).Unix())})
+	}
+
+	client := NewTestClient(logger, backend, nil)
+	ctx := context.Background()
+
+	id, err := client.FindMessageIDAtDate(ctx, 100, start.Add(10*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 10, id)
+
+	id, err = client.FindMessageIDAtDate(ctx, 100, start.Add(10*time.Hour+30*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 10, id)
+
+	id, err = client.FindMessageIDAtDate(ctx, 100, start)
+	require.NoError(t, err)
+	require.Zero(t, id)
+
+	// A window fetch starts at MaxDate and stops at MinDate
+	messages, err := client.GetChannelMessages(100, &ChannelMessagesOptions{
+		MinDate:   start.Add(20 * time.Hour),
+		MaxDate:   start.Add(24 * time.Hour),
+		BatchSize: 2,
+		Sleep:     time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 5)
+	require.Equal(t, 24, messages[0].ID)
+	require.Equal(t, 20, messages[4].ID)
+}
+
+func TestTestClientMiddlewares(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	var calls []string
+	record := func(name string) telegram.Middleware {
+		return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+			return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+				calls = append(calls, name+":"+input.(interface{ TypeName() string }).TypeName())
+				return next.Invoke(ctx, input, output)
+			}
+		})
+	}
+
+	// A mock answering the requests the backend has no fixtures for
+	mock := telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			if _, ok := input.(*tg.AccountUpdateEmojiStatusRequest); ok {
+				var buf bin.Buffer
+				if err := (&tg.BoolTrue{}).Encode(&buf); err != nil {
+					return err
+				}
+
+				return output.Decode(&buf)
+			}
+
+			return next.Invoke(ctx, input, output)
+		}
+	})
+
+	client := NewTestClient(logger, backend, &Config{
+		Middlewares: []telegram.Middleware{record("outer"), mock, record("inner")},
+	})
+
+	_, err := client.GetChannelMembers(context.Background(), "news", nil)
+	require.NoError(t, err)
+	require.NoError(t, client.SetEmojiStatus(context.Background(), EmojiStatus{DocumentID: 1}))
+
+	require.Equal(t, []string{
+		"outer:contacts.resolveUsername",
+		"inner:contacts.resolveUsername",
+		"outer:channels.getParticipants",
+		"inner:channels.getParticipants",
+		"outer:account.updateEmojiStatus",
+	}, calls)
+}
@@ -0,0 +1,147 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gammazero/workerpool"
+	"github.com/gotd/td/tg"
+	"golang.org/x/exp/slog"
+)
+
+const defaultFetchWorkers = 5
+
+// FetchJob describes what to fetch from a single channel
+type FetchJob struct {
+	// Username of the channel, required for member fetches
+	Username string
+	// ChatID of the channel, resolved from the username if zero
+	ChatID int64
+	// Messages fetches the channel history when set
+	Messages *ChannelMessagesOptions
+	// Members fetches the channel members when set
+	Members *ChannelMembersOptions
+}
+
+// FetchResult holds the outcome of a single job
+type FetchResult struct {
+	Job      FetchJob
+	Messages []*tg.Message
+	Members  []*Member
+	// Err is a *ResumableError when the job failed part way, Messages and
+	// Members hold what was fetched before
+	Err error
+}
+
+// FetchOptions configures a multi-channel fetch
+type FetchOptions struct {
+	// Workers is the number of channels fetched in parallel, defaults to 5.
+	// Use Config.RateLimit to bound the request rate across all workers.
+	Workers int
+}
+
+// FetchChannels fetches the jobs in parallel and emits a result per job on
+// the returned channel, which is closed once all jobs are done. Jobs not yet
+// started when the context is cancelled report the context error, results
+// not read by then may be dropped so the workers don't block on a caller
+// that stopped reading.
+func (c *Client) FetchChannels(ctx context.Context, jobs []FetchJob, opts *FetchOptions) <-chan FetchResult {
+	workers := defaultFetchWorkers
+	if opts != nil && opts.Workers > 0 {
+		workers = opts.Workers
+	}
+
+	results := make(chan FetchResult, workers)
+	pool := workerpool.New(workers)
+
+	go func() {
+		defer close(results)
+
+		for _, job := range jobs {
+			job := job
+			pool.Submit(func() {
+				select {
+				case results <- c.fetchJob(ctx, job):
+				case <-ctx.Done():
+				}
+			})
+		}
+
+		pool.StopWait()
+	}()
+
+	return results
+}
+
+func (c *Client) fetchJob(ctx context.Context, job FetchJob) FetchResult {
+	result := FetchResult{Job: job}
+
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if job.ChatID == 0 {
+		if job.Username == "" {
+			result.Err = fmt.Errorf("job has no username or chat ID")
+			return result
+		}
+
+		input, err := c.getChannelInputByUsername(job.Username)
+		if err != nil {
+			result.Err = fmt.Errorf("resolve channel: %w", err)
+			return result
+		}
+
+		result.Job.ChatID = input.ChannelID
+	}
+
+	if job.Messages != nil {
+		// Copy the options, the fetch fills in defaults
+		msgOpts := *job.Messages
+
+		err := c.fetchChannelMessages(ctx, result.Job.ChatID, &msgOpts, func(batch []*tg.Message) (bool, error) {
+			var done bool
+
+			if msgOpts.Hook != nil {
+				for _, msg := range batch {
+					if msgOpts.Hook(msg) {
+						done = true
+						break
+					}
+				}
+			}
+
+			result.Messages = append(result.Messages, batch...)
+
+			return done, nil
+		})
+		if err != nil {
+			result.Err = fmt.Errorf("get messages: %w", err)
+			return result
+		}
+	}
+
+	if job.Members != nil {
+		if job.Username == "" {
+			result.Err = fmt.Errorf("member fetch requires a username")
+			return result
+		}
+
+		members, err := c.GetChannelMembers(ctx, job.Username, job.Members)
+		// Members fetched before a failure are kept, like the messages
+		result.Members = members
+		if err != nil {
+			result.Err = fmt.Errorf("get members: %w", err)
+			return result
+		}
+	}
+
+	c.log().Debug("Fetched channel",
+		slog.Int64("chatID", result.Job.ChatID),
+		slog.Int("messages", len(result.Messages)),
+		slog.Int("members", len(result.Members)),
+	)
+
+	return result
+}
@@ -0,0 +1,89 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestFetchChannels(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+	backend.AddChannel(200, "sports", "Sports")
+
+	for id := 1; id <= 4; id++ {
+		backend.AddMessages(100, &tg.Message{ID: id, Message: "news", Date: 1700000000 + id})
+		backend.AddMessages(200, &tg.Message{ID: id, Message: "sports", Date: 1700000000 + id})
+	}
+
+	backend.AddMembers(200, &tg.User{ID: 1, FirstName: "Alice"})
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	client := NewTestClient(logger, invoker, nil)
+
+	byID := &ChannelMessagesOptions{MinMessages: 10, BatchSize: 10}
+	byUsername := &ChannelMessagesOptions{
+		MinMessages: 10,
+		BatchSize:   10,
+		Hook:        func(msg *tg.Message) bool { return msg.ID == 3 },
+	}
+	unknown := &ChannelMessagesOptions{}
+	empty := &ChannelMessagesOptions{}
+
+	jobs := []FetchJob{
+		{ChatID: 100, Messages: byID},
+		{Username: "sports", Messages: byUsername, Members: &ChannelMembersOptions{}},
+		{Username: "unknown", Messages: unknown},
+		{Messages: empty},
+	}
+
+	// The message options tell the results apart
+	results := make(map[*ChannelMessagesOptions]FetchResult)
+	for result := range client.FetchChannels(context.Background(), jobs, &FetchOptions{Workers: 2}) {
+		results[result.Job.Messages] = result
+	}
+	require.Len(t, results, len(jobs))
+
+	news := results[byID]
+	require.NoError(t, news.Err)
+	require.Len(t, news.Messages, 4)
+	require.Equal(t, 4, news.Messages[0].ID)
+
+	// The username is resolved, the hook stops the fetch after its batch
+	sports := results[byUsername]
+	require.NoError(t, sports.Err)
+	require.Equal(t, int64(200), sports.Job.ChatID)
+	require.Len(t, sports.Messages, 4)
+	require.Equal(t, "sports", sports.Messages[0].Message)
+	require.Len(t, sports.Members, 1)
+	require.Equal(t, "Alice", sports.Members[0].FirstName)
+
+	require.Error(t, results[unknown].Err)
+	require.Contains(t, results[empty].Err.Error(), "no username or chat ID")
+
+	var resolved []string
+	for _, req := range requestsOf[*tg.ContactsResolveUsernameRequest](invoker) {
+		resolved = append(resolved, req.Username)
+	}
+	sort.Strings(resolved)
+	require.Equal(t, []string{"sports", "unknown"}, resolved)
+
+	peers := make(map[int64]int)
+	for _, req := range requestsOf[*tg.MessagesGetHistoryRequest](invoker) {
+		peers[req.Peer.(*tg.InputPeerChannel).ChannelID]++
+		require.Equal(t, 10, req.Limit)
+	}
+	require.Equal(t, map[int64]int{100: 1, 200: 1}, peers)
+
+	// Jobs of a canceled fetch report the context error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for result := range client.FetchChannels(ctx, jobs, nil) {
+		require.True(t, errors.Is(result.Err, context.Canceled))
+	}
+}
@@ -0,0 +1,180 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// Peer is a resolved user, chat or channel
+type Peer struct {
+	ID         int64
+	Kind       ForwardKind
+	AccessHash int64 `json:"-"`
+	Title      string
+	Username   string
+}
+
+// ForwardEdge links a forwarded message to the message it was forwarded from
+type ForwardEdge struct {
+	// FromPeerID is the original sender, zero for hidden users
+	FromPeerID int64
+	FromKind   ForwardKind
+	FromName   string
+	// FromPostID is the original channel post, zero for users and chats
+	FromPostID int
+	// ToPeerID and ToMessageID are the forwarded copy
+	ToPeerID    int64
+	ToMessageID int64
+	// OriginalDate is the date of the original message
+	OriginalDate time.Time
+	ForwardedAt  time.Time
+}
+
+// ForwardEdges returns the provenance edges of the forwarded messages
+func ForwardEdges(msgs []*Message) []ForwardEdge {
+	var edges []ForwardEdge
+
+	for _, msg := range msgs {
+		if msg == nil || msg.Forward == nil {
+			continue
+		}
+
+		edges = append(edges, ForwardEdge{
+			FromPeerID:   msg.Forward.FromID,
+			FromKind:     msg.Forward.Kind,
+			FromName:     msg.Forward.FromName,
+			FromPostID:   msg.Forward.ChannelPostID,
+			ToPeerID:     msg.PeerID,
+			ToMessageID:  msg.ID,
+			OriginalDate: msg.Forward.Date,
+			ForwardedAt:  msg.Timestamp,
+		})
+	}
+
+	return edges
+}
+
+// ResolveForwardPeers resolves the original senders of the forwarded messages.
+// Peers seen in fetched history are resolved from memory, unknown channels
+// are looked up through the API. Hidden users are not included.
+func (c *Client) ResolveForwardPeers(ctx context.Context, msgs []*Message) (map[int64]*Peer, error) {
+	peers := make(map[int64]*Peer)
+
+	var unknown []tg.InputChannelClass
+	for _, edge := range ForwardEdges(msgs) {
+		if edge.FromPeerID == 0 {
+			continue
+		}
+		if _, ok := peers[edge.FromPeerID]; ok {
+			continue
+		}
+
+		if peer, ok := c.peers.get(edge.FromPeerID); ok {
+			peers[edge.FromPeerID] = peer
+			continue
+		}
+
+		peers[edge.FromPeerID] = &Peer{ID: edge.FromPeerID, Kind: edge.FromKind}
+
+		if edge.FromKind == ForwardFromChannel {
+			unknown = append(unknown, &tg.InputChannel{ChannelID: edge.FromPeerID})
+		}
+	}
+
+	if len(unknown) == 0 {
+		return peers, nil
+	}
+
+	c.takeRequest()
+
+	res, err := c.api().ChannelsGetChannels(ctx, unknown)
+	if err != nil {
+		return peers, fmt.Errorf("get channels: %w", err)
+	}
+
+	for _, chat := range res.GetChats() {
+		if peer := c.peers.addChat(chat); peer != nil {
+			peers[peer.ID] = peer
+		}
+	}
+
+	return peers, nil
+}
+
+// peerStore remembers the peers included in API responses, so forwards can
+// be resolved without extra requests
+type peerStore struct {
+	mu    sync.RWMutex
+	peers map[int64]*Peer
+}
+
+func (s *peerStore) get(id int64) (*Peer, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	peer, ok := s.peers[id]
+	return peer, ok
+}
+
+func (s *peerStore) add(peer *Peer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peers == nil {
+		s.peers = make(map[int64]*Peer)
+	}
+	s.peers[peer.ID] = peer
+}
+
+func (s *peerStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.peers = nil
+}
+
+func (s *peerStore) addChat(chat tg.ChatClass) *Peer {
+	var peer *Peer
+
+	switch v := chat.(type) {
+	case *tg.Channel:
+		peer = &Peer{
+			ID:         v.ID,
+			Kind:       ForwardFromChannel,
+			AccessHash: v.AccessHash,
+			Title:      v.Title,
+			Username:   v.Username,
+		}
+	case *tg.Chat:
+		peer = &Peer{ID: v.ID, Kind: ForwardFromChat, Title: v.Title}
+	default:
+		return nil
+	}
+
+	s.add(peer)
+	return peer
+}
+
+func (s *peerStore) addUser(user tg.UserClass) {
+	u, ok := user.(*tg.User)
+	if !ok {
+		return
+	}
+
+	title := u.FirstName
+	if u.LastName != "" {
+		title += " " + u.LastName
+	}
+
+	s.add(&Peer{
+		ID:         u.ID,
+		Kind:       ForwardFromUser,
+		AccessHash: u.AccessHash,
+		Title:      title,
+		Username:   u.Username,
+	})
+}
@@ -0,0 +1,94 @@
+package mtproto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestResolveForwardPeers(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+	backend.AddChannel(200, "source", "Source")
+
+	backend.AddMessages(100,
+		&tg.Message{ID: 1, Message: "from a channel", Date: 1700000100, FwdFrom: tg.MessageFwdHeader{
+			FromID:      &tg.PeerChannel{ChannelID: 200},
+			ChannelPost: 55,
+			Date:        1700000000,
+		}},
+		&tg.Message{ID: 2, Message: "from itself", Date: 1700000200, FwdFrom: tg.MessageFwdHeader{
+			FromID:      &tg.PeerChannel{ChannelID: 100},
+			ChannelPost: 1,
+			Date:        1700000100,
+		}},
+		&tg.Message{ID: 3, Message: "from a user", Date: 1700000300, FwdFrom: tg.MessageFwdHeader{
+			FromID: &tg.PeerUser{UserID: 7},
+			Date:   1700000250,
+		}},
+		&tg.Message{ID: 4, Message: "from a hidden user", Date: 1700000400, FwdFrom: tg.MessageFwdHeader{
+			FromName: "Anonymous",
+			Date:     1700000350,
+		}},
+		&tg.Message{ID: 5, Message: "not forwarded", Date: 1700000500},
+	)
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	client := NewTestClient(logger, invoker, nil)
+
+	messages, err := client.GetChannelMessagesConverted(100, &ChannelMessagesOptions{MinMessages: 5})
+	require.NoError(t, err)
+
+	edges := ForwardEdges(messages)
+	require.Len(t, edges, 4)
+
+	// Newest first, like the history
+	require.Equal(t, ForwardEdge{
+		FromKind:     ForwardHidden,
+		FromName:     "Anonymous",
+		ToPeerID:     100,
+		ToMessageID:  4,
+		OriginalDate: time.Unix(1700000350, 0),
+		ForwardedAt:  time.Unix(1700000400, 0),
+	}, edges[0])
+	require.Equal(t, ForwardFromUser, edges[1].FromKind)
+	require.Equal(t, int64(7), edges[1].FromPeerID)
+	require.Equal(t, ForwardEdge{
+		FromPeerID:   200,
+		FromKind:     ForwardFromChannel,
+		FromPostID:   55,
+		ToPeerID:     100,
+		ToMessageID:  1,
+		OriginalDate: time.Unix(1700000000, 0),
+		ForwardedAt:  time.Unix(1700000100, 0),
+	}, edges[3])
+
+	peers, err := client.ResolveForwardPeers(context.Background(), messages)
+	require.NoError(t, err)
+	require.Len(t, peers, 3)
+
+	// The channel of the history was resolved from the response
+	require.Equal(t, &Peer{ID: 100, Kind: ForwardFromChannel, AccessHash: 100 ^ 0x5f5f5f5f, Title: "News", Username: "news"}, peers[100])
+	require.Equal(t, &Peer{ID: 200, Kind: ForwardFromChannel, AccessHash: 200 ^ 0x5f5f5f5f, Title: "Source", Username: "source"}, peers[200])
+
+	// Users that weren't in a response only have their ID
+	require.Equal(t, &Peer{ID: 7, Kind: ForwardFromUser}, peers[7])
+
+	// Only the unknown channel was looked up
+	var lookups [][]tg.InputChannelClass
+	for _, req := range requestsOf[*tg.ChannelsGetChannelsRequest](invoker) {
+		if req.ID[0].(*tg.InputChannel).ChannelID != 100 {
+			lookups = append(lookups, req.ID)
+		}
+	}
+	require.Equal(t, [][]tg.InputChannelClass{{&tg.InputChannel{ChannelID: 200}}}, lookups)
+
+	// Resolved channels are remembered
+	peers, err = client.ResolveForwardPeers(context.Background(), messages)
+	require.NoError(t, err)
+	require.Equal(t, "Source", peers[200].Title)
+	require.Len(t, requestsOf[*tg.ChannelsGetChannelsRequest](invoker), len(lookups)+1)
+}
@@ -0,0 +1,105 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// ChannelInfo is the metadata of a channel or supergroup
+type ChannelInfo struct {
+	ID                int64
+	AccessHash        int64 `json:"-"`
+	Title             string
+	About             string
+	Username          string
+	Usernames         []string
+	ParticipantsCount int
+	LinkedChatID      int64
+	PhotoID           int64
+	PhotoDCID         int
+	Broadcast         bool
+	Megagroup         bool
+	Verified          bool
+	Scam              bool
+	Fake              bool
+	CreatedAt         time.Time
+	FetchedAt         time.Time
+}
+
+// GetChannelInfo fetches the metadata of a channel by username
+func (c *Client) GetChannelInfo(ctx context.Context, username string) (*ChannelInfo, error) {
+	input, err := c.getChannelInputByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("resolve channel: %w", err)
+	}
+
+	c.takeRequest()
+
+	res, err := c.api().ChannelsGetFullChannel(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("get full channel: %w", err)
+	}
+
+	full, ok := res.FullChat.(*tg.ChannelFull)
+	if !ok {
+		return nil, fmt.Errorf("unexpected channel type: %T", res.FullChat)
+	}
+
+	info := &ChannelInfo{
+		ID:        full.ID,
+		About:     full.About,
+		FetchedAt: time.Now(),
+	}
+
+	info.ParticipantsCount, _ = full.GetParticipantsCount()
+	info.LinkedChatID, _ = full.GetLinkedChatID()
+
+	for _, chat := range res.Chats {
+		channel, ok := chat.(*tg.Channel)
+		if !ok || channel.ID != full.ID {
+			continue
+		}
+
+		applyChannel(info, channel)
+	}
+
+	return info, nil
+}
+
+// applyChannel fills in the info from the channel, the participant count
+// is kept when the full channel had it
+func applyChannel(info *ChannelInfo, channel *tg.Channel) {
+	info.ID = channel.ID
+	info.AccessHash = channel.AccessHash
+	info.Title = channel.Title
+	info.Username = channel.Username
+	info.Broadcast = channel.Broadcast
+	info.Megagroup = channel.Megagroup
+	info.Verified = channel.Verified
+	info.Scam = channel.Scam
+	info.Fake = channel.Fake
+	info.CreatedAt = time.Unix(int64(channel.Date), 0)
+
+	for _, name := range channel.Usernames {
+		if name.Active {
+			info.Usernames = append(info.Usernames, name.Username)
+		}
+	}
+
+	if photo, ok := channel.Photo.(*tg.ChatPhoto); ok {
+		info.PhotoID = photo.PhotoID
+		info.PhotoDCID = photo.DCID
+	}
+
+	// The full channel only has the count for some channel types
+	if info.ParticipantsCount == 0 {
+		info.ParticipantsCount, _ = channel.GetParticipantsCount()
+	}
+
+	if info.Username == "" && len(info.Usernames) > 0 {
+		info.Username = info.Usernames[0]
+	}
+}
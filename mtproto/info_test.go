@@ -0,0 +1,132 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fullChannelResponder answers full channel requests with the about text
+// and participant count of the channels, and the channel of the backend
+func fullChannelResponder(backend *FakeBackend, about map[int64]string, participants map[int64]int) func(input bin.Encoder) (bin.Encoder, error) {
+	return func(input bin.Encoder) (bin.Encoder, error) {
+		req, ok := input.(*tg.ChannelsGetFullChannelRequest)
+		if !ok {
+			return nil, nil
+		}
+
+		id := req.Channel.(*tg.InputChannel).ChannelID
+
+		backend.mu.Lock()
+		channel := backend.channels[id]
+		backend.mu.Unlock()
+
+		full := &tg.ChannelFull{
+			ID:             id,
+			About:          about[id],
+			ChatPhoto:      &tg.PhotoEmpty{},
+			NotifySettings: tg.PeerNotifySettings{},
+		}
+		if count := participants[id]; count > 0 {
+			full.SetParticipantsCount(count)
+		}
+		full.SetLinkedChatID(id + 1)
+
+		return &tg.MessagesChatFull{
+			FullChat: full,
+			Chats:    []tg.ChatClass{channel},
+		}, nil
+	}
+}
+
+func TestGetChannelInfo(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	invoker.respond = fullChannelResponder(backend, map[int64]string{100: "All the news"}, map[int64]int{100: 42})
+
+	client := NewTestClient(logger, invoker, nil)
+
+	info, err := client.GetChannelInfo(context.Background(), "news")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), info.ID)
+	require.Equal(t, int64(100^0x5f5f5f5f), info.AccessHash)
+	require.Equal(t, "News", info.Title)
+	require.Equal(t, "All the news", info.About)
+	require.Equal(t, "news", info.Username)
+	require.Equal(t, 42, info.ParticipantsCount)
+	require.Equal(t, int64(101), info.LinkedChatID)
+	require.True(t, info.Broadcast)
+
+	requests := requestsOf[*tg.ChannelsGetFullChannelRequest](invoker)
+	require.Len(t, requests, 1)
+	require.Equal(t, &tg.InputChannel{ChannelID: 100, AccessHash: 100 ^ 0x5f5f5f5f}, requests[0].Channel)
+
+	_, err = client.GetChannelInfo(context.Background(), "unknown")
+	require.Error(t, err)
+}
+
+func TestSnapshotter(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	about := map[int64]string{100: "All the news"}
+	invoker := &stubInvoker{FakeBackend: backend}
+	invoker.respond = fullChannelResponder(backend, about, map[int64]int{100: 42})
+
+	client := NewTestClient(logger, invoker, nil)
+
+	_, err := client.NewSnapshotter(SnapshotterConfig{})
+	require.True(t, errors.Is(err, ErrNotInitialized))
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	client.db = db
+
+	var changes [][2]*ChannelSnapshot
+	snapshotter, err := client.NewSnapshotter(SnapshotterConfig{
+		OnChange: func(prev, next *ChannelSnapshot) {
+			changes = append(changes, [2]*ChannelSnapshot{prev, next})
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Now().Add(-time.Minute)
+
+	snapshot, recorded, err := snapshotter.Snapshot(context.Background(), "news")
+	require.NoError(t, err)
+	require.True(t, recorded)
+	require.Equal(t, "All the news", snapshot.About)
+	require.Equal(t, 42, snapshot.ParticipantsCount)
+	require.Equal(t, "news", snapshot.Username)
+
+	// Unchanged channels aren't recorded again
+	_, recorded, err = snapshotter.Snapshot(context.Background(), "news")
+	require.NoError(t, err)
+	require.False(t, recorded)
+	require.Empty(t, changes)
+
+	about[100] = "Only the good news"
+
+	_, recorded, err = snapshotter.Snapshot(context.Background(), "news")
+	require.NoError(t, err)
+	require.True(t, recorded)
+	require.Len(t, changes, 1)
+	require.Equal(t, "All the news", changes[0][0].About)
+	require.Equal(t, "Only the good news", changes[0][1].About)
+
+	history, err := snapshotter.History(context.Background(), 100, start)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, "Only the good news", history[1].About)
+
+	require.Len(t, requestsOf[*tg.ChannelsGetFullChannelRequest](invoker), 3)
+}
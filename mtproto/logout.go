@@ -0,0 +1,54 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/celestix/gotgproto/storage"
+	"github.com/gotd/td/tgerr"
+	"gorm.io/gorm"
+)
+
+// LogOut terminates the session on Telegram, removes the stored session and
+// peers of the account and resets the client. Create a new client to log in
+// with another account.
+func (c *Client) LogOut(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.api() == nil {
+		return ErrNotInitialized
+	}
+
+	// An unregistered key means the session was already terminated elsewhere
+	if _, err := c.api().AuthLogOut(ctx); err != nil && !tgerr.Is(err, "AUTH_KEY_UNREGISTERED", "SESSION_REVOKED") {
+		return fmt.Errorf("log out: %w", err)
+	}
+
+	// Clients of NewTestClient have no connection or stored session
+	if c.client != nil {
+		c.client.Stop()
+
+		if store := c.client.PeerStorage; store != nil && store.SqlSession != nil {
+			db := store.SqlSession.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true})
+
+			if err := db.Delete(&storage.Session{}).Error; err != nil {
+				return fmt.Errorf("delete session: %w", err)
+			}
+			if err := db.Delete(&storage.Peer{}).Error; err != nil {
+				return fmt.Errorf("delete peers: %w", err)
+			}
+		}
+	}
+
+	c.client = nil
+	c.testAPI = nil
+	c.dispatcher = nil
+	c.started = false
+	c.peers.reset()
+
+	c.cancel()
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	return nil
+}
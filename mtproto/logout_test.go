@@ -0,0 +1,60 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/test-go/testify/require"
+)
+
+func TestLogOut(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+	backend.AddMessages(100, &tg.Message{ID: 1, Message: "message", Date: 1700000000})
+
+	var logOutErr error
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	invoker.respond = func(input bin.Encoder) (bin.Encoder, error) {
+		if _, ok := input.(*tg.AuthLogOutRequest); !ok {
+			return nil, nil
+		}
+		if logOutErr != nil {
+			return nil, logOutErr
+		}
+
+		return &tg.AuthLoggedOut{}, nil
+	}
+
+	client := NewTestClient(logger, invoker, nil)
+
+	// The history response fills the peer store
+	_, err := client.GetChannelMessages(100, &ChannelMessagesOptions{MinMessages: 1})
+	require.NoError(t, err)
+
+	_, ok := client.peers.get(100)
+	require.True(t, ok)
+
+	// A failed log out keeps the session
+	logOutErr = tgerr.New(500, "INTERNAL")
+	require.Error(t, client.LogOut(context.Background()))
+	require.NotNil(t, client.api())
+
+	logOutErr = nil
+	require.NoError(t, client.LogOut(context.Background()))
+	require.Len(t, requestsOf[*tg.AuthLogOutRequest](invoker), 2)
+
+	_, ok = client.peers.get(100)
+	require.False(t, ok)
+	require.Nil(t, client.api())
+	require.True(t, errors.Is(client.LogOut(context.Background()), ErrNotInitialized))
+
+	// Sessions terminated elsewhere are logged out without an error
+	client = NewTestClient(logger, invoker, nil)
+	logOutErr = tgerr.New(401, "AUTH_KEY_UNREGISTERED")
+	require.NoError(t, client.LogOut(context.Background()))
+}
@@ -0,0 +1,156 @@
+package mtproto
+
+import (
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// Member roles, set when participant info is included
+const (
+	MemberRoleMember  = "member"
+	MemberRoleAdmin   = "admin"
+	MemberRoleCreator = "creator"
+	MemberRoleBanned  = "banned"
+	MemberRoleLeft    = "left"
+)
+
+// Member statuses derived from the last seen status
+const (
+	MemberStatusOnline    = "online"
+	MemberStatusOffline   = "offline"
+	MemberStatusRecently  = "recently"
+	MemberStatusLastWeek  = "last_week"
+	MemberStatusLastMonth = "last_month"
+	MemberStatusUnknown   = "unknown"
+)
+
+// Member is a user in a channel
+type Member struct {
+	ID         int64
+	AccessHash int64 `json:"-"`
+	Username   string
+	FirstName  string
+	LastName   string
+	Bot        bool
+	Premium    bool
+	Verified   bool
+	Deleted    bool
+	Scam       bool
+	Fake       bool
+	Status     string
+	// LastSeen is set for offline members that share their last seen time
+	LastSeen *time.Time
+
+	// Participant info, only set with ChannelMembersOptions.IncludeParticipant
+	Role      string
+	Rank      string
+	JoinedAt  *time.Time
+	InviterID int64
+}
+
+// convertUser maps a raw user to a Member
+func convertUser(user *tg.User) *Member {
+	m := &Member{
+		ID:         user.ID,
+		AccessHash: user.AccessHash,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Bot:        user.Bot,
+		Premium:    user.Premium,
+		Verified:   user.Verified,
+		Deleted:    user.Deleted,
+		Scam:       user.Scam,
+		Fake:       user.Fake,
+		Status:     MemberStatusUnknown,
+	}
+
+	if m.Username == "" {
+		for _, name := range user.Usernames {
+			if name.Active {
+				m.Username = name.Username
+				break
+			}
+		}
+	}
+
+	switch s := user.Status.(type) {
+	case *tg.UserStatusOnline:
+		m.Status = MemberStatusOnline
+	case *tg.UserStatusOffline:
+		m.Status = MemberStatusOffline
+		lastSeen := time.Unix(int64(s.WasOnline), 0)
+		m.LastSeen = &lastSeen
+	case *tg.UserStatusRecently:
+		m.Status = MemberStatusRecently
+	case *tg.UserStatusLastWeek:
+		m.Status = MemberStatusLastWeek
+	case *tg.UserStatusLastMonth:
+		m.Status = MemberStatusLastMonth
+	}
+
+	return m
+}
+
+// applyParticipant adds the participant info to the members it belongs to
+func applyParticipant(members map[int64]*Member, participant tg.ChannelParticipantClass) {
+	var (
+		userID    int64
+		role      = MemberRoleMember
+		rank      string
+		date      int
+		inviterID int64
+	)
+
+	switch p := participant.(type) {
+	case *tg.ChannelParticipant:
+		userID, date = p.UserID, p.Date
+	case *tg.ChannelParticipantSelf:
+		userID, date, inviterID = p.UserID, p.Date, p.InviterID
+	case *tg.ChannelParticipantCreator:
+		userID, role, rank = p.UserID, MemberRoleCreator, p.Rank
+	case *tg.ChannelParticipantAdmin:
+		userID, role, rank, date, inviterID = p.UserID, MemberRoleAdmin, p.Rank, p.Date, p.InviterID
+	case *tg.ChannelParticipantBanned:
+		userID, role, date = peerID(p.Peer), MemberRoleBanned, p.Date
+		if p.Left {
+			role = MemberRoleLeft
+		}
+	case *tg.ChannelParticipantLeft:
+		userID, role = peerID(p.Peer), MemberRoleLeft
+	}
+
+	member, ok := members[userID]
+	if !ok {
+		return
+	}
+
+	member.Role = role
+	member.Rank = rank
+	member.InviterID = inviterID
+
+	if date > 0 {
+		joined := time.Unix(int64(date), 0)
+		member.JoinedAt = &joined
+	}
+}
+
+// participantsFilter maps the Filter option to the API filter, unknown
+// values are used as a search query
+func participantsFilter(filter string) tg.ChannelParticipantsFilterClass {
+	switch filter {
+	case "", "recent":
+		return &tg.ChannelParticipantsRecent{}
+	case "admins":
+		return &tg.ChannelParticipantsAdmins{}
+	case "bots":
+		return &tg.ChannelParticipantsBots{}
+	case "kicked":
+		return &tg.ChannelParticipantsKicked{}
+	case "banned":
+		return &tg.ChannelParticipantsBanned{}
+	}
+
+	return &tg.ChannelParticipantsSearch{Q: filter}
+}
@@ -0,0 +1,103 @@
+package mtproto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestGetChannelMembersTyped(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	users := []tg.UserClass{
+		&tg.User{ID: 1, FirstName: "Alice", Username: "alice", Premium: true, Status: &tg.UserStatusOnline{Expires: 1700000000}},
+		&tg.User{ID: 2, FirstName: "Bob", LastName: "Builder", Status: &tg.UserStatusOffline{WasOnline: 1700000000}, Usernames: []tg.Username{
+			{Username: "old"},
+			{Username: "bob", Active: true},
+		}},
+		&tg.User{ID: 3, FirstName: "Carol", Bot: true, Status: &tg.UserStatusRecently{}},
+		&tg.User{ID: 4, Deleted: true},
+	}
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	invoker.respond = func(input bin.Encoder) (bin.Encoder, error) {
+		req, ok := input.(*tg.ChannelsGetParticipantsRequest)
+		if !ok {
+			return nil, nil
+		}
+
+		// A single page
+		if req.Offset > 0 {
+			return &tg.ChannelsChannelParticipants{Count: len(users)}, nil
+		}
+
+		return &tg.ChannelsChannelParticipants{
+			Count: len(users),
+			Participants: []tg.ChannelParticipantClass{
+				&tg.ChannelParticipantCreator{UserID: 1, Rank: "owner", AdminRights: tg.ChatAdminRights{}},
+				&tg.ChannelParticipantAdmin{UserID: 2, Rank: "mod", Date: 1690000000, InviterID: 1, PromotedBy: 1, AdminRights: tg.ChatAdminRights{}},
+				&tg.ChannelParticipant{UserID: 3, Date: 1695000000},
+				&tg.ChannelParticipantLeft{Peer: &tg.PeerUser{UserID: 4}},
+			},
+			Users: users,
+		}, nil
+	}
+
+	client := NewTestClient(logger, invoker, nil)
+
+	members, err := client.GetChannelMembers(context.Background(), "news", &ChannelMembersOptions{
+		Filter:             "admins",
+		IncludeParticipant: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, members, 4)
+
+	require.Equal(t, "alice", members[0].Username)
+	require.True(t, members[0].Premium)
+	require.Equal(t, MemberStatusOnline, members[0].Status)
+	require.Equal(t, MemberRoleCreator, members[0].Role)
+	require.Equal(t, "owner", members[0].Rank)
+	require.Nil(t, members[0].JoinedAt)
+
+	// The first active username is used without a main one
+	require.Equal(t, "bob", members[1].Username)
+	require.Equal(t, "Builder", members[1].LastName)
+	require.Equal(t, MemberStatusOffline, members[1].Status)
+	require.Equal(t, time.Unix(1700000000, 0), *members[1].LastSeen)
+	require.Equal(t, MemberRoleAdmin, members[1].Role)
+	require.Equal(t, "mod", members[1].Rank)
+	require.Equal(t, int64(1), members[1].InviterID)
+	require.Equal(t, time.Unix(1690000000, 0), *members[1].JoinedAt)
+
+	require.True(t, members[2].Bot)
+	require.Equal(t, MemberStatusRecently, members[2].Status)
+	require.Equal(t, MemberRoleMember, members[2].Role)
+
+	require.True(t, members[3].Deleted)
+	require.Equal(t, MemberStatusUnknown, members[3].Status)
+	require.Equal(t, MemberRoleLeft, members[3].Role)
+
+	requests := requestsOf[*tg.ChannelsGetParticipantsRequest](invoker)
+	require.Len(t, requests, 1)
+	require.Equal(t, &tg.ChannelParticipantsAdmins{}, requests[0].Filter)
+	require.Equal(t, &tg.InputChannel{ChannelID: 100, AccessHash: 100 ^ 0x5f5f5f5f}, requests[0].Channel)
+	require.Equal(t, 100, requests[0].Limit)
+
+	// Without participant info the roles are left empty, deleted accounts can
+	// be skipped
+	members, err = client.GetChannelMembers(context.Background(), "news", &ChannelMembersOptions{
+		Filter:     "ali",
+		ActiveOnly: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	require.Empty(t, members[0].Role)
+
+	requests = requestsOf[*tg.ChannelsGetParticipantsRequest](invoker)
+	require.Equal(t, &tg.ChannelParticipantsSearch{Q: "ali"}, requests[1].Filter)
+}
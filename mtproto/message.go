@@ -1,6 +1,8 @@
 package mtproto
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"time"
 
@@ -16,6 +18,80 @@ type Message struct {
 	PeerID    int64
 	Timestamp time.Time
 	Entities  []MessageEntity
+
+	EditedAt   *time.Time
+	Post       bool
+	Pinned     bool
+	PostAuthor string
+	GroupedID  int64
+	Views      int
+	Forwards   int
+	Replies    int
+	ReplyTo    *MessageReply
+	Forward    *MessageForward
+	Media      *MessageMedia
+	Reactions  []MessageReaction
+
+	// Raw is the message the model was converted from
+	Raw *tg.Message `json:"-"`
+}
+
+// MessageReply describes the message a message replies to
+type MessageReply struct {
+	MessageID int
+	PeerID    int64
+	TopID     int
+	Quote     string
+}
+
+// MessageForward describes where a forwarded message originally came from
+type MessageForward struct {
+	Kind          ForwardKind
+	FromID        int64
+	FromName      string
+	Date          time.Time
+	ChannelPostID int
+	PostAuthor    string
+	// SavedFromPeerID and SavedFromMsgID point to the message the forward was
+	// saved from, set for forwards to Saved Messages and some reposts
+	SavedFromPeerID int64
+	SavedFromMsgID  int
+	Imported        bool
+}
+
+// ForwardKind is the type of the original sender of a forwarded message
+type ForwardKind string
+
+const (
+	ForwardFromUser    ForwardKind = "user"
+	ForwardFromChannel ForwardKind = "channel"
+	ForwardFromChat    ForwardKind = "chat"
+	// ForwardHidden is a user that hides their account in forwards, only the
+	// name is known
+	ForwardHidden ForwardKind = "hidden"
+)
+
+// MessageMedia describes the media attached to a message, the content itself
+// is not downloaded
+type MessageMedia struct {
+	Type     string
+	ID       int64
+	MimeType string
+	FileName string
+	Size     int64
+	Width    int
+	Height   int
+	Duration time.Duration
+	URL      string
+	Title    string
+}
+
+// MessageReaction is the count of a single reaction on a message
+type MessageReaction struct {
+	Emoji         string
+	CustomEmojiID int64
+	Paid          bool
+	Count         int
 }
 
 // MessageEntity represents a message entity (URL, mention, etc.)
@@ -68,6 +144,10 @@ func (c *Client) SendMessage(peerID int64, text string, opts *SendMessageOptions
 		ReplyTo:      replyTo,
 	}
 
+	if c.testAPI != nil {
+		return c.sendMessageRaw(peerID, req)
+	}
+
 	randomID, err := c.client.RandInt64()
 	if err != nil {
 		return nil, fmt.Errorf("generate random_id: %w", err)
@@ -81,3 +161,40 @@ func (c *Client) SendMessage(peerID int64, text string, opts *SendMessageOptions
 
 	return sent.Message, nil
 }
+
+// sendMessageRaw sends the message with the raw API, test clients have no
+// peer storage for the helpers of gotgproto
+func (c *Client) sendMessageRaw(peerID int64, req *tg.MessagesSendMessageRequest) (*tg.Message, error) {
+	var random [8]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return nil, fmt.Errorf("generate random_id: %w", err)
+	}
+	req.RandomID = int64(binary.LittleEndian.Uint64(random[:]))
+
+	updates, err := c.api().MessagesSendMessage(c.ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("send message: %w", err)
+	}
+
+	switch u := updates.(type) {
+	case *tg.UpdateShortSentMessage:
+		return &tg.Message{
+			ID:       u.ID,
+			Date:     u.Date,
+			Out:      true,
+			PeerID:   &tg.PeerUser{UserID: peerID},
+			Message:  req.Message,
+			Entities: u.Entities,
+		}, nil
+	case *tg.Updates:
+		for _, update := range u.Updates {
+			if update, ok := update.(*tg.UpdateNewMessage); ok {
+				if msg, ok := update.Message.(*tg.Message); ok {
+					return msg, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("send message: unexpected updates: %T", updates)
+}
@@ -1,12 +1,17 @@
 package mtproto
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gotd/td/tg"
 	"github.com/joho/godotenv"
 	"github.com/test-go/testify/require"
 	"golang.org/x/exp/slog"
@@ -15,6 +20,9 @@ import (
 	"github.com/Davincible/tgbot/bots/loginbot"
 )
 
+// The login bot reports completed logins to the client
+var _ LoginReporter = (*loginbot.Conversator)(nil)
+
 var (
 	chats = map[string]int64{
 		"david": 739125269,
@@ -25,9 +33,18 @@ var (
 	}
 )
 
-func init() {
-	if err := godotenv.Load("../.env"); err != nil {
-		panic(fmt.Errorf("Error loading .env file: %w", err))
+// requireLiveEnv loads ../.env and skips the test unless it has the
+// credentials of a live client
+func requireLiveEnv(t *testing.T) {
+	t.Helper()
+
+	// The variables may also be set in the environment
+	_ = godotenv.Load("../.env")
+
+	for _, name := range []string{"TELEGRAM_APP_ID", "TELEGRAM_API_HASH", "TELEGRAM_PHONE", "TELEGRAM_BOT_TOKEN"} {
+		if getEnv(name) == "" {
+			t.Skipf("live test: %s is not set", name)
+		}
 	}
 }
 
@@ -54,6 +71,8 @@ func setupTestLogger() *slog.Logger {
 var logger = setupTestLogger()
 
 func setupTestClient(t *testing.T) *Client {
+	requireLiveEnv(t)
+
 	loginBot := loginbot.New(logger, loginbot.Config{})
 
 	tgSrv, err := tgbot.NewService(logger, &tgbot.Config{
@@ -126,3 +145,74 @@ func getEnvInt(name string, fallback ...int) int {
 
 	return 0
 }
+
+func TestValidateConfig(t *testing.T) {
+	err := validateConfig(&Config{
+		AppID:          12345,
+		Phone:          "abc",
+		DatabaseConfig: DatabaseConfig{Type: "mysql"},
+	})
+	require.True(t, errors.Is(err, ErrInvalidConfig))
+	for _, problem := range []string{"APIHash is required", "Phone", "mysql"} {
+		require.Contains(t, err.Error(), problem)
+	}
+
+	err = validateConfig(&Config{
+		AppID:          12345,
+		APIHash:        "0123456789abcdef0123456789abcdef",
+		Phone:          "+31 6 1234 5678",
+		DatabaseConfig: DatabaseConfig{DSN: "postgres://localhost/db"},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `set DatabaseConfig.Type to "postgres"`)
+
+	cfg := &Config{
+		AppID:          12345,
+		APIHash:        "0123456789abcdef0123456789abcdef",
+		Phone:          "+31 6 1234 5678",
+		DatabaseConfig: DatabaseConfig{Type: "sqlite", DSN: ":memory:"},
+	}
+	require.NoError(t, validateConfig(cfg))
+	require.NotNil(t, cfg.PeerCache)
+}
+
+func TestAccountLogs(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+	backend.AddMessages(100, &tg.Message{ID: 1, Message: "message", Date: 1700000000})
+
+	// accountOf returns the account attribute of the logged records
+	accountOf := func(cfg *Config) []string {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		client := NewTestClient(logger, backend, cfg)
+		require.Zero(t, client.AccountID())
+
+		_, err := client.GetChannelMessages(100, &ChannelMessagesOptions{MinMessages: 1})
+		require.NoError(t, err)
+
+		var accounts []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record struct {
+				Account string `json:"account"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			accounts = append(accounts, record.Account)
+		}
+
+		return accounts
+	}
+
+	accounts := accountOf(&Config{Phone: "+31612345678"})
+	require.NotEmpty(t, accounts)
+	for _, account := range accounts {
+		require.Equal(t, "********5678", account)
+	}
+
+	accounts = accountOf(&Config{Phone: "+31612345678", AccountLabel: "scraper-1"})
+	require.NotEmpty(t, accounts)
+	for _, account := range accounts {
+		require.Equal(t, "scraper-1", account)
+	}
+}
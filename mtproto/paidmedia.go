@@ -0,0 +1,220 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gotd/td/telegram/downloader"
+	"github.com/gotd/td/tg"
+)
+
+var (
+	// ErrNotPaidMedia is returned for messages without paid media
+	ErrNotPaidMedia = errors.New("message has no paid media")
+	// ErrPaidMediaPrice is returned when the media costs more than the
+	// account is willing to pay
+	ErrPaidMediaPrice = errors.New("paid media costs more than the maximum")
+	// ErrNoDownloadableMedia is returned for media without a file, like
+	// locations and polls
+	ErrNoDownloadableMedia = errors.New("media has no file to download")
+)
+
+// PaidMedia is the paid media of a channel message
+type PaidMedia struct {
+	ChannelID int64
+	MessageID int
+	// Stars is the price of the media in Telegram Stars
+	Stars int64
+	// Unlocked is true once the account bought the media
+	Unlocked bool
+	// Items are the photos and videos, download them with DownloadMedia.
+	// Empty while the media is locked, Telegram only sends blurred previews.
+	Items []tg.MessageMediaClass
+}
+
+// GetPaidMedia returns the paid media of a channel message
+func (c *Client) GetPaidMedia(ctx context.Context, channelID int64, msgID int) (*PaidMedia, error) {
+	if c.api() == nil {
+		return nil, ErrNotInitialized
+	}
+
+	channel, err := c.getChannelInputByChatID(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("get channel input: %w", err)
+	}
+
+	return c.paidMedia(ctx, channel, msgID)
+}
+
+// UnlockPaidMedia buys the paid media of a channel message with the Stars
+// balance of the account, for archiving channels the account subscribes to.
+// Media costing more than maxStars is not bought and ErrPaidMediaPrice is
+// returned. Media that is already unlocked is returned without paying again.
+func (c *Client) UnlockPaidMedia(ctx context.Context, channelID int64, msgID int, maxStars int64) (*PaidMedia, error) {
+	if c.api() == nil {
+		return nil, ErrNotInitialized
+	}
+
+	channel, err := c.getChannelInputByChatID(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("get channel input: %w", err)
+	}
+
+	media, err := c.paidMedia(ctx, channel, msgID)
+	if err != nil || media.Unlocked {
+		return media, err
+	}
+
+	if media.Stars > maxStars {
+		return nil, fmt.Errorf("%w: %d stars, at most %d", ErrPaidMediaPrice, media.Stars, maxStars)
+	}
+
+	invoice := &tg.InputInvoiceMessage{
+		Peer:  &tg.InputPeerChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash},
+		MsgID: msgID,
+	}
+
+	c.takeRequest()
+
+	form, err := c.api().PaymentsGetPaymentForm(ctx, &tg.PaymentsGetPaymentFormRequest{Invoice: invoice})
+	if err != nil {
+		return nil, fmt.Errorf("get payment form: %w", err)
+	}
+
+	starsForm, ok := form.(*tg.PaymentsPaymentFormStars)
+	if !ok {
+		return nil, fmt.Errorf("unexpected payment form type: %T", form)
+	}
+
+	c.takeRequest()
+
+	if _, err := c.api().PaymentsSendStarsForm(ctx, &tg.PaymentsSendStarsFormRequest{
+		FormID:  starsForm.FormID,
+		Invoice: invoice,
+	}); err != nil {
+		return nil, fmt.Errorf("send stars form: %w", err)
+	}
+
+	// The message is fetched again for the unlocked media
+	return c.paidMedia(ctx, channel, msgID)
+}
+
+// DownloadMedia writes the file of a photo or document to w, the largest
+// size of photos is downloaded
+func (c *Client) DownloadMedia(ctx context.Context, media tg.MessageMediaClass, w io.Writer) error {
+	if c.api() == nil {
+		return ErrNotInitialized
+	}
+
+	location, err := mediaLocation(media)
+	if err != nil {
+		return err
+	}
+
+	c.takeRequest()
+
+	if _, err := downloader.NewDownloader().Download(c.api(), location).Stream(ctx, w); err != nil {
+		return fmt.Errorf("download media: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) paidMedia(ctx context.Context, channel *tg.InputChannel, msgID int) (*PaidMedia, error) {
+	c.takeRequest()
+
+	res, err := c.api().ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+		Channel: channel,
+		ID:      []tg.InputMessageClass{&tg.InputMessageID{ID: msgID}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get message: %w", err)
+	}
+
+	messages, ok := res.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("unexpected messages type: %T", res)
+	}
+
+	for _, m := range messages.GetMessages() {
+		msg, ok := m.(*tg.Message)
+		if !ok || msg.ID != msgID {
+			continue
+		}
+
+		paid, ok := msg.Media.(*tg.MessageMediaPaidMedia)
+		if !ok {
+			return nil, fmt.Errorf("message %d: %w", msgID, ErrNotPaidMedia)
+		}
+
+		return convertPaidMedia(channel.ChannelID, msgID, paid), nil
+	}
+
+	return nil, fmt.Errorf("message %d: %w", msgID, ErrNotPaidMedia)
+}
+
+func convertPaidMedia(channelID int64, msgID int, paid *tg.MessageMediaPaidMedia) *PaidMedia {
+	media := &PaidMedia{
+		ChannelID: channelID,
+		MessageID: msgID,
+		Stars:     paid.StarsAmount,
+	}
+
+	for _, extended := range paid.ExtendedMedia {
+		if item, ok := extended.(*tg.MessageExtendedMedia); ok {
+			media.Items = append(media.Items, item.Media)
+		}
+	}
+
+	media.Unlocked = len(paid.ExtendedMedia) > 0 && len(media.Items) == len(paid.ExtendedMedia)
+
+	return media
+}
+
+// mediaLocation returns the file location of a photo or document
+func mediaLocation(media tg.MessageMediaClass) (tg.InputFileLocationClass, error) {
+	switch v := media.(type) {
+	case *tg.MessageMediaPhoto:
+		photo, ok := v.Photo.(*tg.Photo)
+		if !ok {
+			break
+		}
+
+		// Sizes are ordered from small to large, keep the largest
+		var size string
+		for _, s := range photo.Sizes {
+			switch s := s.(type) {
+			case *tg.PhotoSize:
+				size = s.Type
+			case *tg.PhotoSizeProgressive:
+				size = s.Type
+			}
+		}
+		if size == "" {
+			break
+		}
+
+		return &tg.InputPhotoFileLocation{
+			ID:            photo.ID,
+			AccessHash:    photo.AccessHash,
+			FileReference: photo.FileReference,
+			ThumbSize:     size,
+		}, nil
+
+	case *tg.MessageMediaDocument:
+		doc, ok := v.Document.(*tg.Document)
+		if !ok {
+			break
+		}
+
+		return &tg.InputDocumentFileLocation{
+			ID:            doc.ID,
+			AccessHash:    doc.AccessHash,
+			FileReference: doc.FileReference,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrNoDownloadableMedia, media)
+}
@@ -0,0 +1,49 @@
+package mtproto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestConvertPaidMedia(t *testing.T) {
+	photo := &tg.MessageMediaPhoto{Photo: &tg.Photo{ID: 1}}
+
+	locked := convertPaidMedia(10, 5, &tg.MessageMediaPaidMedia{
+		StarsAmount:   50,
+		ExtendedMedia: []tg.MessageExtendedMediaClass{&tg.MessageExtendedMediaPreview{}},
+	})
+	require.False(t, locked.Unlocked)
+	require.Empty(t, locked.Items)
+	require.Equal(t, int64(50), locked.Stars)
+
+	unlocked := convertPaidMedia(10, 5, &tg.MessageMediaPaidMedia{
+		StarsAmount:   50,
+		ExtendedMedia: []tg.MessageExtendedMediaClass{&tg.MessageExtendedMedia{Media: photo}},
+	})
+	require.True(t, unlocked.Unlocked)
+	require.Equal(t, []tg.MessageMediaClass{photo}, unlocked.Items)
+}
+
+func TestMediaLocation(t *testing.T) {
+	location, err := mediaLocation(&tg.MessageMediaPhoto{Photo: &tg.Photo{
+		ID:         1,
+		AccessHash: 2,
+		Sizes: []tg.PhotoSizeClass{
+			&tg.PhotoStrippedSize{Type: "i"},
+			&tg.PhotoSize{Type: "m"},
+			&tg.PhotoSize{Type: "y"},
+		},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, &tg.InputPhotoFileLocation{ID: 1, AccessHash: 2, ThumbSize: "y"}, location)
+
+	location, err = mediaLocation(&tg.MessageMediaDocument{Document: &tg.Document{ID: 3, AccessHash: 4}})
+	require.NoError(t, err)
+	require.Equal(t, &tg.InputDocumentFileLocation{ID: 3, AccessHash: 4}, location)
+
+	_, err = mediaLocation(&tg.MessageMediaGeo{})
+	require.True(t, errors.Is(err, ErrNoDownloadableMedia))
+}
@@ -0,0 +1,171 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+var (
+	// ErrPasswordRequired is returned when the account has a password and the
+	// current password was not given
+	ErrPasswordRequired = errors.New("current password required")
+	// ErrEmailUnconfirmed is returned when the settings were saved but the
+	// recovery email has to be confirmed with ConfirmRecoveryEmail first
+	ErrEmailUnconfirmed = errors.New("recovery email unconfirmed")
+)
+
+// PasswordStatus is the two-step verification state of the account
+type PasswordStatus struct {
+	HasPassword bool
+	HasRecovery bool
+	Hint        string
+	// EmailUnconfirmedPattern is the masked email awaiting confirmation
+	EmailUnconfirmedPattern string
+}
+
+// PasswordOptions configures the new two-step verification password
+type PasswordOptions struct {
+	Hint string
+	// Email is the recovery email, Telegram sends it a confirmation code
+	Email string
+}
+
+// PasswordStatus returns the two-step verification state of the account
+func (c *Client) PasswordStatus(ctx context.Context) (*PasswordStatus, error) {
+	p, err := c.api().AccountGetPassword(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get password: %w", err)
+	}
+
+	return &PasswordStatus{
+		HasPassword:             p.HasPassword,
+		HasRecovery:             p.HasRecovery,
+		Hint:                    p.Hint,
+		EmailUnconfirmedPattern: p.EmailUnconfirmedPattern,
+	}, nil
+}
+
+// SetPassword enables two-step verification or changes the password, current
+// is ignored when the account has no password yet. ErrEmailUnconfirmed is
+// returned when a recovery email was set and awaits confirmation.
+func (c *Client) SetPassword(ctx context.Context, current, password string, opts *PasswordOptions) error {
+	if password == "" {
+		return errors.New("password is empty, use DisablePassword to remove it")
+	}
+
+	if opts == nil {
+		opts = &PasswordOptions{}
+	}
+
+	p, check, err := c.checkPassword(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	algo, ok := p.NewAlgo.(*tg.PasswordKdfAlgoSHA256SHA256PBKDF2HMACSHA512iter100000SHA256ModPow)
+	if !ok {
+		return fmt.Errorf("unsupported password algorithm: %T", p.NewAlgo)
+	}
+
+	hash, err := auth.NewPasswordHash([]byte(password), algo)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	return c.updatePasswordSettings(ctx, check, tg.AccountPasswordInputSettings{
+		NewAlgo:         algo,
+		NewPasswordHash: hash,
+		Hint:            opts.Hint,
+		Email:           opts.Email,
+	})
+}
+
+// DisablePassword removes two-step verification from the account
+func (c *Client) DisablePassword(ctx context.Context, current string) error {
+	p, check, err := c.checkPassword(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	if !p.HasPassword {
+		return nil
+	}
+
+	return c.updatePasswordSettings(ctx, check, tg.AccountPasswordInputSettings{
+		NewAlgo:         &tg.PasswordKdfAlgoUnknown{},
+		NewPasswordHash: []byte{},
+	})
+}
+
+// SetRecoveryEmail sets the recovery email of the password, the email has to
+// be confirmed with ConfirmRecoveryEmail, so ErrEmailUnconfirmed is expected
+func (c *Client) SetRecoveryEmail(ctx context.Context, current, email string) error {
+	p, check, err := c.checkPassword(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	if !p.HasPassword {
+		return errors.New("set a password before the recovery email")
+	}
+
+	return c.updatePasswordSettings(ctx, check, tg.AccountPasswordInputSettings{
+		Email: email,
+	})
+}
+
+// ConfirmRecoveryEmail confirms the recovery email with the code sent to it
+func (c *Client) ConfirmRecoveryEmail(ctx context.Context, code string) error {
+	if _, err := c.api().AccountConfirmPasswordEmail(ctx, code); err != nil {
+		return fmt.Errorf("confirm password email: %w", err)
+	}
+
+	return nil
+}
+
+// checkPassword fetches the SRP parameters and computes the proof of the
+// current password, or the empty proof for accounts without a password
+func (c *Client) checkPassword(ctx context.Context, current string) (*tg.AccountPassword, tg.InputCheckPasswordSRPClass, error) {
+	p, err := c.api().AccountGetPassword(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get password: %w", err)
+	}
+
+	if !p.HasPassword {
+		return p, &tg.InputCheckPasswordEmpty{}, nil
+	}
+
+	if current == "" {
+		return nil, nil, ErrPasswordRequired
+	}
+
+	check, err := auth.PasswordHash([]byte(current), p.SRPID, p.SRPB, p.SecureRandom, p.CurrentAlgo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("hash current password: %w", err)
+	}
+
+	return p, check, nil
+}
+
+func (c *Client) updatePasswordSettings(ctx context.Context, check tg.InputCheckPasswordSRPClass, settings tg.AccountPasswordInputSettings) error {
+	_, err := c.api().AccountUpdatePasswordSettings(ctx, &tg.AccountUpdatePasswordSettingsRequest{
+		Password:    check,
+		NewSettings: settings,
+	})
+
+	// The settings are saved, Telegram only waits for the email code
+	if rpcErr, ok := tgerr.As(err); ok && strings.HasPrefix(rpcErr.Type, "EMAIL_UNCONFIRMED") {
+		return ErrEmailUnconfirmed
+	}
+	if err != nil {
+		return fmt.Errorf("update password settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/test-go/testify/require"
+)
+
+// testPasswordAlgo is the algorithm Telegram sends with a 2048-bit safe prime
+func testPasswordAlgo() *tg.PasswordKdfAlgoSHA256SHA256PBKDF2HMACSHA512iter100000SHA256ModPow {
+	return &tg.PasswordKdfAlgoSHA256SHA256PBKDF2HMACSHA512iter100000SHA256ModPow{
+		Salt1: []byte{230, 200, 149, 125, 223, 152, 141, 72},
+		Salt2: []byte{159, 99, 68, 130, 43, 9, 108, 255, 135, 239, 164, 38, 245, 120, 87, 182},
+		G:     3,
+		P: []byte{
+			199, 28, 174, 185, 198, 177, 201, 4, 142, 108, 82, 47, 112, 241, 63, 115,
+			152, 13, 64, 35, 142, 62, 33, 193, 73, 52, 208, 55, 86, 61, 147, 15,
+			72, 25, 138, 10, 167, 193, 64, 88, 34, 148, 147, 210, 37, 48, 244, 219,
+			250, 51, 111, 110, 10, 201, 37, 19, 149, 67, 174, 212, 76, 206, 124, 55,
+			32, 253, 81, 246, 148, 88, 112, 90, 198, 140, 212, 254, 107, 107, 19, 171,
+			220, 151, 70, 81, 41, 105, 50, 132, 84, 241, 143, 175, 140, 89, 95, 100,
+			36, 119, 254, 150, 187, 42, 148, 29, 91, 205, 29, 74, 200, 204, 73, 136,
+			7, 8, 250, 155, 55, 142, 60, 79, 58, 144, 96, 190, 230, 124, 249, 164,
+			164, 166, 149, 129, 16, 81, 144, 126, 22, 39, 83, 181, 107, 15, 107, 65,
+			13, 186, 116, 216, 168, 75, 42, 20, 179, 20, 78, 14, 241, 40, 71, 84,
+			253, 23, 237, 149, 13, 89, 101, 180, 185, 221, 70, 88, 45, 177, 23, 141,
+			22, 156, 107, 196, 101, 176, 214, 255, 156, 163, 146, 143, 239, 91, 154, 228,
+			228, 24, 252, 21, 232, 62, 190, 160, 248, 127, 169, 255, 94, 237, 112, 5,
+			13, 237, 40, 73, 244, 123, 249, 89, 217, 86, 133, 12, 233, 41, 133, 31,
+			13, 129, 21, 246, 53, 177, 5, 238, 46, 78, 21, 208, 75, 36, 84, 191,
+			111, 79, 173, 240, 52, 177, 4, 3, 17, 156, 216, 227, 185, 47, 204, 91,
+		},
+	}
+}
+
+func TestPassword(t *testing.T) {
+	password := &tg.AccountPassword{
+		NewAlgo:       testPasswordAlgo(),
+		NewSecureAlgo: &tg.SecurePasswordKdfAlgoUnknown{},
+		SecureRandom:  make([]byte, 32),
+	}
+
+	var updateErr error
+
+	invoker := &stubInvoker{FakeBackend: NewFakeBackend()}
+	invoker.respond = func(input bin.Encoder) (bin.Encoder, error) {
+		switch input.(type) {
+		case *tg.AccountGetPasswordRequest:
+			return password, nil
+		case *tg.AccountUpdatePasswordSettingsRequest:
+			if updateErr != nil {
+				return nil, updateErr
+			}
+			return &tg.BoolTrue{}, nil
+		case *tg.AccountConfirmPasswordEmailRequest:
+			return &tg.BoolTrue{}, nil
+		}
+
+		return nil, nil
+	}
+
+	client := NewTestClient(logger, invoker, nil)
+	ctx := context.Background()
+
+	status, err := client.PasswordStatus(ctx)
+	require.NoError(t, err)
+	require.False(t, status.HasPassword)
+
+	require.Error(t, client.SetRecoveryEmail(ctx, "", "me@example.com"))
+
+	// Telegram waits for the email code, the password is set
+	updateErr = tgerr.New(400, "EMAIL_UNCONFIRMED_6")
+
+	err = client.SetPassword(ctx, "", "secret", &PasswordOptions{Hint: "hint", Email: "me@example.com"})
+	require.True(t, errors.Is(err, ErrEmailUnconfirmed))
+
+	updates := requestsOf[*tg.AccountUpdatePasswordSettingsRequest](invoker)
+	require.Len(t, updates, 1)
+	require.Equal(t, &tg.InputCheckPasswordEmpty{}, updates[0].Password)
+	require.Equal(t, "hint", updates[0].NewSettings.Hint)
+	require.Equal(t, "me@example.com", updates[0].NewSettings.Email)
+	require.Len(t, updates[0].NewSettings.NewPasswordHash, 256)
+
+	// The new salt extends the one of Telegram
+	algo := updates[0].NewSettings.NewAlgo.(*tg.PasswordKdfAlgoSHA256SHA256PBKDF2HMACSHA512iter100000SHA256ModPow)
+	require.Len(t, algo.Salt1, 8+32)
+	require.Equal(t, testPasswordAlgo().Salt1, algo.Salt1[:8])
+
+	require.NoError(t, client.ConfirmRecoveryEmail(ctx, "12345"))
+
+	confirms := requestsOf[*tg.AccountConfirmPasswordEmailRequest](invoker)
+	require.Len(t, confirms, 1)
+	require.Equal(t, "12345", confirms[0].Code)
+
+	// Changes to an account with a password need the current one
+	password.HasPassword = true
+	password.Hint = "hint"
+	password.CurrentAlgo = testPasswordAlgo()
+	password.SRPID = 42
+	password.SRPB = new(big.Int).Exp(big.NewInt(3), big.NewInt(1<<40+7), new(big.Int).SetBytes(testPasswordAlgo().P)).FillBytes(make([]byte, 256))
+	updateErr = nil
+
+	status, err = client.PasswordStatus(ctx)
+	require.NoError(t, err)
+	require.True(t, status.HasPassword)
+	require.Equal(t, "hint", status.Hint)
+
+	require.True(t, errors.Is(client.SetPassword(ctx, "", "new", nil), ErrPasswordRequired))
+	require.True(t, errors.Is(client.DisablePassword(ctx, ""), ErrPasswordRequired))
+
+	require.NoError(t, client.DisablePassword(ctx, "secret"))
+
+	updates = requestsOf[*tg.AccountUpdatePasswordSettingsRequest](invoker)
+	require.Len(t, updates, 2)
+
+	check, ok := updates[1].Password.(*tg.InputCheckPasswordSRP)
+	require.True(t, ok)
+	require.Equal(t, int64(42), check.SRPID)
+	require.Len(t, check.M1, 32)
+	require.Equal(t, &tg.PasswordKdfAlgoUnknown{}, updates[1].NewSettings.NewAlgo)
+	require.Empty(t, updates[1].NewSettings.NewPasswordHash)
+
+	// Other failures are returned
+	updateErr = tgerr.New(400, "PASSWORD_HASH_INVALID")
+	require.True(t, tgerr.Is(client.SetRecoveryEmail(ctx, "wrong", "me@example.com"), "PASSWORD_HASH_INVALID"))
+}
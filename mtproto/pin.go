@@ -0,0 +1,154 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"golang.org/x/exp/slog"
+)
+
+const defaultPinDelay = time.Second
+
+// PinOptions configures bulk pinning and unpinning
+type PinOptions struct {
+	// Silent pins without notifying the members of the channel
+	Silent bool
+	// Delay between requests, defaults to a second. Config.RateLimit applies too.
+	Delay time.Duration
+}
+
+// PinResult is the outcome of pinning or unpinning a single message
+type PinResult struct {
+	MessageID int
+	// Err is set when the message could not be pinned or unpinned
+	Err error `json:"-"`
+}
+
+// PinMessages pins the messages in a channel the account administers. Flood
+// waits up to five minutes are waited out, a failed pin is reported on its
+// result and does not stop the batch. An error is only returned when the
+// channel can't be resolved or the context is done.
+func (c *Client) PinMessages(ctx context.Context, channelID int64, msgIDs []int, opts *PinOptions) ([]PinResult, error) {
+	return c.updatePins(ctx, channelID, msgIDs, false, opts)
+}
+
+// UnpinMessages unpins the messages in a channel the account administers,
+// it reports the results like PinMessages
+func (c *Client) UnpinMessages(ctx context.Context, channelID int64, msgIDs []int, opts *PinOptions) ([]PinResult, error) {
+	return c.updatePins(ctx, channelID, msgIDs, true, opts)
+}
+
+// UnpinAllMessages clears all pinned messages of a channel the account
+// administers
+func (c *Client) UnpinAllMessages(ctx context.Context, channelID int64) error {
+	if c.api() == nil {
+		return ErrNotInitialized
+	}
+
+	peer, err := c.channelPeer(channelID)
+	if err != nil {
+		return err
+	}
+
+	// Telegram unpins in chunks, the offset is positive while pins are left
+	for {
+		c.takeRequest()
+
+		affected, err := c.api().MessagesUnpinAllMessages(ctx, &tg.MessagesUnpinAllMessagesRequest{Peer: peer})
+		if err != nil {
+			return fmt.Errorf("unpin all messages: %w", err)
+		}
+
+		if affected.Offset <= 0 {
+			return nil
+		}
+	}
+}
+
+func (c *Client) updatePins(ctx context.Context, channelID int64, msgIDs []int, unpin bool, opts *PinOptions) ([]PinResult, error) {
+	if c.api() == nil {
+		return nil, ErrNotInitialized
+	}
+
+	if opts == nil {
+		opts = &PinOptions{}
+	}
+
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = defaultPinDelay
+	}
+
+	peer, err := c.channelPeer(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PinResult, 0, len(msgIDs))
+
+	for i, msgID := range msgIDs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := c.updatePin(ctx, &tg.MessagesUpdatePinnedMessageRequest{
+			Silent: opts.Silent,
+			Unpin:  unpin,
+			Peer:   peer,
+			ID:     msgID,
+		})
+		if err != nil && ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		results = append(results, PinResult{MessageID: msgID, Err: err})
+	}
+
+	return results, nil
+}
+
+func (c *Client) updatePin(ctx context.Context, req *tg.MessagesUpdatePinnedMessageRequest) error {
+	for {
+		c.takeRequest()
+
+		_, err := c.api().MessagesUpdatePinnedMessage(ctx, req)
+
+		if wait, ok := tgerr.AsFloodWait(err); ok && wait <= maxFloodWait {
+			c.log().Warn("flood wait updating pins", slog.Duration("wait", wait))
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if err != nil {
+			if req.Unpin {
+				return fmt.Errorf("unpin message %d: %w", req.ID, err)
+			}
+
+			return fmt.Errorf("pin message %d: %w", req.ID, err)
+		}
+
+		return nil
+	}
+}
+
+// channelPeer resolves the input peer of the channel
+func (c *Client) channelPeer(channelID int64) (*tg.InputPeerChannel, error) {
+	input, err := c.getChannelInputByChatID(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("get channel input: %w", err)
+	}
+
+	return &tg.InputPeerChannel{ChannelID: input.ChannelID, AccessHash: input.AccessHash}, nil
+}
@@ -0,0 +1,86 @@
+package mtproto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/test-go/testify/require"
+)
+
+func TestPinMessages(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	flooded := false
+	unpinRounds := 3
+
+	invoker := &stubInvoker{FakeBackend: backend}
+	invoker.respond = func(input bin.Encoder) (bin.Encoder, error) {
+		switch req := input.(type) {
+		case *tg.MessagesUpdatePinnedMessageRequest:
+			switch req.ID {
+			case 2:
+				if !flooded {
+					flooded = true
+					return nil, tgerr.New(420, "FLOOD_WAIT_0")
+				}
+			case 3:
+				return nil, tgerr.New(400, "MESSAGE_ID_INVALID")
+			}
+
+			return &tg.Updates{}, nil
+
+		case *tg.MessagesUnpinAllMessagesRequest:
+			// Telegram unpins in chunks
+			unpinRounds--
+			return &tg.MessagesAffectedHistory{Offset: unpinRounds}, nil
+		}
+
+		return nil, nil
+	}
+
+	client := NewTestClient(logger, invoker, nil)
+	ctx := context.Background()
+	peer := &tg.InputPeerChannel{ChannelID: 100, AccessHash: 100 ^ 0x5f5f5f5f}
+
+	results, err := client.PinMessages(ctx, 100, []int{1, 2, 3}, &PinOptions{Silent: true, Delay: time.Millisecond})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	require.NoError(t, results[1].Err)
+	require.True(t, tgerr.Is(results[2].Err, "MESSAGE_ID_INVALID"))
+	require.Contains(t, results[2].Err.Error(), "pin message 3")
+
+	// The flooded pin was sent again
+	requests := requestsOf[*tg.MessagesUpdatePinnedMessageRequest](invoker)
+	require.Len(t, requests, 4)
+	for _, req := range requests {
+		require.True(t, req.Silent)
+		require.False(t, req.Unpin)
+		require.Equal(t, peer, req.Peer)
+	}
+
+	results, err = client.UnpinMessages(ctx, 100, []int{1, 3}, &PinOptions{Delay: time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.Contains(t, results[1].Err.Error(), "unpin message 3")
+
+	requests = requestsOf[*tg.MessagesUpdatePinnedMessageRequest](invoker)[4:]
+	require.Len(t, requests, 2)
+	require.True(t, requests[0].Unpin)
+	require.False(t, requests[0].Silent)
+
+	// Unpinning all continues until no pins are left
+	require.NoError(t, client.UnpinAllMessages(ctx, 100))
+
+	unpins := requestsOf[*tg.MessagesUnpinAllMessagesRequest](invoker)
+	require.Len(t, unpins, 3)
+	require.Equal(t, peer, unpins[0].Peer)
+
+	_, err = client.PinMessages(ctx, 999, []int{1}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package mtproto
+
+import "fmt"
+
+// ResumableError is returned by paginated fetches that failed part way
+// through, together with everything fetched before the failure. Pass Offset
+// as the offset of the same fetch to resume where it stopped: the Offset of
+// ChannelMembersOptions or the OffsetID of ChannelMessagesOptions.
+type ResumableError struct {
+	Err error
+	// Offset is where the failed page started
+	Offset int
+	// Fetched is the number of results fetched before the failure
+	Fetched int
+}
+
+func (e *ResumableError) Error() string {
+	return fmt.Sprintf("%v (resume at offset %d, %d fetched)", e.Err, e.Offset, e.Fetched)
+}
+
+func (e *ResumableError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,90 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/test-go/testify/require"
+)
+
+// failingInvoker fails the requests after the first n of the given type
+type failingInvoker struct {
+	*FakeBackend
+	n     int
+	match func(input bin.Encoder) bool
+}
+
+func (f *failingInvoker) Invoke(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+	if f.match(input) {
+		if f.n == 0 {
+			return tgerr.New(500, "INTERNAL")
+		}
+		f.n--
+	}
+
+	return f.FakeBackend.Invoke(ctx, input, output)
+}
+
+func TestResumableError(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	for id := 1; id <= 6; id++ {
+		backend.AddMessages(100, &tg.Message{ID: id, Message: "message", Date: 1700000000 + id})
+	}
+
+	history := &failingInvoker{FakeBackend: backend, n: 1, match: func(input bin.Encoder) bool {
+		_, ok := input.(*tg.MessagesGetHistoryRequest)
+		return ok
+	}}
+
+	client := NewTestClient(logger, history, nil)
+
+	messages, err := client.GetChannelMessages(100, &ChannelMessagesOptions{MinMessages: 6, BatchSize: 2})
+	require.Len(t, messages, 2)
+
+	var resumable *ResumableError
+	require.True(t, errors.As(err, &resumable))
+	require.Equal(t, 5, resumable.Offset)
+	require.Equal(t, 2, resumable.Fetched)
+
+	// Resuming fetches the rest
+	history.n = -1
+	messages, err = client.GetChannelMessages(100, &ChannelMessagesOptions{MinMessages: 4, BatchSize: 2, OffsetID: resumable.Offset})
+	require.NoError(t, err)
+	require.Len(t, messages, 4)
+	require.Equal(t, 4, messages[0].ID)
+
+	// A failing first batch has nothing to resume
+	history.n = 0
+	messages, err = client.GetChannelMessages(100, &ChannelMessagesOptions{BatchSize: 2})
+	require.Nil(t, messages)
+	require.Error(t, err)
+	require.False(t, errors.As(err, &resumable))
+
+	for id := int64(1); id <= 150; id++ {
+		backend.AddMembers(100, &tg.User{ID: id})
+	}
+
+	participants := &failingInvoker{FakeBackend: backend, n: 1, match: func(input bin.Encoder) bool {
+		_, ok := input.(*tg.ChannelsGetParticipantsRequest)
+		return ok
+	}}
+
+	client = NewTestClient(logger, participants, nil)
+
+	members, err := client.GetChannelMembers(context.Background(), "news", &ChannelMembersOptions{})
+	require.Len(t, members, 100)
+	require.True(t, errors.As(err, &resumable))
+	require.Equal(t, 100, resumable.Offset)
+
+	participants.n = -1
+	members, err = client.GetChannelMembers(context.Background(), "news", &ChannelMembersOptions{Offset: resumable.Offset})
+	require.NoError(t, err)
+	require.Len(t, members, 50)
+	require.Equal(t, int64(101), members[0].ID)
+}
@@ -0,0 +1,112 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"golang.org/x/exp/slog"
+)
+
+// ErrEmptyQuery is returned for searches without a query
+var ErrEmptyQuery = errors.New("search query is empty")
+
+const defaultSearchLimit = 50
+
+// SearchChannelsOptions configures a channel search
+type SearchChannelsOptions struct {
+	// Limit is the number of results, defaults to 50. Telegram returns at
+	// most 100.
+	Limit int
+	// IncludeGroups returns supergroups too, by default only broadcast
+	// channels are returned
+	IncludeGroups bool
+}
+
+// SearchChannels searches the public channels matching the query by title
+// and username, like the global search of the apps. Channels the account is
+// in come first. The results are added to the peer cache, so fetching their
+// history or members doesn't resolve the username again.
+func (c *Client) SearchChannels(ctx context.Context, query string, opts *SearchChannelsOptions) ([]*ChannelInfo, error) {
+	if c.api() == nil {
+		return nil, ErrNotInitialized
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+
+	if opts == nil {
+		opts = &SearchChannelsOptions{}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	c.takeRequest()
+
+	found, err := c.api().ContactsSearch(ctx, &tg.ContactsSearchRequest{
+		Q:     query,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search contacts: %w", err)
+	}
+
+	channels := make(map[int64]*tg.Channel, len(found.Chats))
+	for _, chat := range found.Chats {
+		c.peers.addChat(chat)
+
+		if channel, ok := chat.(*tg.Channel); ok {
+			channels[channel.ID] = channel
+		}
+	}
+
+	var (
+		results []*ChannelInfo
+		seen    = make(map[int64]bool)
+	)
+
+	for _, peer := range append(found.MyResults, found.Results...) {
+		id, ok := peer.(*tg.PeerChannel)
+		if !ok || seen[id.ChannelID] {
+			continue
+		}
+
+		channel, ok := channels[id.ChannelID]
+		if !ok || (!channel.Broadcast && !opts.IncludeGroups) {
+			continue
+		}
+
+		seen[channel.ID] = true
+
+		info := &ChannelInfo{}
+		applyChannel(info, channel)
+		results = append(results, info)
+
+		c.cacheChannelUsernames(ctx, info)
+	}
+
+	return results, nil
+}
+
+// cacheChannelUsernames stores the usernames of the channel in the peer
+// cache
+func (c *Client) cacheChannelUsernames(ctx context.Context, info *ChannelInfo) {
+	input := tg.InputChannel{ChannelID: info.ID, AccessHash: info.AccessHash}
+
+	for _, name := range append([]string{info.Username}, info.Usernames...) {
+		if name == "" {
+			continue
+		}
+
+		if err := c.cfg.PeerCache.Set(ctx, usernameKey(name), input, peerCacheTTL); err != nil {
+			c.log().Warn("failed to write peer cache", slog.String("err", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestSearchChannels(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "cryptonews", "Crypto News")
+	backend.AddChannel(101, "weather", "Weather")
+	backend.AddChannel(102, "cryptosignals", "Signals")
+
+	cfg := &Config{}
+	client := NewTestClient(logger, backend, cfg)
+	ctx := context.Background()
+
+	results, err := client.SearchChannels(ctx, "crypto", nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, int64(100), results[0].ID)
+	require.Equal(t, "cryptonews", results[0].Username)
+	require.Equal(t, "Crypto News", results[0].Title)
+	require.True(t, results[0].Broadcast)
+	require.Equal(t, int64(102), results[1].ID)
+
+	// The results are cached by username
+	cached, ok, err := cfg.PeerCache.Get(ctx, usernameKey("CryptoSignals"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, tg.InputChannel{ChannelID: 102, AccessHash: results[1].AccessHash}, cached)
+
+	results, err = client.SearchChannels(ctx, "crypto", &SearchChannelsOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	_, err = client.SearchChannels(ctx, " ", nil)
+	require.True(t, errors.Is(err, ErrEmptyQuery))
+}
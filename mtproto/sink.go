@@ -0,0 +1,121 @@
+package mtproto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Sink event kinds
+const (
+	SinkMessages = "messages"
+	SinkMembers  = "members"
+)
+
+const defaultSinkTimeout = 30 * time.Second
+
+// SinkEvent is a batch of fetched data pushed to a sink
+type SinkEvent struct {
+	Kind     string     `json:"kind"`
+	ChatID   int64      `json:"chat_id,omitempty"`
+	Channel  string     `json:"channel,omitempty"`
+	Messages []*Message `json:"messages,omitempty"`
+	Members  []*Member  `json:"members,omitempty"`
+	Time     time.Time  `json:"time"`
+}
+
+// Sink receives fetched batches, set it on the fetch options to stream data
+// into an ingestion pipeline. An error aborts the fetch.
+type Sink interface {
+	Write(ctx context.Context, event SinkEvent) error
+}
+
+// SinkFunc adapts a function to the Sink interface
+type SinkFunc func(ctx context.Context, event SinkEvent) error
+
+func (f SinkFunc) Write(ctx context.Context, event SinkEvent) error {
+	return f(ctx, event)
+}
+
+// ChanSink sends events to a Go channel, blocking until the event is
+// received or the context is done
+type ChanSink chan<- SinkEvent
+
+func (c ChanSink) Write(ctx context.Context, event SinkEvent) error {
+	select {
+	case c <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HTTPSink posts events as JSON to an endpoint
+type HTTPSink struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+}
+
+// NewHTTPSink creates a sink posting to the URL
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		URL:    url,
+		Header: make(http.Header),
+		Client: &http.Client{Timeout: defaultSinkTimeout},
+	}
+}
+
+func (h *HTTPSink) Write(ctx context.Context, event SinkEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	for key, values := range h.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("post event: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func writeSink(ctx context.Context, sink Sink, event SinkEvent) error {
+	if sink == nil || (len(event.Messages) == 0 && len(event.Members) == 0) {
+		return nil
+	}
+
+	event.Time = time.Now()
+
+	if err := sink.Write(ctx, event); err != nil {
+		return fmt.Errorf("write sink: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package mtproto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/test-go/testify/require"
+)
+
+func TestSinks(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.AddChannel(100, "news", "News")
+
+	for id := 1; id <= 5; id++ {
+		backend.AddMessages(100, &tg.Message{ID: id, Message: "message", Date: 1700000000 + id})
+	}
+
+	backend.AddMembers(100,
+		&tg.User{ID: 1, FirstName: "Alice"},
+		&tg.User{ID: 2, FirstName: "Bob"},
+	)
+
+	client := NewTestClient(logger, backend, nil)
+
+	// Every batch is pushed as converted messages
+	events := make(chan SinkEvent, 10)
+	messages, err := client.GetChannelMessages(100, &ChannelMessagesOptions{
+		MinMessages: 5,
+		BatchSize:   2,
+		Sink:        ChanSink(events),
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 5)
+	close(events)
+
+	var ids []int64
+	for event := range events {
+		require.Equal(t, SinkMessages, event.Kind)
+		require.Equal(t, int64(100), event.ChatID)
+		require.False(t, event.Time.IsZero())
+
+		for _, msg := range event.Messages {
+			ids = append(ids, msg.ID)
+		}
+	}
+	require.Equal(t, []int64{5, 4, 3, 2, 1}, ids)
+
+	// Members are posted as JSON
+	var posted []SinkEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		var event SinkEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		posted = append(posted, event)
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL)
+	sink.Header.Set("X-Token", "secret")
+
+	members, err := client.GetChannelMembers(context.Background(), "news", &ChannelMembersOptions{Sink: sink})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.Len(t, posted, 1)
+	require.Equal(t, SinkMembers, posted[0].Kind)
+	require.Equal(t, "news", posted[0].Channel)
+	require.Equal(t, int64(100), posted[0].ChatID)
+	require.Len(t, posted[0].Members, 2)
+	require.Equal(t, "Bob", posted[0].Members[1].FirstName)
+
+	// A failing sink aborts the fetch
+	failed := errors.New("sink down")
+	_, err = client.GetChannelMessages(100, &ChannelMessagesOptions{
+		MinMessages: 5,
+		BatchSize:   2,
+		Sink: SinkFunc(func(context.Context, SinkEvent) error {
+			return failed
+		}),
+	})
+	require.True(t, errors.Is(err, failed))
+}
@@ -0,0 +1,186 @@
+package mtproto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+)
+
+const (
+	snapshotTable           = "channel_snapshots"
+	defaultSnapshotInterval = 6 * time.Hour
+)
+
+// ChannelSnapshot is a recorded state of a channel
+type ChannelSnapshot struct {
+	ID                uint  `gorm:"primaryKey"`
+	ChannelID         int64 `gorm:"index:idx_channel_snapshot,priority:1"`
+	Title             string
+	About             string
+	Username          string
+	Usernames         string // comma separated active usernames
+	ParticipantsCount int
+	LinkedChatID      int64
+	PhotoID           int64
+	CreatedAt         time.Time `gorm:"index:idx_channel_snapshot,priority:2"`
+}
+
+// SnapshotterConfig configures periodic channel snapshots
+type SnapshotterConfig struct {
+	// Channels are the usernames of the channels to snapshot
+	Channels []string
+	// Interval between snapshot rounds, defaults to 6 hours
+	Interval time.Duration
+	// RecordUnchanged stores a snapshot every round instead of only on changes
+	RecordUnchanged bool
+	// OnChange is called when a channel changed since the previous snapshot
+	OnChange func(prev, next *ChannelSnapshot)
+}
+
+// Snapshotter records channel metadata over time in the client database
+type Snapshotter struct {
+	client *Client
+	cfg    SnapshotterConfig
+	db     *gorm.DB
+	table  string
+}
+
+// NewSnapshotter creates a snapshotter, the client must be initialized
+func (c *Client) NewSnapshotter(cfg SnapshotterConfig) (*Snapshotter, error) {
+	if c.db == nil {
+		return nil, ErrNotInitialized
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultSnapshotInterval
+	}
+
+	s := &Snapshotter{
+		client: c,
+		cfg:    cfg,
+		db:     c.db,
+		table:  c.cfg.DatabaseConfig.TablePrefix + snapshotTable,
+	}
+
+	if err := s.db.Table(s.table).AutoMigrate(&ChannelSnapshot{}); err != nil {
+		return nil, fmt.Errorf("migrate snapshots: %w", err)
+	}
+
+	return s, nil
+}
+
+// Run snapshots the configured channels every interval until the context is done
+func (s *Snapshotter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, channel := range s.cfg.Channels {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if _, _, err := s.Snapshot(ctx, channel); err != nil {
+				s.client.log().Error("failed to snapshot channel",
+					slog.String("channel", channel),
+					slog.String("err", err.Error()),
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot fetches the channel info and records it if it changed, it returns
+// the current snapshot and whether it was recorded
+func (s *Snapshotter) Snapshot(ctx context.Context, username string) (*ChannelSnapshot, bool, error) {
+	info, err := s.client.GetChannelInfo(ctx, username)
+	if err != nil {
+		return nil, false, err
+	}
+
+	next := &ChannelSnapshot{
+		ChannelID:         info.ID,
+		Title:             info.Title,
+		About:             info.About,
+		Username:          info.Username,
+		Usernames:         strings.Join(info.Usernames, ","),
+		ParticipantsCount: info.ParticipantsCount,
+		LinkedChatID:      info.LinkedChatID,
+		PhotoID:           info.PhotoID,
+		CreatedAt:         info.FetchedAt,
+	}
+
+	prev, err := s.Latest(ctx, info.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	changed := prev == nil || !prev.equal(next)
+	if !changed && !s.cfg.RecordUnchanged {
+		return next, false, nil
+	}
+
+	if err := s.db.WithContext(ctx).Table(s.table).Create(next).Error; err != nil {
+		return nil, false, fmt.Errorf("save snapshot: %w", err)
+	}
+
+	if changed && prev != nil && s.cfg.OnChange != nil {
+		s.cfg.OnChange(prev, next)
+	}
+
+	return next, true, nil
+}
+
+// Latest returns the most recent snapshot of a channel, nil if none exists
+func (s *Snapshotter) Latest(ctx context.Context, channelID int64) (*ChannelSnapshot, error) {
+	var snapshot ChannelSnapshot
+
+	err := s.db.WithContext(ctx).Table(s.table).
+		Where("channel_id = ?", channelID).
+		Order("created_at DESC").
+		Take(&snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// History returns the snapshots of a channel since the given time, oldest first
+func (s *Snapshotter) History(ctx context.Context, channelID int64, since time.Time) ([]ChannelSnapshot, error) {
+	var snapshots []ChannelSnapshot
+
+	err := s.db.WithContext(ctx).Table(s.table).
+		Where("channel_id = ? AND created_at >= ?", channelID, since).
+		Order("created_at ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+func (s *ChannelSnapshot) equal(o *ChannelSnapshot) bool {
+	return s.Title == o.Title &&
+		s.About == o.About &&
+		s.Username == o.Username &&
+		s.Usernames == o.Usernames &&
+		s.ParticipantsCount == o.ParticipantsCount &&
+		s.LinkedChatID == o.LinkedChatID &&
+		s.PhotoID == o.PhotoID
+}
@@ -0,0 +1,130 @@
+package mtproto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"golang.org/x/exp/slog"
+)
+
+// Username check statuses
+const (
+	UsernameAvailable   = "available"
+	UsernameTaken       = "taken"
+	UsernameInvalid     = "invalid"
+	UsernamePurchasable = "purchasable"
+	UsernameError       = "error"
+)
+
+const (
+	defaultUsernameDelay = time.Second
+	maxFloodWait         = 5 * time.Minute
+)
+
+// UsernameCheck is the availability of a single username
+type UsernameCheck struct {
+	Username  string
+	Available bool
+	Status    string
+	// Err is set when the check failed, Status is UsernameError
+	Err error `json:"-"`
+}
+
+// CheckUsernamesOptions configures a batch username check
+type CheckUsernamesOptions struct {
+	// Channel checks availability for a new channel instead of the account,
+	// the rules for channel usernames differ slightly
+	Channel bool
+	// Delay between checks, defaults to a second. Config.RateLimit applies too.
+	Delay time.Duration
+}
+
+// CheckUsernames checks which usernames are available. Flood waits up to five
+// minutes are waited out, a failed check is reported on its result and does
+// not stop the batch. An error is only returned when the context is done.
+func (c *Client) CheckUsernames(ctx context.Context, names []string, opts *CheckUsernamesOptions) ([]UsernameCheck, error) {
+	if opts == nil {
+		opts = &CheckUsernamesOptions{}
+	}
+
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = defaultUsernameDelay
+	}
+
+	results := make([]UsernameCheck, 0, len(names))
+
+	for i, name := range names {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+		result, err := c.checkUsername(ctx, name, opts.Channel)
+		if err != nil && ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (c *Client) checkUsername(ctx context.Context, name string, channel bool) (UsernameCheck, error) {
+	result := UsernameCheck{Username: name}
+
+	for {
+		c.takeRequest()
+
+		var (
+			available bool
+			err       error
+		)
+		if channel {
+			available, err = c.api().ChannelsCheckUsername(ctx, &tg.ChannelsCheckUsernameRequest{
+				Channel:  &tg.InputChannelEmpty{},
+				Username: name,
+			})
+		} else {
+			available, err = c.api().AccountCheckUsername(ctx, name)
+		}
+
+		if wait, ok := tgerr.AsFloodWait(err); ok && wait <= maxFloodWait {
+			c.log().Warn("flood wait checking usernames", slog.Duration("wait", wait))
+
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		switch {
+		case err == nil && available:
+			result.Available = true
+			result.Status = UsernameAvailable
+		case err == nil, tgerr.Is(err, "USERNAME_OCCUPIED"):
+			result.Status = UsernameTaken
+		case tgerr.Is(err, "USERNAME_PURCHASE_AVAILABLE"):
+			result.Status = UsernamePurchasable
+		case tgerr.Is(err, "USERNAME_INVALID"):
+			result.Status = UsernameInvalid
+		default:
+			result.Status = UsernameError
+			result.Err = fmt.Errorf("check username: %w", err)
+			return result, result.Err
+		}
+
+		return result, nil
+	}
+}
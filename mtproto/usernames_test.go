@@ -0,0 +1,102 @@
+package mtproto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/test-go/testify/require"
+)
+
+func TestCheckUsernames(t *testing.T) {
+	flooded := false
+
+	invoker := &stubInvoker{FakeBackend: NewFakeBackend()}
+	invoker.respond = func(input bin.Encoder) (bin.Encoder, error) {
+		var name string
+		switch req := input.(type) {
+		case *tg.AccountCheckUsernameRequest:
+			name = req.Username
+		case *tg.ChannelsCheckUsernameRequest:
+			name = req.Username
+		default:
+			return nil, nil
+		}
+
+		switch name {
+		case "free":
+			return &tg.BoolTrue{}, nil
+		case "false":
+			return &tg.BoolFalse{}, nil
+		case "taken":
+			return nil, tgerr.New(400, "USERNAME_OCCUPIED")
+		case "auction":
+			return nil, tgerr.New(400, "USERNAME_PURCHASE_AVAILABLE")
+		case "a":
+			return nil, tgerr.New(400, "USERNAME_INVALID")
+		case "flood":
+			// The flood wait is waited out once
+			if !flooded {
+				flooded = true
+				return nil, tgerr.New(420, "FLOOD_WAIT_0")
+			}
+
+			return &tg.BoolTrue{}, nil
+		case "longflood":
+			return nil, tgerr.New(420, "FLOOD_WAIT_3600")
+		}
+
+		return nil, tgerr.New(500, "INTERNAL")
+	}
+
+	client := NewTestClient(logger, invoker, nil)
+	opts := &CheckUsernamesOptions{Delay: time.Millisecond}
+
+	results, err := client.CheckUsernames(context.Background(),
+		[]string{" @free", "false", "taken", "auction", "a", "flood", "longflood", "broken"}, opts)
+	require.NoError(t, err)
+
+	statuses := make(map[string]string)
+	for _, result := range results {
+		statuses[result.Username] = result.Status
+		require.Equal(t, result.Status == UsernameAvailable, result.Available)
+		require.Equal(t, result.Status == UsernameError, result.Err != nil)
+	}
+
+	require.Equal(t, map[string]string{
+		"free":      UsernameAvailable,
+		"false":     UsernameTaken,
+		"taken":     UsernameTaken,
+		"auction":   UsernamePurchasable,
+		"a":         UsernameInvalid,
+		"flood":     UsernameAvailable,
+		"longflood": UsernameError,
+		"broken":    UsernameError,
+	}, statuses)
+
+	// The prefix is trimmed, the flooded check was sent again
+	requests := requestsOf[*tg.AccountCheckUsernameRequest](invoker)
+	require.Len(t, requests, 9)
+	require.Equal(t, "free", requests[0].Username)
+	require.Empty(t, requestsOf[*tg.ChannelsCheckUsernameRequest](invoker))
+
+	// Channel usernames are checked for a new channel
+	results, err = client.CheckUsernames(context.Background(), []string{"free"}, &CheckUsernamesOptions{Channel: true})
+	require.NoError(t, err)
+	require.True(t, results[0].Available)
+
+	channelRequests := requestsOf[*tg.ChannelsCheckUsernameRequest](invoker)
+	require.Len(t, channelRequests, 1)
+	require.Equal(t, &tg.InputChannelEmpty{}, channelRequests[0].Channel)
+
+	// A canceled context stops the batch
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err = client.CheckUsernames(ctx, []string{"free", "taken"}, opts)
+	require.Error(t, err)
+	require.Empty(t, results)
+}
@@ -3,7 +3,7 @@ package tgbot
 import (
 	"context"
 	"fmt"
-	"strings"
+	"runtime/debug"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -11,26 +11,85 @@ import (
 )
 
 // createBotOptions creates the configuration options for the telegram bot
-func createBotOptions(logger *slog.Logger, cfg *Config) []bot.Option {
+func (s *Service) createBotOptions() []bot.Option {
 	options := []bot.Option{
 		bot.WithAllowedUpdates(allowedUpdates),
 		bot.WithCheckInitTimeout(defaultTimeout),
 		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *models.Update) {}),
-		createDebugHandler(logger),
-		createErrorHandler(logger),
+		createDebugHandler(s.logger),
+		createErrorHandler(s.logger),
+		bot.WithMiddlewares(s.serviceMiddleware()...),
 	}
 
-	if cfg.UseTestEnvironment {
+	if s.cfg.UseTestEnvironment {
 		options = append(options, bot.UseTestEnvironment())
 	}
 
-	if cfg.Bot != nil {
-		options = append(options, createBotSpecificOptions(cfg.Bot)...)
+	if s.cfg.APIServer != nil {
+		options = append(options, bot.WithServerURL(s.apiServerURL()))
+	}
+
+	switch {
+	case s.cfg.DryRun != nil:
+		options = append(options, s.dryRunOption())
+	case s.cfg.TokenProvider != nil:
+		options = append(options, s.tokenClientOption())
+	}
+
+	if s.cfg.Bot != nil {
+		options = append(options, createBotSpecificOptions(s.cfg.Bot, s.commandRoutes(), func() string { return s.username })...)
 	}
 
 	return options
 }
 
+// serviceMiddleware returns the middleware the service runs in front of the
+// middleware of the bot
+func (s *Service) serviceMiddleware() []bot.Middleware {
+	return []bot.Middleware{
+		s.chatQueueMiddleware(),
+		s.inflightMiddleware(),
+		s.recoverMiddleware(),
+		s.instanceMiddleware(),
+		s.staleMiddleware(),
+		s.maintenanceMiddleware(),
+		s.localeMiddleware(),
+		s.profileMiddleware(),
+		s.moderationMiddleware(),
+		s.pollMiddleware(),
+		s.reactionMiddleware(),
+		s.paymentMiddleware(),
+		s.giveawayMiddleware(),
+		s.joinRequestMiddleware(),
+		s.callbackMiddleware(),
+		s.readMoreMiddleware(),
+	}
+}
+
+// recoverMiddleware recovers panics in handlers, logs them and notifies the admin
+func (s *Service) recoverMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Error("handler panicked",
+						slog.Any("panic", r),
+						slog.Int64("update", update.ID),
+						slog.String("stack", string(debug.Stack())),
+					)
+
+					s.notifyAdminAsync(slog.LevelError, "Handler panicked",
+						slog.Any("panic", r),
+						slog.Int64("update", update.ID),
+					)
+				}
+			}()
+
+			next(ctx, b, update)
+		}
+	}
+}
+
 func createDebugHandler(logger *slog.Logger) bot.Option {
 	return bot.WithDebugHandler(func(format string, args ...any) {
 		logger.Debug(fmt.Sprintf(format, args...))
@@ -43,7 +102,7 @@ func createErrorHandler(logger *slog.Logger) bot.Option {
 	})
 }
 
-func createBotSpecificOptions(b Bot) []bot.Option {
+func createBotSpecificOptions(b Bot, commands []commandRoute, username func() string) []bot.Option {
 	var options []bot.Option
 
 	// Add callback handlers
@@ -55,10 +114,15 @@ func createBotSpecificOptions(b Bot) []bot.Option {
 		))
 	}
 
+	// Route inline queries before the bot middleware sees them
+	if handler := inlineHandler(b); handler != nil {
+		options = append(options, bot.WithMiddlewares(createInlineMiddleware(handler)))
+	}
+
 	// Add middleware
 	if middleware := b.Middleware(); len(middleware) > 0 {
 		options = append(options, bot.WithMiddlewares(
-			append(middleware, createCaptionCommandMiddleware(b))...,
+			append(middleware, createCaptionCommandMiddleware(commands, username))...,
 		))
 	}
 
@@ -70,7 +134,7 @@ func createBotSpecificOptions(b Bot) []bot.Option {
 	return options
 }
 
-func createCaptionCommandMiddleware(bb Bot) bot.Middleware {
+func createCaptionCommandMiddleware(commands []commandRoute, username func() string) bot.Middleware {
 	return func(next bot.HandlerFunc) bot.HandlerFunc {
 		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
 			if update.Message == nil || update.Message.Caption == "" {
@@ -78,10 +142,9 @@ func createCaptionCommandMiddleware(bb Bot) bot.Middleware {
 				return
 			}
 
-			for command, handler := range bb.Commands() {
-				if strings.HasPrefix(update.Message.Text, command) ||
-					strings.HasPrefix(update.Message.Caption, command) {
-					handler(ctx, b, update)
+			for _, route := range commands {
+				if route.matches(update.Message.Caption, username()) {
+					route.handler(ctx, b, update)
 					return
 				}
 			}
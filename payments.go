@@ -0,0 +1,314 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+// CurrencyStars is the currency of payments in Telegram Stars
+const CurrencyStars = "XTR"
+
+var (
+	ErrInvoicePrices = errors.New("invoice needs at least one price")
+	ErrStarsInvoice  = errors.New("stars invoices take a single price and no provider token")
+)
+
+// Invoice describes an invoice. Amounts are in the smallest units of the
+// currency, e.g. cents, or whole stars for CurrencyStars.
+type Invoice struct {
+	Title       string
+	Description string
+	// Payload is returned in the queries and the payment, it is not shown to the user
+	Payload  string
+	Currency string
+	// Prices is the price breakdown, e.g. product, tax and discount
+	Prices []models.LabeledPrice
+	// ProviderToken of the payment provider, empty for CurrencyStars
+	ProviderToken string
+
+	MaxTipAmount        int
+	SuggestedTipAmounts []int
+	PhotoURL            string
+
+	NeedName            bool
+	NeedPhoneNumber     bool
+	NeedEmail           bool
+	NeedShippingAddress bool
+	// Flexible invoices send a shipping query, the price depends on the
+	// shipping method
+	Flexible bool
+
+	ReplyTo  int
+	ThreadID int
+}
+
+// ShippingHandler returns the shipping options for the address of the query,
+// the error text is shown to the user when shipping is not possible
+type ShippingHandler func(ctx context.Context, query *models.ShippingQuery) ([]models.ShippingOption, error)
+
+// PreCheckoutHandler confirms the order before payment, e.g. checks stock. The
+// error text is shown to the user when the order is declined. Telegram
+// requires an answer within 10 seconds.
+type PreCheckoutHandler func(ctx context.Context, query *models.PreCheckoutQuery) error
+
+// PaymentHandler receives successful payments
+type PaymentHandler func(ctx context.Context, msg *models.Message, payment *models.SuccessfulPayment)
+
+// SendInvoice sends an invoice through the send pipeline
+func (s *Service) SendInvoice(ctx context.Context, chatID int64, invoice Invoice) (*models.Message, error) {
+	params, err := invoice.params(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.pipeline.enqueue(chatID, func() (*models.Message, error) {
+		s.ratelimit.take(chatID)
+
+		ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		msg, err := s.bot.SendInvoice(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("send invoice: %w", err)
+		}
+
+		s.tracker.track(chatID, msg)
+
+		return msg, nil
+	})
+
+	select {
+	case res := <-result:
+		return res.Message, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CreateInvoiceLink creates a link to pay the invoice, e.g. for a URL button
+// or an inline result. ReplyTo and ThreadID don't apply to links.
+func (s *Service) CreateInvoiceLink(ctx context.Context, invoice Invoice) (string, error) {
+	params, err := invoice.linkParams()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	link, err := s.bot.CreateInvoiceLink(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("create invoice link: %w", err)
+	}
+
+	return link, nil
+}
+
+// RefundStarPayment refunds a payment in stars
+func (s *Service) RefundStarPayment(ctx context.Context, userID int64, chargeID string) error {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.RefundStarPayment(ctx, &bot.RefundStarPaymentParams{
+		UserID:                  userID,
+		TelegramPaymentChargeID: chargeID,
+	}); err != nil {
+		return fmt.Errorf("refund star payment: %w", err)
+	}
+
+	return nil
+}
+
+// HandleShipping sets the handler answering shipping queries, without one
+// the queries are passed on to the bot
+func (s *Service) HandleShipping(handler ShippingHandler) {
+	s.payments.mu.Lock()
+	defer s.payments.mu.Unlock()
+
+	s.payments.shipping = handler
+}
+
+// HandlePreCheckout sets the handler answering pre-checkout queries, without
+// one the queries are passed on to the bot
+func (s *Service) HandlePreCheckout(handler PreCheckoutHandler) {
+	s.payments.mu.Lock()
+	defer s.payments.mu.Unlock()
+
+	s.payments.preCheckout = handler
+}
+
+// OnPayment registers a handler for successful payments and returns a
+// function that removes it
+func (s *Service) OnPayment(handler PaymentHandler) func() {
+	s.payments.mu.Lock()
+	defer s.payments.mu.Unlock()
+
+	if s.payments.handlers == nil {
+		s.payments.handlers = make(map[int]PaymentHandler)
+	}
+
+	id := s.payments.nextID
+	s.payments.nextID++
+	s.payments.handlers[id] = handler
+
+	return func() {
+		s.payments.mu.Lock()
+		defer s.payments.mu.Unlock()
+
+		delete(s.payments.handlers, id)
+	}
+}
+
+// paymentMiddleware answers shipping and pre-checkout queries with the
+// registered handlers and hands successful payments to the payment handlers
+func (s *Service) paymentMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			s.payments.mu.RLock()
+			shipping, preCheckout := s.payments.shipping, s.payments.preCheckout
+			handlers := make([]PaymentHandler, 0, len(s.payments.handlers))
+			for _, handler := range s.payments.handlers {
+				handlers = append(handlers, handler)
+			}
+			s.payments.mu.RUnlock()
+
+			switch {
+			case update.ShippingQuery != nil && shipping != nil:
+				s.answerShipping(ctx, b, update.ShippingQuery, shipping)
+				return
+			case update.PreCheckoutQuery != nil && preCheckout != nil:
+				s.answerPreCheckout(ctx, b, update.PreCheckoutQuery, preCheckout)
+				return
+			case update.Message != nil && update.Message.SuccessfulPayment != nil:
+				for _, handler := range handlers {
+					handler(ctx, update.Message, update.Message.SuccessfulPayment)
+				}
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func (s *Service) answerShipping(ctx context.Context, b *bot.Bot, query *models.ShippingQuery, handler ShippingHandler) {
+	params := &bot.AnswerShippingQueryParams{ShippingQueryID: query.ID}
+
+	options, err := handler(ctx, query)
+	switch {
+	case err != nil:
+		params.ErrorMessage = err.Error()
+	case len(options) == 0:
+		params.ErrorMessage = "Shipping to this address is not available"
+	default:
+		params.OK = true
+		params.ShippingOptions = options
+	}
+
+	if _, err := b.AnswerShippingQuery(ctx, params); err != nil {
+		s.logger.Error("failed to answer shipping query",
+			slog.String("err", err.Error()),
+			slog.String("payload", query.InvoicePayload),
+		)
+	}
+}
+
+func (s *Service) answerPreCheckout(ctx context.Context, b *bot.Bot, query *models.PreCheckoutQuery, handler PreCheckoutHandler) {
+	params := &bot.AnswerPreCheckoutQueryParams{PreCheckoutQueryID: query.ID, OK: true}
+
+	if err := handler(ctx, query); err != nil {
+		params.OK = false
+		params.ErrorMessage = err.Error()
+	}
+
+	if _, err := b.AnswerPreCheckoutQuery(ctx, params); err != nil {
+		s.logger.Error("failed to answer pre-checkout query",
+			slog.String("err", err.Error()),
+			slog.String("payload", query.InvoicePayload),
+		)
+	}
+}
+
+func (i Invoice) validate() error {
+	if len(i.Prices) == 0 {
+		return ErrInvoicePrices
+	}
+
+	if i.Currency == CurrencyStars && (len(i.Prices) != 1 || i.ProviderToken != "") {
+		return ErrStarsInvoice
+	}
+
+	return nil
+}
+
+func (i Invoice) params(chatID int64) (*bot.SendInvoiceParams, error) {
+	if err := i.validate(); err != nil {
+		return nil, err
+	}
+
+	params := &bot.SendInvoiceParams{
+		ChatID:              chatID,
+		MessageThreadID:     i.ThreadID,
+		Title:               i.Title,
+		Description:         i.Description,
+		Payload:             i.Payload,
+		ProviderToken:       i.ProviderToken,
+		Currency:            i.Currency,
+		Prices:              i.Prices,
+		MaxTipAmount:        i.MaxTipAmount,
+		SuggestedTipAmounts: i.SuggestedTipAmounts,
+		PhotoURL:            i.PhotoURL,
+		NeedName:            i.NeedName,
+		NeedPhoneNumber:     i.NeedPhoneNumber,
+		NeedEmail:           i.NeedEmail,
+		NeedShippingAddress: i.NeedShippingAddress || i.Flexible,
+		IsFlexible:          i.Flexible,
+	}
+
+	if i.ReplyTo > 0 {
+		params.ReplyParameters = &models.ReplyParameters{
+			ChatID:                   chatID,
+			MessageID:                i.ReplyTo,
+			AllowSendingWithoutReply: true,
+		}
+	}
+
+	return params, nil
+}
+
+func (i Invoice) linkParams() (*bot.CreateInvoiceLinkParams, error) {
+	if err := i.validate(); err != nil {
+		return nil, err
+	}
+
+	return &bot.CreateInvoiceLinkParams{
+		Title:               i.Title,
+		Description:         i.Description,
+		Payload:             i.Payload,
+		ProviderToken:       i.ProviderToken,
+		Currency:            i.Currency,
+		Prices:              i.Prices,
+		MaxTipAmount:        i.MaxTipAmount,
+		SuggestedTipAmounts: i.SuggestedTipAmounts,
+		PhotoURL:            i.PhotoURL,
+		NeedName:            i.NeedName,
+		NeedPhoneNumber:     i.NeedPhoneNumber,
+		NeedEmail:           i.NeedEmail,
+		NeedShippingAddress: i.NeedShippingAddress || i.Flexible,
+		IsFlexible:          i.Flexible,
+	}, nil
+}
+
+type paymentState struct {
+	mu          sync.RWMutex
+	shipping    ShippingHandler
+	preCheckout PreCheckoutHandler
+	nextID      int
+	handlers    map[int]PaymentHandler
+}
@@ -0,0 +1,75 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceParams(t *testing.T) {
+	_, err := Invoice{Currency: "EUR"}.params(1)
+	require.ErrorIs(t, err, ErrInvoicePrices)
+
+	_, err = Invoice{
+		Currency: CurrencyStars,
+		Prices:   []models.LabeledPrice{{Label: "a", Amount: 1}, {Label: "b", Amount: 2}},
+	}.params(1)
+	require.ErrorIs(t, err, ErrStarsInvoice)
+
+	params, err := Invoice{
+		Title:    "Coffee",
+		Currency: "EUR",
+		Prices:   []models.LabeledPrice{{Label: "Coffee", Amount: 250}, {Label: "Tax", Amount: 50}},
+		Flexible: true,
+		ReplyTo:  3,
+	}.params(1)
+	require.NoError(t, err)
+	require.True(t, params.NeedShippingAddress)
+	require.True(t, params.IsFlexible)
+	require.Len(t, params.Prices, 2)
+	require.Equal(t, 3, params.ReplyParameters.MessageID)
+}
+
+func TestInvoiceLinkParams(t *testing.T) {
+	_, err := Invoice{Currency: CurrencyStars, ProviderToken: "token", Prices: []models.LabeledPrice{{Label: "a", Amount: 1}}}.linkParams()
+	require.ErrorIs(t, err, ErrStarsInvoice)
+
+	params, err := Invoice{
+		Title:    "Premium",
+		Payload:  "premium-30d",
+		Currency: CurrencyStars,
+		Prices:   []models.LabeledPrice{{Label: "Premium", Amount: 100}},
+	}.linkParams()
+	require.NoError(t, err)
+	require.Equal(t, "premium-30d", params.Payload)
+	require.Empty(t, params.ProviderToken)
+}
+
+func TestPaymentMiddleware(t *testing.T) {
+	s := &Service{}
+
+	var payments, passed int
+	unsubscribe := s.OnPayment(func(ctx context.Context, msg *models.Message, payment *models.SuccessfulPayment) {
+		payments++
+	})
+
+	handler := s.paymentMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		passed++
+	})
+
+	paid := &models.Update{Message: &models.Message{SuccessfulPayment: &models.SuccessfulPayment{Currency: CurrencyStars}}}
+	handler(context.Background(), nil, paid)
+	require.Equal(t, 1, payments)
+	require.Equal(t, 1, passed)
+
+	// Queries without a registered handler are passed on to the bot
+	handler(context.Background(), nil, &models.Update{PreCheckoutQuery: &models.PreCheckoutQuery{ID: "q"}})
+	require.Equal(t, 2, passed)
+
+	unsubscribe()
+	handler(context.Background(), nil, paid)
+	require.Equal(t, 1, payments)
+}
@@ -0,0 +1,204 @@
+package tgbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const defaultPhotoWatchInterval = time.Hour
+
+// PhotoFetcher fetches the current profile photo of a user. The Service
+// implements it through the Bot API, an MTProto client can be plugged in
+// through PhotoFetcherFunc.
+type PhotoFetcher interface {
+	GetProfilePhoto(chatID int64) ([]byte, error)
+}
+
+// PhotoFetcherFunc adapts a function to the PhotoFetcher interface
+type PhotoFetcherFunc func(chatID int64) ([]byte, error)
+
+func (f PhotoFetcherFunc) GetProfilePhoto(chatID int64) ([]byte, error) {
+	return f(chatID)
+}
+
+// PhotoChange is emitted when the profile photo of a tracked user changed.
+// An empty hash means the user has no profile photo.
+type PhotoChange struct {
+	UserID  int64
+	OldHash string
+	NewHash string
+	Photo   []byte
+	At      time.Time
+}
+
+// PhotoWatcherConfig holds the configuration of the photo watcher
+type PhotoWatcherConfig struct {
+	// Interval between two checks of all tracked users, defaults to an hour
+	Interval time.Duration
+	// Delay between the checks of two users, to spread the API calls
+	Delay time.Duration
+	// OnChange is called for every detected change
+	OnChange func(change PhotoChange)
+}
+
+// PhotoWatcher periodically checks the profile photos of tracked users and
+// reports changes.
+type PhotoWatcher struct {
+	logger  *slog.Logger
+	fetcher PhotoFetcher
+	cfg     PhotoWatcherConfig
+
+	mu      sync.Mutex
+	tracked map[int64]*string
+}
+
+// NewPhotoWatcher creates a new photo watcher, call Run to start watching
+func NewPhotoWatcher(logger *slog.Logger, fetcher PhotoFetcher, cfg PhotoWatcherConfig) *PhotoWatcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultPhotoWatchInterval
+	}
+
+	return &PhotoWatcher{
+		logger:  logger,
+		fetcher: fetcher,
+		cfg:     cfg,
+		tracked: make(map[int64]*string),
+	}
+}
+
+// Track adds users to the watch list, their first check records the
+// current photo without emitting a change.
+func (w *PhotoWatcher) Track(userIDs ...int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range userIDs {
+		if _, ok := w.tracked[id]; !ok {
+			w.tracked[id] = nil
+		}
+	}
+}
+
+// TrackWithHash adds a user with a previously known photo hash, so changes
+// that happened while the watcher was not running are detected.
+func (w *PhotoWatcher) TrackWithHash(userID int64, hash string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tracked[userID] = &hash
+}
+
+// Untrack removes users from the watch list
+func (w *PhotoWatcher) Untrack(userIDs ...int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range userIDs {
+		delete(w.tracked, id)
+	}
+}
+
+// Hash returns the last known photo hash of a user
+func (w *PhotoWatcher) Hash(userID int64) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	hash, ok := w.tracked[userID]
+	if !ok || hash == nil {
+		return "", false
+	}
+
+	return *hash, true
+}
+
+// Run checks all tracked users every interval until the context is canceled
+func (w *PhotoWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.CheckAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks all tracked users once
+func (w *PhotoWatcher) CheckAll(ctx context.Context) {
+	w.mu.Lock()
+	ids := make([]int64, 0, len(w.tracked))
+	for id := range w.tracked {
+		ids = append(ids, id)
+	}
+	w.mu.Unlock()
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if _, err := w.Check(id); err != nil {
+			w.logger.Warn("failed to check profile photo",
+				slog.String("err", err.Error()),
+				slog.Int64("user", id),
+			)
+		}
+
+		if w.cfg.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.Delay):
+			}
+		}
+	}
+}
+
+// Check fetches the photo of a single user and reports whether it changed
+func (w *PhotoWatcher) Check(userID int64) (bool, error) {
+	photo, err := w.fetcher.GetProfilePhoto(userID)
+	if err != nil && !errors.Is(err, ErrNoProfilePhoto) {
+		return false, err
+	}
+
+	var hash string
+	if len(photo) > 0 {
+		sum := sha256.Sum256(photo)
+		hash = hex.EncodeToString(sum[:])
+	}
+
+	w.mu.Lock()
+	old, ok := w.tracked[userID]
+	if !ok {
+		w.mu.Unlock()
+		return false, nil
+	}
+	w.tracked[userID] = &hash
+	w.mu.Unlock()
+
+	if old == nil || *old == hash {
+		return false, nil
+	}
+
+	if w.cfg.OnChange != nil {
+		w.cfg.OnChange(PhotoChange{
+			UserID:  userID,
+			OldHash: *old,
+			NewHash: hash,
+			Photo:   photo,
+			At:      time.Now(),
+		})
+	}
+
+	return true, nil
+}
@@ -0,0 +1,303 @@
+package tgbot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// fakePhotos serves the photo bytes set per user, users without one have no
+// profile photo
+type fakePhotos struct {
+	mu     sync.Mutex
+	photos map[int64][]byte
+	errs   map[int64]error
+	calls  map[int64]int
+}
+
+func newFakePhotos() *fakePhotos {
+	return &fakePhotos{
+		photos: make(map[int64][]byte),
+		errs:   make(map[int64]error),
+		calls:  make(map[int64]int),
+	}
+}
+
+func (f *fakePhotos) set(userID int64, photo string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if photo == "" {
+		delete(f.photos, userID)
+		return
+	}
+
+	f.photos[userID] = []byte(photo)
+}
+
+func (f *fakePhotos) fail(userID int64, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errs[userID] = err
+}
+
+func (f *fakePhotos) callCount(userID int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[userID]
+}
+
+func (f *fakePhotos) fetcher() PhotoFetcher {
+	return PhotoFetcherFunc(func(userID int64) ([]byte, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.calls[userID]++
+
+		if err := f.errs[userID]; err != nil {
+			return nil, err
+		}
+
+		photo, ok := f.photos[userID]
+		if !ok {
+			return nil, ErrNoProfilePhoto
+		}
+
+		return photo, nil
+	})
+}
+
+// changeRecorder collects the changes reported by a watcher
+type changeRecorder struct {
+	mu      sync.Mutex
+	changes []PhotoChange
+}
+
+func (r *changeRecorder) record(change PhotoChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, change)
+}
+
+func (r *changeRecorder) get() []PhotoChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]PhotoChange(nil), r.changes...)
+}
+
+func photoHash(photo string) string {
+	sum := sha256.Sum256([]byte(photo))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPhotoWatcherCheck(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	photos := newFakePhotos()
+	var changes changeRecorder
+
+	w := NewPhotoWatcher(logger, photos.fetcher(), PhotoWatcherConfig{OnChange: changes.record})
+
+	photos.set(1, "first")
+	w.Track(1, 2)
+
+	// The first check records the photo without a change
+	w.CheckAll(context.Background())
+	require.Empty(t, changes.get())
+
+	hash, ok := w.Hash(1)
+	require.True(t, ok)
+	require.Equal(t, photoHash("first"), hash)
+
+	// Users without a photo have an empty hash
+	hash, ok = w.Hash(2)
+	require.True(t, ok)
+	require.Empty(t, hash)
+
+	// The same photo is no change
+	changed, err := w.Check(1)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	photos.set(1, "second")
+
+	changed, err = w.Check(1)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	changed, err = w.Check(1)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	require.Len(t, changes.get(), 1)
+	change := changes.get()[0]
+	require.Equal(t, int64(1), change.UserID)
+	require.Equal(t, photoHash("first"), change.OldHash)
+	require.Equal(t, photoHash("second"), change.NewHash)
+	require.Equal(t, []byte("second"), change.Photo)
+	require.False(t, change.At.IsZero())
+
+	// Removing and adding a photo are changes
+	photos.set(1, "")
+	photos.set(2, "new")
+	w.CheckAll(context.Background())
+
+	require.Len(t, changes.get(), 3)
+	for _, change := range changes.get()[1:] {
+		switch change.UserID {
+		case 1:
+			require.Equal(t, photoHash("second"), change.OldHash)
+			require.Empty(t, change.NewHash)
+			require.Empty(t, change.Photo)
+		case 2:
+			require.Empty(t, change.OldHash)
+			require.Equal(t, photoHash("new"), change.NewHash)
+		}
+	}
+
+	// Failed fetches keep the known hash
+	failed := errors.New("fetch failed")
+	photos.fail(2, failed)
+
+	_, err = w.Check(2)
+	require.ErrorIs(t, err, failed)
+
+	hash, _ = w.Hash(2)
+	require.Equal(t, photoHash("new"), hash)
+	require.Len(t, changes.get(), 3)
+}
+
+func TestPhotoWatcherTrackWithHash(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	photos := newFakePhotos()
+	var changes changeRecorder
+
+	w := NewPhotoWatcher(logger, photos.fetcher(), PhotoWatcherConfig{OnChange: changes.record})
+
+	// A change while the watcher wasn't running is reported on the first check
+	photos.set(1, "current")
+	w.TrackWithHash(1, photoHash("stored"))
+
+	changed, err := w.Check(1)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Len(t, changes.get(), 1)
+	require.Equal(t, photoHash("stored"), changes.get()[0].OldHash)
+
+	// Tracking again keeps the known hash
+	w.Track(1)
+
+	changed, err = w.Check(1)
+	require.NoError(t, err)
+	require.False(t, changed)
+}
+
+func TestPhotoWatcherUntrack(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	photos := newFakePhotos()
+	var changes changeRecorder
+
+	w := NewPhotoWatcher(logger, photos.fetcher(), PhotoWatcherConfig{OnChange: changes.record})
+
+	photos.set(1, "first")
+	w.Track(1)
+	w.CheckAll(context.Background())
+
+	w.Untrack(1)
+
+	_, ok := w.Hash(1)
+	require.False(t, ok)
+
+	// Untracked users aren't checked anymore, a check in flight reports nothing
+	photos.set(1, "second")
+	w.CheckAll(context.Background())
+	require.Equal(t, 1, photos.callCount(1))
+
+	changed, err := w.Check(1)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Empty(t, changes.get())
+
+	_, ok = w.Hash(1)
+	require.False(t, ok)
+}
+
+func TestPhotoWatcherRun(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	photos := newFakePhotos()
+	var changes changeRecorder
+
+	w := NewPhotoWatcher(logger, photos.fetcher(), PhotoWatcherConfig{
+		Interval: 5 * time.Millisecond,
+		OnChange: changes.record,
+	})
+
+	photos.set(1, "v0")
+	photos.set(2, "static")
+	w.Track(1, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		w.Run(ctx)
+	}()
+
+	// The first round records the photos
+	require.Eventually(t, func() bool {
+		return photos.callCount(1) >= 1
+	}, time.Second, time.Millisecond)
+
+	// Every change is reported once however many rounds see it
+	for _, photo := range []string{"v1", "v2", "v3"} {
+		calls := photos.callCount(1)
+		photos.set(1, photo)
+
+		require.Eventually(t, func() bool {
+			return photos.callCount(1) >= calls+3
+		}, time.Second, time.Millisecond)
+	}
+
+	// Untracked users stop being checked by the loop
+	w.Untrack(1)
+	photos.set(1, "v4")
+	calls, rounds := photos.callCount(1), photos.callCount(2)
+
+	require.Eventually(t, func() bool {
+		return photos.callCount(2) >= rounds+3
+	}, time.Second, time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the context was canceled")
+	}
+
+	// A check in flight during Untrack may still fetch, but never reports
+	require.LessOrEqual(t, photos.callCount(1), calls+1)
+
+	got := changes.get()
+	require.Len(t, got, 3)
+	for i, change := range got {
+		require.Equal(t, int64(1), change.UserID)
+		require.Equal(t, photoHash([]string{"v1", "v2", "v3"}[i]), change.NewHash)
+	}
+}
@@ -0,0 +1,75 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-telegram/bot"
+)
+
+// PinMessage pins a message, silent pins don't notify the chat members. The
+// bot needs the pin messages right in groups and channels.
+func (s *Service) PinMessage(chatID int64, msgID int, silent bool) error {
+	return s.PinMessageContext(context.Background(), chatID, msgID, silent)
+}
+
+// PinMessageContext is PinMessage with a context
+func (s *Service) PinMessageContext(ctx context.Context, chatID int64, msgID int, silent bool) error {
+	s.ratelimit.take(chatID)
+
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.PinChatMessage(ctx, &bot.PinChatMessageParams{
+		ChatID:              chatID,
+		MessageID:           msgID,
+		DisableNotification: silent,
+	}); err != nil {
+		return fmt.Errorf("pin message: %w", err)
+	}
+
+	return nil
+}
+
+// UnpinMessage unpins a message, a zero msgID unpins the most recent pin
+func (s *Service) UnpinMessage(chatID int64, msgID int) error {
+	return s.UnpinMessageContext(context.Background(), chatID, msgID)
+}
+
+// UnpinMessageContext is UnpinMessage with a context
+func (s *Service) UnpinMessageContext(ctx context.Context, chatID int64, msgID int) error {
+	s.ratelimit.take(chatID)
+
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.UnpinChatMessage(ctx, &bot.UnpinChatMessageParams{
+		ChatID:    chatID,
+		MessageID: msgID,
+	}); err != nil {
+		return fmt.Errorf("unpin message: %w", err)
+	}
+
+	return nil
+}
+
+// UnpinAllMessages unpins all messages of the chat
+func (s *Service) UnpinAllMessages(chatID int64) error {
+	return s.UnpinAllMessagesContext(context.Background(), chatID)
+}
+
+// UnpinAllMessagesContext is UnpinAllMessages with a context
+func (s *Service) UnpinAllMessagesContext(ctx context.Context, chatID int64) error {
+	s.ratelimit.take(chatID)
+
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.UnpinAllChatMessages(ctx, &bot.UnpinAllChatMessagesParams{
+		ChatID: chatID,
+	}); err != nil {
+		return fmt.Errorf("unpin all messages: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package tgbot
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/gammazero/workerpool"
+	"github.com/go-telegram/bot/models"
+)
+
+// sendBatchSize is the number of queued messages a worker sends to a chat
+// before yielding the worker to other chats
+const sendBatchSize = 5
+
+// ErrServiceClosed is returned for messages sent after Close
+var ErrServiceClosed = errors.New("service closed")
+
+// SendResult holds the outcome of an asynchronous send
+type SendResult struct {
+	Message *models.Message
+	Err     error
+}
+
+type sendJob struct {
+	run    func() (*models.Message, error)
+	result chan SendResult
+}
+
+// sendPipeline keeps a queue per chat and drains them over the worker pool.
+// A chat is only ever drained by one worker at a time, so messages to the
+// same chat keep their order while different chats are sent in parallel.
+type sendPipeline struct {
+	pool *workerpool.WorkerPool
+
+	mu     sync.Mutex
+	queues map[int64][]*sendJob
+	closed bool
+}
+
+func newSendPipeline(pool *workerpool.WorkerPool) *sendPipeline {
+	return &sendPipeline{
+		pool:   pool,
+		queues: make(map[int64][]*sendJob),
+	}
+}
+
+// enqueue adds the job to the chat queue and schedules the chat if idle
+func (p *sendPipeline) enqueue(chatID int64, run func() (*models.Message, error)) <-chan SendResult {
+	job := &sendJob{
+		run:    run,
+		result: make(chan SendResult, 1),
+	}
+
+	// The pool is submitted to under the lock, so close can't stop it in
+	// between
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		job.result <- SendResult{Err: ErrServiceClosed}
+		return job.result
+	}
+
+	queue, active := p.queues[chatID]
+	p.queues[chatID] = append(queue, job)
+
+	if !active {
+		p.pool.Submit(func() { p.drain(chatID) })
+	}
+
+	return job.result
+}
+
+func (p *sendPipeline) drain(chatID int64) {
+	for {
+		for i := 0; i < sendBatchSize; i++ {
+			job := p.next(chatID)
+			if job == nil {
+				return
+			}
+
+			msg, err := job.run()
+			job.result <- SendResult{Message: msg, Err: err}
+		}
+
+		// Yield to other chats, the chat stays marked active so no one else
+		// picks it up. Once closed the pool takes no more work and the chat
+		// is drained here.
+		p.mu.Lock()
+		if len(p.queues[chatID]) == 0 {
+			delete(p.queues, chatID)
+			p.mu.Unlock()
+			return
+		}
+
+		if !p.closed {
+			p.pool.Submit(func() { p.drain(chatID) })
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+	}
+}
+
+// close rejects new messages, the queued ones are still sent. It must be
+// called before the pool is stopped.
+func (p *sendPipeline) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+}
+
+// next pops the next job of the chat queue, the chat is marked idle once empty
+func (p *sendPipeline) next(chatID int64) *sendJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queue := p.queues[chatID]
+	if len(queue) == 0 {
+		delete(p.queues, chatID)
+		return nil
+	}
+
+	job := queue[0]
+	queue[0] = nil
+	p.queues[chatID] = queue[1:]
+
+	return job
+}
+
+// depth returns the number of messages waiting to be sent
+func (p *sendPipeline) depth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var n int
+	for _, queue := range p.queues {
+		n += len(queue)
+	}
+
+	return n
+}
@@ -0,0 +1,82 @@
+package tgbot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gammazero/workerpool"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendPipelineOrder(t *testing.T) {
+	pool := workerpool.New(4)
+	defer pool.StopWait()
+
+	p := newSendPipeline(pool)
+
+	var (
+		mu   sync.Mutex
+		sent = map[int64][]int{}
+	)
+
+	var results []<-chan SendResult
+	for i := 0; i < 20; i++ {
+		for _, chatID := range []int64{1, 2, 3} {
+			chatID, i := chatID, i
+			results = append(results, p.enqueue(chatID, func() (*models.Message, error) {
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				sent[chatID] = append(sent[chatID], i)
+				mu.Unlock()
+
+				return &models.Message{ID: i}, nil
+			}))
+		}
+	}
+
+	for _, res := range results {
+		r := <-res
+		require.NoError(t, r.Err)
+	}
+
+	for chatID, ids := range sent {
+		require.Len(t, ids, 20, "chat %d", chatID)
+		for i, id := range ids {
+			require.Equal(t, i, id, "chat %d out of order", chatID)
+		}
+	}
+
+	require.Zero(t, p.depth())
+}
+
+func TestSendPipelineClose(t *testing.T) {
+	pool := workerpool.New(1)
+	p := newSendPipeline(pool)
+
+	release := make(chan struct{})
+
+	var results []<-chan SendResult
+	for i := 0; i < sendBatchSize*2; i++ {
+		results = append(results, p.enqueue(1, func() (*models.Message, error) {
+			<-release
+			return &models.Message{ID: 1}, nil
+		}))
+	}
+
+	// Messages queued before closing are still sent, the chat is drained
+	// past its batch without submitting to the stopped pool
+	p.close()
+	close(release)
+	pool.StopWait()
+
+	for _, res := range results {
+		require.NoError(t, (<-res).Err)
+	}
+
+	require.ErrorIs(t, (<-p.enqueue(1, func() (*models.Message, error) {
+		return nil, nil
+	})).Err, ErrServiceClosed)
+}
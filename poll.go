@@ -0,0 +1,242 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	minPollOptions = 2
+	maxPollOptions = 10
+)
+
+var ErrPollOptions = fmt.Errorf("poll must have %d to %d options", minPollOptions, maxPollOptions)
+
+// PollConfig describes a regular or quiz poll
+type PollConfig struct {
+	Question string
+	Options  []string
+	// Public shows who voted, answers are only delivered for public polls
+	Public          bool
+	MultipleAnswers bool
+	// Quiz makes the poll a quiz with CorrectOption as the right answer
+	Quiz          bool
+	CorrectOption int
+	// Explanation is shown when a quiz answer is wrong
+	Explanation string
+	// OpenPeriod closes the poll after the duration, 5 to 600 seconds
+	OpenPeriod time.Duration
+	// CloseAt closes the poll at the given time, ignored with OpenPeriod
+	CloseAt time.Time
+	ReplyTo int
+	// ThreadID sends the poll to a forum topic
+	ThreadID int
+
+	BusinessConnectionID string
+}
+
+// PollHandler receives poll state updates, sent when the vote counts change
+// or the poll is closed
+type PollHandler func(ctx context.Context, poll *models.Poll)
+
+// PollAnswerHandler receives the votes of users in public polls
+type PollAnswerHandler func(ctx context.Context, answer *models.PollAnswer)
+
+// SendPoll sends a poll through the send pipeline
+func (s *Service) SendPoll(chatID int64, cfg PollConfig) (*models.Message, error) {
+	params, err := cfg.params(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := <-s.pipeline.enqueue(chatID, func() (*models.Message, error) {
+		s.ratelimit.take(chatID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg, err := s.bot.SendPoll(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("send poll: %w", err)
+		}
+
+		if len(cfg.BusinessConnectionID) == 0 {
+			s.tracker.track(chatID, msg)
+		}
+
+		return msg, nil
+	})
+
+	return res.Message, res.Err
+}
+
+// StopPoll closes a poll and returns its final state
+func (s *Service) StopPoll(chatID int64, msgID int) (*models.Poll, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	poll, err := s.bot.StopPoll(ctx, &bot.StopPollParams{
+		ChatID:    chatID,
+		MessageID: msgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("stop poll: %w", err)
+	}
+
+	return poll, nil
+}
+
+// OnPoll registers a handler for poll updates and returns a function that
+// removes it
+func (s *Service) OnPoll(handler PollHandler) func() {
+	return s.polls.add(handler, nil)
+}
+
+// OnPollAnswer registers a handler for poll answers and returns a function
+// that removes it
+func (s *Service) OnPollAnswer(handler PollAnswerHandler) func() {
+	return s.polls.add(nil, handler)
+}
+
+// pollMiddleware hands poll updates to the registered handlers, the update is
+// still passed on to the bot
+func (s *Service) pollMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if update.Poll != nil {
+				for _, handler := range s.polls.pollHandlers() {
+					handler(ctx, update.Poll)
+				}
+			}
+
+			if update.PollAnswer != nil {
+				for _, handler := range s.polls.answerHandlers() {
+					handler(ctx, update.PollAnswer)
+				}
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func (c PollConfig) params(chatID int64) (*bot.SendPollParams, error) {
+	if c.Question == "" {
+		return nil, errors.New("poll question is empty")
+	}
+
+	if len(c.Options) < minPollOptions || len(c.Options) > maxPollOptions {
+		return nil, ErrPollOptions
+	}
+
+	if c.Quiz && (c.CorrectOption < 0 || c.CorrectOption >= len(c.Options)) {
+		return nil, fmt.Errorf("correct option %d out of range", c.CorrectOption)
+	}
+
+	options := make([]models.InputPollOption, 0, len(c.Options))
+	for _, option := range c.Options {
+		options = append(options, models.InputPollOption{Text: option})
+	}
+
+	anonymous := !c.Public
+
+	params := &bot.SendPollParams{
+		ChatID:               chatID,
+		BusinessConnectionID: c.BusinessConnectionID,
+		MessageThreadID:      c.ThreadID,
+		Question:             c.Question,
+		Options:              options,
+		IsAnonymous:          &anonymous,
+		Type:                 "regular",
+		// Quizzes only allow a single answer
+		AllowsMultipleAnswers: c.MultipleAnswers && !c.Quiz,
+	}
+
+	if c.Quiz {
+		params.Type = "quiz"
+		params.CorrectOptionID = c.CorrectOption
+		params.Explanation = c.Explanation
+	}
+
+	switch {
+	case c.OpenPeriod > 0:
+		params.OpenPeriod = int(c.OpenPeriod.Seconds())
+	case !c.CloseAt.IsZero():
+		params.CloseDate = int(c.CloseAt.Unix())
+	}
+
+	if c.ReplyTo > 0 {
+		params.ReplyParameters = &models.ReplyParameters{
+			ChatID:                   chatID,
+			MessageID:                c.ReplyTo,
+			AllowSendingWithoutReply: true,
+		}
+	}
+
+	return params, nil
+}
+
+type pollSubscriptions struct {
+	mu      sync.RWMutex
+	nextID  int
+	polls   map[int]PollHandler
+	answers map[int]PollAnswerHandler
+}
+
+func (p *pollSubscriptions) add(poll PollHandler, answer PollAnswerHandler) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.polls == nil {
+		p.polls = make(map[int]PollHandler)
+		p.answers = make(map[int]PollAnswerHandler)
+	}
+
+	id := p.nextID
+	p.nextID++
+
+	if poll != nil {
+		p.polls[id] = poll
+	}
+	if answer != nil {
+		p.answers[id] = answer
+	}
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		delete(p.polls, id)
+		delete(p.answers, id)
+	}
+}
+
+func (p *pollSubscriptions) pollHandlers() []PollHandler {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	handlers := make([]PollHandler, 0, len(p.polls))
+	for _, handler := range p.polls {
+		handlers = append(handlers, handler)
+	}
+
+	return handlers
+}
+
+func (p *pollSubscriptions) answerHandlers() []PollAnswerHandler {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	handlers := make([]PollAnswerHandler, 0, len(p.answers))
+	for _, handler := range p.answers {
+		handlers = append(handlers, handler)
+	}
+
+	return handlers
+}
@@ -0,0 +1,61 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollConfigParams(t *testing.T) {
+	_, err := PollConfig{Question: "?", Options: []string{"a"}}.params(1)
+	require.ErrorIs(t, err, ErrPollOptions)
+
+	_, err = PollConfig{Question: "?", Options: []string{"a", "b"}, Quiz: true, CorrectOption: 2}.params(1)
+	require.Error(t, err)
+
+	params, err := PollConfig{
+		Question:        "?",
+		Options:         []string{"a", "b", "c"},
+		Quiz:            true,
+		CorrectOption:   1,
+		MultipleAnswers: true,
+		OpenPeriod:      time.Minute,
+	}.params(1)
+	require.NoError(t, err)
+	require.Equal(t, "quiz", params.Type)
+	require.Equal(t, 1, params.CorrectOptionID)
+	require.False(t, params.AllowsMultipleAnswers)
+	require.True(t, *params.IsAnonymous)
+	require.Equal(t, 60, params.OpenPeriod)
+	require.Len(t, params.Options, 3)
+}
+
+func TestPollMiddleware(t *testing.T) {
+	s := &Service{}
+
+	var answers, polls, passed int
+	unsubscribe := s.OnPollAnswer(func(ctx context.Context, answer *models.PollAnswer) {
+		answers++
+	})
+	s.OnPoll(func(ctx context.Context, poll *models.Poll) {
+		polls++
+	})
+
+	handler := s.pollMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		passed++
+	})
+
+	handler(context.Background(), nil, &models.Update{PollAnswer: &models.PollAnswer{PollID: "1"}})
+	handler(context.Background(), nil, &models.Update{Poll: &models.Poll{ID: "1"}})
+
+	unsubscribe()
+	handler(context.Background(), nil, &models.Update{PollAnswer: &models.PollAnswer{PollID: "1"}})
+
+	require.Equal(t, 1, answers)
+	require.Equal(t, 1, polls)
+	require.Equal(t, 3, passed)
+}
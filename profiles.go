@@ -0,0 +1,211 @@
+package tgbot
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+// UserProfile is a user the bot has seen
+type UserProfile struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username,omitempty"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	LanguageCode string    `json:"language_code,omitempty"`
+	IsPremium    bool      `json:"is_premium,omitempty"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	// Blocked is set when the user blocked the bot
+	Blocked bool `json:"blocked"`
+}
+
+// ChatProfile is a chat the bot has seen
+type ChatProfile struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Left is set when the bot left or was removed from the chat, or blocked
+	// in a private chat
+	Left bool `json:"left"`
+}
+
+// ProfileStore persists the users and chats the bot has seen
+type ProfileStore interface {
+	GetUser(userID int64) (UserProfile, bool, error)
+	SaveUser(user UserProfile) error
+	GetChat(chatID int64) (ChatProfile, bool, error)
+	SaveChat(chat ChatProfile) error
+	// Users and Chats return all profiles ordered by ID
+	Users() ([]UserProfile, error)
+	Chats() ([]ChatProfile, error)
+}
+
+// MemoryProfileStore is an in-memory ProfileStore
+type MemoryProfileStore struct {
+	mu    sync.RWMutex
+	users map[int64]UserProfile
+	chats map[int64]ChatProfile
+}
+
+var _ ProfileStore = (*MemoryProfileStore)(nil)
+
+// NewMemoryProfileStore creates a new in-memory profile store
+func NewMemoryProfileStore() *MemoryProfileStore {
+	return &MemoryProfileStore{
+		users: make(map[int64]UserProfile),
+		chats: make(map[int64]ChatProfile),
+	}
+}
+
+func (m *MemoryProfileStore) GetUser(userID int64) (UserProfile, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[userID]
+	return user, ok, nil
+}
+
+func (m *MemoryProfileStore) SaveUser(user UserProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *MemoryProfileStore) GetChat(chatID int64) (ChatProfile, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chat, ok := m.chats[chatID]
+	return chat, ok, nil
+}
+
+func (m *MemoryProfileStore) SaveChat(chat ChatProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.chats[chat.ID] = chat
+	return nil
+}
+
+func (m *MemoryProfileStore) Users() ([]UserProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]UserProfile, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return users, nil
+}
+
+func (m *MemoryProfileStore) Chats() ([]ChatProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chats := make([]ChatProfile, 0, len(m.chats))
+	for _, chat := range m.chats {
+		chats = append(chats, chat)
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].ID < chats[j].ID })
+
+	return chats, nil
+}
+
+// profileMiddleware records the users and chats of updates, and whether the
+// bot was blocked or removed, when Config.Profiles is set
+func (s *Service) profileMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if s.cfg.Profiles != nil {
+				s.recordProfiles(update, time.Now())
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func (s *Service) recordProfiles(update *models.Update, now time.Time) {
+	if user := UpdateUser(update); user != nil && !user.IsBot {
+		s.recordUser(user, now, nil)
+	}
+
+	if msg := UpdateMessage(update); msg != nil {
+		s.recordChat(&msg.Chat, now, nil)
+	}
+
+	if member := update.MyChatMember; member != nil {
+		status := member.NewChatMember.Type
+		gone := status == models.ChatMemberTypeLeft || status == models.ChatMemberTypeBanned
+
+		s.recordChat(&member.Chat, now, &gone)
+		if member.Chat.Type == ChatTypePrivate {
+			s.recordUser(&member.From, now, &gone)
+		}
+	}
+}
+
+func (s *Service) recordUser(user *models.User, now time.Time, blocked *bool) {
+	profile, ok, err := s.cfg.Profiles.GetUser(user.ID)
+	if err != nil {
+		s.logger.Error("failed to get user profile", slog.String("err", err.Error()), slog.Int64("user", user.ID))
+		return
+	}
+
+	if !ok {
+		profile = UserProfile{ID: user.ID, FirstSeen: now}
+	}
+
+	profile.Username = user.Username
+	profile.FirstName = user.FirstName
+	profile.LastName = user.LastName
+	profile.IsPremium = user.IsPremium
+	profile.LastSeen = now
+	if user.LanguageCode != "" {
+		profile.LanguageCode = user.LanguageCode
+	}
+
+	// Users that send updates have not blocked the bot
+	profile.Blocked = blocked != nil && *blocked
+
+	if err := s.cfg.Profiles.SaveUser(profile); err != nil {
+		s.logger.Error("failed to save user profile", slog.String("err", err.Error()), slog.Int64("user", user.ID))
+	}
+}
+
+func (s *Service) recordChat(chat *models.Chat, now time.Time, left *bool) {
+	profile, ok, err := s.cfg.Profiles.GetChat(chat.ID)
+	if err != nil {
+		s.logger.Error("failed to get chat profile", slog.String("err", err.Error()), slog.Int64("chat", chat.ID))
+		return
+	}
+
+	if !ok {
+		profile = ChatProfile{ID: chat.ID, FirstSeen: now}
+	}
+
+	profile.Type = string(chat.Type)
+	profile.Title = chat.Title
+	profile.Username = chat.Username
+	profile.LastSeen = now
+	if left != nil {
+		profile.Left = *left
+	}
+
+	if err := s.cfg.Profiles.SaveChat(profile); err != nil {
+		s.logger.Error("failed to save chat profile", slog.String("err", err.Error()), slog.Int64("chat", chat.ID))
+	}
+}
@@ -0,0 +1,181 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// deleteWindow is how long Telegram allows a message to be deleted
+	deleteWindow = 48 * time.Hour
+	// deleteBatchSize is the maximum number of messages per deleteMessages call
+	deleteBatchSize = 100
+	// maxTrackedPerChat caps the number of tracked messages per chat
+	maxTrackedPerChat = 1000
+	// trackerSweepInterval is how often the chats that are no longer sent to
+	// are checked for expired messages
+	trackerSweepInterval = time.Hour
+)
+
+type trackedMessage struct {
+	id   int
+	sent time.Time
+}
+
+// messageTracker keeps the messages the bot sent, so they can be purged
+// later. Messages are forgotten once they are past the delete window.
+type messageTracker struct {
+	mu        sync.Mutex
+	chats     map[int64][]trackedMessage
+	lastSweep time.Time
+}
+
+func (t *messageTracker) track(chatID int64, msg *models.Message) {
+	if msg == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.chats == nil {
+		t.chats = make(map[int64][]trackedMessage)
+	}
+
+	now := time.Now()
+	expired := now.Add(-deleteWindow)
+
+	if now.Sub(t.lastSweep) >= trackerSweepInterval {
+		t.lastSweep = now
+
+		for id, msgs := range t.chats {
+			if msgs = dropExpired(msgs, expired); len(msgs) == 0 {
+				delete(t.chats, id)
+			} else {
+				t.chats[id] = msgs
+			}
+		}
+	}
+
+	msgs := append(dropExpired(t.chats[chatID], expired), trackedMessage{id: msg.ID, sent: time.Unix(int64(msg.Date), 0)})
+	if len(msgs) > maxTrackedPerChat {
+		msgs = msgs[len(msgs)-maxTrackedPerChat:]
+	}
+
+	t.chats[chatID] = msgs
+}
+
+// dropExpired removes the messages sent before expired, they are kept in the
+// order they were sent
+func dropExpired(msgs []trackedMessage, expired time.Time) []trackedMessage {
+	i := 0
+	for i < len(msgs) && msgs[i].sent.Before(expired) {
+		i++
+	}
+
+	return msgs[i:]
+}
+
+// take removes and returns the tracked messages older than the cutoff that
+// can still be deleted, expired messages are forgotten.
+func (t *messageTracker) take(chatID int64, cutoff time.Time) []trackedMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	expired := time.Now().Add(-deleteWindow)
+
+	var taken []trackedMessage
+	keep := t.chats[chatID][:0]
+
+	for _, msg := range t.chats[chatID] {
+		switch {
+		case msg.sent.Before(expired):
+		case msg.sent.Before(cutoff):
+			taken = append(taken, msg)
+		default:
+			keep = append(keep, msg)
+		}
+	}
+
+	if len(keep) == 0 {
+		delete(t.chats, chatID)
+	} else {
+		t.chats[chatID] = keep
+	}
+
+	return taken
+}
+
+// restore puts back messages taken but not deleted, ahead of the messages
+// tracked since as they were sent before them
+func (t *messageTracker) restore(chatID int64, msgs []trackedMessage) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.chats == nil {
+		t.chats = make(map[int64][]trackedMessage)
+	}
+
+	restored := append(slices.Clone(msgs), t.chats[chatID]...)
+	if len(restored) > maxTrackedPerChat {
+		restored = restored[len(restored)-maxTrackedPerChat:]
+	}
+
+	t.chats[chatID] = restored
+}
+
+// PurgeMessages deletes the messages the bot sent to a chat that are older
+// than the given duration. Only messages sent through this service in the
+// last 48 hours can be purged. It returns the number of deleted messages.
+func (s *Service) PurgeMessages(chatID int64, olderThan time.Duration) (int, error) {
+	msgs := s.tracker.take(chatID, time.Now().Add(-olderThan))
+
+	var deleted int
+
+	for start := 0; start < len(msgs); start += deleteBatchSize {
+		end := min(start+deleteBatchSize, len(msgs))
+
+		ids := make([]int, 0, end-start)
+		for _, msg := range msgs[start:end] {
+			ids = append(ids, msg.id)
+		}
+
+		s.ratelimit.take(chatID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err := s.bot.DeleteMessages(ctx, &bot.DeleteMessagesParams{
+			ChatID:     chatID,
+			MessageIDs: ids,
+		})
+		cancel()
+
+		if err != nil {
+			// The messages not deleted can be purged again later
+			s.tracker.restore(chatID, msgs[start:])
+
+			return deleted, fmt.Errorf("delete messages: %w", err)
+		}
+
+		deleted += end - start
+	}
+
+	if deleted > 0 {
+		s.logger.Debug("purged messages",
+			slog.Int64("chat", chatID),
+			slog.Int("count", deleted),
+		)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,58 @@
+package tgbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageTracker(t *testing.T) {
+	var tracker messageTracker
+
+	now := time.Now()
+	tracker.track(1, &models.Message{ID: 1, Date: int(now.Add(-72 * time.Hour).Unix())})
+	tracker.track(1, &models.Message{ID: 2, Date: int(now.Add(-2 * time.Hour).Unix())})
+	tracker.track(1, &models.Message{ID: 3, Date: int(now.Unix())})
+	tracker.track(2, &models.Message{ID: 4, Date: int(now.Add(-2 * time.Hour).Unix())})
+
+	require.Equal(t, []int{2}, trackedIDs(tracker.take(1, now.Add(-time.Hour))))
+	require.Empty(t, tracker.take(1, now.Add(-time.Hour)))
+	require.Equal(t, []int{3}, trackedIDs(tracker.take(1, now.Add(time.Second))))
+	require.NotContains(t, tracker.chats, int64(1))
+	require.Len(t, tracker.chats[2], 1)
+}
+
+func TestMessageTrackerExpiry(t *testing.T) {
+	var tracker messageTracker
+
+	now := time.Now()
+	tracker.track(1, &models.Message{ID: 1, Date: int(now.Add(-72 * time.Hour).Unix())})
+	tracker.track(2, &models.Message{ID: 2, Date: int(now.Add(-50 * time.Hour).Unix())})
+
+	// Expired messages are dropped when the chat is sent to again, other
+	// chats by the periodic sweep
+	tracker.track(1, &models.Message{ID: 3, Date: int(now.Unix())})
+	require.Equal(t, []int{3}, trackedIDs(tracker.chats[1]))
+	require.Contains(t, tracker.chats, int64(2))
+
+	tracker.lastSweep = time.Time{}
+	tracker.track(1, &models.Message{ID: 4, Date: int(now.Unix())})
+	require.NotContains(t, tracker.chats, int64(2))
+
+	// Messages that failed to delete are put back in order
+	taken := tracker.take(1, now.Add(time.Second))
+	tracker.track(1, &models.Message{ID: 5, Date: int(now.Add(time.Minute).Unix())})
+	tracker.restore(1, taken)
+	require.Equal(t, []int{3, 4, 5}, trackedIDs(tracker.chats[1]))
+}
+
+func trackedIDs(msgs []trackedMessage) []int {
+	var ids []int
+	for _, msg := range msgs {
+		ids = append(ids, msg.id)
+	}
+
+	return ids
+}
@@ -0,0 +1,253 @@
+package tgbot
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/ratelimit"
+)
+
+const (
+	defaultRate = 30
+
+	// Telegram allows about one message per second to a chat and 20 messages
+	// per minute to a group
+	telegramChatRate  = 1
+	telegramGroupRate = 20
+
+	// chatBucketIdle is how long a chat bucket is kept after its last request
+	chatBucketIdle = 5 * time.Minute
+)
+
+// RateLimitConfig configures the limiter applied to outgoing API calls
+type RateLimitConfig struct {
+	// Rate is the global number of requests per second, defaults to 30
+	Rate int
+	// Burst is the number of requests that may be sent back to back
+	// after the limiter has been idle
+	Burst int
+	// ChatRate is the number of requests per second to a single chat,
+	// zero disables the per-chat limit
+	ChatRate int
+	// GroupRate is the number of requests per minute to a single group,
+	// supergroup or channel. Zero applies ChatRate to groups as well.
+	GroupRate int
+	// ChatBurst is the number of requests that may be sent back to back to
+	// a chat after it has been idle, defaults to one
+	ChatBurst int
+	// TelegramChatLimits applies the per-chat limits of Telegram, one
+	// request per second to a chat and 20 per minute to a group, where
+	// ChatRate and GroupRate are not set
+	TelegramChatLimits bool
+	// OnWait is called every time a request had to wait for the limiter
+	OnWait func(chatID int64, wait time.Duration)
+}
+
+func (cfg RateLimitConfig) problems() []error {
+	if cfg.Rate < 0 || cfg.Burst < 0 || cfg.ChatRate < 0 || cfg.GroupRate < 0 || cfg.ChatBurst < 0 {
+		return []error{errors.New("RateLimit must not have negative values, use zero for the defaults")}
+	}
+
+	return nil
+}
+
+// RateLimitStats holds the wait time metrics of the rate limiter
+type RateLimitStats struct {
+	Requests  uint64
+	Waited    uint64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// AvgWait returns the average wait time of the requests that had to wait
+func (s RateLimitStats) AvgWait() time.Duration {
+	if s.Waited == 0 {
+		return 0
+	}
+
+	return s.TotalWait / time.Duration(s.Waited)
+}
+
+type rateLimiter struct {
+	cfg    RateLimitConfig
+	global ratelimit.Limiter
+
+	mu        sync.Mutex
+	chats     map[int64]*tokenBucket
+	lastSweep time.Time
+
+	requests  atomic.Uint64
+	waited    atomic.Uint64
+	totalWait atomic.Int64
+	maxWait   atomic.Int64
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	if cfg.Rate <= 0 {
+		cfg.Rate = defaultRate
+	}
+	if cfg.TelegramChatLimits && cfg.ChatRate <= 0 {
+		cfg.ChatRate = telegramChatRate
+	}
+	if cfg.TelegramChatLimits && cfg.GroupRate <= 0 {
+		cfg.GroupRate = telegramGroupRate
+	}
+	if cfg.ChatBurst <= 0 {
+		cfg.ChatBurst = 1
+	}
+
+	return &rateLimiter{
+		cfg:       cfg,
+		global:    newLimiter(cfg.Rate, cfg.Burst),
+		chats:     make(map[int64]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func newLimiter(rate, burst int) ratelimit.Limiter {
+	if burst > 0 {
+		return ratelimit.New(rate, ratelimit.WithSlack(burst))
+	}
+
+	return ratelimit.New(rate, ratelimit.WithoutSlack)
+}
+
+// take blocks until a request to the given chat is allowed
+func (r *rateLimiter) take(chatID int64) {
+	start := time.Now()
+
+	if bucket := r.chatBucket(chatID); bucket != nil {
+		time.Sleep(bucket.reserve(start))
+	}
+	r.global.Take()
+
+	r.record(chatID, time.Since(start))
+}
+
+// chatBucket returns the bucket of the chat, nil if the chat has no limit.
+// Chat IDs of groups, supergroups and channels are negative.
+func (r *rateLimiter) chatBucket(chatID int64) *tokenBucket {
+	if chatID == 0 {
+		return nil
+	}
+
+	perSecond := float64(r.cfg.ChatRate)
+	if chatID < 0 && r.cfg.GroupRate > 0 {
+		perSecond = float64(r.cfg.GroupRate) / 60
+	}
+
+	if perSecond <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) > chatBucketIdle {
+		r.sweep(now)
+	}
+
+	bucket, ok := r.chats[chatID]
+	if !ok {
+		bucket = newTokenBucket(perSecond, r.cfg.ChatBurst, now)
+		r.chats[chatID] = bucket
+	}
+
+	return bucket
+}
+
+// sweep removes the buckets of chats that have been idle long enough to be
+// full again, so the map doesn't grow with every chat the bot ever wrote to
+func (r *rateLimiter) sweep(now time.Time) {
+	r.lastSweep = now
+
+	for chatID, bucket := range r.chats {
+		if bucket.idle(now) {
+			delete(r.chats, chatID)
+		}
+	}
+}
+
+func (r *rateLimiter) record(chatID int64, wait time.Duration) {
+	r.requests.Add(1)
+
+	// The limiter itself takes a few microseconds, only count actual waits
+	if wait < time.Millisecond {
+		return
+	}
+
+	r.waited.Add(1)
+	r.totalWait.Add(int64(wait))
+
+	for {
+		current := r.maxWait.Load()
+		if int64(wait) <= current || r.maxWait.CompareAndSwap(current, int64(wait)) {
+			break
+		}
+	}
+
+	if r.cfg.OnWait != nil {
+		r.cfg.OnWait(chatID, wait)
+	}
+}
+
+func (r *rateLimiter) stats() RateLimitStats {
+	return RateLimitStats{
+		Requests:  r.requests.Load(),
+		Waited:    r.waited.Load(),
+		TotalWait: time.Duration(r.totalWait.Load()),
+		MaxWait:   time.Duration(r.maxWait.Load()),
+	}
+}
+
+// tokenBucket holds up to burst tokens that refill at rate per second. Unlike
+// the leaky bucket of the global limiter it lets an idle chat send a few
+// requests back to back.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// reserve takes a token and returns how long to wait before using it, the
+// token count goes negative for requests that have to wait so concurrent
+// requests queue up behind each other
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// idle reports whether the bucket has refilled and has no waiting requests
+func (b *tokenBucket) idle(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return now.Sub(b.last) > chatBucketIdle && b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// RateLimitStats returns the wait time metrics of the outgoing rate limiter
+func (s *Service) RateLimitStats() RateLimitStats {
+	return s.ratelimit.stats()
+}
@@ -0,0 +1,80 @@
+package tgbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(1, 2, now)
+
+	// A full bucket lets the burst through, then queues requests
+	require.Zero(t, bucket.reserve(now))
+	require.Zero(t, bucket.reserve(now))
+	require.Equal(t, time.Second, bucket.reserve(now))
+	require.Equal(t, 2*time.Second, bucket.reserve(now))
+
+	// Tokens refill with time, up to the burst
+	later := now.Add(10 * time.Second)
+	require.Zero(t, bucket.reserve(later))
+	require.Zero(t, bucket.reserve(later))
+	require.Equal(t, time.Second, bucket.reserve(later))
+
+	require.False(t, bucket.idle(later.Add(time.Minute)))
+	require.True(t, bucket.idle(later.Add(chatBucketIdle+time.Minute)))
+}
+
+func TestChatBucket(t *testing.T) {
+	r := newRateLimiter(RateLimitConfig{TelegramChatLimits: true})
+
+	private := r.chatBucket(42)
+	require.NotNil(t, private)
+	require.Equal(t, float64(telegramChatRate), private.rate)
+
+	// Groups and channels have negative chat IDs
+	group := r.chatBucket(-100123)
+	require.NotNil(t, group)
+	require.InDelta(t, float64(telegramGroupRate)/60, group.rate, 1e-9)
+
+	require.Same(t, private, r.chatBucket(42))
+	require.Nil(t, r.chatBucket(0))
+
+	// Without per-chat rates only the global limit applies
+	require.Nil(t, newRateLimiter(RateLimitConfig{}).chatBucket(42))
+
+	// ChatRate applies to groups unless GroupRate is set
+	r = newRateLimiter(RateLimitConfig{ChatRate: 2})
+	require.Equal(t, float64(2), r.chatBucket(-1).rate)
+
+	// Idle buckets are removed
+	r.sweep(time.Now().Add(2 * chatBucketIdle))
+	require.Empty(t, r.chats)
+}
+
+func TestRateLimitProblems(t *testing.T) {
+	require.Empty(t, RateLimitConfig{TelegramChatLimits: true}.problems())
+	require.Len(t, RateLimitConfig{GroupRate: -1}.problems(), 1)
+}
+
+func TestChatBucketSweep(t *testing.T) {
+	r := newRateLimiter(RateLimitConfig{ChatRate: 1})
+
+	now := time.Now()
+	idle := r.chatBucket(1)
+	active := r.chatBucket(2)
+
+	// A bucket used since keeps its place, the sweep runs from the lookups
+	// once chatBucketIdle has passed
+	idle.reserve(now)
+	active.reserve(now.Add(chatBucketIdle))
+	r.lastSweep = now.Add(-2 * chatBucketIdle)
+	idle.last = now.Add(-2 * chatBucketIdle)
+
+	r.chatBucket(3)
+	require.NotContains(t, r.chats, int64(1))
+	require.Contains(t, r.chats, int64(2))
+	require.Contains(t, r.chats, int64(3))
+}
@@ -0,0 +1,245 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	// customReactionPrefix marks custom emoji reactions, followed by the ID
+	customReactionPrefix = "custom:"
+	// paidReaction is the star reaction of channels
+	paidReaction = "paid"
+)
+
+// ReactionEvent is a change of the reactions of a user on a message. Reactions
+// are the emoji, "custom:<id>" for custom emoji or "paid" for star reactions.
+type ReactionEvent struct {
+	ChatID    int64
+	MessageID int
+	// User is nil for anonymous reactions, ActorChat is set instead
+	User      *models.User
+	ActorChat *models.Chat
+	Old       []string
+	New       []string
+	Added     []string
+	Removed   []string
+	Time      time.Time
+}
+
+// ReactionCountEvent holds the total reaction counts of a message in a chat
+// where reactions are anonymous, like channels
+type ReactionCountEvent struct {
+	ChatID    int64
+	MessageID int
+	Counts    map[string]int
+	Time      time.Time
+}
+
+// ReactionHandler receives reaction changes, the bot must be an admin of the chat
+type ReactionHandler func(ctx context.Context, event *ReactionEvent)
+
+// ReactionCountHandler receives anonymous reaction counts
+type ReactionCountHandler func(ctx context.Context, event *ReactionCountEvent)
+
+// SetReaction sets the reactions of the bot on a message, no emoji removes
+// them. Bots can only use the emoji Telegram allows as reactions.
+func (s *Service) SetReaction(chatID int64, msgID int, emoji ...string) error {
+	reactions := make([]models.ReactionType, 0, len(emoji))
+	for _, e := range emoji {
+		reactions = append(reactions, models.ReactionType{
+			Type: models.ReactionTypeTypeEmoji,
+			ReactionTypeEmoji: &models.ReactionTypeEmoji{
+				Type:  models.ReactionTypeTypeEmoji,
+				Emoji: e,
+			},
+		})
+	}
+
+	s.ratelimit.take(chatID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
+		ChatID:    chatID,
+		MessageID: msgID,
+		Reaction:  reactions,
+	}); err != nil {
+		return fmt.Errorf("set message reaction: %w", err)
+	}
+
+	return nil
+}
+
+// OnReaction registers a handler for reaction changes and returns a function
+// that removes it
+func (s *Service) OnReaction(handler ReactionHandler) func() {
+	return s.reactions.add(handler, nil)
+}
+
+// OnReactionCount registers a handler for anonymous reaction counts and
+// returns a function that removes it
+func (s *Service) OnReactionCount(handler ReactionCountHandler) func() {
+	return s.reactions.add(nil, handler)
+}
+
+// reactionMiddleware hands reaction updates to the registered handlers, the
+// update is still passed on to the bot
+func (s *Service) reactionMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if update.MessageReaction != nil {
+				event := newReactionEvent(update.MessageReaction)
+				for _, handler := range s.reactions.reactionHandlers() {
+					handler(ctx, event)
+				}
+			}
+
+			if update.MessageReactionCount != nil {
+				event := newReactionCountEvent(update.MessageReactionCount)
+				for _, handler := range s.reactions.countHandlers() {
+					handler(ctx, event)
+				}
+			}
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+func newReactionEvent(r *models.MessageReactionUpdated) *ReactionEvent {
+	event := &ReactionEvent{
+		ChatID:    r.Chat.ID,
+		MessageID: r.MessageID,
+		User:      r.User,
+		ActorChat: r.ActorChat,
+		Old:       reactionKeys(r.OldReaction),
+		New:       reactionKeys(r.NewReaction),
+		Time:      time.Unix(int64(r.Date), 0),
+	}
+
+	event.Added = reactionDiff(event.New, event.Old)
+	event.Removed = reactionDiff(event.Old, event.New)
+
+	return event
+}
+
+func newReactionCountEvent(r *models.MessageReactionCountUpdated) *ReactionCountEvent {
+	event := &ReactionCountEvent{
+		ChatID:    r.Chat.ID,
+		MessageID: r.MessageID,
+		Counts:    make(map[string]int, len(r.Reactions)),
+		Time:      time.Unix(int64(r.Date), 0),
+	}
+
+	for _, count := range r.Reactions {
+		event.Counts[reactionKey(count.Type)] = count.TotalCount
+	}
+
+	return event
+}
+
+func reactionKey(r models.ReactionType) string {
+	switch {
+	case r.ReactionTypeEmoji != nil:
+		return r.ReactionTypeEmoji.Emoji
+	case r.ReactionTypeCustomEmoji != nil:
+		return customReactionPrefix + r.ReactionTypeCustomEmoji.CustomEmojiID
+	case r.Type == models.ReactionTypeTypePaid:
+		return paidReaction
+	}
+
+	return string(r.Type)
+}
+
+func reactionKeys(reactions []models.ReactionType) []string {
+	keys := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		keys = append(keys, reactionKey(r))
+	}
+
+	return keys
+}
+
+// reactionDiff returns the reactions in a that are not in b
+func reactionDiff(a, b []string) []string {
+	var diff []string
+
+outer:
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				continue outer
+			}
+		}
+
+		diff = append(diff, x)
+	}
+
+	return diff
+}
+
+type reactionSubscriptions struct {
+	mu        sync.RWMutex
+	nextID    int
+	reactions map[int]ReactionHandler
+	counts    map[int]ReactionCountHandler
+}
+
+func (r *reactionSubscriptions) add(reaction ReactionHandler, count ReactionCountHandler) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reactions == nil {
+		r.reactions = make(map[int]ReactionHandler)
+		r.counts = make(map[int]ReactionCountHandler)
+	}
+
+	id := r.nextID
+	r.nextID++
+
+	if reaction != nil {
+		r.reactions[id] = reaction
+	}
+	if count != nil {
+		r.counts[id] = count
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		delete(r.reactions, id)
+		delete(r.counts, id)
+	}
+}
+
+func (r *reactionSubscriptions) reactionHandlers() []ReactionHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handlers := make([]ReactionHandler, 0, len(r.reactions))
+	for _, handler := range r.reactions {
+		handlers = append(handlers, handler)
+	}
+
+	return handlers
+}
+
+func (r *reactionSubscriptions) countHandlers() []ReactionCountHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handlers := make([]ReactionCountHandler, 0, len(r.counts))
+	for _, handler := range r.counts {
+		handlers = append(handlers, handler)
+	}
+
+	return handlers
+}
@@ -0,0 +1,65 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func emojiReaction(emoji string) models.ReactionType {
+	return models.ReactionType{
+		Type:              models.ReactionTypeTypeEmoji,
+		ReactionTypeEmoji: &models.ReactionTypeEmoji{Type: models.ReactionTypeTypeEmoji, Emoji: emoji},
+	}
+}
+
+func TestReactionMiddleware(t *testing.T) {
+	s := &Service{}
+
+	var events []*ReactionEvent
+	var counts []*ReactionCountEvent
+	var passed int
+
+	unsubscribe := s.OnReaction(func(ctx context.Context, event *ReactionEvent) {
+		events = append(events, event)
+	})
+	s.OnReactionCount(func(ctx context.Context, event *ReactionCountEvent) {
+		counts = append(counts, event)
+	})
+
+	handler := s.reactionMiddleware()(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		passed++
+	})
+
+	handler(context.Background(), nil, &models.Update{MessageReaction: &models.MessageReactionUpdated{
+		Chat:        models.Chat{ID: 1},
+		MessageID:   2,
+		OldReaction: []models.ReactionType{emojiReaction("👍"), emojiReaction("🔥")},
+		NewReaction: []models.ReactionType{
+			emojiReaction("🔥"),
+			{Type: models.ReactionTypeTypeCustomEmoji, ReactionTypeCustomEmoji: &models.ReactionTypeCustomEmoji{CustomEmojiID: "42"}},
+		},
+	}})
+
+	require.Len(t, events, 1)
+	require.Equal(t, []string{"custom:42"}, events[0].Added)
+	require.Equal(t, []string{"👍"}, events[0].Removed)
+	require.Equal(t, 1, passed)
+
+	handler(context.Background(), nil, &models.Update{MessageReactionCount: &models.MessageReactionCountUpdated{
+		Chat:      models.Chat{ID: 1},
+		MessageID: 2,
+		Reactions: []models.ReactionCount{{Type: emojiReaction("🔥"), TotalCount: 3}},
+	}})
+
+	require.Len(t, counts, 1)
+	require.Equal(t, map[string]int{"🔥": 3}, counts[0].Counts)
+
+	unsubscribe()
+	handler(context.Background(), nil, &models.Update{MessageReaction: &models.MessageReactionUpdated{}})
+	require.Len(t, events, 1)
+	require.Equal(t, 3, passed)
+}
@@ -0,0 +1,165 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryDelay    = 500 * time.Millisecond
+	defaultMaxRetryDelay = 10 * time.Second
+	defaultMaxFloodWait  = 30 * time.Second
+)
+
+// ErrFloodWait matches FloodWaitError with errors.Is
+var ErrFloodWait = errors.New("flood wait")
+
+// FloodWaitError is returned when Telegram asks to wait longer than
+// RetryConfig.MaxFloodWait, or still does after the last attempt
+type FloodWaitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *FloodWaitError) Error() string {
+	return fmt.Sprintf("flood wait of %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *FloodWaitError) Unwrap() []error {
+	return []error{ErrFloodWait, e.Err}
+}
+
+// RetryConfig configures the retries of sent messages. Requests rejected
+// with 429 Too Many Requests are retried after the wait Telegram asks for,
+// server errors with exponential backoff.
+type RetryConfig struct {
+	// MaxAttempts is the number of attempts per request, defaults to 3. Set
+	// it to 1 to disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry of a server error, it
+	// doubles for every next retry. Defaults to 500ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff, defaults to 10s
+	MaxDelay time.Duration
+	// MaxFloodWait is the longest wait of a 429 response that is waited out,
+	// longer waits return a FloodWaitError. Defaults to 30s.
+	MaxFloodWait time.Duration
+	// OnRetry is called before every retry
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+func (cfg RetryConfig) withDefaults() RetryConfig {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultRetryAttempts
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = defaultRetryDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = defaultMaxRetryDelay
+	}
+	if cfg.MaxFloodWait <= 0 {
+		cfg.MaxFloodWait = defaultMaxFloodWait
+	}
+
+	return cfg
+}
+
+func (cfg RetryConfig) problems() []error {
+	if cfg.MaxAttempts < 0 || cfg.BaseDelay < 0 || cfg.MaxDelay < 0 || cfg.MaxFloodWait < 0 {
+		return []error{errors.New("Retry must not have negative values, use zero for the defaults")}
+	}
+
+	return nil
+}
+
+// serverErrorPattern matches the errors of the bot library for 5xx responses
+var serverErrorPattern = regexp.MustCompile(`error response from telegram for method \S+, 5\d\d `)
+
+// retryWait returns how long to wait before retrying after the error, and
+// false if the error is not worth retrying
+func (cfg RetryConfig) retryWait(attempt int, err error) (time.Duration, bool) {
+	var tooMany *bot.TooManyRequestsError
+	if errors.As(err, &tooMany) {
+		return time.Duration(tooMany.RetryAfter) * time.Second, true
+	}
+
+	if serverErrorPattern.MatchString(err.Error()) {
+		wait := cfg.BaseDelay << (attempt - 1)
+		if wait > cfg.MaxDelay || wait <= 0 {
+			wait = cfg.MaxDelay
+		}
+
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// withRetry runs the request until it succeeds, fails with an error that is
+// not worth retrying or runs out of attempts
+func withRetry[T any](ctx context.Context, cfg RetryConfig, run func() (T, error)) (T, error) {
+	cfg = cfg.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		result, err := run()
+		if err == nil {
+			return result, nil
+		}
+
+		wait, retry := cfg.retryWait(attempt, err)
+		if !retry {
+			return result, err
+		}
+
+		var tooMany *bot.TooManyRequestsError
+		isFlood := errors.As(err, &tooMany)
+
+		if attempt >= cfg.MaxAttempts || isFlood && wait > cfg.MaxFloodWait {
+			if isFlood {
+				return result, &FloodWaitError{RetryAfter: wait, Err: err}
+			}
+
+			return result, err
+		}
+
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, wait, err)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// retry runs the request with the retry configuration of the service
+func (s *Service) retry(ctx context.Context, chatID int64, run func() (*models.Message, error)) (*models.Message, error) {
+	cfg := s.cfg.Retry
+	onRetry := cfg.OnRetry
+
+	cfg.OnRetry = func(attempt int, wait time.Duration, err error) {
+		s.logger.Warn("retrying request",
+			slog.String("err", err.Error()),
+			slog.Int64("chat", chatID),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+		)
+
+		if onRetry != nil {
+			onRetry(attempt, wait, err)
+		}
+	}
+
+	return withRetry(ctx, cfg, run)
+}
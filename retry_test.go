@@ -0,0 +1,69 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetry(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	var waits []time.Duration
+	cfg.OnRetry = func(attempt int, wait time.Duration, err error) {
+		waits = append(waits, wait)
+	}
+
+	// Server errors are retried with backoff
+	attempts := 0
+	result, err := withRetry(context.Background(), cfg, func() (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")
+		}
+
+		return 42, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 42, result)
+	require.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, waits)
+
+	// Other errors are returned right away
+	attempts = 0
+	_, err = withRetry(context.Background(), cfg, func() (int, error) {
+		attempts++
+		return 0, bot.ErrorBadRequest
+	})
+	require.ErrorIs(t, err, bot.ErrorBadRequest)
+	require.Equal(t, 1, attempts)
+}
+
+func TestWithRetryFloodWait(t *testing.T) {
+	cfg := RetryConfig{MaxFloodWait: time.Second}
+
+	// Waits over the cap are not waited out
+	attempts := 0
+	_, err := withRetry(context.Background(), cfg, func() (int, error) {
+		attempts++
+		return 0, &bot.TooManyRequestsError{Message: "too many requests", RetryAfter: 60}
+	})
+	require.ErrorIs(t, err, ErrFloodWait)
+	require.Equal(t, 1, attempts)
+
+	var flood *FloodWaitError
+	require.ErrorAs(t, err, &flood)
+	require.Equal(t, time.Minute, flood.RetryAfter)
+
+	// Short waits are retried until the attempts run out
+	attempts = 0
+	_, err = withRetry(context.Background(), RetryConfig{MaxAttempts: 2}, func() (int, error) {
+		attempts++
+		return 0, &bot.TooManyRequestsError{Message: "too many requests", RetryAfter: 0}
+	})
+	require.ErrorIs(t, err, ErrFloodWait)
+	require.Equal(t, 2, attempts)
+}
@@ -0,0 +1,339 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+)
+
+const scheduleTable = "scheduled_messages"
+
+var ErrNotScheduled = errors.New("message is not scheduled")
+
+// ScheduledMessage is a message waiting to be sent at a later time, it is
+// also the database row of the schedule table
+type ScheduledMessage struct {
+	ID     uint64 `gorm:"primaryKey"`
+	ChatID int64
+	// Message is stored as JSON, TextArgs come back with JSON types
+	Message   Message   `gorm:"serializer:json"`
+	SendAt    time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// ScheduleStore persists the scheduled messages
+type ScheduleStore interface {
+	// Add stores the message and sets its ID
+	Add(ctx context.Context, msg *ScheduledMessage) error
+	// Remove deletes the message, it returns false if it wasn't stored
+	Remove(ctx context.Context, id uint64) (bool, error)
+	// Pending returns the stored messages ordered by time
+	Pending(ctx context.Context) ([]ScheduledMessage, error)
+}
+
+// Schedule sends the message at the given time, times in the past send it
+// right away. It returns the ID to cancel the message with.
+func (s *Service) Schedule(chatID int64, msg Message, at time.Time) (uint64, error) {
+	return s.ScheduleContext(context.Background(), chatID, msg, at)
+}
+
+// ScheduleContext is Schedule with a context
+func (s *Service) ScheduleContext(ctx context.Context, chatID int64, msg Message, at time.Time) (uint64, error) {
+	return s.scheduler.add(ctx, chatID, msg, at)
+}
+
+// CancelScheduled cancels a scheduled message, it returns ErrNotScheduled
+// when the message was sent or canceled already
+func (s *Service) CancelScheduled(ctx context.Context, id uint64) error {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	ok, err := s.cfg.Schedules.Remove(ctx, id)
+	if err != nil {
+		return fmt.Errorf("cancel scheduled message: %w", err)
+	}
+
+	if !ok {
+		return ErrNotScheduled
+	}
+
+	return nil
+}
+
+// ScheduledMessages returns the messages waiting to be sent, ordered by time
+func (s *Service) ScheduledMessages(ctx context.Context) ([]ScheduledMessage, error) {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	pending, err := s.cfg.Schedules.Pending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get scheduled messages: %w", err)
+	}
+
+	return pending, nil
+}
+
+// scheduler sends the stored messages when they are due. The store is the
+// source of truth, so messages scheduled before a restart are sent after it.
+type scheduler struct {
+	store  ScheduleStore
+	logger *slog.Logger
+	send   func(ctx context.Context, chatID int64, msg Message) (*models.Message, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	wake   chan struct{}
+
+	mu       sync.Mutex
+	inFlight map[uint64]bool
+}
+
+func newScheduler(store ScheduleStore, logger *slog.Logger, send func(ctx context.Context, chatID int64, msg Message) (*models.Message, error)) *scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &scheduler{
+		store:    store,
+		logger:   logger,
+		send:     send,
+		ctx:      ctx,
+		cancel:   cancel,
+		wake:     make(chan struct{}, 1),
+		inFlight: make(map[uint64]bool),
+	}
+}
+
+func (s *scheduler) start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// stop ends the loop and waits for the messages being sent
+func (s *scheduler) stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *scheduler) add(ctx context.Context, chatID int64, msg Message, at time.Time) (uint64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	scheduled := &ScheduledMessage{
+		ChatID:    chatID,
+		Message:   msg,
+		SendAt:    at,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.store.Add(ctx, scheduled); err != nil {
+		return 0, fmt.Errorf("schedule message: %w", err)
+	}
+
+	s.notify()
+
+	return scheduled.ID, nil
+}
+
+func (s *scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *scheduler) run() {
+	defer s.wg.Done()
+
+	for {
+		wait := s.dispatch(time.Now())
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+	}
+}
+
+// dispatch sends the due messages and returns the time until the next one
+func (s *scheduler) dispatch(now time.Time) time.Duration {
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+
+	pending, err := s.store.Pending(ctx)
+	if err != nil {
+		s.logger.Error("failed to get scheduled messages", slog.String("err", err.Error()))
+		return time.Minute
+	}
+
+	for _, msg := range pending {
+		if msg.SendAt.After(now) {
+			return msg.SendAt.Sub(now)
+		}
+
+		s.mu.Lock()
+		busy := s.inFlight[msg.ID]
+		s.inFlight[msg.ID] = true
+		s.mu.Unlock()
+
+		if busy {
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.deliver(msg)
+	}
+
+	// Nothing left, sleep until a message is scheduled
+	return 24 * time.Hour
+}
+
+// deliver sends the message and removes it from the store, messages that
+// could not be sent are dropped as the retries of the service are exhausted
+func (s *scheduler) deliver(msg ScheduledMessage) {
+	defer s.wg.Done()
+
+	_, err := s.send(s.ctx, msg.ChatID, msg.Message)
+
+	// Interrupted by stop, the message is sent after the next start
+	if err != nil && s.ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		s.logger.Error("failed to send scheduled message",
+			slog.String("err", err.Error()),
+			slog.Int64("chat", msg.ChatID),
+			slog.Uint64("id", msg.ID),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	if _, err := s.store.Remove(ctx, msg.ID); err != nil {
+		s.logger.Error("failed to remove scheduled message", slog.String("err", err.Error()))
+	}
+
+	s.mu.Lock()
+	delete(s.inFlight, msg.ID)
+	s.mu.Unlock()
+}
+
+// MemoryScheduleStore keeps the scheduled messages in memory, they are lost
+// on restart
+type MemoryScheduleStore struct {
+	mu       sync.Mutex
+	nextID   uint64
+	messages map[uint64]ScheduledMessage
+}
+
+var _ ScheduleStore = (*MemoryScheduleStore)(nil)
+
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{messages: make(map[uint64]ScheduledMessage)}
+}
+
+func (m *MemoryScheduleStore) Add(_ context.Context, msg *ScheduledMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	msg.ID = m.nextID
+	m.messages[msg.ID] = *msg
+
+	return nil
+}
+
+func (m *MemoryScheduleStore) Remove(_ context.Context, id uint64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.messages[id]
+	delete(m.messages, id)
+
+	return ok, nil
+}
+
+func (m *MemoryScheduleStore) Pending(_ context.Context) ([]ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending := make([]ScheduledMessage, 0, len(m.messages))
+	for _, msg := range m.messages {
+		pending = append(pending, msg)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].SendAt.Equal(pending[j].SendAt) {
+			return pending[i].SendAt.Before(pending[j].SendAt)
+		}
+
+		return pending[i].ID < pending[j].ID
+	})
+
+	return pending, nil
+}
+
+// DBScheduleStore keeps the scheduled messages in a SQLite or Postgres
+// database
+type DBScheduleStore struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ ScheduleStore = (*DBScheduleStore)(nil)
+
+// NewDBScheduleStore creates a schedule store using the given database, the
+// schedule table is created if it does not exist
+func NewDBScheduleStore(db *gorm.DB, tablePrefix string) (*DBScheduleStore, error) {
+	d := &DBScheduleStore{
+		db:    db,
+		table: tablePrefix + scheduleTable,
+	}
+
+	if err := db.Table(d.table).AutoMigrate(&ScheduledMessage{}); err != nil {
+		return nil, fmt.Errorf("migrate scheduled messages: %w", err)
+	}
+
+	return d, nil
+}
+
+func (d *DBScheduleStore) Add(ctx context.Context, msg *ScheduledMessage) error {
+	if err := d.db.WithContext(ctx).Table(d.table).Create(msg).Error; err != nil {
+		return fmt.Errorf("add scheduled message: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DBScheduleStore) Remove(ctx context.Context, id uint64) (bool, error) {
+	result := d.db.WithContext(ctx).Table(d.table).Delete(&ScheduledMessage{}, id)
+	if result.Error != nil {
+		return false, fmt.Errorf("remove scheduled message: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (d *DBScheduleStore) Pending(ctx context.Context) ([]ScheduledMessage, error) {
+	var pending []ScheduledMessage
+	if err := d.db.WithContext(ctx).Table(d.table).Order("send_at, id").Find(&pending).Error; err != nil {
+		return nil, fmt.Errorf("get scheduled messages: %w", err)
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,103 @@
+package tgbot
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestScheduler(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []string
+	)
+
+	send := func(ctx context.Context, chatID int64, msg Message) (*models.Message, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		sent = append(sent, msg.Text)
+
+		return &models.Message{ID: len(sent)}, nil
+	}
+
+	store := NewMemoryScheduleStore()
+	ctx := context.Background()
+
+	// Messages stored before the start are sent once due
+	require.NoError(t, store.Add(ctx, &ScheduledMessage{ChatID: 1, Message: Message{Text: "overdue"}, SendAt: time.Now().Add(-time.Hour)}))
+
+	s := newScheduler(store, slog.Default(), send)
+	s.start()
+	defer s.stop()
+
+	_, err := s.add(ctx, 1, Message{Text: "soon"}, time.Now().Add(20*time.Millisecond))
+	require.NoError(t, err)
+
+	later, err := s.add(ctx, 1, Message{Text: "later"}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(sent) == 2
+	}, time.Second, time.Millisecond)
+
+	require.Equal(t, []string{"overdue", "soon"}, sent)
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, later, pending[0].ID)
+
+	ok, err := store.Remove(ctx, later)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Remove(ctx, later)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDBScheduleStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "schedule.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	store, err := NewDBScheduleStore(db, "test_")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	second := &ScheduledMessage{ChatID: 1, Message: Message{Text: "second"}, SendAt: now.Add(2 * time.Hour)}
+	first := &ScheduledMessage{ChatID: 2, Message: Message{Text: "first", ThreadID: 7}, SendAt: now.Add(time.Hour)}
+	require.NoError(t, store.Add(ctx, second))
+	require.NoError(t, store.Add(ctx, first))
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, "first", pending[0].Message.Text)
+	require.Equal(t, 7, pending[0].Message.ThreadID)
+
+	ok, err := store.Remove(ctx, first.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Remove(ctx, first.ID)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	pending, err = store.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+}
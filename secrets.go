@@ -0,0 +1,111 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"golang.org/x/exp/slog"
+)
+
+// tokenRefresher is implemented by providers that cache the token, like
+// secrets.CachedProvider
+type tokenRefresher interface {
+	Refresh(ctx context.Context) (string, error)
+}
+
+// resolveToken sets Config.Token from Config.TokenProvider
+func (cfg *Config) resolveToken() error {
+	if cfg.TokenProvider == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	token, err := cfg.TokenProvider.Secret(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve token: %w", err)
+	}
+
+	cfg.Token = token
+
+	return nil
+}
+
+// botToken returns the current token, the last known token when the
+// provider fails
+func (s *Service) botToken(ctx context.Context) string {
+	if s.cfg.TokenProvider == nil {
+		return s.cfg.Token
+	}
+
+	token, err := s.cfg.TokenProvider.Secret(ctx)
+	if err != nil || !tokenPattern.MatchString(token) {
+		if last := s.lastToken.Load(); last != nil {
+			return *last
+		}
+
+		return s.cfg.Token
+	}
+
+	if last := s.lastToken.Load(); last == nil || *last != token {
+		s.lastToken.Store(&token)
+	}
+
+	return token
+}
+
+// RefreshToken fetches the token from Config.TokenProvider now, instead of
+// when its cache expires. The next requests use the new token.
+func (s *Service) RefreshToken(ctx context.Context) error {
+	refresher, ok := s.cfg.TokenProvider.(tokenRefresher)
+	if !ok {
+		return nil
+	}
+
+	token, err := refresher.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("refresh token: %w", err)
+	}
+
+	if !tokenPattern.MatchString(token) {
+		return fmt.Errorf("refresh token: %w", ErrInvalidConfig)
+	}
+
+	s.lastToken.Store(&token)
+	s.logger.Info("bot token refreshed", slog.String("bot", s.username))
+
+	return nil
+}
+
+// tokenClient is the HTTP client of the bot library when the token comes
+// from a provider. The library keeps the token it was created with, the
+// client swaps it for the current one in every request.
+type tokenClient struct {
+	client  *http.Client
+	initial string
+	token   func(ctx context.Context) string
+}
+
+// tokenClientOption sets the tokenClient with the timeout of the default
+// client of the library
+func (s *Service) tokenClientOption() bot.Option {
+	return bot.WithHTTPClient(time.Minute, &tokenClient{
+		client:  &http.Client{Timeout: time.Minute},
+		initial: s.cfg.Token,
+		token:   s.botToken,
+	})
+}
+
+func (c *tokenClient) Do(req *http.Request) (*http.Response, error) {
+	if token := c.token(req.Context()); token != c.initial {
+		req.URL.Path = strings.Replace(req.URL.Path, "/bot"+c.initial+"/", "/bot"+token+"/", 1)
+		req.URL.RawPath = ""
+	}
+
+	return c.client.Do(req)
+}
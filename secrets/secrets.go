@@ -0,0 +1,145 @@
+// Package secrets resolves credentials like bot tokens and database DSNs
+// from the environment, files, Vault or a callback, so they can be rotated
+// without restarting the process.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrEmpty = errors.New("secret is empty")
+
+// Provider returns the current value of a secret. Providers are called
+// whenever the secret is needed, wrap slow ones in Cached.
+type Provider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// Func is a callback Provider
+type Func func(ctx context.Context) (string, error)
+
+func (f Func) Secret(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Static returns a Provider with a fixed value
+func Static(value string) Provider {
+	return Func(func(ctx context.Context) (string, error) {
+		return value, nil
+	})
+}
+
+// Env returns a Provider reading the environment variable
+func Env(name string) Provider {
+	return Func(func(ctx context.Context) (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return "", fmt.Errorf("environment variable %s: %w", name, ErrEmpty)
+		}
+
+		return value, nil
+	})
+}
+
+// File returns a Provider reading the file on every call, surrounding
+// whitespace is trimmed. Mounted Kubernetes secrets are updated in place.
+func File(path string) Provider {
+	return Func(func(ctx context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", fmt.Errorf("secret file %s: %w", path, ErrEmpty)
+		}
+
+		return value, nil
+	})
+}
+
+// Resolve returns the secret of the provider, or fallback when the provider
+// is nil
+func Resolve(ctx context.Context, provider Provider, fallback string) (string, error) {
+	if provider == nil {
+		return fallback, nil
+	}
+
+	value, err := provider.Secret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if value == "" {
+		return "", ErrEmpty
+	}
+
+	return value, nil
+}
+
+// CachedProvider keeps the secret of another Provider for a TTL. When the
+// refresh fails the last value is returned, so an unavailable backend does
+// not take the service down.
+type CachedProvider struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+var _ Provider = (*CachedProvider)(nil)
+
+// Cached wraps the provider to fetch the secret at most once per TTL, a zero
+// TTL keeps it until Refresh is called
+func Cached(provider Provider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{provider: provider, ttl: ttl}
+}
+
+func (c *CachedProvider) Secret(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value != "" && (c.ttl <= 0 || time.Now().Before(c.expires)) {
+		return c.value, nil
+	}
+
+	value, err := c.provider.Secret(ctx)
+	if err != nil {
+		if c.value != "" {
+			return c.value, nil
+		}
+
+		return "", err
+	}
+
+	c.value = value
+	c.expires = time.Now().Add(c.ttl)
+
+	return value, nil
+}
+
+// Refresh fetches the secret now, errors are returned instead of falling
+// back to the last value
+func (c *CachedProvider) Refresh(ctx context.Context) (string, error) {
+	value, err := c.provider.Secret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value = value
+	c.expires = time.Now().Add(c.ttl)
+
+	return value, nil
+}
@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("one\n"), 0o600))
+
+	provider := File(path)
+
+	value, err := provider.Secret(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "one", value)
+
+	// The file is read on every call, so rotated secrets are picked up
+	require.NoError(t, os.WriteFile(path, []byte("two"), 0o600))
+
+	value, err = provider.Secret(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "two", value)
+
+	require.NoError(t, os.WriteFile(path, []byte(" \n"), 0o600))
+
+	_, err = provider.Secret(context.Background())
+	require.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestCached(t *testing.T) {
+	var calls int
+	var fail bool
+
+	cached := Cached(Func(func(ctx context.Context) (string, error) {
+		if fail {
+			return "", errors.New("unavailable")
+		}
+
+		calls++
+		return "value", nil
+	}), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		value, err := cached.Secret(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "value", value)
+	}
+	require.Equal(t, 1, calls)
+
+	_, err := cached.Refresh(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// The last value is kept when the backend fails
+	fail = true
+	cached.expires = time.Time{}
+
+	value, err := cached.Secret(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	_, err = cached.Refresh(context.Background())
+	require.Error(t, err)
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		require.Equal(t, "/v1/kv/data/bots/main", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"data":{"token":"123:abc"}}}`))
+	}))
+	defer server.Close()
+
+	cfg := VaultConfig{Address: server.URL, Token: Static("root"), Mount: "kv", Path: "bots/main", Field: "token"}
+
+	value, err := Vault(cfg).Secret(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "123:abc", value)
+
+	cfg.Field = "missing"
+	_, err = Vault(cfg).Secret(context.Background())
+	require.ErrorIs(t, err, ErrVaultField)
+
+	cfg.Token = Static("wrong")
+	_, err = Vault(cfg).Secret(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrVaultField = errors.New("field not found in vault secret")
+
+// VaultConfig points to a field of a secret in a Vault KV version 2 engine
+type VaultConfig struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token authenticates the request, defaults to the VAULT_TOKEN variable
+	Token Provider
+	// Mount is the path of the KV engine, defaults to "secret"
+	Mount string
+	Path  string
+	Field string
+	// Client defaults to a client with a 10 second timeout
+	Client *http.Client
+}
+
+// Vault returns a Provider reading a field of a Vault KV version 2 secret
+func Vault(cfg VaultConfig) Provider {
+	if cfg.Token == nil {
+		cfg.Token = Env("VAULT_TOKEN")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return Func(func(ctx context.Context) (string, error) {
+		token, err := cfg.Token.Secret(ctx)
+		if err != nil {
+			return "", fmt.Errorf("vault token: %w", err)
+		}
+
+		url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(cfg.Address, "/"), strings.Trim(cfg.Mount, "/"), strings.Trim(cfg.Path, "/"))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", fmt.Errorf("create vault request: %w", err)
+		}
+		req.Header.Set("X-Vault-Token", token)
+
+		resp, err := cfg.Client.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("read vault secret: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("read vault secret: status %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Data struct {
+				Data map[string]any `json:"data"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return "", fmt.Errorf("decode vault secret: %w", err)
+		}
+
+		value, ok := result.Data.Data[cfg.Field].(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrVaultField, cfg.Field)
+		}
+
+		return value, nil
+	})
+}
@@ -0,0 +1,48 @@
+package tgbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"github.com/Davincible/tgbot/secrets"
+)
+
+func TestResolveToken(t *testing.T) {
+	cfg := &Config{TokenProvider: secrets.Static("123:abc")}
+	require.NoError(t, validateConfig(slog.Default(), cfg))
+	require.Equal(t, "123:abc", cfg.Token)
+
+	cfg = &Config{TokenProvider: secrets.Env("TGBOT_TEST_MISSING_TOKEN")}
+	require.ErrorIs(t, validateConfig(slog.Default(), cfg), ErrInvalidConfig)
+}
+
+func TestTokenClient(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+	defer server.Close()
+
+	token := "123:new"
+	s := &Service{cfg: &Config{Token: "123:old", TokenProvider: secrets.Func(func(ctx context.Context) (string, error) {
+		return token, nil
+	})}}
+
+	client := &tokenClient{client: server.Client(), initial: "123:old", token: s.botToken}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/bot123:old/getMe", nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "/bot123:new/getMe", path)
+
+	// A malformed token from the provider keeps the last good one
+	token = "garbage"
+	require.Equal(t, "123:new", s.botToken(context.Background()))
+}
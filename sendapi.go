@@ -0,0 +1,122 @@
+package tgbot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// defaultSendAPIMaxBody fits a 50 MB upload in base64
+	defaultSendAPIMaxBody = 72 << 20
+	minSendAPIToken       = 16
+)
+
+// SendRequest is the body of a request to SendHandler
+type SendRequest struct {
+	ChatID  int64   `json:"chatId"`
+	Message Message `json:"message"`
+}
+
+// SendResponse is the answer of SendHandler, Error is set when the message
+// was not sent
+type SendResponse struct {
+	MessageID int    `json:"messageId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SendHandler returns the handler of POST /send, it lets services in other
+// languages send messages through the running bot. The request is a JSON
+// SendRequest with Config.SendAPIToken as bearer token, the message is
+// checked with ValidateMessage and sent like Send. Invalid messages are
+// answered with 400, unknown chats with 404, chats that blocked the bot with
+// 403, flood waits with 429 and Retry-After, other failures with 502.
+// Without SendAPIToken all requests are rejected.
+func (s *Service) SendHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, status, err := s.readSendRequest(w, r)
+		if err != nil {
+			s.logger.Warn("rejected send request", slog.String("remote", r.RemoteAddr), slog.String("err", err.Error()))
+			writeSendResponse(w, status, SendResponse{Error: err.Error()})
+			return
+		}
+
+		sent, err := s.SendContext(r.Context(), req.ChatID, req.Message)
+		if err != nil {
+			var floodWait *FloodWaitError
+			if errors.As(err, &floodWait) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(floodWait.RetryAfter.Round(time.Second)/time.Second)))
+			}
+
+			writeSendResponse(w, sendErrorStatus(err), SendResponse{Error: err.Error()})
+			return
+		}
+
+		writeSendResponse(w, http.StatusOK, SendResponse{MessageID: sent.ID})
+	}
+}
+
+func (s *Service) readSendRequest(w http.ResponseWriter, r *http.Request) (SendRequest, int, error) {
+	var req SendRequest
+
+	if r.Method != http.MethodPost {
+		return req, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method)
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.cfg.SendAPIToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.SendAPIToken)) != 1 {
+		return req, http.StatusUnauthorized, errors.New("invalid token")
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return req, http.StatusUnsupportedMediaType, fmt.Errorf("content type %q", r.Header.Get("Content-Type"))
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, defaultSendAPIMaxBody)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return req, http.StatusRequestEntityTooLarge, err
+		}
+
+		return req, http.StatusBadRequest, fmt.Errorf("decode request: %w", err)
+	}
+
+	if req.ChatID == 0 {
+		return req, http.StatusBadRequest, errors.New("chatId is required")
+	}
+
+	if err := ValidateMessage(req.Message); err != nil {
+		return req, http.StatusBadRequest, err
+	}
+
+	return req, 0, nil
+}
+
+func sendErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrMessageTooLong), errors.Is(err, ErrInvalidMessage):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrChatNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrBotBlocked), errors.Is(err, ErrUserDeactivated), errors.Is(err, ErrBotNotMember):
+		return http.StatusForbidden
+	case errors.Is(err, ErrFloodWait):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeSendResponse(w http.ResponseWriter, status int, resp SendResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
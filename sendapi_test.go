@@ -0,0 +1,111 @@
+package tgbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestMessageJSON(t *testing.T) {
+	msg := Message{
+		Text:      "*hi*",
+		Image:     []byte{0xff, 0xd8},
+		Entities:  []models.MessageEntity{{Type: models.MessageEntityTypeBold, Offset: 0, Length: 2}},
+		Buttons:   []InlineButton{{Row: []InlineButton{{Text: "a", CallbackData: "x"}, {Text: "b", URL: "https://example.com"}}}},
+		ParseMode: ParseModeHTML,
+		Localized: map[string]string{"de": "hallo"},
+		TextArgs:  []any{"name"},
+		Album:     []MediaItem{{Type: "photo", URL: "https://example.com/a.jpg"}},
+		Duration:  time.Second,
+		Venue:     &Venue{Location: Location{Latitude: 1.5, Longitude: 2.5}, Title: "Home"},
+		Keyboard:  &ReplyKeyboard{Buttons: []KeyboardButton{KeyboardRow("yes", "no")}, OneTime: true},
+	}
+
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"parseMode":"html"`)
+	require.Contains(t, string(data), `"image":"/9g="`)
+
+	var decoded Message
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, msg, decoded)
+
+	// Messages stored with the Go field names still decode
+	var legacy Message
+	require.NoError(t, json.Unmarshal([]byte(`{"Text":"a","ImageURL":"https://example.com/a.jpg","ReplyTo":3,"ThreadID":4,"ParseMode":2}`), &legacy))
+	require.Equal(t, Message{Text: "a", ImageURL: "https://example.com/a.jpg", ReplyTo: 3, ThreadID: 4, ParseMode: ParseModeNone}, legacy)
+
+	var mode ParseMode
+	require.NoError(t, json.Unmarshal([]byte(`"CommonMark"`), &mode))
+	require.Equal(t, ParseModeCommonMark, mode)
+	require.Error(t, json.Unmarshal([]byte(`"rtf"`), &mode))
+}
+
+func TestSendHandler(t *testing.T) {
+	const token = "0123456789abcdef"
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	recorder := NewRecorder()
+
+	s, err := NewService(logger, &Config{DryRun: recorder, SendAPIToken: token})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	handler := s.SendHandler()
+
+	send := func(method, auth, body string) (int, SendResponse) {
+		req := httptest.NewRequest(method, "/send", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		var resp SendResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+		return rec.Code, resp
+	}
+
+	status, resp := send(http.MethodPost, token, `{"chatId":42,"message":{"text":"hello","buttons":[{"text":"Open","url":"https://example.com"}]}}`)
+	require.Equal(t, http.StatusOK, status, resp.Error)
+	require.NotZero(t, resp.MessageID)
+
+	calls := recorder.Calls("sendMessage")
+	require.Len(t, calls, 1)
+	require.Equal(t, int64(42), calls[0].ChatID())
+	require.Equal(t, "hello", calls[0].Params["text"])
+
+	for _, tc := range []struct {
+		method, auth, body string
+		status             int
+	}{
+		{http.MethodGet, token, ``, http.StatusMethodNotAllowed},
+		{http.MethodPost, "", `{"chatId":42,"message":{"text":"hello"}}`, http.StatusUnauthorized},
+		{http.MethodPost, "wrong-token-0123", `{"chatId":42,"message":{"text":"hello"}}`, http.StatusUnauthorized},
+		{http.MethodPost, token, `{"chatId":42,"message":`, http.StatusBadRequest},
+		{http.MethodPost, token, `{"message":{"text":"hello"}}`, http.StatusBadRequest},
+		{http.MethodPost, token, `{"chatId":42,"message":{}}`, http.StatusBadRequest},
+		{http.MethodPost, token, fmt.Sprintf(`{"chatId":42,"message":{"text":%q}}`, strings.Repeat("a", 5000)), http.StatusBadRequest},
+	} {
+		status, resp := send(tc.method, tc.auth, tc.body)
+		require.Equal(t, tc.status, status, tc.body)
+		require.NotEmpty(t, resp.Error)
+	}
+
+	require.Len(t, recorder.Calls("sendMessage"), 1)
+
+	require.Equal(t, http.StatusNotFound, sendErrorStatus(&APIError{Kind: ErrChatNotFound, Err: fmt.Errorf("chat not found")}))
+	require.Equal(t, http.StatusTooManyRequests, sendErrorStatus(&FloodWaitError{RetryAfter: time.Second}))
+}
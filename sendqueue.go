@@ -0,0 +1,620 @@
+package tgbot
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm"
+)
+
+const (
+	sendQueueTable     = "send_queue"
+	sendQueueDeadTable = "send_queue_dead"
+
+	defaultQueueWorkers    = 4
+	defaultQueueAttempts   = 5
+	defaultQueueRetryDelay = 30 * time.Second
+	defaultQueueMaxDelay   = 10 * time.Minute
+
+	// queueIdleWait is the longest a worker sleeps without a wake up
+	queueIdleWait = time.Second
+)
+
+var ErrSendQueueClosed = errors.New("send queue closed")
+
+// Priority orders the messages of the send queue, higher priorities are sent
+// first
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// QueuedMessage is a message waiting in the send queue, it is also the
+// database row of the queue and dead letter tables
+type QueuedMessage struct {
+	ID       uint64 `gorm:"primaryKey"`
+	ChatID   int64
+	Priority Priority
+	// Message is stored as JSON, TextArgs come back with JSON types
+	Message   Message `gorm:"serializer:json"`
+	Attempts  int
+	NotBefore time.Time
+	LastError string
+	CreatedAt time.Time
+}
+
+// QueueStore persists the messages of the send queue
+type QueueStore interface {
+	// Add stores the message and sets its ID
+	Add(ctx context.Context, msg *QueuedMessage) error
+	// Update stores the attempts of a message that will be retried
+	Update(ctx context.Context, msg *QueuedMessage) error
+	// Remove deletes a sent message
+	Remove(ctx context.Context, id uint64) error
+	// Pending returns the stored messages that have not been sent
+	Pending(ctx context.Context) ([]QueuedMessage, error)
+	// Dead moves a message that failed permanently to the dead letters
+	Dead(ctx context.Context, msg *QueuedMessage) error
+	// DeadLetters returns the messages that failed permanently
+	DeadLetters(ctx context.Context) ([]QueuedMessage, error)
+}
+
+// SendQueueConfig configures the send queue
+type SendQueueConfig struct {
+	// Store persists the queue, so pending messages survive restarts.
+	// Defaults to memory.
+	Store QueueStore
+	// Workers is the number of messages sent in parallel, defaults to 4.
+	// Messages to the same chat are never sent in parallel.
+	Workers int
+	// MaxAttempts is the number of times a message is tried before it is
+	// moved to the dead letters, defaults to 5. Each attempt includes the
+	// retries of Config.Retry.
+	MaxAttempts int
+	// RetryDelay is the wait before the second attempt, it doubles for every
+	// next attempt. Defaults to 30s.
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the wait between attempts, defaults to 10 minutes
+	MaxRetryDelay time.Duration
+	// OnDead is called when a message is moved to the dead letters
+	OnDead func(msg QueuedMessage, err error)
+}
+
+// SendQueueStats holds the metrics of the send queue
+type SendQueueStats struct {
+	// Depth is the number of queued messages per priority
+	Depth    map[Priority]int
+	InFlight int
+	Sent     uint64
+	Retried  uint64
+	Dead     uint64
+}
+
+// Pending returns the number of queued messages over all priorities
+func (s SendQueueStats) Pending() int {
+	n := 0
+	for _, depth := range s.Depth {
+		n += depth
+	}
+
+	return n
+}
+
+// SendQueue sends messages in order of priority, drained by workers that go
+// through the rate limiter and send pipeline of the service. Messages are
+// stored before they are queued and removed once sent, so with a persistent
+// store they are sent at least once across restarts.
+type SendQueue struct {
+	cfg    SendQueueConfig
+	logger *slog.Logger
+	send   func(ctx context.Context, chatID int64, msg Message) (*models.Message, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	wake   chan struct{}
+
+	mu    sync.Mutex
+	items queueHeap
+	busy  map[int64]bool
+	seq   uint64
+
+	sent    atomic.Uint64
+	retried atomic.Uint64
+	dead    atomic.Uint64
+}
+
+// NewSendQueue creates a send queue and starts its workers, messages left
+// in the store by a previous run are queued again
+func (s *Service) NewSendQueue(ctx context.Context, cfg SendQueueConfig) (*SendQueue, error) {
+	return newSendQueue(ctx, s.logger, s.SendContext, cfg)
+}
+
+func newSendQueue(ctx context.Context, logger *slog.Logger, send func(ctx context.Context, chatID int64, msg Message) (*models.Message, error), cfg SendQueueConfig) (*SendQueue, error) {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryQueueStore()
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultQueueWorkers
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultQueueAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultQueueRetryDelay
+	}
+	if cfg.MaxRetryDelay <= 0 {
+		cfg.MaxRetryDelay = defaultQueueMaxDelay
+	}
+
+	pending, err := cfg.Store.Pending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load send queue: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+
+	q := &SendQueue{
+		cfg:    cfg,
+		logger: logger,
+		send:   send,
+		ctx:    runCtx,
+		cancel: cancel,
+		wake:   make(chan struct{}, cfg.Workers),
+		busy:   make(map[int64]bool),
+	}
+
+	for i := range pending {
+		q.push(&pending[i])
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q, nil
+}
+
+// Enqueue stores the message and queues it, it returns the ID of the queued
+// message
+func (q *SendQueue) Enqueue(ctx context.Context, chatID int64, msg Message, priority Priority) (uint64, error) {
+	if q.ctx.Err() != nil {
+		return 0, ErrSendQueueClosed
+	}
+
+	item := &QueuedMessage{
+		ChatID:    chatID,
+		Priority:  priority,
+		Message:   msg,
+		CreatedAt: time.Now(),
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if err := q.cfg.Store.Add(ctx, item); err != nil {
+		return 0, fmt.Errorf("store queued message: %w", err)
+	}
+
+	q.push(item)
+
+	return item.ID, nil
+}
+
+// DeadLetters returns the messages that failed permanently
+func (q *SendQueue) DeadLetters(ctx context.Context) ([]QueuedMessage, error) {
+	return q.cfg.Store.DeadLetters(ctx)
+}
+
+// Stats returns the queue depth and delivery counts
+func (q *SendQueue) Stats() SendQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	depth := make(map[Priority]int)
+	for _, item := range q.items {
+		depth[item.msg.Priority]++
+	}
+
+	inFlight := 0
+	for _, busy := range q.busy {
+		if busy {
+			inFlight++
+		}
+	}
+
+	return SendQueueStats{
+		Depth:    depth,
+		InFlight: inFlight,
+		Sent:     q.sent.Load(),
+		Retried:  q.retried.Load(),
+		Dead:     q.dead.Load(),
+	}
+}
+
+// Close stops the workers after the messages in flight, queued messages
+// stay in the store
+func (q *SendQueue) Close() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *SendQueue) push(msg *QueuedMessage) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.items, &queueItem{msg: msg, seq: q.seq})
+	q.mu.Unlock()
+
+	q.notify()
+}
+
+func (q *SendQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the first message that is due and whose chat has no message
+// in flight, so messages to a chat keep their order. When there is none it
+// returns how long to wait for the next one.
+func (q *SendQueue) next(now time.Time) (*QueuedMessage, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var (
+		skipped []*queueItem
+		found   *QueuedMessage
+		wait    = queueIdleWait
+	)
+
+	for q.items.Len() > 0 {
+		item := heap.Pop(&q.items).(*queueItem)
+
+		if q.busy[item.msg.ChatID] {
+			skipped = append(skipped, item)
+			continue
+		}
+
+		if item.msg.NotBefore.After(now) {
+			if d := item.msg.NotBefore.Sub(now); d < wait {
+				wait = d
+			}
+
+			skipped = append(skipped, item)
+			continue
+		}
+
+		found = item.msg
+		q.busy[found.ChatID] = true
+
+		break
+	}
+
+	for _, item := range skipped {
+		heap.Push(&q.items, item)
+	}
+
+	return found, wait
+}
+
+func (q *SendQueue) work() {
+	defer q.wg.Done()
+
+	for q.ctx.Err() == nil {
+		msg, wait := q.next(time.Now())
+		if msg == nil {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-q.ctx.Done():
+			case <-q.wake:
+			case <-timer.C:
+			}
+
+			timer.Stop()
+
+			continue
+		}
+
+		q.deliver(msg)
+
+		q.mu.Lock()
+		delete(q.busy, msg.ChatID)
+		q.mu.Unlock()
+
+		// Other messages to the chat may be waiting
+		q.notify()
+	}
+}
+
+func (q *SendQueue) deliver(msg *QueuedMessage) {
+	_, err := q.send(q.ctx, msg.ChatID, msg.Message)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	if err == nil {
+		q.sent.Add(1)
+
+		if err := q.cfg.Store.Remove(ctx, msg.ID); err != nil {
+			q.logger.Error("failed to remove sent message from the queue", slog.String("err", err.Error()))
+		}
+
+		return
+	}
+
+	// Messages interrupted by Close are sent after the next start
+	if q.ctx.Err() != nil {
+		return
+	}
+
+	msg.Attempts++
+	msg.LastError = err.Error()
+
+	if permanentSendError(err) || msg.Attempts >= q.cfg.MaxAttempts {
+		q.dead.Add(1)
+
+		q.logger.Error("queued message failed permanently",
+			slog.String("err", err.Error()),
+			slog.Int64("chat", msg.ChatID),
+			slog.Int("attempts", msg.Attempts),
+		)
+
+		if err := q.cfg.Store.Dead(ctx, msg); err != nil {
+			q.logger.Error("failed to move message to the dead letters", slog.String("err", err.Error()))
+		}
+
+		if q.cfg.OnDead != nil {
+			q.cfg.OnDead(*msg, err)
+		}
+
+		return
+	}
+
+	q.retried.Add(1)
+	msg.NotBefore = time.Now().Add(q.retryDelay(msg.Attempts, err))
+
+	if err := q.cfg.Store.Update(ctx, msg); err != nil {
+		q.logger.Error("failed to update queued message", slog.String("err", err.Error()))
+	}
+
+	q.push(msg)
+}
+
+// retryDelay is the wait after the given number of failed attempts, flood
+// waits are waited out as Telegram asks
+func (q *SendQueue) retryDelay(attempts int, err error) time.Duration {
+	var flood *FloodWaitError
+	if errors.As(err, &flood) {
+		return flood.RetryAfter
+	}
+
+	delay := q.cfg.RetryDelay
+	for i := 1; i < attempts && delay < q.cfg.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, q.cfg.MaxRetryDelay)
+}
+
+// permanentSendError reports whether sending again can't succeed, e.g. when
+// the bot was blocked or the chat doesn't exist
+func permanentSendError(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) ||
+		errors.Is(err, bot.ErrorForbidden) ||
+		errors.Is(err, bot.ErrorNotFound) ||
+		errors.Is(err, bot.ErrorUnauthorized)
+}
+
+type queueItem struct {
+	msg *QueuedMessage
+	seq uint64
+}
+
+// queueHeap orders by priority, then by the order messages were queued in
+type queueHeap []*queueItem
+
+func (h queueHeap) Len() int { return len(h) }
+
+func (h queueHeap) Less(i, j int) bool {
+	if h[i].msg.Priority != h[j].msg.Priority {
+		return h[i].msg.Priority > h[j].msg.Priority
+	}
+
+	return h[i].seq < h[j].seq
+}
+
+func (h queueHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *queueHeap) Push(x any) { *h = append(*h, x.(*queueItem)) }
+
+func (h *queueHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return item
+}
+
+// MemoryQueueStore keeps the queue in memory, pending messages are lost on
+// restart
+type MemoryQueueStore struct {
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]QueuedMessage
+	dead    []QueuedMessage
+}
+
+var _ QueueStore = (*MemoryQueueStore)(nil)
+
+func NewMemoryQueueStore() *MemoryQueueStore {
+	return &MemoryQueueStore{pending: make(map[uint64]QueuedMessage)}
+}
+
+func (m *MemoryQueueStore) Add(_ context.Context, msg *QueuedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	msg.ID = m.nextID
+	m.pending[msg.ID] = *msg
+
+	return nil
+}
+
+func (m *MemoryQueueStore) Update(_ context.Context, msg *QueuedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pending[msg.ID] = *msg
+
+	return nil
+}
+
+func (m *MemoryQueueStore) Remove(_ context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.pending, id)
+
+	return nil
+}
+
+func (m *MemoryQueueStore) Pending(_ context.Context) ([]QueuedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending := make([]QueuedMessage, 0, len(m.pending))
+	for _, msg := range m.pending {
+		pending = append(pending, msg)
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	return pending, nil
+}
+
+func (m *MemoryQueueStore) Dead(_ context.Context, msg *QueuedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.pending, msg.ID)
+	m.dead = append(m.dead, *msg)
+
+	return nil
+}
+
+func (m *MemoryQueueStore) DeadLetters(_ context.Context) ([]QueuedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]QueuedMessage(nil), m.dead...), nil
+}
+
+// DBQueueStore keeps the queue in a SQLite or Postgres database
+type DBQueueStore struct {
+	db        *gorm.DB
+	table     string
+	deadTable string
+}
+
+var _ QueueStore = (*DBQueueStore)(nil)
+
+// NewDBQueueStore creates a queue store using the given database, the queue
+// and dead letter tables are created if they do not exist
+func NewDBQueueStore(db *gorm.DB, tablePrefix string) (*DBQueueStore, error) {
+	d := &DBQueueStore{
+		db:        db,
+		table:     tablePrefix + sendQueueTable,
+		deadTable: tablePrefix + sendQueueDeadTable,
+	}
+
+	if err := db.Table(d.table).AutoMigrate(&QueuedMessage{}); err != nil {
+		return nil, fmt.Errorf("migrate send queue: %w", err)
+	}
+
+	if err := db.Table(d.deadTable).AutoMigrate(&QueuedMessage{}); err != nil {
+		return nil, fmt.Errorf("migrate dead letters: %w", err)
+	}
+
+	return d, nil
+}
+
+func (d *DBQueueStore) Add(ctx context.Context, msg *QueuedMessage) error {
+	if err := d.db.WithContext(ctx).Table(d.table).Create(msg).Error; err != nil {
+		return fmt.Errorf("add queued message: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DBQueueStore) Update(ctx context.Context, msg *QueuedMessage) error {
+	err := d.db.WithContext(ctx).Table(d.table).
+		Where("id = ?", msg.ID).
+		Updates(map[string]any{
+			"attempts":   msg.Attempts,
+			"not_before": msg.NotBefore,
+			"last_error": msg.LastError,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("update queued message: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DBQueueStore) Remove(ctx context.Context, id uint64) error {
+	if err := d.db.WithContext(ctx).Table(d.table).Delete(&QueuedMessage{}, id).Error; err != nil {
+		return fmt.Errorf("remove queued message: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DBQueueStore) Pending(ctx context.Context) ([]QueuedMessage, error) {
+	var pending []QueuedMessage
+	if err := d.db.WithContext(ctx).Table(d.table).Order("id").Find(&pending).Error; err != nil {
+		return nil, fmt.Errorf("get queued messages: %w", err)
+	}
+
+	return pending, nil
+}
+
+func (d *DBQueueStore) Dead(ctx context.Context, msg *QueuedMessage) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(d.deadTable).Create(msg).Error; err != nil {
+			return err
+		}
+
+		return tx.Table(d.table).Delete(&QueuedMessage{}, msg.ID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("move message to dead letters: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DBQueueStore) DeadLetters(ctx context.Context) ([]QueuedMessage, error) {
+	var dead []QueuedMessage
+	if err := d.db.WithContext(ctx).Table(d.deadTable).Order("id").Find(&dead).Error; err != nil {
+		return nil, fmt.Errorf("get dead letters: %w", err)
+	}
+
+	return dead, nil
+}
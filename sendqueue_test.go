@@ -0,0 +1,151 @@
+package tgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSendQueueOrder(t *testing.T) {
+	q := &SendQueue{busy: make(map[int64]bool), wake: make(chan struct{}, 1)}
+
+	now := time.Now()
+	q.push(&QueuedMessage{ID: 1, ChatID: 1, Priority: PriorityLow})
+	q.push(&QueuedMessage{ID: 2, ChatID: 1, Priority: PriorityNormal})
+	q.push(&QueuedMessage{ID: 3, ChatID: 2, Priority: PriorityHigh, NotBefore: now.Add(time.Minute)})
+	q.push(&QueuedMessage{ID: 4, ChatID: 3, Priority: PriorityNormal})
+
+	// Messages that are not due yet are skipped
+	msg, _ := q.next(now)
+	require.Equal(t, uint64(2), msg.ID)
+
+	// The chat of a message in flight is skipped
+	msg, _ = q.next(now)
+	require.Equal(t, uint64(4), msg.ID)
+
+	msg, wait := q.next(now)
+	require.Nil(t, msg)
+	require.Equal(t, queueIdleWait, wait)
+
+	delete(q.busy, 1)
+	msg, _ = q.next(now)
+	require.Equal(t, uint64(1), msg.ID)
+
+	msg, _ = q.next(now.Add(2 * time.Minute))
+	require.Equal(t, uint64(3), msg.ID)
+
+	stats := q.Stats()
+	require.Zero(t, stats.Pending())
+	require.Equal(t, 3, stats.InFlight)
+}
+
+func TestSendQueueDelivery(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		sent  []string
+		fails = map[string]int{"flaky": 1}
+		dead  = make(chan QueuedMessage, 1)
+	)
+
+	send := func(ctx context.Context, chatID int64, msg Message) (*models.Message, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case msg.Text == "blocked":
+			return nil, fmt.Errorf("%w, Forbidden: bot was blocked by the user", bot.ErrorForbidden)
+		case fails[msg.Text] > 0:
+			fails[msg.Text]--
+			return nil, errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")
+		}
+
+		sent = append(sent, msg.Text)
+
+		return &models.Message{ID: len(sent)}, nil
+	}
+
+	store := NewMemoryQueueStore()
+	q, err := newSendQueue(context.Background(), slog.Default(), send, SendQueueConfig{
+		Store:      store,
+		RetryDelay: time.Millisecond,
+		OnDead:     func(msg QueuedMessage, err error) { dead <- msg },
+	})
+	require.NoError(t, err)
+	defer q.Close()
+
+	ctx := context.Background()
+	for _, text := range []string{"flaky", "blocked", "ok"} {
+		_, err := q.Enqueue(ctx, 1, Message{Text: text}, PriorityNormal)
+		require.NoError(t, err)
+	}
+
+	msg := <-dead
+	require.Equal(t, "blocked", msg.Message.Text)
+
+	require.Eventually(t, func() bool {
+		return q.Stats().Sent == 2
+	}, time.Second, time.Millisecond)
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	letters, err := q.DeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+
+	stats := q.Stats()
+	require.Equal(t, uint64(1), stats.Retried)
+	require.Equal(t, uint64(1), stats.Dead)
+}
+
+func TestDBQueueStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "queue.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	store, err := NewDBQueueStore(db, "test_")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	first := &QueuedMessage{ChatID: 1, Priority: PriorityHigh, Message: Message{Text: "first"}}
+	second := &QueuedMessage{ChatID: 2, Message: Message{Text: "second", Buttons: []InlineButton{{Text: "ok", CallbackData: "ok"}}}}
+	require.NoError(t, store.Add(ctx, first))
+	require.NoError(t, store.Add(ctx, second))
+	require.NotZero(t, first.ID)
+
+	second.Attempts = 2
+	second.LastError = "502"
+	require.NoError(t, store.Update(ctx, second))
+
+	// Pending messages are read back after a restart
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, PriorityHigh, pending[0].Priority)
+	require.Equal(t, "ok", pending[1].Message.Buttons[0].CallbackData)
+	require.Equal(t, 2, pending[1].Attempts)
+
+	require.NoError(t, store.Remove(ctx, first.ID))
+	require.NoError(t, store.Dead(ctx, second))
+
+	pending, err = store.Pending(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	dead, err := store.DeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "second", dead[0].Message.Text)
+}
@@ -0,0 +1,143 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+const shutdownPollInterval = 10 * time.Millisecond
+
+// Shutdown stops the service without dropping updates being handled. It
+// stops receiving updates, waits for the handlers running and the updates
+// queued per chat, then flushes the send pipeline and stops the scheduler,
+// like Close. With Config.DeleteWebhookOnShutdown the webhook is deleted
+// first, so Telegram holds new updates until the next start.
+//
+// From then on WebhookHandler answers 503, so Telegram delivers those
+// updates again after the restart, and the server of ListenAndServeWebhook
+// is shut down. Updates the webhook already accepted are still handled. When
+// polling, updates fetched but not yet handled are dropped.
+//
+// When ctx is done first the contexts of the handlers still running are
+// canceled and the error of ctx is returned, the service keeps stopping in
+// the background.
+func (s *Service) Shutdown(ctx context.Context) error {
+	// Stop the intake first: webhook requests are answered with 503 so
+	// Telegram keeps them, polling stops right away. The webhook mode keeps
+	// its run context until the updates it accepted are handled.
+	s.intakeClosed.Store(true)
+
+	s.runMu.Lock()
+	mode := s.runMode
+	s.runMode = runModeNone
+	if mode != runModeWebhook {
+		s.stopRun()
+	}
+	srv := s.webhookServer
+	s.runMu.Unlock()
+
+	if s.cfg.DeleteWebhookOnShutdown && mode == runModeWebhook {
+		if _, err := s.bot.DeleteWebhook(ctx, &bot.DeleteWebhookParams{
+			DropPendingUpdates: false,
+		}); err != nil {
+			s.logger.Warn("failed to delete webhook on shutdown", slog.String("err", err.Error()))
+		}
+	}
+
+	if srv != nil {
+		if err := srv.Shutdown(ctx); err != nil {
+			s.logger.Warn("webhook server shutdown", slog.String("err", err.Error()))
+		}
+	}
+
+	// Updates accepted by the webhook wait in a channel of the bot until
+	// they are handled, stopping the bot drops them
+	s.waitQueuedUpdates(ctx)
+
+	s.runMu.Lock()
+	s.stopRun()
+	s.runMu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// The chat queue runs the handlers, it is drained before counting
+		// the ones running on the update goroutine. Updates still arriving
+		// are handled inline once it is stopped.
+		if s.chatQueue != nil {
+			s.chatQueue.stop()
+		}
+
+		s.inflight.Wait()
+		s.Close()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancelHandlers()
+		return fmt.Errorf("shutdown: %w", ctx.Err())
+	}
+}
+
+// stopRun stops receiving updates, runMu must be held
+func (s *Service) stopRun() {
+	if s.runCancel != nil {
+		s.runCancel()
+		s.runCancel = nil
+	}
+}
+
+func (s *Service) waitQueuedUpdates(ctx context.Context) {
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	for s.queuedUpdates.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// inflightMiddleware counts the updates being handled for Shutdown. The
+// handlers get a context that outlives stopping the bot, and is only
+// canceled when Shutdown gives up on them.
+func (s *Service) inflightMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			s.inflight.Add(1)
+			defer s.inflight.Done()
+
+			s.dequeueUpdate()
+
+			ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+			defer cancel()
+
+			stop := context.AfterFunc(s.handlerCtx, cancel)
+			defer stop()
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+// dequeueUpdate counts down the updates accepted by the webhook, updates
+// received by polling were never counted
+func (s *Service) dequeueUpdate() {
+	for {
+		n := s.queuedUpdates.Load()
+		if n <= 0 || s.queuedUpdates.CompareAndSwap(n, n-1) {
+			return
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package tgbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestShutdown(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	newService := func() *Service {
+		s, err := NewService(logger, &Config{DryRun: NewRecorder()})
+		require.NoError(t, err)
+
+		return s
+	}
+
+	// handle runs a handler through the middleware until release is closed
+	handle := func(s *Service, release <-chan struct{}) (started <-chan struct{}, canceled <-chan error) {
+		start := make(chan struct{})
+		result := make(chan error, 1)
+
+		runCtx, stopRun := context.WithCancel(context.Background())
+
+		handler := s.inflightMiddleware()(func(ctx context.Context, _ *bot.Bot, _ *models.Update) {
+			close(start)
+
+			// Stopping the bot doesn't cancel the handler
+			stopRun()
+
+			select {
+			case <-release:
+				result <- ctx.Err()
+			case <-ctx.Done():
+				result <- ctx.Err()
+			}
+		})
+
+		go handler(runCtx, nil, &models.Update{ID: 1})
+
+		return start, result
+	}
+
+	t.Run("waits for handlers", func(t *testing.T) {
+		s := newService()
+
+		release := make(chan struct{})
+		started, result := handle(s, release)
+		<-started
+
+		done := make(chan error, 1)
+		go func() { done <- s.Shutdown(context.Background()) }()
+
+		select {
+		case <-done:
+			t.Fatal("shutdown returned while a handler was running")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+
+		require.NoError(t, <-result)
+		require.NoError(t, <-done)
+		require.Equal(t, runModeNone, s.currentMode())
+	})
+
+	t.Run("cancels handlers on timeout", func(t *testing.T) {
+		s := newService()
+
+		started, result := handle(s, make(chan struct{}))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+		require.ErrorIs(t, <-result, context.Canceled)
+	})
+
+	t.Run("waits for queued webhook updates", func(t *testing.T) {
+		s := newService()
+		s.queuedUpdates.Add(1)
+
+		done := make(chan error, 1)
+		go func() { done <- s.Shutdown(context.Background()) }()
+
+		select {
+		case <-done:
+			t.Fatal("shutdown returned with a queued update")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		release := make(chan struct{})
+		close(release)
+
+		_, result := handle(s, release)
+		require.NoError(t, <-result)
+		require.NoError(t, <-done)
+	})
+}
+
+func TestShutdownChatBacklog(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	s, err := NewService(logger, &Config{DryRun: NewRecorder(), ChatWorkers: 1})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+
+	var handled atomic.Int32
+	handler := s.chatQueueMiddleware()(s.inflightMiddleware()(func(ctx context.Context, _ *bot.Bot, _ *models.Update) {
+		<-release
+		handled.Add(1)
+	}))
+
+	// One chat with more updates than a batch
+	update := &models.Update{Message: &models.Message{Chat: models.Chat{ID: 1}}}
+	for i := 0; i < handlerBatchSize*2; i++ {
+		handler(context.Background(), nil, update)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	require.Equal(t, int32(handlerBatchSize*2), handled.Load())
+
+	// Updates arriving late are handled, the webhook rejects new ones
+	handler(context.Background(), nil, update)
+	require.Equal(t, int32(handlerBatchSize*2+1), handled.Load())
+
+	rec := httptest.NewRecorder()
+	s.WebhookHandler()(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id":1}`)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,359 @@
+package tgbot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	maxTextLength    = 4096
+	maxCaptionLength = 1024
+
+	codeFence = "```"
+)
+
+// SendSplit sends the message like SendContext and returns all sent
+// messages, long texts are split into several messages even when
+// Config.SplitLongMessages is off
+func (s *Service) SendSplit(ctx context.Context, chatID int64, msg Message) ([]*models.Message, error) {
+	// The messages are only read once the job has finished, a job abandoned
+	// on cancellation may still write them
+	var sent []*models.Message
+
+	result := s.pipeline.enqueue(chatID, func() (*models.Message, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		call := &SendCall{Op: SendOpSend, ChatID: chatID, Message: &msg}
+
+		return s.intercept(ctx, call, func(ctx context.Context, msg Message) (*models.Message, error) {
+			msgs, err := s.sendChunks(ctx, chatID, msg)
+			sent = msgs
+
+			if len(msgs) == 0 {
+				return nil, err
+			}
+
+			return msgs[0], err
+		})
+	})
+
+	select {
+	case res := <-result:
+		return sent, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sendChunks sends the message split in chunks within the length limits of
+// Telegram, the first chunk carries the media and reply, the last one the
+// buttons
+func (s *Service) sendChunks(ctx context.Context, chatID int64, msg Message) ([]*models.Message, error) {
+	msg = s.localize(chatID, msg)
+
+	chunks := splitMessage(msg)
+
+	sent := make([]*models.Message, 0, len(chunks))
+	for _, chunk := range chunks {
+		m, err := s.retry(ctx, chatID, func() (*models.Message, error) {
+			return s.send(ctx, chatID, chunk)
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		sent = append(sent, m)
+	}
+
+	return sent, nil
+}
+
+// splitMessage splits text and caption messages that exceed the length
+// limits. Messages with entities are not split, as the entity offsets would
+// no longer match.
+func splitMessage(msg Message) []Message {
+	first := maxTextLength
+
+	switch {
+	case len(msg.Entities) > 0 || len(msg.Album) > 0:
+		return []Message{msg}
+	case msg.hasMedia() || len(msg.Voice) > 0 || msg.VoiceURL != "":
+		first = maxCaptionLength
+	case msg.hasFixedMedia():
+		// Stickers, video notes, locations and contacts have no text
+		return []Message{msg}
+	}
+
+	texts := splitText(msg.Text, first, maxTextLength)
+	if len(texts) < 2 {
+		return []Message{msg}
+	}
+
+	chunks := make([]Message, 0, len(texts))
+	for i, text := range texts {
+		chunk := Message{
+			Text:                 text,
+			TextFormatting:       msg.TextFormatting,
+			ParseMode:            msg.ParseMode,
+			DisableLinkPreview:   msg.DisableLinkPreview,
+			BusinessConnectionID: msg.BusinessConnectionID,
+			ThreadID:             msg.ThreadID,
+			localized:            msg.localized,
+		}
+
+		if i == 0 {
+			chunk = msg
+			chunk.Text = text
+			chunk.Buttons, chunk.Keyboard, chunk.RemoveKeyboard = nil, nil, false
+		}
+
+		if i == len(texts)-1 {
+			chunk.Buttons, chunk.Keyboard, chunk.RemoveKeyboard = msg.Buttons, msg.Keyboard, msg.RemoveKeyboard
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// splitText splits the text in chunks of at most first characters for the
+// first chunk and rest for the others, counted in UTF-16 code units like
+// Telegram does. It splits between paragraphs where it can, then between
+// lines, words and finally anywhere. Code blocks split over two chunks are
+// closed and reopened, so each chunk stays valid Markdown.
+func splitText(text string, first, rest int) []string {
+	if textLength(text) <= first {
+		return []string{text}
+	}
+
+	c := &chunker{limit: first, rest: rest}
+
+	for i, paragraph := range paragraphs(text) {
+		sep := "\n\n"
+		if i == 0 {
+			sep = ""
+		}
+
+		if c.fits(sep, paragraph) {
+			c.add(sep, paragraph)
+			continue
+		}
+
+		// A paragraph that doesn't fit starts a new chunk either way
+		c.flush()
+
+		if c.fitsEmpty(paragraph) {
+			c.add("", paragraph)
+			continue
+		}
+
+		for j, line := range strings.Split(paragraph, "\n") {
+			lineSep := "\n"
+			if j == 0 {
+				lineSep = sep
+			}
+
+			c.addLine(lineSep, line)
+		}
+	}
+
+	return c.done()
+}
+
+// paragraphs splits the text on blank lines outside of code blocks
+func paragraphs(text string) []string {
+	var (
+		result  []string
+		current []string
+		inCode  bool
+	)
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, codeFence) {
+			inCode = !inCode
+		}
+
+		if !inCode && strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				result = append(result, strings.Join(current, "\n"))
+				current = nil
+			}
+
+			continue
+		}
+
+		current = append(current, line)
+	}
+
+	if len(current) > 0 {
+		result = append(result, strings.Join(current, "\n"))
+	}
+
+	return result
+}
+
+type chunker struct {
+	limit  int
+	rest   int
+	chunks []string
+	buf    strings.Builder
+	length int
+	// fence is the opening line of the code block open at the end of buf
+	fence string
+	// reopened is set while the chunk only holds the reopened code block
+	reopened bool
+}
+
+// fits reports whether the piece fits in the current chunk, keeping room to
+// close a code block
+func (c *chunker) fits(sep, piece string) bool {
+	return c.length+textLength(sep)+textLength(piece)+c.reserve(piece) <= c.limit
+}
+
+// fitsEmpty reports whether the piece fits in a new chunk
+func (c *chunker) fitsEmpty(piece string) bool {
+	limit := c.rest
+	if c.length == 0 {
+		limit = c.limit
+	}
+
+	reopen := 0
+	if c.fence != "" {
+		reopen = textLength(c.fence + "\n")
+	}
+
+	return reopen+textLength(piece)+c.reserve(piece) <= limit
+}
+
+// reserve is the room kept to close the code block, unless the piece
+// closes it
+func (c *chunker) reserve(piece string) int {
+	if c.fence != "" && strings.Contains(piece, codeFence) {
+		return 0
+	}
+
+	return textLength("\n" + codeFence)
+}
+
+func (c *chunker) add(sep, piece string) {
+	if c.length == 0 || c.reopened {
+		sep = ""
+	}
+	c.reopened = false
+
+	c.buf.WriteString(sep)
+	c.buf.WriteString(piece)
+	c.length += textLength(sep) + textLength(piece)
+
+	for _, line := range strings.Split(piece, "\n") {
+		if !strings.Contains(line, codeFence) {
+			continue
+		}
+
+		if c.fence != "" {
+			c.fence = ""
+		} else if strings.HasPrefix(strings.TrimSpace(line), codeFence) {
+			c.fence = strings.TrimSpace(line)
+		} else {
+			c.fence = codeFence
+		}
+	}
+}
+
+// addLine adds a line, splitting it between words when it doesn't fit in
+// a chunk of its own
+func (c *chunker) addLine(sep, line string) {
+	if c.fits(sep, line) {
+		c.add(sep, line)
+		return
+	}
+
+	if c.fitsEmpty(line) {
+		c.flush()
+		c.add("", line)
+		return
+	}
+
+	for i, word := range strings.Fields(line) {
+		wordSep := " "
+		if i == 0 {
+			wordSep = sep
+		}
+
+		if c.fits(wordSep, word) {
+			c.add(wordSep, word)
+			continue
+		}
+
+		if !c.fitsEmpty(word) {
+			// A word longer than a chunk is split anywhere
+			for _, r := range word {
+				if !c.fits(wordSep, string(r)) {
+					c.flush()
+				}
+
+				c.add(wordSep, string(r))
+				wordSep = ""
+			}
+
+			continue
+		}
+
+		c.flush()
+		c.add("", word)
+	}
+}
+
+// flush ends the current chunk, closing an open code block and reopening it
+// in the next chunk
+func (c *chunker) flush() {
+	if c.length == 0 || c.reopened {
+		return
+	}
+
+	fence := c.fence
+	if fence != "" {
+		c.buf.WriteString("\n" + codeFence)
+	}
+
+	c.chunks = append(c.chunks, c.buf.String())
+	c.buf.Reset()
+	c.length = 0
+	c.limit = c.rest
+	c.fence = ""
+
+	if fence != "" {
+		c.buf.WriteString(fence + "\n")
+		c.length = textLength(fence + "\n")
+		c.fence = fence
+		c.reopened = true
+	}
+}
+
+func (c *chunker) done() []string {
+	if c.length > 0 {
+		c.chunks = append(c.chunks, c.buf.String())
+	}
+
+	return c.chunks
+}
+
+// textLength is the length of the text as Telegram counts it, in UTF-16
+// code units
+func textLength(text string) int {
+	n := 0
+	for _, r := range text {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,68 @@
+package tgbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	require.Equal(t, []string{"short"}, splitText("short", 20, 20))
+
+	// Paragraphs are kept together
+	text := "aaaa aaaa\n\nbbbb bbbb\n\ncccc"
+	require.Equal(t, []string{"aaaa aaaa\n\nbbbb bbbb", "cccc"}, splitText(text, 25, 25))
+
+	// Long lines are split between words, long words anywhere
+	text = strings.Repeat("word ", 10) + strings.Repeat("x", 30)
+
+	chunks := splitText(text, 20, 20)
+	for _, chunk := range chunks {
+		require.LessOrEqual(t, textLength(chunk), 20)
+	}
+	require.Equal(t, strings.ReplaceAll(text, " ", ""), strings.ReplaceAll(strings.Join(chunks, ""), " ", ""))
+}
+
+func TestSplitTextCodeBlock(t *testing.T) {
+	text := "intro\n\n```go\nline one\nline two\nline three\n```"
+
+	chunks := splitText(text, 30, 30)
+	require.Equal(t, []string{
+		"intro",
+		"```go\nline one\nline two\n```",
+		"```go\nline three\n```",
+	}, chunks)
+
+	for _, chunk := range chunks {
+		require.Zero(t, strings.Count(chunk, codeFence)%2)
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	text := strings.Repeat("a", 1000) + "\n\n" + strings.Repeat("b", 1000)
+
+	msg := Message{
+		Text:     text,
+		ImageURL: "https://example.com/a.jpg",
+		ReplyTo:  5,
+		ThreadID: 3,
+		Buttons:  []InlineButton{{Text: "ok", CallbackData: "ok"}},
+	}
+
+	chunks := splitMessage(msg)
+	require.Len(t, chunks, 2)
+
+	require.Equal(t, strings.Repeat("a", 1000), chunks[0].Text)
+	require.Equal(t, "https://example.com/a.jpg", chunks[0].ImageURL)
+	require.Equal(t, 5, chunks[0].ReplyTo)
+	require.Empty(t, chunks[0].Buttons)
+
+	require.Equal(t, strings.Repeat("b", 1000), chunks[1].Text)
+	require.Empty(t, chunks[1].ImageURL)
+	require.Equal(t, 3, chunks[1].ThreadID)
+	require.Len(t, chunks[1].Buttons, 1)
+
+	// Plain text fits in one message
+	require.Len(t, splitMessage(Message{Text: text}), 1)
+}
@@ -0,0 +1,154 @@
+package tgbot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/exp/slog"
+)
+
+// staleSummaryDelay is how long the summarize policy waits for the backlog
+// to settle before reporting the dropped updates
+const staleSummaryDelay = 10 * time.Second
+
+// StaleUpdatePolicy determines what happens to updates older than Config.MaxUpdateAge
+type StaleUpdatePolicy int
+
+const (
+	// StaleDrop drops stale updates without calling any handler
+	StaleDrop StaleUpdatePolicy = iota
+	// StaleMark passes stale updates to the handlers, which can check IsStaleUpdate
+	StaleMark
+	// StaleSummarize drops stale updates and reports a summary to the admin chat
+	StaleSummarize
+)
+
+type staleKey struct{}
+
+// IsStaleUpdate returns true if the update being handled is older than
+// Config.MaxUpdateAge, only set with the StaleMark policy.
+func IsStaleUpdate(ctx context.Context) bool {
+	stale, _ := ctx.Value(staleKey{}).(bool)
+	return stale
+}
+
+// UpdateDate returns the time the update was created, or the zero time if
+// the update carries no date, e.g. callback queries.
+func UpdateDate(update *models.Update) time.Time {
+	var date int
+
+	if msg := UpdateMessage(update); msg != nil {
+		date = msg.Date
+		if msg.EditDate > 0 {
+			date = msg.EditDate
+		}
+	} else {
+		switch {
+		case update.MessageReaction != nil:
+			date = update.MessageReaction.Date
+		case update.MessageReactionCount != nil:
+			date = update.MessageReactionCount.Date
+		case update.ChatJoinRequest != nil:
+			date = update.ChatJoinRequest.Date
+		case update.ChatMember != nil:
+			date = update.ChatMember.Date
+		case update.MyChatMember != nil:
+			date = update.MyChatMember.Date
+		}
+	}
+
+	if date == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(date), 0)
+}
+
+// staleSummary collects the dropped updates until the backlog settled
+type staleSummary struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	count  int
+	chats  map[int64]struct{}
+	oldest time.Time
+}
+
+func (s *Service) staleMiddleware() bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if s.cfg.MaxUpdateAge <= 0 {
+				next(ctx, b, update)
+				return
+			}
+
+			date := UpdateDate(update)
+			if date.IsZero() || time.Since(date) <= s.cfg.MaxUpdateAge {
+				next(ctx, b, update)
+				return
+			}
+
+			switch s.cfg.StaleUpdatePolicy {
+			case StaleMark:
+				next(context.WithValue(ctx, staleKey{}, true), b, update)
+			case StaleSummarize:
+				s.recordStale(update, date)
+			default:
+				s.logger.Debug("dropped stale update",
+					slog.Int64("update", update.ID),
+					slog.Time("date", date),
+				)
+			}
+		}
+	}
+}
+
+func (s *Service) recordStale(update *models.Update, date time.Time) {
+	summary := &s.staleSummary
+
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+
+	if summary.chats == nil {
+		summary.chats = make(map[int64]struct{})
+	}
+
+	summary.count++
+	summary.chats[UpdateChatID(update)] = struct{}{}
+	if summary.oldest.IsZero() || date.Before(summary.oldest) {
+		summary.oldest = date
+	}
+
+	if summary.timer != nil {
+		summary.timer.Reset(staleSummaryDelay)
+		return
+	}
+
+	summary.timer = time.AfterFunc(staleSummaryDelay, s.reportStale)
+}
+
+func (s *Service) reportStale() {
+	summary := &s.staleSummary
+
+	summary.mu.Lock()
+	count, chats, oldest := summary.count, len(summary.chats), summary.oldest
+	summary.count = 0
+	summary.chats = nil
+	summary.oldest = time.Time{}
+	summary.timer = nil
+	summary.mu.Unlock()
+
+	s.logger.Warn("dropped stale updates",
+		slog.Int("updates", count),
+		slog.Int("chats", chats),
+		slog.Time("oldest", oldest),
+	)
+
+	s.notifyAdminAsync(slog.LevelWarn, "Dropped stale updates",
+		slog.Int("updates", count),
+		slog.Int("chats", chats),
+		slog.Duration("oldest", time.Since(oldest).Round(time.Second)),
+	)
+}
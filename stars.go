@@ -0,0 +1,168 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// maxStarTransactions is the page size limit of getStarTransactions
+const maxStarTransactions = 100
+
+// StarAmount is an amount of Telegram Stars, NanostarAmount holds the
+// fraction in units of one billionth of a star
+type StarAmount struct {
+	Amount         int `json:"amount"`
+	NanostarAmount int `json:"nanostar_amount,omitempty"`
+}
+
+// StarTransaction is a Telegram Stars transaction of the bot
+type StarTransaction struct {
+	ID string `json:"id"`
+	// Amount is positive for incoming and negative for outgoing transactions,
+	// like refunds and withdrawals
+	Amount         int       `json:"amount"`
+	NanostarAmount int       `json:"nanostar_amount,omitempty"`
+	Date           time.Time `json:"date"`
+	// Partner is the type of the other party, e.g. "user", "fragment" or
+	// "telegram_ads"
+	Partner  string `json:"partner"`
+	UserID   int64  `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Payload is the invoice payload of payments by users
+	Payload string `json:"payload,omitempty"`
+}
+
+var starTransactionExportHeader = []string{"id", "date", "amount", "nanostar_amount", "partner", "user_id", "username", "payload"}
+
+// GetStarBalance returns the current Telegram Stars balance of the bot
+func (s *Service) GetStarBalance(ctx context.Context) (StarAmount, error) {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var balance StarAmount
+	if err := s.apiRequest(ctx, "getMyStarBalance", nil, nil, &balance); err != nil {
+		return StarAmount{}, fmt.Errorf("get star balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// GetStarTransactions returns a page of the Telegram Stars transactions of
+// the bot, oldest first. The limit is capped at 100.
+func (s *Service) GetStarTransactions(ctx context.Context, offset, limit int) ([]StarTransaction, error) {
+	if limit <= 0 || limit > maxStarTransactions {
+		limit = maxStarTransactions
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// The transactions are decoded here instead of through the library, which
+	// fails on transaction partner types it doesn't know
+	var result struct {
+		Transactions []starTransactionResult `json:"transactions"`
+	}
+
+	fields := map[string]string{
+		"offset": strconv.Itoa(offset),
+		"limit":  strconv.Itoa(limit),
+	}
+
+	if err := s.apiRequest(ctx, "getStarTransactions", fields, nil, &result); err != nil {
+		return nil, fmt.Errorf("get star transactions: %w", err)
+	}
+
+	transactions := make([]StarTransaction, 0, len(result.Transactions))
+	for _, tx := range result.Transactions {
+		transactions = append(transactions, tx.transaction())
+	}
+
+	return transactions, nil
+}
+
+// AllStarTransactions pages through all Telegram Stars transactions and
+// returns the ones at or after since, a zero since returns all of them
+func (s *Service) AllStarTransactions(ctx context.Context, since time.Time) ([]StarTransaction, error) {
+	var all []StarTransaction
+
+	for offset := 0; ; offset += maxStarTransactions {
+		page, err := s.GetStarTransactions(ctx, offset, maxStarTransactions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range page {
+			if since.IsZero() || !tx.Date.Before(since) {
+				all = append(all, tx)
+			}
+		}
+
+		if len(page) < maxStarTransactions {
+			return all, nil
+		}
+	}
+}
+
+// ExportStarTransactions writes the transactions as CSV or NDJSON
+func ExportStarTransactions(w io.Writer, format ExportFormat, transactions []StarTransaction) error {
+	return writeExport(w, format, starTransactionExportHeader, transactions, func(tx StarTransaction) []string {
+		var userID string
+		if tx.UserID != 0 {
+			userID = strconv.FormatInt(tx.UserID, 10)
+		}
+
+		return []string{
+			tx.ID, formatExportTime(tx.Date), strconv.Itoa(tx.Amount), strconv.Itoa(tx.NanostarAmount),
+			tx.Partner, userID, tx.Username, tx.Payload,
+		}
+	})
+}
+
+type starTransactionResult struct {
+	ID             string                    `json:"id"`
+	Amount         int                       `json:"amount"`
+	NanostarAmount int                       `json:"nanostar_amount"`
+	Date           int64                     `json:"date"`
+	Source         *transactionPartnerResult `json:"source"`
+	Receiver       *transactionPartnerResult `json:"receiver"`
+}
+
+type transactionPartnerResult struct {
+	Type           string       `json:"type"`
+	User           *models.User `json:"user"`
+	InvoicePayload string       `json:"invoice_payload"`
+}
+
+func (r starTransactionResult) transaction() StarTransaction {
+	tx := StarTransaction{
+		ID:             r.ID,
+		Amount:         r.Amount,
+		NanostarAmount: r.NanostarAmount,
+		Date:           time.Unix(r.Date, 0).UTC(),
+	}
+
+	// Incoming transactions have a source, outgoing ones a receiver
+	partner := r.Source
+	if partner == nil {
+		partner = r.Receiver
+		tx.Amount = -tx.Amount
+		tx.NanostarAmount = -tx.NanostarAmount
+	}
+
+	if partner != nil {
+		tx.Partner = partner.Type
+		tx.Payload = partner.InvoicePayload
+
+		if partner.User != nil {
+			tx.UserID = partner.User.ID
+			tx.Username = partner.User.Username
+		}
+	}
+
+	return tx
+}
@@ -0,0 +1,43 @@
+package tgbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStarTransactions(t *testing.T) {
+	data := `{"transactions": [
+		{"id": "a", "amount": 50, "date": 1714564800, "source": {"type": "user", "user": {"id": 7, "username": "ann"}, "invoice_payload": "order-1"}},
+		{"id": "b", "amount": 50, "date": 1714568400, "receiver": {"type": "user", "user": {"id": 7, "username": "ann"}}},
+		{"id": "c", "amount": 900, "date": 1714572000, "receiver": {"type": "affiliate_program", "sponsor_user": {"id": 9}}}
+	]}`
+
+	var result struct {
+		Transactions []starTransactionResult `json:"transactions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(data), &result))
+
+	var transactions []StarTransaction
+	for _, tx := range result.Transactions {
+		transactions = append(transactions, tx.transaction())
+	}
+
+	require.Equal(t, 50, transactions[0].Amount)
+	require.Equal(t, "order-1", transactions[0].Payload)
+	require.Equal(t, int64(7), transactions[0].UserID)
+	require.Equal(t, -50, transactions[1].Amount)
+	require.Equal(t, "affiliate_program", transactions[2].Partner)
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportStarTransactions(&buf, ExportCSV, transactions))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "id,date,amount,nanostar_amount,partner,user_id,username,payload", lines[0])
+	require.Equal(t, "a,2024-05-01T12:00:00Z,50,0,user,7,ann,order-1", lines[1])
+	require.Equal(t, "c,2024-05-01T14:00:00Z,-900,0,affiliate_program,,,", lines[3])
+}
@@ -0,0 +1,279 @@
+package tgbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Sticker file formats
+const (
+	StickerStatic   = "static"
+	StickerAnimated = "animated"
+	StickerVideo    = "video"
+)
+
+// Sticker set types
+const (
+	StickerSetRegular     = "regular"
+	StickerSetMask        = "mask"
+	StickerSetCustomEmoji = "custom_emoji"
+)
+
+const maxSetStickers = 50
+
+var (
+	ErrStickerSetSize = fmt.Errorf("a new sticker set needs 1 to %d stickers", maxSetStickers)
+	ErrStickerEmoji   = errors.New("sticker needs at least one emoji")
+)
+
+// StickerInput is a sticker to add to a set, set either the file ID of an
+// uploaded sticker or the data to upload
+type StickerInput struct {
+	FileID string
+	Data   []byte
+	// Format is detected from the data when empty
+	Format string
+	// Emoji are the 1 to 20 emoji the sticker is associated with
+	Emoji    []string
+	Keywords []string
+}
+
+// StickerSetConfig describes a new sticker set. The name must end with
+// "_by_<bot username>".
+type StickerSetConfig struct {
+	Name  string
+	Title string
+	// Type defaults to StickerSetRegular
+	Type     string
+	Stickers []StickerInput
+}
+
+// UploadStickerFile uploads a sticker file for later use in a sticker set and
+// returns the uploaded file. The format is detected when empty.
+func (s *Service) UploadStickerFile(ctx context.Context, userID int64, data []byte, format string) (*models.File, error) {
+	if format == "" {
+		format = detectStickerFormat(data)
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var file models.File
+	err := s.apiRequest(ctx, "uploadStickerFile", map[string]string{
+		"user_id":        fmt.Sprint(userID),
+		"sticker_format": format,
+	}, map[string]*models.InputFileUpload{
+		"sticker": {Filename: "sticker", Data: bytes.NewReader(data)},
+	}, &file)
+	if err != nil {
+		return nil, fmt.Errorf("upload sticker file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// CreateStickerSet creates a sticker set owned by the user, stickers with data
+// are uploaded first
+func (s *Service) CreateStickerSet(ctx context.Context, userID int64, cfg StickerSetConfig) error {
+	if len(cfg.Stickers) == 0 || len(cfg.Stickers) > maxSetStickers {
+		return ErrStickerSetSize
+	}
+
+	if cfg.Type == "" {
+		cfg.Type = StickerSetRegular
+	}
+
+	stickers := make([]models.InputSticker, 0, len(cfg.Stickers))
+	for _, sticker := range cfg.Stickers {
+		input, err := s.inputSticker(ctx, userID, sticker)
+		if err != nil {
+			return err
+		}
+
+		stickers = append(stickers, input)
+	}
+
+	encoded, err := json.Marshal(stickers)
+	if err != nil {
+		return fmt.Errorf("encode stickers: %w", err)
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var ok bool
+	err = s.apiRequest(ctx, "createNewStickerSet", map[string]string{
+		"user_id":      fmt.Sprint(userID),
+		"name":         cfg.Name,
+		"title":        cfg.Title,
+		"sticker_type": cfg.Type,
+		"stickers":     string(encoded),
+	}, nil, &ok)
+	if err != nil {
+		return fmt.Errorf("create sticker set: %w", err)
+	}
+
+	return nil
+}
+
+// AddStickerToSet adds a sticker to a set created by the bot
+func (s *Service) AddStickerToSet(ctx context.Context, userID int64, name string, sticker StickerInput) error {
+	input, err := s.inputSticker(ctx, userID, sticker)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.AddStickerToSet(ctx, &bot.AddStickerToSetParams{
+		UserID:  userID,
+		Name:    name,
+		Sticker: input,
+	}); err != nil {
+		return fmt.Errorf("add sticker to set: %w", err)
+	}
+
+	return nil
+}
+
+// GetStickerSet returns a sticker set by name
+func (s *Service) GetStickerSet(ctx context.Context, name string) (*models.StickerSet, error) {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	set, err := s.bot.GetStickerSet(ctx, &bot.GetStickerSetParams{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("get sticker set: %w", err)
+	}
+
+	return set, nil
+}
+
+// DeleteStickerFromSet removes a sticker from a set created by the bot
+func (s *Service) DeleteStickerFromSet(ctx context.Context, fileID string) error {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.DeleteStickerFromSet(ctx, &bot.DeleteStickerFromSetParams{Sticker: fileID}); err != nil {
+		return fmt.Errorf("delete sticker from set: %w", err)
+	}
+
+	return nil
+}
+
+// inputSticker converts the sticker, uploading its data if there is no file ID
+func (s *Service) inputSticker(ctx context.Context, userID int64, sticker StickerInput) (models.InputSticker, error) {
+	if len(sticker.Emoji) == 0 {
+		return models.InputSticker{}, ErrStickerEmoji
+	}
+
+	format := sticker.Format
+	if format == "" {
+		format = detectStickerFormat(sticker.Data)
+	}
+
+	fileID := sticker.FileID
+	if fileID == "" {
+		file, err := s.UploadStickerFile(ctx, userID, sticker.Data, format)
+		if err != nil {
+			return models.InputSticker{}, err
+		}
+
+		fileID = file.FileID
+	}
+
+	return models.InputSticker{
+		Sticker:   &models.InputFileString{Data: fileID},
+		Format:    format,
+		EmojiList: sticker.Emoji,
+		Keywords:  sticker.Keywords,
+	}, nil
+}
+
+// detectStickerFormat guesses the format from the file header, animated
+// stickers are gzipped Lottie files and video stickers WebM
+func detectStickerFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return StickerAnimated
+	case bytes.HasPrefix(data, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		return StickerVideo
+	default:
+		return StickerStatic
+	}
+}
+
+// apiRequest calls a Bot API method directly, for methods where the params of
+// the bot library are out of date
+func (s *Service) apiRequest(ctx context.Context, method string, fields map[string]string, files map[string]*models.InputFileUpload, result any) error {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	for name, file := range files {
+		part, err := form.CreateFormFile(name, file.Filename)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(part, file.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL(ctx, method), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var apiResp struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		Description string          `json:"description"`
+		ErrorCode   int             `json:"error_code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	if !apiResp.OK {
+		return fmt.Errorf("error response from telegram for method %s, %d %s", method, apiResp.ErrorCode, apiResp.Description)
+	}
+
+	return json.Unmarshal(apiResp.Result, result)
+}
+
+func (s *Service) apiURL(ctx context.Context, method string) string {
+	if s.cfg.UseTestEnvironment {
+		return fmt.Sprintf("%s/bot%s/test/%s", s.apiServerURL(), s.botToken(ctx), method)
+	}
+
+	return fmt.Sprintf("%s/bot%s/%s", s.apiServerURL(), s.botToken(ctx), method)
+}
@@ -0,0 +1,37 @@
+package tgbot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectStickerFormat(t *testing.T) {
+	require.Equal(t, StickerAnimated, detectStickerFormat([]byte{0x1f, 0x8b, 0x08}))
+	require.Equal(t, StickerVideo, detectStickerFormat([]byte{0x1a, 0x45, 0xdf, 0xa3, 0x01}))
+	require.Equal(t, StickerStatic, detectStickerFormat([]byte("RIFF....WEBP")))
+	require.Equal(t, StickerStatic, detectStickerFormat(nil))
+}
+
+func TestStickerValidation(t *testing.T) {
+	s := &Service{cfg: &Config{}}
+
+	err := s.CreateStickerSet(context.Background(), 1, StickerSetConfig{Name: "set_by_bot"})
+	require.ErrorIs(t, err, ErrStickerSetSize)
+
+	_, err = s.inputSticker(context.Background(), 1, StickerInput{FileID: "abc"})
+	require.ErrorIs(t, err, ErrStickerEmoji)
+
+	input, err := s.inputSticker(context.Background(), 1, StickerInput{FileID: "abc", Format: StickerVideo, Emoji: []string{"😀"}})
+	require.NoError(t, err)
+	require.Equal(t, StickerVideo, input.Format)
+	require.Equal(t, []string{"😀"}, input.EmojiList)
+}
+
+func TestMessageHasSticker(t *testing.T) {
+	require.True(t, Message{StickerFileID: "abc"}.hasSticker())
+	require.True(t, Message{Sticker: []byte("data")}.hasSticker())
+	require.False(t, Message{Text: "hi"}.hasSticker())
+	require.False(t, Message{StickerFileID: "abc"}.hasMedia())
+}
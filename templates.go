@@ -0,0 +1,233 @@
+package tgbot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"text/template"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrTemplateNotFound is returned when a template is registered in neither
+// the requested nor the default language
+var ErrTemplateNotFound = errors.New("template not found")
+
+// MessageTemplate is a message with text/template placeholders in its text
+// and in the text, callback data and URLs of its buttons
+type MessageTemplate struct {
+	Text    string
+	Buttons []InlineButton
+	// TextFormatting and ParseMode are copied to the rendered message
+	TextFormatting bool
+	ParseMode      ParseMode
+}
+
+// Templates holds named message templates per language. A template is
+// rendered in the requested language, falling back from e.g. pt-br to pt and
+// then to the default language.
+type Templates struct {
+	mu              sync.RWMutex
+	defaultLanguage string
+	// templates are keyed by name and language code
+	templates map[string]map[string]*compiledTemplate
+}
+
+type compiledTemplate struct {
+	source MessageTemplate
+	text   *template.Template
+	// buttons holds the templates of the text, callback data and URL of
+	// every button, rows flattened in order
+	buttons []*template.Template
+}
+
+// NewTemplates creates an empty template registry, fallback is the default
+// language and defaults to en
+func NewTemplates(fallback string) *Templates {
+	if fallback == "" {
+		fallback = defaultLanguage
+	}
+
+	return &Templates{
+		defaultLanguage: fallback,
+		templates:       make(map[string]map[string]*compiledTemplate),
+	}
+}
+
+// Register adds the template under name for the language, replacing an
+// earlier one. The template is parsed right away so mistakes surface at
+// startup.
+func (t *Templates) Register(languageCode, name string, tmpl MessageTemplate) error {
+	compiled := &compiledTemplate{source: tmpl}
+
+	var err error
+	if compiled.text, err = template.New(name).Parse(tmpl.Text); err != nil {
+		return fmt.Errorf("parse template %s (%s): %w", name, languageCode, err)
+	}
+
+	for _, button := range flattenButtons(tmpl.Buttons) {
+		for _, field := range []string{button.Text, button.CallbackData, button.URL} {
+			parsed, err := template.New(name).Parse(field)
+			if err != nil {
+				return fmt.Errorf("parse button of template %s (%s): %w", name, languageCode, err)
+			}
+
+			compiled.buttons = append(compiled.buttons, parsed)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.templates[name] == nil {
+		t.templates[name] = make(map[string]*compiledTemplate)
+	}
+	t.templates[name][languageCode] = compiled
+
+	return nil
+}
+
+// MustRegister is Register panicking on an invalid template, for templates
+// defined in code
+func (t *Templates) MustRegister(languageCode, name string, tmpl MessageTemplate) {
+	if err := t.Register(languageCode, name, tmpl); err != nil {
+		panic(err)
+	}
+}
+
+// Has reports whether the template is registered for exactly the language
+func (t *Templates) Has(languageCode, name string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	_, ok := t.templates[name][languageCode]
+	return ok
+}
+
+// Render renders the template in the language with data into a message
+func (t *Templates) Render(languageCode, name string, data any) (Message, error) {
+	compiled, ok := t.lookup(languageCode, name)
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	return compiled.render(data)
+}
+
+// RenderUpdate renders the template in the language of the user of the
+// update, see Render
+func (t *Templates) RenderUpdate(update *models.Update, name string, data any) (Message, error) {
+	var languageCode string
+	if user := UpdateUser(update); user != nil {
+		languageCode = user.LanguageCode
+	}
+
+	return t.Render(languageCode, name, data)
+}
+
+func (t *Templates) lookup(languageCode, name string) (*compiledTemplate, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	byLanguage := t.templates[name]
+
+	for _, lang := range []string{languageCode, baseLanguage(languageCode), t.defaultLanguage} {
+		if compiled, ok := byLanguage[lang]; ok && lang != "" {
+			return compiled, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c *compiledTemplate) render(data any) (Message, error) {
+	text, err := execute(c.text, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{
+		Text:           text,
+		TextFormatting: c.source.TextFormatting,
+		ParseMode:      c.source.ParseMode,
+	}
+
+	next := c.buttons
+	renderButton := func(button InlineButton) (InlineButton, error) {
+		fields := []*string{&button.Text, &button.CallbackData, &button.URL}
+		for i, field := range fields {
+			if *field, err = execute(next[i], data); err != nil {
+				return button, err
+			}
+		}
+
+		next = next[len(fields):]
+
+		return button, nil
+	}
+
+	for _, button := range c.source.Buttons {
+		if len(button.Row) == 0 {
+			rendered, err := renderButton(button)
+			if err != nil {
+				return Message{}, err
+			}
+
+			msg.Buttons = append(msg.Buttons, rendered)
+			continue
+		}
+
+		row := make([]InlineButton, 0, len(button.Row))
+		for _, b := range button.Row {
+			rendered, err := renderButton(b)
+			if err != nil {
+				return Message{}, err
+			}
+
+			row = append(row, rendered)
+		}
+
+		msg.Buttons = append(msg.Buttons, InlineButton{Row: row})
+	}
+
+	return msg, nil
+}
+
+func execute(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render template %s: %w", tmpl.Name(), err)
+	}
+
+	return buf.String(), nil
+}
+
+// flattenButtons returns the buttons with rows expanded in order
+func flattenButtons(buttons []InlineButton) []InlineButton {
+	var flat []InlineButton
+	for _, button := range buttons {
+		if len(button.Row) > 0 {
+			flat = append(flat, button.Row...)
+		} else {
+			flat = append(flat, button)
+		}
+	}
+
+	return flat
+}
+
+// Render renders a template of Config.Templates in the language of the chat,
+// the stored locale falling back to DefaultLanguage
+func (s *Service) Render(chatID int64, name string, data any) (Message, error) {
+	if s.cfg.Templates == nil {
+		return Message{}, fmt.Errorf("%w: no templates configured", ErrTemplateNotFound)
+	}
+
+	languageCode := s.Locale(chatID).LanguageCode
+	if languageCode == "" {
+		languageCode = s.defaultLanguage()
+	}
+
+	return s.cfg.Templates.Render(languageCode, name, data)
+}
@@ -0,0 +1,74 @@
+package tgbot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates(t *testing.T) {
+	templates := NewTemplates("")
+
+	templates.MustRegister("en", "order", MessageTemplate{
+		Text: "Order {{.ID}} shipped",
+		Buttons: []InlineButton{
+			{Text: "Track", URL: "https://example.com/{{.ID}}"},
+			{Row: []InlineButton{
+				{Text: "Cancel", CallbackData: "cancel:{{.ID}}"},
+				{Text: "Help", CallbackData: "help"},
+			}},
+		},
+		TextFormatting: true,
+	})
+	templates.MustRegister("pt", "order", MessageTemplate{Text: "Pedido {{.ID}} enviado"})
+
+	data := map[string]any{"ID": 42}
+
+	msg, err := templates.Render("en", "order", data)
+	require.NoError(t, err)
+	require.Equal(t, "Order 42 shipped", msg.Text)
+	require.True(t, msg.TextFormatting)
+	require.Len(t, msg.Buttons, 2)
+	require.Equal(t, "https://example.com/42", msg.Buttons[0].URL)
+	require.Equal(t, "cancel:42", msg.Buttons[1].Row[0].CallbackData)
+	require.Equal(t, "help", msg.Buttons[1].Row[1].CallbackData)
+
+	// Regional languages fall back to the base language, unknown ones to
+	// the default language
+	msg, err = templates.Render("pt-br", "order", data)
+	require.NoError(t, err)
+	require.Equal(t, "Pedido 42 enviado", msg.Text)
+
+	msg, err = templates.RenderUpdate(&models.Update{Message: &models.Message{
+		From: &models.User{ID: 1, LanguageCode: "de"},
+	}}, "order", data)
+	require.NoError(t, err)
+	require.Equal(t, "Order 42 shipped", msg.Text)
+
+	_, err = templates.Render("en", "missing", nil)
+	require.True(t, errors.Is(err, ErrTemplateNotFound))
+
+	require.Error(t, templates.Register("en", "broken", MessageTemplate{Text: "{{.ID"}))
+	require.False(t, templates.Has("en", "broken"))
+}
+
+func TestServiceRender(t *testing.T) {
+	locales := NewMemoryLocaleStore()
+	require.NoError(t, locales.SetLocale(1, Locale{LanguageCode: "nl"}))
+
+	templates := NewTemplates("")
+	templates.MustRegister("en", "hello", MessageTemplate{Text: "Hello {{.}}"})
+	templates.MustRegister("nl", "hello", MessageTemplate{Text: "Hallo {{.}}"})
+
+	s := &Service{cfg: &Config{Locales: locales, Templates: templates}}
+
+	msg, err := s.Render(1, "hello", "Ann")
+	require.NoError(t, err)
+	require.Equal(t, "Hallo Ann", msg.Text)
+
+	msg, err = s.Render(2, "hello", "Ann")
+	require.NoError(t, err)
+	require.Equal(t, "Hello Ann", msg.Text)
+}
@@ -0,0 +1,163 @@
+package tgbot
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Entity types the bot library has no constant for
+const (
+	entityTypeSpoiler    models.MessageEntityType = "spoiler"
+	entityTypeBlockquote models.MessageEntityType = "blockquote"
+)
+
+// TextBuilder composes a formatted text as plain text with entities, so no
+// markdown or HTML has to be escaped. Offsets are counted in UTF-16 code
+// units like Telegram does.
+//
+//	msg := tgbot.NewText().Bold("Order ").Code(id).Plain(" shipped, ").
+//		Link("track it", url).Message()
+type TextBuilder struct {
+	text     strings.Builder
+	length   int
+	entities []models.MessageEntity
+}
+
+// NewText creates an empty TextBuilder
+func NewText() *TextBuilder {
+	return &TextBuilder{}
+}
+
+// Plain appends unformatted text
+func (b *TextBuilder) Plain(text string) *TextBuilder {
+	b.text.WriteString(text)
+	b.length += textLength(text)
+
+	return b
+}
+
+// Line appends text followed by a newline
+func (b *TextBuilder) Line(text string) *TextBuilder {
+	return b.Plain(text + "\n")
+}
+
+// Format appends text with all given formats, e.g. bold and italic
+func (b *TextBuilder) Format(text string, types ...models.MessageEntityType) *TextBuilder {
+	entities := make([]models.MessageEntity, 0, len(types))
+	for _, t := range types {
+		entities = append(entities, models.MessageEntity{Type: t})
+	}
+
+	return b.append(text, entities...)
+}
+
+// Bold appends bold text
+func (b *TextBuilder) Bold(text string) *TextBuilder {
+	return b.Format(text, models.MessageEntityTypeBold)
+}
+
+// Italic appends italic text
+func (b *TextBuilder) Italic(text string) *TextBuilder {
+	return b.Format(text, models.MessageEntityTypeItalic)
+}
+
+// Underline appends underlined text
+func (b *TextBuilder) Underline(text string) *TextBuilder {
+	return b.Format(text, models.MessageEntityTypeUnderline)
+}
+
+// Strikethrough appends crossed out text
+func (b *TextBuilder) Strikethrough(text string) *TextBuilder {
+	return b.Format(text, models.MessageEntityTypeStrikethrough)
+}
+
+// Spoiler appends text hidden as a spoiler
+func (b *TextBuilder) Spoiler(text string) *TextBuilder {
+	return b.Format(text, entityTypeSpoiler)
+}
+
+// Blockquote appends a quote
+func (b *TextBuilder) Blockquote(text string) *TextBuilder {
+	return b.Format(text, entityTypeBlockquote)
+}
+
+// Code appends inline monospace text
+func (b *TextBuilder) Code(text string) *TextBuilder {
+	return b.Format(text, models.MessageEntityTypeCode)
+}
+
+// Pre appends a code block, language may be empty
+func (b *TextBuilder) Pre(text, language string) *TextBuilder {
+	return b.append(text, models.MessageEntity{Type: models.MessageEntityTypePre, Language: language})
+}
+
+// Link appends text linking to the URL
+func (b *TextBuilder) Link(text, url string) *TextBuilder {
+	return b.append(text, models.MessageEntity{Type: models.MessageEntityTypeTextLink, URL: url})
+}
+
+// Mention appends text mentioning a user, for users without a username
+func (b *TextBuilder) Mention(text string, userID int64) *TextBuilder {
+	return b.append(text, models.MessageEntity{Type: models.MessageEntityTypeTextMention, User: &models.User{ID: userID}})
+}
+
+// CustomEmoji appends a custom emoji, emoji is shown where custom emoji are
+// not supported
+func (b *TextBuilder) CustomEmoji(emoji string, id int64) *TextBuilder {
+	return b.append(emoji, models.MessageEntity{
+		Type:          models.MessageEntityTypeCustomEmoji,
+		CustomEmojiID: strconv.FormatInt(id, 10),
+	})
+}
+
+// append appends text covered by the entities
+func (b *TextBuilder) append(text string, entities ...models.MessageEntity) *TextBuilder {
+	b.span(func() { b.Plain(text) }, entities...)
+
+	return b
+}
+
+// span covers everything write appends with the entities, so entities can
+// nest. Empty spans have no entities as Telegram rejects empty entities.
+func (b *TextBuilder) span(write func(), entities ...models.MessageEntity) {
+	offset, inner := b.length, len(b.entities)
+	write()
+
+	length := b.length - offset
+	if length == 0 {
+		return
+	}
+
+	// The entities go before the nested ones, so outer entities come first
+	outer := make([]models.MessageEntity, 0, len(entities))
+	for _, e := range entities {
+		e.Offset, e.Length = offset, length
+		outer = append(outer, e)
+	}
+
+	b.entities = slices.Insert(b.entities, inner, outer...)
+}
+
+// String returns the plain text
+func (b *TextBuilder) String() string {
+	return b.text.String()
+}
+
+// Entities returns the entities of the text ordered by offset
+func (b *TextBuilder) Entities() []models.MessageEntity {
+	entities := append([]models.MessageEntity(nil), b.entities...)
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Offset < entities[j].Offset })
+
+	return entities
+}
+
+// Message returns a message with the text and its entities, set the other
+// fields on the result. Messages with entities are sent without parse mode
+// and are not split by Config.SplitLongMessages.
+func (b *TextBuilder) Message() Message {
+	return Message{Text: b.String(), Entities: b.Entities()}
+}
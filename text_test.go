@@ -0,0 +1,32 @@
+package tgbot
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextBuilder(t *testing.T) {
+	// The emoji takes two UTF-16 code units
+	msg := NewText().
+		Plain("😀 ").
+		Bold("bold").
+		Plain(" ").
+		Link("a.b", "https://example.com").
+		Bold("").
+		Format("x", models.MessageEntityTypeBold, models.MessageEntityTypeItalic).
+		Message()
+
+	require.Equal(t, "😀 bold a.bx", msg.Text)
+	require.Equal(t, []models.MessageEntity{
+		{Type: models.MessageEntityTypeBold, Offset: 3, Length: 4},
+		{Type: models.MessageEntityTypeTextLink, Offset: 8, Length: 3, URL: "https://example.com"},
+		{Type: models.MessageEntityTypeBold, Offset: 11, Length: 1},
+		{Type: models.MessageEntityTypeItalic, Offset: 11, Length: 1},
+	}, msg.Entities)
+
+	// The text is sent as it is, without parse mode
+	require.Equal(t, msg.Text, msg.escapeText())
+	require.Equal(t, models.ParseMode(""), msg.parseMode())
+}
@@ -0,0 +1,95 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Forum topic icon colors, the only colors Telegram accepts
+const (
+	TopicColorBlue   = 0x6FB9F0
+	TopicColorYellow = 0xFFD67E
+	TopicColorViolet = 0xCB86DB
+	TopicColorGreen  = 0x8EEE98
+	TopicColorRose   = 0xFF93B2
+	TopicColorRed    = 0xFB6F5F
+)
+
+// ForumTopicConfig describes a new forum topic
+type ForumTopicConfig struct {
+	Name string
+	// IconColor is one of the TopicColor constants, defaults to blue
+	IconColor int
+	// IconCustomEmojiID sets a custom emoji as the icon
+	IconCustomEmojiID string
+}
+
+// CreateForumTopic creates a topic in a forum supergroup, the bot needs the
+// can_manage_topics right. Send to it with the returned MessageThreadID.
+func (s *Service) CreateForumTopic(ctx context.Context, chatID int64, cfg ForumTopicConfig) (*models.ForumTopic, error) {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	topic, err := s.bot.CreateForumTopic(ctx, &bot.CreateForumTopicParams{
+		ChatID:            chatID,
+		Name:              cfg.Name,
+		IconColor:         cfg.IconColor,
+		IconCustomEmojiID: cfg.IconCustomEmojiID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create forum topic: %w", err)
+	}
+
+	return topic, nil
+}
+
+// EditForumTopic renames a topic or changes its icon, empty values are left as is
+func (s *Service) EditForumTopic(ctx context.Context, chatID int64, threadID int, name, iconCustomEmojiID string) error {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.EditForumTopic(ctx, &bot.EditForumTopicParams{
+		ChatID:            chatID,
+		MessageThreadID:   threadID,
+		Name:              name,
+		IconCustomEmojiID: iconCustomEmojiID,
+	}); err != nil {
+		return fmt.Errorf("edit forum topic: %w", err)
+	}
+
+	return nil
+}
+
+// CloseForumTopic closes a topic, only admins can post in closed topics
+func (s *Service) CloseForumTopic(ctx context.Context, chatID int64, threadID int) error {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.CloseForumTopic(ctx, &bot.CloseForumTopicParams{
+		ChatID:          chatID,
+		MessageThreadID: threadID,
+	}); err != nil {
+		return fmt.Errorf("close forum topic: %w", err)
+	}
+
+	return nil
+}
+
+// ReopenForumTopic reopens a closed topic
+func (s *Service) ReopenForumTopic(ctx context.Context, chatID int64, threadID int) error {
+	ctx, cancel := withDefaultTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.bot.ReopenForumTopic(ctx, &bot.ReopenForumTopicParams{
+		ChatID:          chatID,
+		MessageThreadID: threadID,
+	}); err != nil {
+		return fmt.Errorf("reopen forum topic: %w", err)
+	}
+
+	return nil
+}
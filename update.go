@@ -0,0 +1,127 @@
+package tgbot
+
+import (
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// UpdateMessage returns the message carried by the update, regardless of
+// whether it is a regular, edited, channel or business message.
+func UpdateMessage(update *models.Update) *models.Message {
+	switch {
+	case update == nil:
+		return nil
+	case update.Message != nil:
+		return update.Message
+	case update.EditedMessage != nil:
+		return update.EditedMessage
+	case update.ChannelPost != nil:
+		return update.ChannelPost
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost
+	case update.BusinessMessage != nil:
+		return update.BusinessMessage
+	case update.EditedBusinessMessage != nil:
+		return update.EditedBusinessMessage
+	}
+
+	return nil
+}
+
+// UpdateUser returns the user that triggered the update, or nil if the
+// update has no user, e.g. channel posts.
+func UpdateUser(update *models.Update) *models.User {
+	if msg := UpdateMessage(update); msg != nil {
+		return msg.From
+	}
+
+	switch {
+	case update == nil:
+		return nil
+	case update.CallbackQuery != nil:
+		return &update.CallbackQuery.From
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From
+	case update.ChosenInlineResult != nil:
+		return &update.ChosenInlineResult.From
+	case update.MessageReaction != nil:
+		return update.MessageReaction.User
+	case update.PollAnswer != nil:
+		return update.PollAnswer.User
+	case update.ShippingQuery != nil:
+		return update.ShippingQuery.From
+	case update.PreCheckoutQuery != nil:
+		return update.PreCheckoutQuery.From
+	case update.ChatJoinRequest != nil:
+		return &update.ChatJoinRequest.From
+	case update.ChatMember != nil:
+		return &update.ChatMember.From
+	case update.MyChatMember != nil:
+		return &update.MyChatMember.From
+	}
+
+	return nil
+}
+
+// UpdateChatID returns the ID of the chat the update belongs to, or zero if
+// the update is not bound to a chat.
+func UpdateChatID(update *models.Update) int64 {
+	if msg := UpdateMessage(update); msg != nil {
+		return msg.Chat.ID
+	}
+
+	switch {
+	case update == nil:
+		return 0
+	case update.CallbackQuery != nil:
+		if msg := update.CallbackQuery.Message.Message; msg != nil {
+			return msg.Chat.ID
+		}
+		if msg := update.CallbackQuery.Message.InaccessibleMessage; msg != nil {
+			return msg.Chat.ID
+		}
+		return update.CallbackQuery.From.ID
+	case update.MessageReaction != nil:
+		return update.MessageReaction.Chat.ID
+	case update.MessageReactionCount != nil:
+		return update.MessageReactionCount.Chat.ID
+	case update.ChatJoinRequest != nil:
+		return update.ChatJoinRequest.Chat.ID
+	case update.ChatMember != nil:
+		return update.ChatMember.Chat.ID
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID
+	case update.ChatBoost != nil:
+		return update.ChatBoost.Chat.ID
+	case update.RemovedChatBoost != nil:
+		return update.RemovedChatBoost.Chat.ID
+	}
+
+	if user := UpdateUser(update); user != nil {
+		return user.ID
+	}
+
+	return 0
+}
+
+// UpdateThreadID returns the forum topic of the update message, or zero if
+// the message is not in a topic. Pass it as Message.ThreadID to reply in the
+// same topic.
+func UpdateThreadID(update *models.Update) int {
+	msg := UpdateMessage(update)
+	if msg == nil && update != nil && update.CallbackQuery != nil {
+		msg = update.CallbackQuery.Message.Message
+	}
+
+	if msg == nil || !msg.IsTopicMessage {
+		return 0
+	}
+
+	return msg.MessageThreadID
+}
+
+// isCommand returns true if the text is a bot command
+func isCommand(text string) bool {
+	return strings.HasPrefix(text, "/")
+}
@@ -0,0 +1,21 @@
+package tgbot
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateThreadID(t *testing.T) {
+	topic := &models.Message{MessageThreadID: 4, IsTopicMessage: true}
+	require.Equal(t, 4, UpdateThreadID(&models.Update{Message: topic}))
+
+	// Replies in regular groups carry a thread ID without being in a topic
+	reply := &models.Message{MessageThreadID: 4}
+	require.Zero(t, UpdateThreadID(&models.Update{Message: reply}))
+
+	query := &models.CallbackQuery{Message: models.MaybeInaccessibleMessage{Message: topic}}
+	require.Equal(t, 4, UpdateThreadID(&models.Update{CallbackQuery: query}))
+	require.Zero(t, UpdateThreadID(nil))
+}
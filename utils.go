@@ -34,6 +34,52 @@ func getParseMode(textFormatting bool) models.ParseMode {
 	return models.ParseModeMarkdown
 }
 
+// convertMarkdown converts the text of ParseModeCommonMark messages to
+// entities
+func (m Message) convertMarkdown() Message {
+	if m.ParseMode != ParseModeCommonMark || len(m.Entities) > 0 || m.converted {
+		return m
+	}
+
+	text := ParseMarkdown(m.Text)
+	m.Text, m.Entities = text.String(), text.Entities()
+	m.converted = true
+
+	return m
+}
+
+// parseMode returns the parse mode of the Bot API for the message, messages
+// with entities have none
+func (m Message) parseMode() models.ParseMode {
+	switch {
+	case len(m.Entities) > 0:
+		return ""
+	case m.ParseMode == ParseModeHTML:
+		return models.ParseModeHTML
+	case m.ParseMode == ParseModeNone, m.ParseMode == ParseModeCommonMark:
+		return ""
+	default:
+		return getParseMode(m.TextFormatting)
+	}
+}
+
+// escapeText escapes the text of the message for its parse mode, the text
+// of messages with entities is not escaped as that would move the entities
+func (m Message) escapeText() string {
+	switch {
+	case len(m.Entities) > 0, m.ParseMode == ParseModeNone, m.ParseMode == ParseModeCommonMark:
+		return m.Text
+	case m.ParseMode == ParseModeHTML:
+		if m.TextFormatting {
+			return m.Text
+		}
+
+		return EscapeHTML(m.Text)
+	default:
+		return EscapeMarkdown(m.Text, m.TextFormatting)
+	}
+}
+
 func createInlineKeyboard(msg Message) any {
 	switch {
 	case len(msg.Buttons) > 0:
@@ -123,13 +169,28 @@ var httpClient = &http.Client{
 	Timeout: time.Second * 20,
 }
 
-func (s *Service) downloadFile(url string) ([]byte, error) {
-	file, ok := s.fileCache.Get(url)
-	if ok {
+func (s *Service) downloadFile(ctx context.Context, url string) ([]byte, error) {
+	if file, ok := s.cachedFile(ctx, url); ok {
 		return file, nil
 	}
 
-	resp, err := httpClient.Get(url)
+	body, err := fetchURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheFile(ctx, url, body)
+
+	return body, nil
+}
+
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -145,8 +206,6 @@ func (s *Service) downloadFile(url string) ([]byte, error) {
 		return nil, fmt.Errorf("received status code %d from server: %s", resp.StatusCode, body)
 	}
 
-	s.fileCache.Set(url, body)
-
 	return body, nil
 }
 
@@ -159,3 +218,41 @@ func createWebAppInfo(url string) *models.WebAppInfo {
 		URL: url,
 	}
 }
+
+// messageText returns the text of the message, or its caption for media
+func messageText(msg *models.Message) string {
+	if len(msg.Text) > 0 {
+		return msg.Text
+	}
+
+	return msg.Caption
+}
+
+// userDisplayName returns the @username of the user, or the full name if
+// the user has no username
+func userDisplayName(user *models.User) string {
+	if len(user.Username) > 0 {
+		return "@" + user.Username
+	}
+
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// truncate shortens the text to n runes
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+
+	return string(runes[:n]) + "…"
+}
+
+// withDefaultTimeout applies the timeout unless the context already has a deadline
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
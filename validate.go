@@ -0,0 +1,148 @@
+package tgbot
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrInvalidMessage is returned by ValidateMessage, wrapping the problems
+// found
+var ErrInvalidMessage = errors.New("invalid message")
+
+// ValidateMessage checks a message against the limits of Telegram before it
+// is sent, so callers can show users what to fix instead of a Bad Request.
+// All problems are reported at once, joined in an error wrapping
+// ErrInvalidMessage. Texts over the length limit also match
+// ErrMessageTooLong, Send splits them when Config.SplitLongMessages is set,
+// and oversized callback data matches ErrCallbackDataTooLong.
+//
+// The length of texts with TextFormatting is not checked, Telegram counts
+// them without their markup.
+func ValidateMessage(msg Message) error {
+	msg = msg.convertMarkdown()
+
+	var problems []error
+
+	problems = append(problems, validateText(msg)...)
+	problems = append(problems, validateButtons(msg.Buttons)...)
+	problems = append(problems, validateEntities(msg.Text, msg.Entities)...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidMessage, errors.Join(problems...))
+	}
+
+	return nil
+}
+
+func validateText(msg Message) []error {
+	// These messages have no text or caption
+	if msg.hasSticker() || len(msg.VideoNote) > 0 || msg.VideoNoteURL != "" ||
+		msg.Location != nil || msg.Venue != nil || msg.Contact != nil {
+		return nil
+	}
+
+	caption := msg.hasMedia() || len(msg.Album) > 0 || len(msg.Voice) > 0 || msg.VoiceURL != ""
+
+	if !caption && msg.Text == "" && len(msg.Localized) == 0 {
+		return []error{errors.New("message has no text or media")}
+	}
+
+	if msg.TextFormatting && len(msg.Entities) == 0 {
+		return nil
+	}
+
+	limit, kind := maxTextLength, "text"
+	if caption {
+		limit, kind = maxCaptionLength, "caption"
+	}
+
+	if n := textLength(msg.Text); n > limit {
+		return []error{fmt.Errorf("%w: %s is %d characters, at most %d", ErrMessageTooLong, kind, n, limit)}
+	}
+
+	return nil
+}
+
+func validateButtons(buttons []InlineButton) []error {
+	var problems []error
+
+	for i, button := range flattenButtons(buttons) {
+		name := fmt.Sprintf("button %d (%q)", i+1, button.Text)
+
+		if button.Text == "" {
+			problems = append(problems, fmt.Errorf("button %d has no text", i+1))
+		}
+
+		actions := 0
+		for _, action := range []string{button.CallbackData, button.URL, button.WebAppURL} {
+			if action != "" {
+				actions++
+			}
+		}
+
+		if actions != 1 {
+			problems = append(problems, fmt.Errorf("%s needs exactly one of callback data, URL or web app URL", name))
+		}
+
+		if len(button.CallbackData) > maxCallbackData {
+			problems = append(problems, fmt.Errorf("%s: %w", name, ErrCallbackDataTooLong))
+		}
+
+		if button.URL != "" && !validURL(button.URL, "http", "https", "tg") {
+			problems = append(problems, fmt.Errorf("%s has an invalid URL %q", name, button.URL))
+		}
+
+		if button.WebAppURL != "" && !validURL(button.WebAppURL, "https") {
+			problems = append(problems, fmt.Errorf("%s has an invalid web app URL %q, it must be https", name, button.WebAppURL))
+		}
+	}
+
+	return problems
+}
+
+func validateEntities(text string, entities []models.MessageEntity) []error {
+	var problems []error
+
+	length := textLength(text)
+
+	for i, entity := range entities {
+		name := fmt.Sprintf("entity %d (%s)", i+1, entity.Type)
+
+		if entity.Offset < 0 || entity.Length <= 0 || entity.Offset+entity.Length > length {
+			problems = append(problems, fmt.Errorf("%s covers %d+%d, outside the text of %d characters",
+				name, entity.Offset, entity.Length, length))
+		}
+
+		switch entity.Type {
+		case models.MessageEntityTypeTextLink:
+			if !validURL(entity.URL, "http", "https", "tg") {
+				problems = append(problems, fmt.Errorf("%s has an invalid URL %q", name, entity.URL))
+			}
+		case models.MessageEntityTypeTextMention:
+			if entity.User == nil {
+				problems = append(problems, fmt.Errorf("%s has no user", name))
+			}
+		case models.MessageEntityTypeCustomEmoji:
+			if entity.CustomEmojiID == "" {
+				problems = append(problems, fmt.Errorf("%s has no custom emoji ID", name))
+			}
+		}
+	}
+
+	return problems
+}
+
+// validURL reports whether the URL is absolute with one of the schemes,
+// tg://resolve?domain=name links have resolve as host
+func validURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(schemes, u.Scheme) && u.Host != ""
+}
@@ -0,0 +1,70 @@
+package tgbot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMessage(t *testing.T) {
+	require.NoError(t, ValidateMessage(Message{
+		Text: "hello",
+		Buttons: []InlineButton{
+			{Text: "Open", URL: "https://example.com"},
+			{Row: []InlineButton{
+				{Text: "Yes", CallbackData: "yes"},
+				{Text: "Chat", URL: "tg://resolve?domain=example"},
+			}},
+		},
+	}))
+
+	err := ValidateMessage(Message{Text: strings.Repeat("a", 4097)})
+	require.True(t, errors.Is(err, ErrInvalidMessage))
+	require.True(t, errors.Is(err, ErrMessageTooLong))
+
+	// Captions have a lower limit
+	err = ValidateMessage(Message{Text: strings.Repeat("a", 1025), ImageURL: "https://example.com/a.jpg"})
+	require.True(t, errors.Is(err, ErrMessageTooLong))
+	require.Contains(t, err.Error(), "caption")
+
+	// Emoji count as two characters
+	require.Error(t, ValidateMessage(Message{Text: strings.Repeat("😀", 2049)}))
+	require.NoError(t, ValidateMessage(Message{Text: strings.Repeat("😀", 2048)}))
+
+	require.Error(t, ValidateMessage(Message{}))
+	require.NoError(t, ValidateMessage(Message{StickerFileID: "sticker"}))
+
+	// All problems are reported at once
+	err = ValidateMessage(Message{
+		Text: "hello",
+		Buttons: []InlineButton{
+			{Text: "Long", CallbackData: strings.Repeat("x", 65)},
+			{Text: "Link", URL: "example.com"},
+			{Text: "App", WebAppURL: "http://example.com"},
+			{Text: "None"},
+		},
+	})
+	require.True(t, errors.Is(err, ErrInvalidMessage))
+	require.False(t, errors.Is(err, ErrMessageTooLong))
+	require.True(t, errors.Is(err, ErrCallbackDataTooLong))
+	require.Contains(t, err.Error(), `invalid URL "example.com"`)
+	require.Contains(t, err.Error(), "must be https")
+	require.Contains(t, err.Error(), `("None") needs exactly one`)
+
+	err = ValidateMessage(Message{
+		Text: "hello",
+		Entities: []models.MessageEntity{
+			{Type: models.MessageEntityTypeBold, Offset: 3, Length: 5},
+			{Type: models.MessageEntityTypeTextLink, Offset: 0, Length: 5},
+		},
+	})
+	require.Contains(t, err.Error(), "outside the text of 5 characters")
+	require.Contains(t, err.Error(), `invalid URL ""`)
+
+	// Markdown is validated after conversion
+	require.NoError(t, ValidateMessage(Message{Text: "**bold** [link](https://example.com)", ParseMode: ParseModeCommonMark}))
+	require.NoError(t, ValidateMessage(NewText().Bold("hi").Link("there", "https://example.com").Message()))
+}
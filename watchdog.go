@@ -0,0 +1,208 @@
+package tgbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	defaultWatchdogTimeout  = 10 * time.Minute
+	defaultWatchdogInterval = time.Minute
+	defaultWatchdogRetry    = 15 * time.Minute
+)
+
+// WatchdogConfig configures the webhook watchdog, which falls back to polling
+// when the webhook stops delivering updates and restores it afterwards.
+type WatchdogConfig struct {
+	// Enabled turns on the watchdog, only used in webhook mode
+	Enabled bool
+	// Timeout without webhook updates after which the webhook is verified,
+	// defaults to 10 minutes
+	Timeout time.Duration
+	// Interval between two watchdog checks, defaults to a minute
+	Interval time.Duration
+	// RetryInterval after which the watchdog tries to restore the webhook
+	// while polling, defaults to 15 minutes
+	RetryInterval time.Duration
+}
+
+type runMode int
+
+const (
+	runModeNone runMode = iota
+	runModeWebhook
+	runModePolling
+)
+
+func (m runMode) String() string {
+	switch m {
+	case runModeWebhook:
+		return "webhook"
+	case runModePolling:
+		return "polling"
+	default:
+		return "none"
+	}
+}
+
+// run starts receiving updates in the given mode, stopping the current mode
+func (s *Service) run(mode runMode) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	// Shutdown stops the intake for good, the watchdog can't restart it
+	if mode != runModeNone && s.intakeClosed.Load() {
+		return
+	}
+
+	if s.runCancel != nil {
+		s.runCancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.runCancel = cancel
+	s.runMode = mode
+
+	switch mode {
+	case runModeWebhook:
+		s.lastWebhookUpdate.Store(time.Now().UnixNano())
+		go s.bot.StartWebhook(ctx)
+	case runModePolling:
+		go s.bot.Start(ctx)
+	}
+}
+
+func (s *Service) currentMode() runMode {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	return s.runMode
+}
+
+func (s *Service) watchWebhook() {
+	cfg := s.cfg.WebhookWatchdog
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultWatchdogTimeout
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultWatchdogInterval
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultWatchdogRetry
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	var fallbackSince time.Time
+
+	for {
+		select {
+		case <-s.closeCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		switch s.currentMode() {
+		case runModeNone:
+			return
+		case runModeWebhook:
+			last := time.Unix(0, s.lastWebhookUpdate.Load())
+			if time.Since(last) < cfg.Timeout {
+				continue
+			}
+
+			if err := s.checkWebhook(cfg.Timeout); err == nil {
+				// Nobody talked to the bot, but the webhook itself is fine
+				s.lastWebhookUpdate.Store(time.Now().UnixNano())
+				continue
+			} else if err := s.fallbackToPolling(err); err != nil {
+				s.logger.Error("webhook fallback failed",
+					slog.String("err", err.Error()),
+					slog.String("bot", s.username),
+				)
+				continue
+			}
+
+			fallbackSince = time.Now()
+		case runModePolling:
+			if time.Since(fallbackSince) < cfg.RetryInterval {
+				continue
+			}
+
+			if err := s.restoreWebhook(); err != nil {
+				s.logger.Error("failed to restore webhook",
+					slog.String("err", err.Error()),
+					slog.String("bot", s.username),
+				)
+				fallbackSince = time.Now()
+			}
+		}
+	}
+}
+
+// checkWebhook verifies the webhook through getWebhookInfo
+func (s *Service) checkWebhook(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	info, err := s.bot.GetWebhookInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("get webhook info: %w", err)
+	}
+
+	if info.URL != s.cfg.WebhookURL {
+		return fmt.Errorf("webhook URL is %q", info.URL)
+	}
+
+	if info.LastErrorDate > 0 && time.Since(time.Unix(int64(info.LastErrorDate), 0)) < timeout {
+		return fmt.Errorf("webhook delivery error: %s", info.LastErrorMessage)
+	}
+
+	if info.PendingUpdateCount > 0 {
+		return fmt.Errorf("%d updates pending delivery", info.PendingUpdateCount)
+	}
+
+	return nil
+}
+
+func (s *Service) fallbackToPolling(reason error) error {
+	s.logger.Warn("webhook unhealthy, falling back to polling",
+		slog.String("reason", reason.Error()),
+		slog.String("bot", s.username),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	if _, err := s.bot.DeleteWebhook(ctx, &bot.DeleteWebhookParams{
+		DropPendingUpdates: false,
+	}); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+
+	s.run(runModePolling)
+
+	s.notifyAdminAsync(slog.LevelWarn, "Webhook unhealthy, fell back to polling",
+		slog.String("reason", reason.Error()),
+	)
+
+	return nil
+}
+
+func (s *Service) restoreWebhook() error {
+	if err := s.setupWebhook(); err != nil {
+		return err
+	}
+
+	s.run(runModeWebhook)
+
+	s.logger.Info("webhook restored", slog.String("bot", s.username))
+	s.notifyAdminAsync(slog.LevelInfo, "Webhook restored")
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package tgbot
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestWatchdogStopsOnClose(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	s, err := NewService(logger, &Config{
+		DryRun:          NewRecorder(),
+		WebhookWatchdog: WatchdogConfig{Interval: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	// The watchdog keeps running while the webhook is healthy
+	s.run(runModeWebhook)
+	t.Cleanup(func() { s.run(runModeNone) })
+
+	done := make(chan struct{})
+	go func() {
+		s.watchWebhook()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("watchdog stopped before Close")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watchdog still running after Close")
+	}
+}
@@ -0,0 +1,185 @@
+package tgbot
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// defaultWebhookMaxBody is the default size limit of webhook requests,
+// Telegram updates are far smaller
+const defaultWebhookMaxBody = 1 << 20
+
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookRejectReason is the reason a webhook request was rejected
+type WebhookRejectReason string
+
+const (
+	WebhookRejectMethod      WebhookRejectReason = "method"
+	WebhookRejectSecret      WebhookRejectReason = "secret"
+	WebhookRejectContentType WebhookRejectReason = "content_type"
+	WebhookRejectTooLarge    WebhookRejectReason = "too_large"
+	WebhookRejectInvalidJSON WebhookRejectReason = "invalid_json"
+	// WebhookRejectShutdown is answered with 503 during Shutdown, so
+	// Telegram delivers the update again after the restart
+	WebhookRejectShutdown WebhookRejectReason = "shutdown"
+)
+
+// WebhookRejection describes a rejected webhook request
+type WebhookRejection struct {
+	Reason WebhookRejectReason
+	// Status is the HTTP status the request was answered with
+	Status int
+	Err    error
+}
+
+// WebhookStats holds the counts of accepted and rejected webhook requests
+type WebhookStats struct {
+	Accepted uint64
+	Rejected map[WebhookRejectReason]uint64
+}
+
+// WebhookHandler returns the handler receiving updates from Telegram. It
+// rejects requests with another method, a wrong secret token, a content
+// type other than JSON, a body over Config.WebhookMaxBody or a body that is
+// not an update, before they reach the bot library.
+func (s *Service) WebhookHandler() http.HandlerFunc {
+	handler := s.bot.WebhookHandler()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.intakeClosed.Load() {
+			s.rejectWebhook(w, r, &WebhookRejection{Reason: WebhookRejectShutdown, Status: http.StatusServiceUnavailable})
+			return
+		}
+
+		body, rejection := s.verifyWebhookRequest(w, r)
+		if rejection != nil {
+			s.rejectWebhook(w, r, rejection)
+			return
+		}
+
+		s.webhookStats.accepted.Add(1)
+		s.lastWebhookUpdate.Store(time.Now().UnixNano())
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		s.queuedUpdates.Add(1)
+		handler(w, r)
+
+		// The bot drops the update when the request ends before it is queued
+		if r.Context().Err() != nil {
+			s.dequeueUpdate()
+		}
+	}
+}
+
+// WebhookStats returns the counts of accepted and rejected webhook requests
+func (s *Service) WebhookStats() WebhookStats {
+	return s.webhookStats.stats()
+}
+
+func (s *Service) verifyWebhookRequest(w http.ResponseWriter, r *http.Request) ([]byte, *WebhookRejection) {
+	if r.Method != http.MethodPost {
+		return nil, &WebhookRejection{Reason: WebhookRejectMethod, Status: http.StatusMethodNotAllowed}
+	}
+
+	secret := s.cfg.WebhookSecret
+	if secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(secret)) != 1 {
+		return nil, &WebhookRejection{Reason: WebhookRejectSecret, Status: http.StatusUnauthorized}
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return nil, &WebhookRejection{
+			Reason: WebhookRejectContentType,
+			Status: http.StatusUnsupportedMediaType,
+			Err:    fmt.Errorf("content type %q", r.Header.Get("Content-Type")),
+		}
+	}
+
+	limit := s.cfg.WebhookMaxBody
+	if limit <= 0 {
+		limit = defaultWebhookMaxBody
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &WebhookRejection{Reason: WebhookRejectTooLarge, Status: http.StatusRequestEntityTooLarge, Err: err}
+		}
+
+		return nil, &WebhookRejection{Reason: WebhookRejectInvalidJSON, Status: http.StatusBadRequest, Err: fmt.Errorf("read body: %w", err)}
+	}
+
+	var update struct {
+		UpdateID *int64 `json:"update_id"`
+	}
+	if err := json.Unmarshal(body, &update); err != nil {
+		return nil, &WebhookRejection{Reason: WebhookRejectInvalidJSON, Status: http.StatusBadRequest, Err: err}
+	}
+
+	if update.UpdateID == nil {
+		return nil, &WebhookRejection{Reason: WebhookRejectInvalidJSON, Status: http.StatusBadRequest, Err: errors.New("missing update_id")}
+	}
+
+	return body, nil
+}
+
+func (s *Service) rejectWebhook(w http.ResponseWriter, r *http.Request, rejection *WebhookRejection) {
+	s.webhookStats.reject(rejection.Reason)
+
+	attrs := []any{
+		slog.String("reason", string(rejection.Reason)),
+		slog.String("remote", r.RemoteAddr),
+	}
+	if rejection.Err != nil {
+		attrs = append(attrs, slog.String("err", rejection.Err.Error()))
+	}
+	s.logger.Warn("rejected webhook request", attrs...)
+
+	if s.cfg.OnWebhookReject != nil {
+		s.cfg.OnWebhookReject(r, *rejection)
+	}
+
+	http.Error(w, http.StatusText(rejection.Status), rejection.Status)
+}
+
+type webhookStats struct {
+	accepted atomic.Uint64
+
+	mu       sync.Mutex
+	rejected map[WebhookRejectReason]uint64
+}
+
+func (w *webhookStats) reject(reason WebhookRejectReason) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.rejected == nil {
+		w.rejected = make(map[WebhookRejectReason]uint64)
+	}
+
+	w.rejected[reason]++
+}
+
+func (w *webhookStats) stats() WebhookStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rejected := make(map[WebhookRejectReason]uint64, len(w.rejected))
+	for reason, n := range w.rejected {
+		rejected[reason] = n
+	}
+
+	return WebhookStats{Accepted: w.accepted.Load(), Rejected: rejected}
+}
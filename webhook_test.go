@@ -0,0 +1,63 @@
+package tgbot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestVerifyWebhookRequest(t *testing.T) {
+	var rejections []WebhookRejection
+	s := &Service{
+		cfg: &Config{
+			WebhookSecret:  "secret",
+			WebhookMaxBody: 64,
+			OnWebhookReject: func(r *http.Request, rejection WebhookRejection) {
+				rejections = append(rejections, rejection)
+			},
+		},
+		logger: slog.Default(),
+	}
+
+	request := func(method, contentType, secret, body string) *http.Request {
+		r := httptest.NewRequest(method, "/webhook", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set(webhookSecretHeader, secret)
+		return r
+	}
+
+	tests := []struct {
+		req    *http.Request
+		reason WebhookRejectReason
+		status int
+	}{
+		{request(http.MethodGet, "application/json", "secret", ""), WebhookRejectMethod, http.StatusMethodNotAllowed},
+		{request(http.MethodPost, "application/json", "wrong", `{"update_id":1}`), WebhookRejectSecret, http.StatusUnauthorized},
+		{request(http.MethodPost, "text/plain", "secret", `{"update_id":1}`), WebhookRejectContentType, http.StatusUnsupportedMediaType},
+		{request(http.MethodPost, "application/json", "secret", `{"update_id":1,"message":{"text":"`+strings.Repeat("a", 64)+`"}}`), WebhookRejectTooLarge, http.StatusRequestEntityTooLarge},
+		{request(http.MethodPost, "application/json", "secret", `{"update_id":`), WebhookRejectInvalidJSON, http.StatusBadRequest},
+		{request(http.MethodPost, "application/json", "secret", `{"message":{}}`), WebhookRejectInvalidJSON, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		_, rejection := s.verifyWebhookRequest(w, tt.req)
+		require.NotNil(t, rejection, tt.reason)
+		require.Equal(t, tt.reason, rejection.Reason)
+		require.Equal(t, tt.status, rejection.Status)
+
+		s.rejectWebhook(w, tt.req, rejection)
+		require.Equal(t, tt.status, w.Code)
+	}
+
+	require.Len(t, rejections, len(tests))
+	require.Equal(t, uint64(2), s.WebhookStats().Rejected[WebhookRejectInvalidJSON])
+
+	body, rejection := s.verifyWebhookRequest(httptest.NewRecorder(), request(http.MethodPost, "application/json; charset=utf-8", "secret", `{"update_id":1}`))
+	require.Nil(t, rejection)
+	require.Equal(t, `{"update_id":1}`, string(body))
+}
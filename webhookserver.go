@@ -0,0 +1,175 @@
+package tgbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	defaultWebhookHealthPath = "/healthz"
+	webhookShutdownTimeout   = 10 * time.Second
+)
+
+// WebhookHealth is the answer of the health endpoint of the webhook server
+type WebhookHealth struct {
+	Status string `json:"status"`
+	// Mode is webhook, or polling while the watchdog fell back to it
+	Mode       string     `json:"mode"`
+	LastUpdate *time.Time `json:"lastUpdate,omitempty"`
+	Accepted   uint64     `json:"accepted"`
+	Rejected   uint64     `json:"rejected"`
+}
+
+// ListenAndServeWebhook runs an HTTP server receiving the updates of the
+// webhook at the path of Config.WebhookURL, with TLS when certFile and
+// keyFile are set. Requests are checked by WebhookHandler, so a wrong
+// X-Telegram-Bot-Api-Secret-Token is rejected. The server also answers the
+// health endpoint of Config.WebhookHealthPath and, with
+// Config.SendAPIToken, POST /send.
+//
+// Telegram only delivers to ports 443, 80, 88 and 8443. For a self-signed
+// certificate set Config.WebhookCertificate to certFile so it is uploaded
+// with the webhook.
+func (s *Service) ListenAndServeWebhook(addr, certFile, keyFile string) error {
+	return s.ListenAndServeWebhookContext(context.Background(), addr, certFile, keyFile)
+}
+
+// ListenAndServeWebhookContext is ListenAndServeWebhook shutting down
+// gracefully when the context is canceled, requests in flight get 10 seconds
+// to finish
+func (s *Service) ListenAndServeWebhookContext(ctx context.Context, addr, certFile, keyFile string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	return s.serveWebhook(ctx, listener, certFile, keyFile)
+}
+
+func (s *Service) serveWebhook(ctx context.Context, listener net.Listener, certFile, keyFile string) error {
+	if !s.cfg.UseWebhook {
+		listener.Close()
+		return errors.New("webhook server needs UseWebhook")
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		listener.Close()
+		return errors.New("webhook server needs both certFile and keyFile for TLS")
+	}
+
+	mux, err := s.webhookMux()
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.runMu.Lock()
+	s.webhookServer = srv
+	s.runMu.Unlock()
+
+	defer func() {
+		s.runMu.Lock()
+		s.webhookServer = nil
+		s.runMu.Unlock()
+	}()
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+			defer cancel()
+
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				s.logger.Warn("webhook server shutdown", slog.String("err", err.Error()))
+			}
+		case <-stopped:
+		}
+	}()
+
+	s.logger.Info("webhook server listening", slog.String("addr", listener.Addr().String()))
+
+	if certFile != "" {
+		err = srv.ServeTLS(listener, certFile, keyFile)
+	} else {
+		err = srv.Serve(listener)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *Service) webhookMux() (*http.ServeMux, error) {
+	path := "/"
+	if u, err := url.Parse(s.cfg.WebhookURL); err == nil && u.Path != "" {
+		path = u.Path
+	}
+
+	health := s.cfg.WebhookHealthPath
+	if health == "" {
+		health = defaultWebhookHealthPath
+	}
+
+	if path == health || (path == "/send" && s.cfg.SendAPIToken != "") {
+		return nil, fmt.Errorf("webhook path %s is taken by the health endpoint or the send API", path)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, s.WebhookHandler())
+	mux.HandleFunc(health, s.webhookHealthHandler)
+
+	if s.cfg.SendAPIToken != "" {
+		mux.Handle("/send", s.SendHandler())
+	}
+
+	return mux, nil
+}
+
+// webhookHealthHandler reports 200 while updates are received by webhook
+// or polling, 503 otherwise
+func (s *Service) webhookHealthHandler(w http.ResponseWriter, r *http.Request) {
+	stats := s.WebhookStats()
+
+	health := WebhookHealth{
+		Status:   "ok",
+		Mode:     s.currentMode().String(),
+		Accepted: stats.Accepted,
+	}
+
+	for _, n := range stats.Rejected {
+		health.Rejected += n
+	}
+
+	if last := s.lastWebhookUpdate.Load(); last > 0 {
+		t := time.Unix(0, last).UTC()
+		health.LastUpdate = &t
+	}
+
+	status := http.StatusOK
+	if s.currentMode() == runModeNone {
+		health.Status = "stopped"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(health)
+}
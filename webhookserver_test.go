@@ -0,0 +1,75 @@
+package tgbot
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestWebhookServer(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	s, err := NewService(logger, &Config{
+		DryRun:        NewRecorder(),
+		UseWebhook:    true,
+		WebhookURL:    "https://example.com/tg/webhook",
+		WebhookSecret: "secret",
+	})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	s.run(runModeWebhook)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.serveWebhook(ctx, listener, "", "") }()
+
+	base := "http://" + listener.Addr().String()
+
+	post := func(secret string) int {
+		req, err := http.NewRequest(http.MethodPost, base+"/tg/webhook", strings.NewReader(`{"update_id":1}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(webhookSecretHeader, secret)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, post("secret"))
+	require.Equal(t, http.StatusUnauthorized, post("wrong"))
+
+	resp, err := http.Get(base + "/healthz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var health WebhookHealth
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "webhook", health.Mode)
+	require.Equal(t, uint64(1), health.Accepted)
+	require.Equal(t, uint64(1), health.Rejected)
+	require.NotNil(t, health.LastUpdate)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// Servers need webhook mode
+	s.cfg.UseWebhook = false
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.Error(t, s.serveWebhook(context.Background(), listener, "", ""))
+}